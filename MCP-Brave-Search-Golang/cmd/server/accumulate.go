@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxPendingMessageBytes bounds how much of an incomplete message is held
+// while waiting for the rest of it on later lines
+const maxPendingMessageBytes = 1 << 20
+
+// messageAccumulator joins a JSON message split across several lines by a
+// client that doesn't strictly newline-delimit its messages. A line that is
+// the start of a JSON value but not all of it is held and joined with the
+// following lines until the message is complete.
+type messageAccumulator struct {
+	pending string
+}
+
+// Add takes the next line and returns the message to process, with false
+// while a message is still incomplete. Lines that can't be the start of a
+// JSON message are returned as they are, to be reported as parse errors.
+func (a *messageAccumulator) Add(line string) (string, bool) {
+	if a.pending == "" {
+		if isIncompleteJSON(line) {
+			a.pending = line
+			return "", false
+		}
+		return line, true
+	}
+
+	combined := a.pending + line
+	switch {
+	case json.Valid([]byte(combined)):
+		a.pending = ""
+		return combined, true
+	case isIncompleteJSON(combined):
+		if len(combined) > maxPendingMessageBytes {
+			fmt.Fprintf(os.Stderr, "Discarding incomplete message over %d bytes\n", maxPendingMessageBytes)
+			a.pending = ""
+			return "", false
+		}
+		a.pending = combined
+		return "", false
+	default:
+		// The held fragment was never going to parse; start again from this line
+		fmt.Fprintf(os.Stderr, "Discarding incomplete message of %d bytes\n", len(a.pending))
+		a.pending = ""
+		return a.Add(line)
+	}
+}
+
+// isIncompleteJSON reports whether text is the start of a JSON value that
+// ends before the value does
+func isIncompleteJSON(text string) bool {
+	var value json.RawMessage
+	err := json.NewDecoder(strings.NewReader(text)).Decode(&value)
+	return errors.Is(err, io.ErrUnexpectedEOF)
+}
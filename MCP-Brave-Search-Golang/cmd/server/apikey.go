@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// apiKeyPattern matches the shape of a Brave Search API key
+var apiKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{16,128}$`)
+
+// apiKeyFieldPattern finds api_key values in raw JSON so they can be redacted from logs
+var apiKeyFieldPattern = regexp.MustCompile(`("api_key"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// errInvalidAPIKey is returned when an override key is malformed; the key itself is never included
+var errInvalidAPIKey = errors.New("invalid api_key: expected 16-128 letters, digits, '-' or '_'")
+
+// keyLimiters rate limits requests made with per-request API keys, one limiter per key
+var keyLimiters *ratelimit.KeyedLimiters
+
+// resolveAPIKey returns the API key and rate limiter for a tool call. An
+// api_key in the call arguments, or else in the request _meta, overrides the
+// configured key and is limited separately.
+func resolveAPIKey(arguments, meta json.RawMessage) (string, *ratelimit.RateLimiter, error) {
+	var override struct {
+		APIKey string `json:"api_key"`
+	}
+	if len(arguments) > 0 {
+		json.Unmarshal(arguments, &override)
+	}
+	if override.APIKey == "" && len(meta) > 0 {
+		json.Unmarshal(meta, &override)
+	}
+
+	if override.APIKey == "" {
+		return apiKey, rateLimiter, nil
+	}
+	if !apiKeyPattern.MatchString(override.APIKey) {
+		return "", nil, errInvalidAPIKey
+	}
+	return override.APIKey, keyLimiters.Get(override.APIKey), nil
+}
+
+// limiterForKey returns the rate limiter for requests made with an API key:
+// the shared limiter for the configured key, or else that key's own limiter
+func limiterForKey(key string) *ratelimit.RateLimiter {
+	if key == apiKey {
+		return rateLimiter
+	}
+	return keyLimiters.Get(key)
+}
+
+// redactAPIKeys masks api_key values in a raw JSON message before it is logged
+func redactAPIKeys(raw string) string {
+	return apiKeyFieldPattern.ReplaceAllString(raw, `$1"[redacted]"`)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestResolveAPIKey(t *testing.T) {
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 1, PerMonth: 10})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 1, PerMonth: 10})
+
+	key, limiter, err := resolveAPIKey(json.RawMessage(`{"query": "go"}`), nil)
+	if err != nil || key != apiKey || limiter != rateLimiter {
+		t.Errorf("Expected configured key and limiter without an override, got %q, %v", key, err)
+	}
+
+	key, limiter, err = resolveAPIKey(json.RawMessage(`{"query": "go", "api_key": "tenantA_1234567890"}`), nil)
+	if err != nil || key != "tenantA_1234567890" {
+		t.Fatalf("Expected argument override, got %q, %v", key, err)
+	}
+	if limiter == rateLimiter || limiter != keyLimiters.Get("tenantA_1234567890") {
+		t.Error("Expected a dedicated limiter for the override key")
+	}
+
+	key, _, err = resolveAPIKey(json.RawMessage(`{"query": "go"}`), json.RawMessage(`{"api_key": "tenantB_1234567890"}`))
+	if err != nil || key != "tenantB_1234567890" {
+		t.Errorf("Expected _meta override, got %q, %v", key, err)
+	}
+
+	_, _, err = resolveAPIKey(json.RawMessage(`{"api_key": "short"}`), nil)
+	if err == nil || strings.Contains(err.Error(), "short") {
+		t.Errorf("Expected a format error that does not echo the key, got %v", err)
+	}
+}
+
+func TestRedactAPIKeys(t *testing.T) {
+	line := `{"params":{"arguments":{"query":"go","api_key":"secret_1234567890"},"_meta":{"api_key": "other\"secret"}}}`
+	redacted := redactAPIKeys(line)
+
+	if strings.Contains(redacted, "secret") {
+		t.Errorf("Expected keys to be redacted, got %s", redacted)
+	}
+	if !strings.Contains(redacted, `"query":"go"`) {
+		t.Errorf("Expected other fields to be preserved, got %s", redacted)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// defaultOperationCosts is what each search tool costs against the cost
+// budget, roughly in proportion to the Brave requests it makes
+var defaultOperationCosts = map[string]int{
+	"brave_web_search":      1,
+	"brave_news_search":     1,
+	"brave_combined_search": 2, // a web and a news search
+	"brave_local_search":    3, // a web search, then POI and description requests
+}
+
+// Cost accounting for searches made with the server's API key
+var (
+	costBudget     *ratelimit.CostBudget
+	operationCosts = defaultOperationCosts
+)
+
+// setOperationCosts applies configured costs over the defaults
+func setOperationCosts(overrides map[string]int) {
+	costs := make(map[string]int, len(defaultOperationCosts)+len(overrides))
+	for tool, cost := range defaultOperationCosts {
+		costs[tool] = cost
+	}
+	for tool, cost := range overrides {
+		costs[tool] = cost
+	}
+	operationCosts = costs
+}
+
+// chargeOperation charges a tool call to the cost budget and returns a
+// function to call, with the number of requests the call sent to Brave, once
+// it has finished. That function refunds the charge when the call sent none,
+// so calls rejected for invalid arguments or as busy, and results answered
+// from the cache or the debouncer, cost nothing. Tools without a cost, and
+// callers using their own API key, are not charged.
+func chargeOperation(tool string, callRateLimiter *ratelimit.RateLimiter) (func(sent int), error) {
+	if callRateLimiter != rateLimiter || costBudget == nil {
+		return func(int) {}, nil
+	}
+
+	cost := operationCosts[tool]
+	if err := costBudget.Charge(cost); err != nil {
+		return nil, err
+	}
+	return func(sent int) {
+		if sent == 0 {
+			costBudget.Refund(cost)
+		}
+	}, nil
+}
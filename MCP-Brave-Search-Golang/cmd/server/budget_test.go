@@ -0,0 +1,106 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
+)
+
+func TestCachedSearchIsNotCharged(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"web": {"results": [{"title": "Go", "description": "The Go language", "url": "https://go.dev"}]}}`))
+	}))
+	defer server.Close()
+
+	if err := brave.SetBaseURL(server.URL); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer brave.SetBaseURL("")
+	brave.SetResultCache(time.Minute, 10)
+	defer brave.SetResultCache(0, 0)
+
+	originalProvider := provider
+	provider = braveClient
+	defer func() { provider = originalProvider }()
+	costBudget = ratelimit.NewCostBudget(0, 10)
+	defer func() { costBudget = nil }()
+
+	initialized = true
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	callTool(t, "brave_web_search", `{"query": "golang"}`)
+	callTool(t, "brave_web_search", `{"query": "golang"}`)
+
+	if requests != 1 {
+		t.Fatalf("Expected the repeated search to be answered from the cache, got %d requests", requests)
+	}
+	if spent := costBudget.Stats().MonthlySpent; spent != 1 {
+		t.Errorf("Expected only the search sent to Brave to be charged, got %d spent", spent)
+	}
+
+	// Calls rejected before searching are refunded too
+	handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "2", Method: "tools/call",
+		Params: []byte(`{"name": "brave_web_search", "arguments": {"query": 5}}`)})
+	if spent := costBudget.Stats().MonthlySpent; spent != 1 {
+		t.Errorf("Expected an invalid call not to be charged, got %d spent", spent)
+	}
+}
+
+// sharedLimiterProvider reports a fixed number of requests sent while the
+// shared limiter's count moves on its own, as it does under concurrent
+// calls, rate limit syncs and the monthly reset
+type sharedLimiterProvider struct {
+	fakeProvider
+	sent int
+}
+
+func (p *sharedLimiterProvider) WebSearch(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	rateLimiter.Reserve(1)
+	if p.sent > 0 {
+		rateLimiter.ResetMonthlyCounter()
+	}
+	return "web results for " + query, p.sent, nil
+}
+
+func TestChargeFollowsRequestsSentByTheCall(t *testing.T) {
+	fake := &sharedLimiterProvider{}
+	originalProvider := provider
+	provider = fake
+	defer func() { provider = originalProvider }()
+	costBudget = ratelimit.NewCostBudget(0, 10)
+	defer func() { costBudget = nil }()
+
+	initialized = true
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	// Another call's request doesn't make this one chargeable
+	callTool(t, "brave_web_search", `{"query": "cached"}`)
+	if spent := costBudget.Stats().MonthlySpent; spent != 0 {
+		t.Errorf("Expected a call sending no requests to be refunded, got %d spent", spent)
+	}
+
+	// A monthly reset during the call doesn't make it free
+	fake.sent = 1
+	response := handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call",
+		Params: []byte(`{"name": "brave_web_search", "arguments": {"query": "sent", "debug": true}}`)})
+	if spent := costBudget.Stats().MonthlySpent; spent != 1 {
+		t.Errorf("Expected a call sending a request to be charged, got %d spent", spent)
+	}
+
+	// The debug output reports the same per-call figure
+	if !strings.Contains(string(response.Result), "Requests sent to Brave: 1") {
+		t.Errorf("Expected the debug output to report 1 request sent, got %s", string(response.Result))
+	}
+}
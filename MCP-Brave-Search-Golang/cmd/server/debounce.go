@@ -0,0 +1,49 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/debounce"
+)
+
+// queryDebouncer answers identical queries repeated within the configured interval
+var queryDebouncer = debounce.New(0)
+
+// queryKey identifies a search by tool, API key and normalized arguments.
+// The arguments are JSON encoded so adjacent strings can't run together, and
+// the key is hashed so API keys are not held in the clear.
+func queryKey(toolName, key, query string, params ...interface{}) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	data, _ := json.Marshal(append([]interface{}{toolName, key, normalized}, params...))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// debouncedSearch runs search unless the same query succeeded within the
+// debounce interval, in which case the previous result is returned, repeated
+// is true and no requests are sent. sent is the number of requests search
+// reported sending, even when it fails.
+func debouncedSearch(key string, search func() (string, int, error)) (results string, sent int, repeated bool, err error) {
+	if previous, ok := queryDebouncer.Recent(key); ok {
+		return previous, 0, true, nil
+	}
+
+	results, sent, err = search()
+	if err != nil {
+		return "", sent, false, err
+	}
+	queryDebouncer.Record(key, results)
+	return results, sent, false, nil
+}
+
+// repeatedQueryNote tells the caller that a result was reused rather than searched again
+func repeatedQueryNote() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "text",
+		"text": fmt.Sprintf("Note: this query was already run within the last %v; returning the previous result without searching again.", queryDebouncer.Interval()),
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import "github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
+
+// explainPlanNote returns a text content item showing the requests a search
+// sends to Brave and how many it sent this time, for the debug argument
+func explainPlanNote(plans []brave.RequestPlan, err error, sent int) map[string]interface{} {
+	text := brave.FormatExplainPlan(plans, sent)
+	if err != nil {
+		text = "Debug: the request plan is unavailable: " + err.Error()
+	}
+	return map[string]interface{}{
+		"type": "text",
+		"text": text,
+	}
+}
@@ -4,14 +4,20 @@ import (
 	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/debounce"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/idle"
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/config"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/mcp"
 )
 
 // JSONRPCMessage represents a JSON-RPC message
@@ -30,20 +36,51 @@ type ErrorMessage struct {
 	Message string `json:"message"`
 }
 
+// ServerFeatures reports which optional features are enabled by the
+// server's effective configuration, so clients can adapt their behavior.
+// It is sent as an extension field of the initialize capabilities.
+type ServerFeatures struct {
+	ReadOnly bool                   `json:"readOnly"`
+	Tools    []string               `json:"tools"`
+	Cache    bool                   `json:"cache"`
+	Retry    map[string]interface{} `json:"retry,omitempty"`
+	Limits   map[string]interface{} `json:"limits,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+// knownMethods lists every method the server responds to
+var knownMethods = []string{
+	"initialize",
+	"initialized",
+	"tools/list",
+	"tools/call",
+	"tools/call_batch",
+	"list_tools",
+	"call_tool",
+}
+
 // Main server state
 var (
-	initialized bool
-	apiKey      string
-	rateLimiter *ratelimit.RateLimiter
+	initialized   bool
+	apiKey        string
+	rateLimiter   *ratelimit.RateLimiter
+	toolLimiter   *ratelimit.ToolLimiter
+	callLimiter   *ratelimit.ConcurrencyLimiter // tool calls in flight
+	idleMonitor   = idle.NewMonitor(0)
+	debugTiming   bool
+	strictJSONRPC bool // reject messages without jsonrpc "2.0"
+	features      ServerFeatures
 )
 
 func main() {
 	// Set up signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	shutdown := make(chan struct{})
 
 	go func() {
 		<-sigChan
+		close(shutdown)
 		fmt.Fprintln(os.Stderr, "Shutting down...")
 		os.Exit(0)
 	}()
@@ -57,91 +94,278 @@ func main() {
 
 	// Store configuration in global variables
 	apiKey = cfg.BraveAPIKey
+	debugTiming = cfg.DebugTiming
 	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{
 		PerSecond: cfg.RateLimit.PerSecond,
 		PerMonth:  cfg.RateLimit.PerMonth,
 	})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{
+		PerSecond: cfg.RateLimit.PerSecond,
+		PerMonth:  cfg.RateLimit.PerMonth,
+	})
+
+	// Configure how the Brave client handles redirects
+	if err := brave.SetRedirectPolicy(brave.RedirectPolicy(cfg.RedirectPolicy)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Send API requests to the configured host, such as an egress proxy
+	if err := brave.SetBaseURL(cfg.BaseURL); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	brave.SetMaxWorkers(cfg.MaxWorkers)
+	brave.SetLocalDetailLimit(cfg.LocalDetailRequestsPerMinute)
+	brave.SetResultCache(cfg.GetCacheTTL(), cfg.CacheMaxEntries)
+	brave.SetTransportOptions(brave.TransportOptions{
+		MaxIdleConns:        cfg.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.GetIdleConnTimeout(),
+	})
+
+	allowedSearchLangs = cfg.AllowedSearchLangs
+	strictJSONRPC = cfg.StrictJSONRPC
+	toolLimiter = ratelimit.NewToolLimiter(cfg.GetToolRateLimits())
+	callLimiter = cfg.NewConcurrencyLimiter()
+	costBudget = ratelimit.NewCostBudget(cfg.CostBudget.Daily, cfg.CostBudget.Monthly)
+	setOperationCosts(cfg.OperationCosts)
+	braveClient.SetTimeoutBounds(cfg.GetMinRequestTimeout(), cfg.GetMaxRequestTimeout())
+	brave.SetRetryPolicy(cfg.MaxRetries, cfg.GetRetryBaseDelay())
+	brave.SetWaitForRateLimit(cfg.WaitForRateLimit)
+	brave.SetContentTypeCheck(*cfg.CheckContentType)
+	brave.SetRateLimitSync(func(key string, remaining int, reset time.Time) {
+		limiterForKey(key).Sync(remaining, reset)
+	})
+
+	queryDebouncer = debounce.New(cfg.GetMinQueryInterval())
+	features = serverFeatures(cfg)
+
+	// Start each month with a fresh monthly quota
+	go scheduleMonthlyReset(shutdown)
+
+	// Shut down once no request has arrived within the idle timeout
+	if cfg.IdleTimeout > 0 {
+		idleMonitor = idle.NewMonitor(cfg.GetIdleTimeout())
+		fmt.Fprintf(os.Stderr, "Idle timeout: %v\n", cfg.GetIdleTimeout())
+		go func() {
+			<-idleMonitor.Done()
+			fmt.Fprintf(os.Stderr, "No requests for %v, shutting down...\n", cfg.GetIdleTimeout())
+			os.Exit(0)
+		}()
+	}
 
 	// Start the server
 	fmt.Fprintln(os.Stderr, "Brave Search MCP Server starting...")
 	RunServer()
 }
 
+// serverFeatures describes the optional features enabled by the effective configuration
+func serverFeatures(cfg *config.Config) ServerFeatures {
+	return ServerFeatures{
+		ReadOnly: true,
+		Tools: []string{
+			brave.WebSearchTool["name"].(string),
+			brave.LocalSearchTool["name"].(string),
+			brave.NewsSearchTool["name"].(string),
+			brave.CombinedSearchTool["name"].(string),
+			statusTool["name"].(string),
+			rateLimitStatusTool["name"].(string),
+		},
+		Cache: cfg.GetCacheTTL() > 0,
+		Limits: map[string]interface{}{
+			"requestsPerSecond":  cfg.RateLimit.PerSecond,
+			"requestsPerMonth":   cfg.RateLimit.PerMonth,
+			"maxWorkers":         cfg.MaxWorkers,
+			"localDetailLimit":   cfg.LocalDetailRequestsPerMinute,
+			"maxWebOffset":       brave.MaxWebOffset,
+			"idleTimeoutSeconds": cfg.IdleTimeout,
+			"minQueryInterval":   cfg.MinQueryInterval,
+			"minRequestTimeout":  cfg.MinRequestTimeout,
+			"maxRequestTimeout":  cfg.MaxRequestTimeout,
+			"toolRateLimits":     cfg.ToolRateLimits,
+			"maxRetries":         cfg.MaxRetries,
+			"retryBaseDelayMs":   cfg.RetryBaseDelay,
+			"cacheTtlSeconds":    int(cfg.GetCacheTTL().Seconds()),
+			"cacheMaxEntries":    cfg.CacheMaxEntries,
+		},
+		Options: map[string]interface{}{
+			"redirectPolicy":     cfg.RedirectPolicy,
+			"debugTiming":        cfg.DebugTiming,
+			"allowedSearchLangs": cfg.AllowedSearchLangs,
+			"strictJsonRpc":      cfg.StrictJSONRPC,
+			"waitForRateLimit":   cfg.WaitForRateLimit,
+			"checkContentType":   *cfg.CheckContentType,
+		},
+	}
+}
+
 // RunServer starts the MCP server
 func RunServer() {
-	// Create scanner for stdin
-	scanner := bufio.NewScanner(os.Stdin)
-	// Create writer for stdout
-	writer := bufio.NewWriter(os.Stdout)
+	serve(os.Stdin, bufio.NewWriter(os.Stdout))
+}
+
+// maxMessageSize is the longest line serve reads. bufio.Scanner's default of
+// 64KB is too small for large tool call or initialize params.
+const maxMessageSize = 16 * 1024 * 1024
+
+// serve processes the messages read from reader, one per line, writing
+// responses to writer. A message split across lines is joined before it is
+// processed. Recoverable read errors are logged and reading resumes with a
+// new scanner; serve returns at end of input or on any other error.
+func serve(reader io.Reader, writer *bufio.Writer) {
+	var accumulator messageAccumulator
+	readFailures := 0
 
 	// Process requests
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			continue // Skip empty lines
-		}
-
-		fmt.Fprintf(os.Stderr, "Received: %s\n", line)
-
-		// Parse the message
-		var message JSONRPCMessage
-		if err := json.Unmarshal([]byte(line), &message); err != nil {
-			fmt.Fprintf(os.Stderr, "Error parsing message: %v\n", err)
-			continue
-		}
-
-		// Process the message
-		var responseMsg *JSONRPCMessage
-
-		switch message.Method {
-		case "initialize":
-			responseMsg = handleInitialize(message)
-		case "initialized":
-			initialized = true
-			continue // No response for notification
-		case "tools/list":
-			responseMsg = handleToolsList(message)
-		case "tools/call":
-			responseMsg = handleToolsCall(message)
-		case "list_tools": // Backward compatibility
-			responseMsg = handleToolsList(message)
-		case "call_tool": // Backward compatibility
-			responseMsg = handleToolsCall(message)
-		default:
+	idleMonitor.Start()
+	for {
+		// Create scanner for the input
+		scanner := bufio.NewScanner(reader)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			readFailures = 0
+			line := scanner.Text()
+			if strings.TrimSpace(line) == "" {
+				continue // Skip empty lines
+			}
+
+			// Wait for the rest of a message split across lines
+			message, complete := accumulator.Add(line)
+			if !complete {
+				continue
+			}
+
+			// Hold off the idle timer while the request is in flight
+			idleMonitor.Begin()
+			processLine(message, writer)
+			idleMonitor.End()
+		}
+
+		err := scanner.Err()
+		if err == nil {
+			fmt.Fprintln(os.Stderr, "Input closed, shutting down")
+			return
+		}
+		if !isRecoverableReadError(err) || readFailures >= maxReadFailures {
+			fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+			return
+		}
+
+		// A scanner stops for good after an error, so resume with a new one
+		readFailures++
+		fmt.Fprintf(os.Stderr, "Recoverable error reading stdin, resuming (%d/%d): %v\n", readFailures, maxReadFailures, err)
+		time.Sleep(readRetryDelay)
+	}
+}
+
+// processLine parses and dispatches a single message and writes any response
+func processLine(line string, writer *bufio.Writer) {
+	fmt.Fprintf(os.Stderr, "Received: %s\n", redactAPIKeys(line))
+
+	// Parse the message
+	var message JSONRPCMessage
+	if err := json.Unmarshal([]byte(line), &message); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing message: %v\n", err)
+		return
+	}
+
+	// In strict mode only JSON-RPC 2.0 messages are accepted
+	if strictJSONRPC && message.JsonRPC != "2.0" {
+		fmt.Fprintf(os.Stderr, "Rejecting message with jsonrpc version %q\n", message.JsonRPC)
+		writeResponse(writer, &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32600,
+				Message: fmt.Sprintf("Invalid Request: jsonrpc must be \"2.0\", got %q", message.JsonRPC),
+			},
+		})
+		return
+	}
+
+	// Send response if applicable
+	if responseMsg := dispatchMessage(message); responseMsg != nil {
+		writeResponse(writer, responseMsg)
+	}
+}
+
+// dispatchMessage handles a parsed message and returns its response, or nil
+// for a notification. A panic while handling it is logged with its stack
+// and answered with an internal error, so one bad request can't stop the server.
+func dispatchMessage(message JSONRPCMessage) (responseMsg *JSONRPCMessage) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "Panic handling method %s: %v\n%s", message.Method, r, debug.Stack())
+			if message.ID == "" {
+				responseMsg = nil // No response for a notification
+				return
+			}
 			responseMsg = &JSONRPCMessage{
 				JsonRPC: "2.0",
 				ID:      message.ID,
 				Error: &ErrorMessage{
-					Code:    -32601,
-					Message: "Method not supported: " + message.Method,
+					Code:    -32603,
+					Message: fmt.Sprintf("Internal error: %v", r),
 				},
 			}
 		}
+	}()
 
-		// Send response if applicable
-		if responseMsg != nil {
-			responseBytes, err := json.Marshal(responseMsg)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error marshaling response: %v\n", err)
-				continue
-			}
-
-			fmt.Fprintf(os.Stderr, "Sending: %s\n", string(responseBytes))
-			_, err = writer.WriteString(string(responseBytes) + "\n")
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
-				continue
-			}
-			err = writer.Flush()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error flushing response: %v\n", err)
-				continue
-			}
+	switch message.Method {
+	case "initialize":
+		responseMsg = handleInitialize(message)
+	case "initialized":
+		initialized = true
+		return nil // No response for notification
+	case "tools/list":
+		responseMsg = handleToolsList(message)
+	case "tools/call":
+		responseMsg = handleToolsCall(message)
+	case "tools/call_batch":
+		responseMsg = handleToolsCallBatch(message)
+	case "list_tools": // Backward compatibility
+		responseMsg = handleToolsList(message)
+	case "call_tool": // Backward compatibility
+		responseMsg = handleToolsCall(message)
+	default:
+		// Suggest the closest known method for likely typos
+		errorMessage := "Method not supported: " + message.Method
+		if suggestion := suggestMethod(message.Method, knownMethods); suggestion != "" {
+			errorMessage += " (did you mean " + suggestion + "?)"
+		}
+		responseMsg = &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32601,
+				Message: errorMessage,
+			},
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading stdin: %v\n", err)
+	return responseMsg
+}
+
+// writeResponse writes a response message as a single line
+func writeResponse(writer *bufio.Writer, responseMsg *JSONRPCMessage) {
+	responseBytes, err := json.Marshal(responseMsg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling response: %v\n", err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Sending: %s\n", string(responseBytes))
+	_, err = writer.WriteString(string(responseBytes) + "\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
+		return
+	}
+	err = writer.Flush()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error flushing response: %v\n", err)
+		return
 	}
 }
 
@@ -181,6 +405,7 @@ func handleInitialize(message JSONRPCMessage) *JSONRPCMessage {
 			"list": true,
 			"call": true,
 		},
+		"features": features,
 	}
 
 	// Create result
@@ -243,11 +468,29 @@ func handleToolsList(message JSONRPCMessage) *JSONRPCMessage {
 		"inputSchema": brave.LocalSearchTool["inputSchema"],
 	}
 
+	// Create news search tool
+	newsSearchTool := map[string]interface{}{
+		"name":        brave.NewsSearchTool["name"],
+		"description": brave.NewsSearchTool["description"],
+		"inputSchema": brave.NewsSearchTool["inputSchema"],
+	}
+
+	// Create combined search tool
+	combinedSearchTool := map[string]interface{}{
+		"name":        brave.CombinedSearchTool["name"],
+		"description": brave.CombinedSearchTool["description"],
+		"inputSchema": brave.CombinedSearchTool["inputSchema"],
+	}
+
 	// Create tools list
 	toolsList := map[string]interface{}{
 		"tools": []interface{}{
 			webSearchTool,
 			localSearchTool,
+			newsSearchTool,
+			combinedSearchTool,
+			statusTool,
+			rateLimitStatusTool,
 		},
 	}
 
@@ -341,16 +584,84 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 		}
 	}
 
-	// Process the tool call
+	// Extract the request _meta (e.g. progressToken), preserved verbatim
+	var request mcp.CallToolRequest
+	if err := json.Unmarshal(message.Params, &request); err != nil {
+		request.Meta = nil
+	}
+	meta := newCallMeta(request.Meta)
+
+	// Use the caller's own API key and quota when one is supplied
+	callAPIKey, callRateLimiter, err := resolveAPIKey(argumentsBytes, request.Meta)
+	if err != nil {
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32602,
+				Message: "Invalid params: " + err.Error(),
+			},
+		}
+	}
+
+	// Enforce the configured search language policy
+	if err := checkSearchLang(argumentsBytes); err != nil {
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32602,
+				Message: "Invalid params: " + err.Error(),
+			},
+		}
+	}
+
+	// Enforce the per-tool rate limits before doing any work
+	if err := toolLimiter.Allow(toolName); err != nil {
+		fmt.Fprintf(os.Stderr, "Tool rate limit exceeded: %v\n", err)
+		return toolErrorResult(message.ID, err)
+	}
+
+	// Charge the call to the cost budget, refunded if it sends no requests
+	var sent int // requests this call sent to Brave, as reported by the provider
+	settle, err := chargeOperation(toolName, callRateLimiter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cost budget exhausted: %v\n", err)
+		return toolErrorResult(message.ID, err)
+	}
+	defer func() { settle(sent) }()
+
+	// Bound the number of tool calls in flight
+	release, err := callLimiter.Acquire()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Rejecting tool call: %v\n", err)
+		return toolErrorResult(message.ID, err)
+	}
+	defer release()
+
+	// Process the tool call; tools may attach entries to the response _meta
 	var response map[string]interface{}
+	start := time.Now()
 
 	switch toolName {
 	case "brave_web_search":
 		// Parse web search arguments
 		var args struct {
-			Query  string `json:"query"`
-			Count  int    `json:"count"`
-			Offset int    `json:"offset"`
+			Query              string   `json:"query"`
+			Count              int      `json:"count"`
+			Offset             int      `json:"offset"`
+			Fields             []string `json:"fields"`
+			IncludeThumbnails  bool     `json:"include_thumbnails"`
+			SafeSearch         string   `json:"safesearch"`
+			Freshness          string   `json:"freshness"`
+			Country            string   `json:"country"`
+			SearchLang         string   `json:"search_lang"`
+			SortByAge          bool     `json:"sort_by_age"`
+			IncludeInfobox     bool     `json:"include_infobox"`
+			IncludeDiscussions bool     `json:"include_discussions"`
+			IncludeFAQ         bool     `json:"include_faq"`
+			Format             string   `json:"format"`
+			Debug              bool     `json:"debug"`
 		}
 		if err := json.Unmarshal(argumentsBytes, &args); err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing web search arguments: %v\n", err)
@@ -363,6 +674,36 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 				},
 			}
 		}
+		if args.Format != "" && args.Format != "text" && args.Format != "json" {
+			return &JSONRPCMessage{
+				JsonRPC: "2.0",
+				ID:      message.ID,
+				Error: &ErrorMessage{
+					Code:    -32602,
+					Message: fmt.Sprintf("Invalid params: format %q must be one of %s", args.Format, strings.Join(brave.WebResultFormats, ", ")),
+				},
+			}
+		}
+		var sections []string
+		if args.IncludeInfobox {
+			sections = append(sections, "infobox")
+		}
+		if args.IncludeDiscussions {
+			sections = append(sections, "discussions")
+		}
+		if args.IncludeFAQ {
+			sections = append(sections, "faq")
+		}
+		if len(sections) > 0 && args.Format == "json" {
+			return &JSONRPCMessage{
+				JsonRPC: "2.0",
+				ID:      message.ID,
+				Error: &ErrorMessage{
+					Code:    -32602,
+					Message: "Invalid params: include_infobox, include_discussions and include_faq are only supported with the text format",
+				},
+			}
+		}
 
 		// Set default count if needed
 		if args.Count <= 0 {
@@ -370,7 +711,41 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 		}
 
 		// Perform web search
-		results, err := brave.WebSearch(apiKey, args.Query, args.Count, args.Offset, rateLimiter)
+		opts := brave.WebSearchOptions{
+			Count:      args.Count,
+			Offset:     args.Offset,
+			Fields:     args.Fields,
+			SafeSearch: args.SafeSearch,
+			Freshness:  args.Freshness,
+			Country:    args.Country,
+			SearchLang: args.SearchLang,
+			SortByAge:  args.SortByAge,
+			Sections:   sections,
+		}
+		var results string
+		var thumbnails []brave.Thumbnail
+		var webResults []brave.WebResult
+		var repeated bool
+		thumbnailProvider, supportsThumbnails := provider.(ThumbnailSearchProvider)
+		structuredProvider, supportsStructured := provider.(StructuredSearchProvider)
+		if args.Format == "json" {
+			// Structured results aren't kept by the debouncer, so always search
+			if !supportsStructured {
+				err = fmt.Errorf("the search provider does not support the json format")
+			} else {
+				webResults, sent, err = structuredProvider.WebSearchStructured(callAPIKey, args.Query, opts, callRateLimiter)
+			}
+			if err == nil {
+				results, err = formatResultsJSON(webResults)
+			}
+		} else if args.IncludeThumbnails && supportsThumbnails {
+			// Thumbnails aren't kept by the debouncer, so always search
+			results, thumbnails, sent, err = thumbnailProvider.WebSearchWithThumbnails(callAPIKey, args.Query, opts, callRateLimiter)
+		} else {
+			results, sent, repeated, err = debouncedSearch(queryKey(toolName, callAPIKey, args.Query, opts), func() (string, int, error) {
+				return provider.WebSearch(callAPIKey, args.Query, opts, callRateLimiter)
+			})
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Web search error: %v\n", err)
 			response = map[string]interface{}{
@@ -384,22 +759,51 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 			}
 		} else {
 			fmt.Fprintf(os.Stderr, "Web search success\n")
-			response = map[string]interface{}{
-				"content": []map[string]interface{}{
-					{
-						"type": "text",
-						"text": results,
-					},
+			content := []map[string]interface{}{
+				{
+					"type": "text",
+					"text": results,
 				},
+			}
+			if repeated {
+				content = append(content, repeatedQueryNote())
+			}
+			if args.Debug {
+				plans, err := brave.ExplainWebSearch(args.Query, opts)
+				content = append(content, explainPlanNote(plans, err, sent))
+			}
+			for _, thumbnail := range thumbnails {
+				content = append(content, map[string]interface{}{
+					"type":     "image",
+					"data":     thumbnail.Data,
+					"mimeType": thumbnail.MimeType,
+				})
+			}
+			response = map[string]interface{}{
+				"content": content,
 				"isError": false,
 			}
+			if args.Format == "json" {
+				// Clients that understand structuredContent can use the results directly
+				if webResults == nil {
+					webResults = []brave.WebResult{}
+				}
+				response["structuredContent"] = map[string]interface{}{
+					"results": webResults,
+				}
+			}
 		}
 
 	case "brave_local_search":
 		// Parse local search arguments
 		var args struct {
-			Query string `json:"query"`
-			Count int    `json:"count"`
+			Query      string   `json:"query"`
+			Count      int      `json:"count"`
+			SearchLang string   `json:"search_lang"`
+			MinRating  float64  `json:"min_rating"`
+			Latitude   *float64 `json:"latitude"`
+			Longitude  *float64 `json:"longitude"`
+			Debug      bool     `json:"debug"`
 		}
 		if err := json.Unmarshal(argumentsBytes, &args); err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing local search arguments: %v\n", err)
@@ -412,6 +816,27 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 				},
 			}
 		}
+		if args.MinRating < 0 || args.MinRating > 5 {
+			return &JSONRPCMessage{
+				JsonRPC: "2.0",
+				ID:      message.ID,
+				Error: &ErrorMessage{
+					Code:    -32602,
+					Message: fmt.Sprintf("Invalid params: min_rating %v must be between 0 and 5", args.MinRating),
+				},
+			}
+		}
+		reference, err := brave.ReferenceLocation(args.Latitude, args.Longitude)
+		if err != nil {
+			return &JSONRPCMessage{
+				JsonRPC: "2.0",
+				ID:      message.ID,
+				Error: &ErrorMessage{
+					Code:    -32602,
+					Message: "Invalid params: " + err.Error(),
+				},
+			}
+		}
 
 		// Set default count if needed
 		if args.Count <= 0 {
@@ -419,7 +844,11 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 		}
 
 		// Perform local search
-		results, err := brave.LocalSearch(apiKey, args.Query, args.Count, rateLimiter)
+		var results string
+		var repeated bool
+		results, sent, repeated, err = debouncedSearch(queryKey(toolName, callAPIKey, args.Query, args.Count, args.SearchLang, reference, args.MinRating), func() (string, int, error) {
+			return provider.LocalSearch(callAPIKey, args.Query, args.Count, args.SearchLang, reference, args.MinRating, callRateLimiter)
+		})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Local search error: %v\n", err)
 			response = map[string]interface{}{
@@ -433,17 +862,186 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 			}
 		} else {
 			fmt.Fprintf(os.Stderr, "Local search success\n")
+			content := []map[string]interface{}{
+				{
+					"type": "text",
+					"text": results,
+				},
+			}
+			if repeated {
+				content = append(content, repeatedQueryNote())
+			}
+			if args.Debug {
+				plans, err := brave.ExplainLocalSearch(args.Query, args.Count, args.SearchLang)
+				content = append(content, explainPlanNote(plans, err, sent))
+			}
+			response = map[string]interface{}{
+				"content": content,
+				"isError": false,
+			}
+		}
+
+	case "brave_news_search":
+		// Parse news search arguments
+		var args struct {
+			Query     string `json:"query"`
+			Count     int    `json:"count"`
+			Freshness string `json:"freshness"`
+		}
+		if err := json.Unmarshal(argumentsBytes, &args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing news search arguments: %v\n", err)
+			return &JSONRPCMessage{
+				JsonRPC: "2.0",
+				ID:      message.ID,
+				Error: &ErrorMessage{
+					Code:    -32602,
+					Message: "Invalid params: " + err.Error(),
+				},
+			}
+		}
+
+		// Set default count if needed
+		if args.Count <= 0 {
+			args.Count = 10
+		}
+
+		// Perform news search
+		newsProvider, ok := provider.(NewsSearchProvider)
+		var results string
+		var repeated bool
+		if !ok {
+			err = fmt.Errorf("news search is not supported by the search provider")
+		} else {
+			results, sent, repeated, err = debouncedSearch(queryKey(toolName, callAPIKey, args.Query, args.Count, args.Freshness), func() (string, int, error) {
+				return newsProvider.NewsSearch(callAPIKey, args.Query, args.Count, args.Freshness, callRateLimiter)
+			})
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "News search error: %v\n", err)
+			response = map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": "Error: " + err.Error(),
+					},
+				},
+				"isError": true,
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "News search success\n")
+			content := []map[string]interface{}{
+				{
+					"type": "text",
+					"text": results,
+				},
+			}
+			if repeated {
+				content = append(content, repeatedQueryNote())
+			}
+			response = map[string]interface{}{
+				"content": content,
+				"isError": false,
+			}
+		}
+
+	case "brave_combined_search":
+		// Parse combined search arguments
+		var args struct {
+			Query string `json:"query"`
+			Count int    `json:"count"`
+		}
+		if err := json.Unmarshal(argumentsBytes, &args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error parsing combined search arguments: %v\n", err)
+			return &JSONRPCMessage{
+				JsonRPC: "2.0",
+				ID:      message.ID,
+				Error: &ErrorMessage{
+					Code:    -32602,
+					Message: "Invalid params: " + err.Error(),
+				},
+			}
+		}
+
+		// Set default count if needed
+		if args.Count <= 0 {
+			args.Count = 10
+		}
+
+		// Perform combined search
+		combinedProvider, ok := provider.(CombinedSearchProvider)
+		var results string
+		var repeated bool
+		if !ok {
+			err = fmt.Errorf("combined search is not supported by the search provider")
+		} else {
+			results, sent, repeated, err = debouncedSearch(queryKey(toolName, callAPIKey, args.Query, args.Count), func() (string, int, error) {
+				return combinedProvider.CombinedSearch(callAPIKey, args.Query, args.Count, callRateLimiter)
+			})
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Combined search error: %v\n", err)
+			response = map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": "Error: " + err.Error(),
+					},
+				},
+				"isError": true,
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "Combined search success\n")
+			content := []map[string]interface{}{
+				{
+					"type": "text",
+					"text": results,
+				},
+			}
+			if repeated {
+				content = append(content, repeatedQueryNote())
+			}
+			response = map[string]interface{}{
+				"content": content,
+				"isError": false,
+			}
+		}
+
+	case "brave_status":
+		status, err := serverStatus()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Status error: %v\n", err)
+			response = map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": "Error: " + err.Error(),
+					},
+				},
+				"isError": true,
+			}
+		} else {
 			response = map[string]interface{}{
 				"content": []map[string]interface{}{
 					{
 						"type": "text",
-						"text": results,
+						"text": status,
 					},
 				},
 				"isError": false,
 			}
 		}
 
+	case "brave_rate_limit_status":
+		response = map[string]interface{}{
+			"content": []map[string]interface{}{
+				{
+					"type": "text",
+					"text": rateLimitStatus(callRateLimiter.Stats()),
+				},
+			},
+			"isError": false,
+		}
+
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown tool: %s\n", toolName)
 		response = map[string]interface{}{
@@ -457,6 +1055,17 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 		}
 	}
 
+	// Report how long the tool took when timing is enabled
+	if debugTiming {
+		meta.Set("durationMs", float64(time.Since(start).Microseconds())/1000)
+	}
+	if responseMeta := meta.Response(); responseMeta != nil {
+		response["_meta"] = responseMeta
+	}
+
+	created, reused := brave.ConnectionStats()
+	fmt.Fprintf(os.Stderr, "API connections: %d new, %d reused\n", created, reused)
+
 	// Marshal response to JSON
 	resultBytes, err := json.Marshal(response)
 	if err != nil {
@@ -478,3 +1087,149 @@ func handleToolsCall(message JSONRPCMessage) *JSONRPCMessage {
 		Result:  resultBytes,
 	}
 }
+
+// formatResultsJSON renders search results as a JSON array for the json output format
+func formatResultsJSON(results []brave.WebResult) (string, error) {
+	if results == nil {
+		results = []brave.WebResult{}
+	}
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode results: %w", err)
+	}
+	return string(data), nil
+}
+
+// toolErrorResult returns a tool result reporting err, for failures detected
+// before a tool runs
+func toolErrorResult(id string, err error) *JSONRPCMessage {
+	resultBytes, marshalErr := json.Marshal(map[string]interface{}{
+		"content": []map[string]interface{}{
+			{
+				"type": "text",
+				"text": "Error: " + err.Error(),
+			},
+		},
+		"isError": true,
+	})
+	if marshalErr != nil {
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      id,
+			Error: &ErrorMessage{
+				Code:    -32603,
+				Message: "Internal error",
+			},
+		}
+	}
+
+	return &JSONRPCMessage{
+		JsonRPC: "2.0",
+		ID:      id,
+		Result:  resultBytes,
+	}
+}
+
+// handleToolsCallBatch handles the tools/call_batch request, running each call in order
+func handleToolsCallBatch(message JSONRPCMessage) *JSONRPCMessage {
+	// If not initialized, reject the request
+	if !initialized {
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32002,
+				Message: "Server not initialized",
+			},
+		}
+	}
+
+	// Parse the params
+	var params struct {
+		Calls       []json.RawMessage `json:"calls"`
+		StopOnError bool              `json:"stop_on_error"`
+	}
+	if err := json.Unmarshal(message.Params, &params); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing batch call params: %v\n", err)
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32700,
+				Message: "Parse error",
+			},
+		}
+	}
+
+	if len(params.Calls) == 0 {
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32602,
+				Message: "Invalid params: missing calls",
+			},
+		}
+	}
+
+	results := make([]json.RawMessage, 0, len(params.Calls))
+	for _, call := range params.Calls {
+		// Reuse the single-call handler so behavior matches tools/call
+		callResponse := handleToolsCall(JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Method:  "tools/call",
+			Params:  call,
+		})
+
+		result := callResponse.Result
+		isError := false
+		if callResponse.Error != nil {
+			isError = true
+			result, _ = json.Marshal(map[string]interface{}{
+				"content": []map[string]interface{}{
+					{
+						"type": "text",
+						"text": "Error: " + callResponse.Error.Message,
+					},
+				},
+				"isError": true,
+			})
+		} else {
+			var status struct {
+				IsError bool `json:"isError"`
+			}
+			if err := json.Unmarshal(result, &status); err == nil {
+				isError = status.IsError
+			}
+		}
+		results = append(results, result)
+
+		if params.StopOnError && isError {
+			break
+		}
+	}
+
+	// Marshal result to JSON
+	resultBytes, err := json.Marshal(map[string]interface{}{
+		"results": results,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling result: %v\n", err)
+		return &JSONRPCMessage{
+			JsonRPC: "2.0",
+			ID:      message.ID,
+			Error: &ErrorMessage{
+				Code:    -32603,
+				Message: "Internal error",
+			},
+		}
+	}
+
+	// Return response
+	return &JSONRPCMessage{
+		JsonRPC: "2.0",
+		ID:      message.ID,
+		Result:  resultBytes,
+	}
+}
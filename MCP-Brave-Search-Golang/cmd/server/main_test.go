@@ -0,0 +1,231 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
+)
+
+// processLineResponse processes a single line and returns the response written
+func processLineResponse(t *testing.T, line string) JSONRPCMessage {
+	t.Helper()
+
+	var output bytes.Buffer
+	writer := bufio.NewWriter(&output)
+	processLine(line, writer)
+
+	var response JSONRPCMessage
+	if err := json.Unmarshal(output.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response %q: %v", output.String(), err)
+	}
+	return response
+}
+
+func TestStrictJSONRPC(t *testing.T) {
+	lines := []string{
+		`{"id": "1", "method": "ping"}`,
+		`{"jsonrpc": "1.0", "id": "2", "method": "ping"}`,
+	}
+
+	// Lenient mode dispatches messages without a valid jsonrpc version
+	strictJSONRPC = false
+	for _, line := range lines {
+		response := processLineResponse(t, line)
+		if response.Error == nil || response.Error.Code != -32601 {
+			t.Errorf("Expected %s to be dispatched in lenient mode, got %+v", line, response.Error)
+		}
+	}
+
+	strictJSONRPC = true
+	defer func() { strictJSONRPC = false }()
+	for _, line := range lines {
+		response := processLineResponse(t, line)
+		if response.Error == nil || response.Error.Code != -32600 {
+			t.Errorf("Expected a -32600 error for %s in strict mode, got %+v", line, response.Error)
+		}
+	}
+
+	response := processLineResponse(t, `{"jsonrpc": "2.0", "id": "3", "method": "ping"}`)
+	if response.Error == nil || response.Error.Code != -32601 {
+		t.Errorf("Expected a valid message to be dispatched in strict mode, got %+v", response.Error)
+	}
+}
+
+func TestServeJoinsMessageSplitAcrossReads(t *testing.T) {
+	reader, pipeWriter := io.Pipe()
+	go func() {
+		// The message arrives in two reads, each ending in a newline
+		io.WriteString(pipeWriter, `{"jsonrpc": "2.0", "id": "split",`+"\n")
+		io.WriteString(pipeWriter, ` "method": "ping"}`+"\n")
+		io.WriteString(pipeWriter, `{"jsonrpc": "2.0", "id": "whole", "method": "ping"}`+"\n")
+		pipeWriter.Close()
+	}()
+
+	var output bytes.Buffer
+	serve(reader, bufio.NewWriter(&output))
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 responses, got %d: %q", len(lines), output.String())
+	}
+	for i, id := range []string{"split", "whole"} {
+		var response JSONRPCMessage
+		if err := json.Unmarshal([]byte(lines[i]), &response); err != nil {
+			t.Fatalf("Failed to parse response %q: %v", lines[i], err)
+		}
+		if response.ID != id || response.Error == nil || response.Error.Code != -32601 {
+			t.Errorf("Expected a -32601 response to %q, got id %v and error %+v", id, response.ID, response.Error)
+		}
+	}
+}
+
+func TestServeReadsMessageLongerThan64KB(t *testing.T) {
+	padding := strings.Repeat("x", 100*1024)
+	message := `{"jsonrpc": "2.0", "id": "large", "method": "ping", "params": {"padding": "` + padding + `"}}` + "\n"
+
+	var output bytes.Buffer
+	serve(strings.NewReader(message), bufio.NewWriter(&output))
+
+	var response JSONRPCMessage
+	if err := json.Unmarshal(bytes.TrimSpace(output.Bytes()), &response); err != nil {
+		t.Fatalf("Expected a response to the large message, got %q: %v", output.String(), err)
+	}
+	if response.ID != "large" {
+		t.Errorf("Expected a response to %q, got id %v", "large", response.ID)
+	}
+}
+
+func TestMessageAccumulatorRecoversFromMalformedFragment(t *testing.T) {
+	var accumulator messageAccumulator
+
+	if _, complete := accumulator.Add(`{"jsonrpc": "2.0",`); complete {
+		t.Fatal("Expected an incomplete message to be held")
+	}
+
+	// A fragment that can't continue the held message replaces it
+	line := `{"jsonrpc": "2.0", "id": "1", "method": "ping"}`
+	if message, complete := accumulator.Add(line); !complete || message != line {
+		t.Errorf("Expected the complete message %q, got %q (complete %v)", line, message, complete)
+	}
+
+	// Malformed lines are passed through to be reported
+	if message, complete := accumulator.Add("not json"); !complete || message != "not json" {
+		t.Errorf("Expected a malformed line to be passed through, got %q (complete %v)", message, complete)
+	}
+}
+
+// temporaryError is a read error that reports itself as temporary
+type temporaryError struct{}
+
+func (temporaryError) Error() string   { return "resource temporarily unavailable" }
+func (temporaryError) Temporary() bool { return true }
+
+// flakyReader returns its chunks in turn, failing with err where a chunk is empty
+type flakyReader struct {
+	chunks []string
+	err    error
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+	chunk := r.chunks[0]
+	r.chunks = r.chunks[1:]
+	if chunk == "" {
+		return 0, r.err
+	}
+	return copy(p, chunk), nil
+}
+
+func TestServeResumesAfterTemporaryReadError(t *testing.T) {
+	readRetryDelay = 0
+	defer func() { readRetryDelay = 100 * time.Millisecond }()
+
+	reader := &flakyReader{
+		chunks: []string{
+			`{"jsonrpc": "2.0", "id": "before", "method": "ping"}` + "\n",
+			"",
+			`{"jsonrpc": "2.0", "id": "after", "method": "ping"}` + "\n",
+		},
+		err: temporaryError{},
+	}
+
+	var output bytes.Buffer
+	serve(reader, bufio.NewWriter(&output))
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected responses to the messages before and after the error, got %q", output.String())
+	}
+}
+
+func TestServeStopsOnPermanentReadError(t *testing.T) {
+	reader := &flakyReader{
+		chunks: []string{
+			`{"jsonrpc": "2.0", "id": "before", "method": "ping"}` + "\n",
+			"",
+			`{"jsonrpc": "2.0", "id": "after", "method": "ping"}` + "\n",
+		},
+		err: io.ErrClosedPipe,
+	}
+
+	var output bytes.Buffer
+	serve(reader, bufio.NewWriter(&output))
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected only the message before a closed pipe to be answered, got %q", output.String())
+	}
+}
+
+// panickingProvider is a fakeProvider whose web searches panic
+type panickingProvider struct {
+	fakeProvider
+}
+
+func (p *panickingProvider) WebSearch(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	var results []string
+	return results[opts.Count], 1, nil
+}
+
+func TestServeRecoversFromHandlerPanic(t *testing.T) {
+	originalProvider := provider
+	provider = &panickingProvider{}
+	defer func() { provider = originalProvider }()
+
+	initialized = true
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	input := `{"jsonrpc": "2.0", "id": "panic", "method": "tools/call", "params": {"name": "brave_web_search", "arguments": {"query": "panic"}}}` + "\n" +
+		`{"jsonrpc": "2.0", "id": "after", "method": "ping"}` + "\n"
+
+	var output bytes.Buffer
+	serve(strings.NewReader(input), bufio.NewWriter(&output))
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected responses to both messages, got %q", output.String())
+	}
+	for i, expected := range []struct {
+		id   string
+		code int
+	}{{"panic", -32603}, {"after", -32601}} {
+		var response JSONRPCMessage
+		if err := json.Unmarshal([]byte(lines[i]), &response); err != nil {
+			t.Fatalf("Failed to parse response %q: %v", lines[i], err)
+		}
+		if response.ID != expected.id || response.Error == nil || response.Error.Code != expected.code {
+			t.Errorf("Expected a %d response to %q, got id %v and error %+v", expected.code, expected.id, response.ID, response.Error)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import "encoding/json"
+
+// callMeta carries a tool call's request _meta for the tool cases to read,
+// and collects the entries they attach to the response _meta
+type callMeta struct {
+	request  map[string]json.RawMessage
+	response map[string]interface{}
+}
+
+// newCallMeta decodes a request _meta object; a missing or malformed _meta
+// reads as empty. The progressToken is echoed so clients can match the
+// result to the progress notifications they were sent.
+func newCallMeta(raw json.RawMessage) *callMeta {
+	meta := &callMeta{response: make(map[string]interface{})}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &meta.request); err != nil {
+			meta.request = nil
+		}
+	}
+	if token, ok := meta.request["progressToken"]; ok {
+		meta.response["progressToken"] = token
+	}
+	return meta
+}
+
+// Set attaches an entry to the response _meta
+func (m *callMeta) Set(key string, value interface{}) {
+	m.response[key] = value
+}
+
+// Response returns the response _meta, or nil when nothing was attached
+func (m *callMeta) Response() map[string]interface{} {
+	if len(m.response) == 0 {
+		return nil
+	}
+	return m.response
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// callToolMeta sends a tools/call message with the given request _meta and
+// returns the raw response _meta
+func callToolMeta(t *testing.T, name, arguments, meta string) json.RawMessage {
+	t.Helper()
+
+	params := `{"name": "` + name + `", "arguments": ` + arguments + `, "_meta": ` + meta + `}`
+	response := handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call", Params: json.RawMessage(params)})
+	if response.Error != nil {
+		t.Fatalf("Expected a result, got error %s", response.Error.Message)
+	}
+
+	var result struct {
+		Meta json.RawMessage `json:"_meta"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to parse tool result %s: %v", string(response.Result), err)
+	}
+	return result.Meta
+}
+
+func TestToolCallMeta(t *testing.T) {
+	originalProvider := provider
+	provider = &fakeProvider{}
+	defer func() { provider = originalProvider }()
+
+	initialized = true
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	// The progress token is echoed verbatim; other request entries, such as a key, are not
+	meta := callToolMeta(t, "brave_web_search", `{"query": "golang"}`, `{"progressToken": {"id": 7}, "api_key": "tenantA_1234567890"}`)
+	if string(meta) != `{"progressToken":{"id":7}}` {
+		t.Errorf("Expected the progress token in the response _meta, got %s", string(meta))
+	}
+	if strings.Contains(string(meta), "tenantA") {
+		t.Errorf("Expected the api_key to be left out of the response _meta, got %s", string(meta))
+	}
+
+	// Entries attached by the server are merged with the echoed token
+	debugTiming = true
+	defer func() { debugTiming = false }()
+	meta = callToolMeta(t, "brave_web_search", `{"query": "golang"}`, `{"progressToken": "abc"}`)
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(meta, &decoded); err != nil {
+		t.Fatalf("Failed to parse response _meta %s: %v", string(meta), err)
+	}
+	if decoded["progressToken"] != "abc" {
+		t.Errorf("Expected progressToken abc, got %v", decoded["progressToken"])
+	}
+	if _, ok := decoded["durationMs"]; !ok {
+		t.Errorf("Expected durationMs alongside the progress token, got %s", string(meta))
+	}
+
+	// Without a progress token or timing there is no response _meta
+	debugTiming = false
+	if meta := callToolMeta(t, "brave_web_search", `{"query": "golang"}`, `{"trace": "x"}`); meta != nil {
+		t.Errorf("Expected no response _meta, got %s", string(meta))
+	}
+}
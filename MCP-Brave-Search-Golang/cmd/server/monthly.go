@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// nextMonthStart returns midnight UTC on the first day of the month after now
+func nextMonthStart(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+// scheduleMonthlyReset resets the monthly request counts of the shared and
+// per-key rate limiters at the start of each month (UTC), until done is
+// closed
+func scheduleMonthlyReset(done <-chan struct{}) {
+	for {
+		timer := time.NewTimer(time.Until(nextMonthStart(time.Now())))
+		select {
+		case <-timer.C:
+			previous := rateLimiter.ResetMonthlyCounter()
+			keyLimiters.ResetMonthlyCounters()
+			fmt.Fprintf(os.Stderr, "Reset monthly rate limit counter (previous count: %d)\n", previous)
+		case <-done:
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextMonthStart(t *testing.T) {
+	tests := []struct {
+		now      time.Time
+		expected time.Time
+	}{
+		{time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC), time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 1, 31, 8, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		// Midnight on the first already counts as the new month
+		{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		// Times in other zones are compared in UTC
+		{time.Date(2024, 7, 1, 1, 0, 0, 0, time.FixedZone("CEST", 2*60*60)), time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := nextMonthStart(tt.now); !got.Equal(tt.expected) {
+			t.Errorf("nextMonthStart(%v) = %v, expected %v", tt.now, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
+)
+
+// SearchProvider performs the searches behind the search tools. Brave is the
+// only implementation, but tool handlers depend on this interface so another
+// provider, or a composite that falls back from one to another, can be used.
+// Each search also returns the number of requests it sent to the provider's
+// API, which the cost budget and the debug output rely on.
+type SearchProvider interface {
+	WebSearch(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, int, error)
+	LocalSearch(apiKey, query string, count int, searchLang string, reference *brave.Coordinates, minRating float64, rateLimiter *ratelimit.RateLimiter) (string, int, error)
+}
+
+// ThumbnailSearchProvider is implemented by providers that can also return
+// result thumbnails from a web search
+type ThumbnailSearchProvider interface {
+	WebSearchWithThumbnails(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, []brave.Thumbnail, int, error)
+}
+
+// StructuredSearchProvider is implemented by providers that can return web
+// search results as data rather than formatted text
+type StructuredSearchProvider interface {
+	WebSearchStructured(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) ([]brave.WebResult, int, error)
+}
+
+// NewsSearchProvider is implemented by providers that can search news articles
+type NewsSearchProvider interface {
+	NewsSearch(apiKey, query string, count int, freshness string, rateLimiter *ratelimit.RateLimiter) (string, int, error)
+}
+
+// CombinedSearchProvider is implemented by providers that can blend web and
+// news results in a single search
+type CombinedSearchProvider interface {
+	CombinedSearch(apiKey, query string, count int, rateLimiter *ratelimit.RateLimiter) (string, int, error)
+}
+
+// provider handles all searches made by the server
+var provider SearchProvider = braveClient
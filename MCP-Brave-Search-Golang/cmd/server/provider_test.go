@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
+)
+
+// fakeProvider records the searches it is asked to perform, reporting one
+// request sent for each
+type fakeProvider struct {
+	webQueries   []string
+	localQueries []string
+}
+
+func (f *fakeProvider) WebSearch(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	f.webQueries = append(f.webQueries, query)
+	return "web results for " + query, 1, nil
+}
+
+func (f *fakeProvider) LocalSearch(apiKey, query string, count int, searchLang string, reference *brave.Coordinates, minRating float64, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	f.localQueries = append(f.localQueries, query)
+	return "local results for " + query, 1, nil
+}
+
+// structuredProvider is a fakeProvider that can also return structured web results
+type structuredProvider struct {
+	fakeProvider
+}
+
+func (s *structuredProvider) WebSearchStructured(apiKey, query string, opts brave.WebSearchOptions, rateLimiter *ratelimit.RateLimiter) ([]brave.WebResult, int, error) {
+	s.webQueries = append(s.webQueries, query)
+	return []brave.WebResult{{Title: "Go", URL: "https://go.dev", PageAge: "2024-01-02T00:00:00"}}, 1, nil
+}
+
+// callTool sends a tools/call message for the given tool and returns the text of the first content item
+func callTool(t *testing.T, name, arguments string) string {
+	t.Helper()
+
+	params := `{"name": "` + name + `", "arguments": ` + arguments + `}`
+	response := handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call", Params: json.RawMessage(params)})
+	if response.Error != nil {
+		t.Fatalf("Expected a result, got error %s", response.Error.Message)
+	}
+
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil || len(result.Content) == 0 {
+		t.Fatalf("Failed to parse tool result %s: %v", string(response.Result), err)
+	}
+	return result.Content[0].Text
+}
+
+func TestToolsUseSearchProvider(t *testing.T) {
+	fake := &fakeProvider{}
+	originalProvider := provider
+	provider = fake
+	defer func() { provider = originalProvider }()
+
+	initialized = true
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	if text := callTool(t, "brave_web_search", `{"query": "golang"}`); text != "web results for golang" {
+		t.Errorf("Expected web results from the provider, got %q", text)
+	}
+	if text := callTool(t, "brave_local_search", `{"query": "pizza"}`); text != "local results for pizza" {
+		t.Errorf("Expected local results from the provider, got %q", text)
+	}
+
+	// Providers without thumbnail support still serve thumbnail requests as plain searches
+	if text := callTool(t, "brave_web_search", `{"query": "gophers", "include_thumbnails": true}`); !strings.Contains(text, "gophers") {
+		t.Errorf("Expected web results without thumbnails, got %q", text)
+	}
+
+	if strings.Join(fake.webQueries, ",") != "golang,gophers" || strings.Join(fake.localQueries, ",") != "pizza" {
+		t.Errorf("Expected provider to receive each query, got web %v and local %v", fake.webQueries, fake.localQueries)
+	}
+}
+
+func TestWebSearchJSONFormat(t *testing.T) {
+	fake := &structuredProvider{}
+	originalProvider := provider
+	provider = fake
+	defer func() { provider = originalProvider }()
+
+	initialized = true
+	apiKey = "default-key-0000000000"
+	rateLimiter = ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	keyLimiters = ratelimit.NewKeyedLimiters(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	text := callTool(t, "brave_web_search", `{"query": "golang", "format": "json"}`)
+	var results []brave.WebResult
+	if err := json.Unmarshal([]byte(text), &results); err != nil {
+		t.Fatalf("Expected a JSON array of results, got %q: %v", text, err)
+	}
+	if len(results) != 1 || results[0].URL != "https://go.dev" || results[0].PageAge != "2024-01-02T00:00:00" {
+		t.Errorf("Expected the provider's result with every field, got %+v", results)
+	}
+
+	// The same results are returned as structured content alongside the text
+	params := `{"name": "brave_web_search", "arguments": {"query": "golang", "format": "json"}}`
+	response := handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call", Params: json.RawMessage(params)})
+	var result struct {
+		Content           []map[string]interface{} `json:"content"`
+		StructuredContent struct {
+			Results []brave.WebResult `json:"results"`
+		} `json:"structuredContent"`
+	}
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		t.Fatalf("Failed to parse tool result %s: %v", string(response.Result), err)
+	}
+	if len(result.Content) != 1 || len(result.StructuredContent.Results) != 1 || result.StructuredContent.Results[0].URL != "https://go.dev" {
+		t.Errorf("Expected text content and structured results, got %s", string(response.Result))
+	}
+
+	// Text results have no structured content
+	params = `{"name": "brave_web_search", "arguments": {"query": "golang"}}`
+	response = handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call", Params: json.RawMessage(params)})
+	if strings.Contains(string(response.Result), "structuredContent") {
+		t.Errorf("Expected no structured content for text results, got %s", string(response.Result))
+	}
+
+	// The default format is still text
+	if text := callTool(t, "brave_web_search", `{"query": "golang"}`); text != "web results for golang" {
+		t.Errorf("Expected text results by default, got %q", text)
+	}
+
+	params = `{"name": "brave_web_search", "arguments": {"query": "golang", "format": "xml"}}`
+	response = handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call", Params: json.RawMessage(params)})
+	if response.Error == nil || response.Error.Code != -32602 {
+		t.Errorf("Expected an invalid params error for an unknown format, got %+v", response)
+	}
+}
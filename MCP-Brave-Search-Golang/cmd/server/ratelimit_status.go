@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// rateLimitStatusTool defines the schema for the brave_rate_limit_status tool
+var rateLimitStatusTool = map[string]interface{}{
+	"name": "brave_rate_limit_status",
+	"description": "Reports how much of the Brave Search rate limit is used: requests in the last second " +
+		"and this month against the configured limits, how many searches are left this month, and the " +
+		"quota Brave last reported. Reports on the caller's own quota when api_key is given. " +
+		"Does not use any quota.",
+	"inputSchema": map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	},
+}
+
+// rateLimitStatus formats a rate limiter's usage for brave_rate_limit_status
+func rateLimitStatus(stats ratelimit.Stats) string {
+	remaining := stats.MonthlyLimit - stats.MonthlyCount
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	lines := []string{
+		fmt.Sprintf("Per second: %d of %d requests", stats.PerSecondCount, stats.PerSecondLimit),
+		fmt.Sprintf("This month: %d of %d requests (%d remaining)", stats.MonthlyCount, stats.MonthlyLimit, remaining),
+		fmt.Sprintf("Monthly count resets: %s", nextMonthStart(time.Now()).Format(time.RFC3339)),
+	}
+	if stats.APISynced {
+		lines = append(lines, fmt.Sprintf("Brave reported: %d requests remaining until %s",
+			stats.APIRemaining, stats.APIReset.UTC().Format(time.RFC3339)))
+	}
+	return strings.Join(lines, "\n")
+}
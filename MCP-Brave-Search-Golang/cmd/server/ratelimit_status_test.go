@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestRateLimitStatus(t *testing.T) {
+	status := rateLimitStatus(ratelimit.Stats{
+		PerSecondCount: 1,
+		PerSecondLimit: 1,
+		MonthlyCount:   1500,
+		MonthlyLimit:   2000,
+	})
+
+	for _, expected := range []string{"Per second: 1 of 1 requests", "This month: 1500 of 2000 requests (500 remaining)"} {
+		if !strings.Contains(status, expected) {
+			t.Errorf("Expected status to contain %q, got:\n%s", expected, status)
+		}
+	}
+	if strings.Contains(status, "Brave reported") {
+		t.Errorf("Expected no API quota line without a synced quota, got:\n%s", status)
+	}
+
+	reset := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	status = rateLimitStatus(ratelimit.Stats{
+		MonthlyCount: 2100,
+		MonthlyLimit: 2000,
+		APISynced:    true,
+		APIRemaining: 0,
+		APIReset:     reset,
+	})
+	for _, expected := range []string{"(0 remaining)", "Brave reported: 0 requests remaining until 2024-06-01T00:00:00Z"} {
+		if !strings.Contains(status, expected) {
+			t.Errorf("Expected status to contain %q, got:\n%s", expected, status)
+		}
+	}
+}
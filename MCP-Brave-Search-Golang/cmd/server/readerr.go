@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// maxReadFailures is how many recoverable read errors in a row serve
+// tolerates before giving up on its input
+const maxReadFailures = 5
+
+// readRetryDelay is how long serve waits before reading again after a
+// recoverable error
+var readRetryDelay = 100 * time.Millisecond
+
+// isRecoverableReadError reports whether reading can be retried after err,
+// as for an interrupted or timed out read. A closed input never recovers.
+func isRecoverableReadError(err error) bool {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed) {
+		return false
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) && temporary.Temporary() {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// allowedSearchLangs restricts the search_lang argument; empty allows any language
+var allowedSearchLangs []string
+
+// checkSearchLang rejects a tool call whose search_lang argument is not on the
+// configured allowlist. Calls without search_lang, and all calls when no
+// allowlist is configured, are accepted.
+func checkSearchLang(arguments json.RawMessage) error {
+	if len(allowedSearchLangs) == 0 {
+		return nil
+	}
+
+	var args struct {
+		SearchLang string `json:"search_lang"`
+	}
+	if len(arguments) > 0 {
+		json.Unmarshal(arguments, &args)
+	}
+	if args.SearchLang == "" {
+		return nil
+	}
+
+	for _, lang := range allowedSearchLangs {
+		if strings.EqualFold(args.SearchLang, lang) {
+			return nil
+		}
+	}
+	return fmt.Errorf("search_lang %q is not allowed: must be one of %s", args.SearchLang, strings.Join(allowedSearchLangs, ", "))
+}
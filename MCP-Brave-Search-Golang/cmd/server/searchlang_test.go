@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCheckSearchLang(t *testing.T) {
+	allowedSearchLangs = nil
+	if err := checkSearchLang(json.RawMessage(`{"query": "go", "search_lang": "fr"}`)); err != nil {
+		t.Errorf("Expected any language without an allowlist, got %v", err)
+	}
+
+	allowedSearchLangs = []string{"en", "de"}
+	defer func() { allowedSearchLangs = nil }()
+
+	for _, lang := range []string{"en", "de", "EN"} {
+		if err := checkSearchLang(json.RawMessage(`{"query": "go", "search_lang": "` + lang + `"}`)); err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", lang, err)
+		}
+	}
+
+	if err := checkSearchLang(json.RawMessage(`{"query": "go"}`)); err != nil {
+		t.Errorf("Expected a call without search_lang to be allowed, got %v", err)
+	}
+
+	err := checkSearchLang(json.RawMessage(`{"query": "go", "search_lang": "fr"}`))
+	if err == nil || !strings.Contains(err.Error(), `"fr"`) || !strings.Contains(err.Error(), "en, de") {
+		t.Errorf("Expected an error naming the language and the allowlist, got %v", err)
+	}
+}
+
+func TestDisallowedSearchLangRejected(t *testing.T) {
+	fake := &fakeProvider{}
+	originalProvider := provider
+	provider = fake
+	defer func() { provider = originalProvider }()
+
+	initialized = true
+	allowedSearchLangs = []string{"en"}
+	defer func() { allowedSearchLangs = nil }()
+
+	params := `{"name": "brave_web_search", "arguments": {"query": "golang", "search_lang": "fr"}}`
+	response := handleToolsCall(JSONRPCMessage{JsonRPC: "2.0", ID: "1", Method: "tools/call", Params: json.RawMessage(params)})
+	if response.Error == nil || response.Error.Code != -32602 {
+		t.Fatalf("Expected an invalid params error, got %+v", response)
+	}
+	if len(fake.webQueries) != 0 {
+		t.Errorf("Expected no search for a disallowed language, got %v", fake.webQueries)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/pkg/brave"
+)
+
+// braveClient is the Brave client behind provider, kept for its statistics
+var braveClient = brave.NewClient()
+
+// statusTool defines the schema for the brave_status tool
+var statusTool = map[string]interface{}{
+	"name": "brave_status",
+	"description": "Reports the state of the connection to the Brave Search API: " +
+		"the moving average of response latency, the timeout currently applied to requests, " +
+		"how many connections were opened or reused, and the cost budget spent today and this month " +
+		"when one is configured. Does not use any quota.",
+	"inputSchema": map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	},
+}
+
+// serverStatus returns the brave_status report as indented JSON
+func serverStatus() (string, error) {
+	created, reused := brave.ConnectionStats()
+	status := map[string]interface{}{
+		"latencyEmaMs":       braveClient.LatencyEMA().Milliseconds(),
+		"requestTimeoutMs":   braveClient.RequestTimeout().Milliseconds(),
+		"connectionsCreated": created,
+		"connectionsReused":  reused,
+	}
+	if costBudget != nil {
+		status["costBudget"] = costBudget.Stats()
+	}
+
+	data, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
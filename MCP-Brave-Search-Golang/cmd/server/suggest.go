@@ -0,0 +1,54 @@
+package main
+
+import "sort"
+
+// maxSuggestionDistance is the largest edit distance for which a method is suggested
+const maxSuggestionDistance = 3
+
+// suggestMethod returns the known method closest to an unknown one, or an
+// empty string if nothing is close enough to be a likely typo
+func suggestMethod(method string, known []string) string {
+	// Sort for a deterministic choice between equally close candidates
+	candidates := append([]string(nil), known...)
+	sort.Strings(candidates)
+
+	best := ""
+	bestDistance := maxSuggestionDistance + 1
+	for _, candidate := range candidates {
+		distance := levenshtein(method, candidate)
+		if distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	// Don't suggest for very short inputs where almost anything is "close"
+	if bestDistance >= len(method) {
+		return ""
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// entry is a cached value and when it expires
+type entry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+// Cache holds string values for a fixed time, evicting the least recently
+// used entry once it is full. It is safe for concurrent use.
+type Cache struct {
+	ttl        time.Duration
+	maxEntries int
+	order      *list.List // most recently used at the front
+	entries    map[string]*list.Element
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+// New creates a cache keeping values for ttl, holding at most maxEntries. A
+// zero ttl or maxEntries disables the cache.
+func New(ttl time.Duration, maxEntries int) *Cache {
+	return &Cache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		now:        time.Now,
+	}
+}
+
+// enabled reports whether the cache stores anything
+func (c *Cache) enabled() bool {
+	return c.ttl > 0 && c.maxEntries > 0
+}
+
+// Get returns the value cached for key if it hasn't expired
+func (c *Cache) Get(key string) (string, bool) {
+	if !c.enabled() {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	e := element.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Set caches value for key, evicting the least recently used entry if the
+// cache is full
+func (c *Cache) Set(key, value string) {
+	if !c.enabled() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if element, ok := c.entries[key]; ok {
+		e := element.Value.(*entry)
+		e.value = value
+		e.expires = expires
+		c.order.MoveToFront(element)
+		return
+	}
+
+	for c.order.Len() >= c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).key)
+	}
+	c.entries[key] = c.order.PushFront(&entry{key: key, value: value, expires: expires})
+}
+
+// Len returns the number of entries held, including any that have expired
+// but not yet been evicted
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock returns a settable time for tests of expiry
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.current }
+
+func TestCacheExpiresEntries(t *testing.T) {
+	clock := &fakeClock{current: time.Now()}
+	c := New(time.Minute, 10)
+	c.now = clock.now
+
+	c.Set("query", "results")
+	if value, ok := c.Get("query"); !ok || value != "results" {
+		t.Errorf("Expected the cached results, got %q (found %v)", value, ok)
+	}
+
+	clock.current = clock.current.Add(time.Minute)
+	if _, ok := c.Get("query"); ok {
+		t.Error("Expected the entry to expire after the TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Expected the expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := New(time.Minute, 2)
+
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Get("a") // a is now more recently used than b
+	c.Set("c", "3")
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Errorf("Expected %s to still be cached", key)
+		}
+	}
+}
+
+func TestCacheDisabled(t *testing.T) {
+	for _, c := range []*Cache{New(0, 10), New(time.Minute, 0)} {
+		c.Set("query", "results")
+		if _, ok := c.Get("query"); ok {
+			t.Error("Expected a disabled cache to store nothing")
+		}
+	}
+}
+
+func TestCacheConcurrentAccess(t *testing.T) {
+	c := New(time.Minute, 50)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				key := fmt.Sprintf("%d-%d", worker, j%100)
+				c.Set(key, key)
+				c.Get(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if c.Len() > 50 {
+		t.Errorf("Expected at most 50 entries, got %d", c.Len())
+	}
+}
@@ -0,0 +1,67 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// entry is the last result recorded for a key
+type entry struct {
+	at     time.Time
+	result string
+}
+
+// Debouncer remembers recent results so that a request repeated within a
+// minimum interval can be answered without repeating the work
+type Debouncer struct {
+	interval time.Duration
+	entries  map[string]entry
+	mu       sync.Mutex
+}
+
+// New creates a debouncer; a zero interval disables it
+func New(interval time.Duration) *Debouncer {
+	return &Debouncer{
+		interval: interval,
+		entries:  make(map[string]entry),
+	}
+}
+
+// Interval returns the minimum interval between identical requests
+func (d *Debouncer) Interval() time.Duration {
+	return d.interval
+}
+
+// Recent returns the result recorded for key if it is younger than the interval
+func (d *Debouncer) Recent(key string) (string, bool) {
+	if d.interval <= 0 {
+		return "", false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e, ok := d.entries[key]
+	if !ok || time.Since(e.at) >= d.interval {
+		return "", false
+	}
+	return e.result, true
+}
+
+// Record stores the result for key and drops entries older than the interval
+func (d *Debouncer) Record(key, result string) {
+	if d.interval <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range d.entries {
+		if now.Sub(e.at) >= d.interval {
+			delete(d.entries, k)
+		}
+	}
+	d.entries[key] = entry{at: now, result: result}
+}
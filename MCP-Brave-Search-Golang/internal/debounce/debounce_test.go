@@ -0,0 +1,35 @@
+package debounce
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDebouncer(t *testing.T) {
+	d := New(50 * time.Millisecond)
+
+	if _, ok := d.Recent("q"); ok {
+		t.Fatal("Expected no result before anything is recorded")
+	}
+
+	d.Record("q", "result")
+	if result, ok := d.Recent("q"); !ok || result != "result" {
+		t.Errorf("Expected recorded result within the interval, got %q, %t", result, ok)
+	}
+	if _, ok := d.Recent("other"); ok {
+		t.Error("Expected no result for a different key")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := d.Recent("q"); ok {
+		t.Error("Expected result to expire after the interval")
+	}
+}
+
+func TestDebouncerDisabled(t *testing.T) {
+	d := New(0)
+	d.Record("q", "result")
+	if _, ok := d.Recent("q"); ok {
+		t.Error("Expected a zero interval to disable debouncing")
+	}
+}
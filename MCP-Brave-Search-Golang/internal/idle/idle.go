@@ -0,0 +1,75 @@
+package idle
+
+import (
+	"sync"
+	"time"
+)
+
+// Monitor signals when no request has been in flight for a configured duration
+type Monitor struct {
+	timeout  time.Duration
+	timer    *time.Timer
+	inFlight int
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+}
+
+// NewMonitor creates a new idle monitor; a zero timeout disables it
+func NewMonitor(timeout time.Duration) *Monitor {
+	return &Monitor{
+		timeout: timeout,
+		done:    make(chan struct{}),
+	}
+}
+
+// Done returns a channel that is closed when the idle timeout fires.
+// The channel is never closed if the timeout is disabled.
+func (m *Monitor) Done() <-chan struct{} {
+	return m.done
+}
+
+// Start arms the idle timer
+func (m *Monitor) Start() {
+	if m.timeout <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timer = time.AfterFunc(m.timeout, m.fire)
+}
+
+// Begin marks a request as in flight, preventing the idle timeout from firing
+func (m *Monitor) Begin() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+}
+
+// End marks a request as complete and restarts the idle timer once nothing is in flight
+func (m *Monitor) End() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight > 0 {
+		m.inFlight--
+	}
+	if m.inFlight == 0 && m.timer != nil {
+		m.timer.Reset(m.timeout)
+	}
+}
+
+// fire is called by the timer and signals idle unless a request is in flight
+func (m *Monitor) fire() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight > 0 {
+		return
+	}
+	m.once.Do(func() {
+		close(m.done)
+	})
+}
@@ -0,0 +1,57 @@
+package pool
+
+import "sync"
+
+// Pool bounds the number of goroutines running concurrent operations across
+// the whole server, regardless of how many requests arrive at once
+type Pool struct {
+	slots chan struct{}
+}
+
+// Group tracks a batch of tasks submitted to a pool so the caller can wait
+// for just its own tasks to finish
+type Group struct {
+	pool *Pool
+	wg   sync.WaitGroup
+}
+
+// New creates a pool that runs at most max tasks at a time
+func New(max int) *Pool {
+	if max <= 0 {
+		max = 1
+	}
+	return &Pool{
+		slots: make(chan struct{}, max),
+	}
+}
+
+// Max returns the maximum number of concurrently running tasks
+func (p *Pool) Max() int {
+	return cap(p.slots)
+}
+
+// Group creates a new group of tasks sharing the pool's limit
+func (p *Pool) Group() *Group {
+	return &Group{pool: p}
+}
+
+// Submit runs task in a new goroutine once a slot is free, blocking the
+// caller while the pool is at capacity. Tasks must not submit to the same
+// pool themselves, as that can deadlock once every slot is taken.
+func (g *Group) Submit(task func()) {
+	g.pool.slots <- struct{}{}
+	g.wg.Add(1)
+
+	go func() {
+		defer func() {
+			<-g.pool.slots
+			g.wg.Done()
+		}()
+		task()
+	}()
+}
+
+// Wait blocks until every task submitted to the group has finished
+func (g *Group) Wait() {
+	g.wg.Wait()
+}
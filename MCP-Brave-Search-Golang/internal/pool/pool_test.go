@@ -0,0 +1,67 @@
+package pool
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolCapsConcurrency(t *testing.T) {
+	const max = 3
+	p := New(max)
+
+	var running, peak, completed int32
+	var wg sync.WaitGroup
+
+	// Several groups submitting at once share the same cap
+	for g := 0; g < 5; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			group := p.Group()
+			for i := 0; i < 10; i++ {
+				group.Submit(func() {
+					current := atomic.AddInt32(&running, 1)
+					for {
+						old := atomic.LoadInt32(&peak)
+						if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+							break
+						}
+					}
+					time.Sleep(time.Millisecond)
+					atomic.AddInt32(&running, -1)
+					atomic.AddInt32(&completed, 1)
+				})
+			}
+			group.Wait()
+		}()
+	}
+	wg.Wait()
+
+	if peak > max {
+		t.Errorf("Expected at most %d concurrent tasks, observed %d", max, peak)
+	}
+	if completed != 50 {
+		t.Errorf("Expected 50 completed tasks, got %d", completed)
+	}
+}
+
+func TestGroupWaitOnlyWaitsForOwnTasks(t *testing.T) {
+	p := New(2)
+
+	block := make(chan struct{})
+	other := p.Group()
+	other.Submit(func() { <-block })
+
+	group := p.Group()
+	done := false
+	group.Submit(func() { done = true })
+	group.Wait()
+
+	if !done {
+		t.Error("Expected group task to finish before Wait returned")
+	}
+	close(block)
+	other.Wait()
+}
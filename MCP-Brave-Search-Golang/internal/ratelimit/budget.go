@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is returned when an operation would exceed the cost budget
+var ErrBudgetExhausted = errors.New("cost budget exhausted")
+
+// CostBudget tracks the cost of operations against daily and monthly
+// budgets, so operations that fan out into several API requests count for
+// more than a single search. Spending resets at midnight UTC and on the
+// first day of each month (UTC), as the monthly request count does.
+type CostBudget struct {
+	dailyLimit   int // 0 means no daily budget
+	monthlyLimit int // 0 means no monthly budget
+	dailySpent   int
+	monthlySpent int
+	dayStart     time.Time // start of the day dailySpent counts
+	monthStart   time.Time // start of the month monthlySpent counts
+	now          func() time.Time
+	mu           sync.Mutex
+}
+
+// NewCostBudget creates a budget allowing daily and monthly total costs; a
+// limit of 0 leaves that period unlimited. With neither set it returns nil,
+// which allows everything.
+func NewCostBudget(daily, monthly int) *CostBudget {
+	if daily <= 0 && monthly <= 0 {
+		return nil
+	}
+	return &CostBudget{
+		dailyLimit:   daily,
+		monthlyLimit: monthly,
+		now:          time.Now,
+	}
+}
+
+// Charge adds cost to the spending if it fits within both budgets, or
+// otherwise returns an error wrapping ErrBudgetExhausted that says when the
+// exhausted budget resets. A nil budget allows every charge.
+func (b *CostBudget) Charge(cost int) error {
+	if b == nil || cost <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.roll(now)
+
+	if b.dailyLimit > 0 && b.dailySpent+cost > b.dailyLimit {
+		return fmt.Errorf("%w: %d of the daily budget of %d is spent and this operation costs %d; it resets in %v",
+			ErrBudgetExhausted, b.dailySpent, b.dailyLimit, cost, b.dayStart.AddDate(0, 0, 1).Sub(now).Round(time.Second))
+	}
+	if b.monthlyLimit > 0 && b.monthlySpent+cost > b.monthlyLimit {
+		return fmt.Errorf("%w: %d of the monthly budget of %d is spent and this operation costs %d; it resets in %v",
+			ErrBudgetExhausted, b.monthlySpent, b.monthlyLimit, cost, b.monthStart.AddDate(0, 1, 0).Sub(now).Round(time.Second))
+	}
+
+	b.dailySpent += cost
+	b.monthlySpent += cost
+	return nil
+}
+
+// Refund gives back cost charged for an operation that turned out not to
+// make any API requests. Spending never drops below zero, so a refund after
+// the budget has reset doesn't carry over into the new period.
+func (b *CostBudget) Refund(cost int) {
+	if b == nil || cost <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(b.now())
+	b.dailySpent = max(b.dailySpent-cost, 0)
+	b.monthlySpent = max(b.monthlySpent-cost, 0)
+}
+
+// roll resets the spending of a day or month that has ended
+func (b *CostBudget) roll(now time.Time) {
+	now = now.UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	if !day.Equal(b.dayStart) {
+		b.dayStart = day
+		b.dailySpent = 0
+	}
+	if !month.Equal(b.monthStart) {
+		b.monthStart = month
+		b.monthlySpent = 0
+	}
+}
+
+// BudgetStats is a snapshot of a cost budget's spending and limits
+type BudgetStats struct {
+	DailySpent   int       `json:"dailySpent"`
+	DailyLimit   int       `json:"dailyLimit,omitempty"`
+	DailyReset   time.Time `json:"dailyReset"`
+	MonthlySpent int       `json:"monthlySpent"`
+	MonthlyLimit int       `json:"monthlyLimit,omitempty"`
+	MonthlyReset time.Time `json:"monthlyReset"`
+}
+
+// Stats returns the current spending of the budget
+func (b *CostBudget) Stats() BudgetStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.roll(b.now())
+	return BudgetStats{
+		DailySpent:   b.dailySpent,
+		DailyLimit:   b.dailyLimit,
+		DailyReset:   b.dayStart.AddDate(0, 0, 1),
+		MonthlySpent: b.monthlySpent,
+		MonthlyLimit: b.monthlyLimit,
+		MonthlyReset: b.monthStart.AddDate(0, 1, 0),
+	}
+}
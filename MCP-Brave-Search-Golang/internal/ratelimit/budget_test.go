@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCostBudget(t *testing.T) {
+	now := time.Date(2024, 1, 31, 22, 0, 0, 0, time.UTC)
+	budget := NewCostBudget(5, 8)
+	budget.now = func() time.Time { return now }
+
+	// A local search costing 3 and two web searches fill the daily budget
+	for _, cost := range []int{3, 1, 1} {
+		if err := budget.Charge(cost); err != nil {
+			t.Fatalf("Expected a charge of %d to fit, got %v", cost, err)
+		}
+	}
+
+	err := budget.Charge(1)
+	if !errors.Is(err, ErrBudgetExhausted) || !strings.Contains(err.Error(), "daily budget of 5") || !strings.Contains(err.Error(), "resets in 2h0m0s") {
+		t.Fatalf("Expected the daily budget to be exhausted until midnight, got %v", err)
+	}
+
+	stats := budget.Stats()
+	if stats.DailySpent != 5 || stats.MonthlySpent != 5 || !stats.DailyReset.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 5 spent today and this month, got %+v", stats)
+	}
+
+	// The next day has a fresh daily budget, but it's also a new month
+	now = now.Add(3 * time.Hour)
+	if err := budget.Charge(3); err != nil {
+		t.Fatalf("Expected the budget to reset at midnight, got %v", err)
+	}
+	if stats := budget.Stats(); stats.DailySpent != 3 || stats.MonthlySpent != 3 {
+		t.Errorf("Expected both budgets to reset on the first of the month, got %+v", stats)
+	}
+
+	// The monthly budget carries over days
+	now = now.AddDate(0, 0, 1)
+	if err := budget.Charge(5); err != nil {
+		t.Fatalf("Expected a charge of 5 to fit, got %v", err)
+	}
+	now = now.AddDate(0, 0, 1)
+	err = budget.Charge(1)
+	if !errors.Is(err, ErrBudgetExhausted) || !strings.Contains(err.Error(), "monthly budget of 8") {
+		t.Errorf("Expected the monthly budget to be exhausted, got %v", err)
+	}
+}
+
+func TestCostBudgetUnlimited(t *testing.T) {
+	budget := NewCostBudget(0, 0)
+	if budget != nil {
+		t.Fatalf("Expected no budget without limits")
+	}
+	if err := budget.Charge(100); err != nil {
+		t.Errorf("Expected a nil budget to allow every charge, got %v", err)
+	}
+}
@@ -1,7 +1,10 @@
 package ratelimit
 
 import (
+	"context"
+	"crypto/sha256"
 	"errors"
+	"math"
 	"sync"
 	"time"
 )
@@ -12,58 +15,245 @@ type RateLimits struct {
 	PerMonth  int
 }
 
-// RateLimiter manages rate limiting for API requests
+// RateLimiter manages rate limiting for API requests. The per-second limit
+// is a token bucket holding up to PerSecond tokens and refilled at PerSecond
+// tokens a second, so no one-second span ever sees more than about
+// PerSecond requests, unlike a counter reset at window boundaries. The
+// monthly limit is a plain counter.
 type RateLimiter struct {
-	limits      RateLimits
-	requestCount struct {
-		second int
-		month  int
-	}
-	lastReset time.Time
-	mu        sync.Mutex
+	limits       RateLimits
+	tokens       float64   // requests available in the per-second bucket
+	lastRefill   time.Time // when tokens was last brought up to date
+	monthlyCount int
+	now          func() time.Time
+	// The quota reported by the API, which applies until its reset time
+	synced          bool
+	syncedRemaining int
+	syncedReset     time.Time
+	mu              sync.Mutex
 }
 
 // ErrRateLimitExceeded is returned when the rate limit is exceeded
 var ErrRateLimitExceeded = errors.New("rate limit exceeded")
 
-// NewRateLimiter creates a new rate limiter with the given limits
+// NewRateLimiter creates a new rate limiter with the given limits. The
+// per-second bucket starts full.
 func NewRateLimiter(limits RateLimits) *RateLimiter {
 	return &RateLimiter{
-		limits:    limits,
-		lastReset: time.Now(),
+		limits:     limits,
+		tokens:     float64(limits.PerSecond),
+		lastRefill: time.Now(),
+		now:        time.Now,
 	}
 }
 
 // CheckLimit checks if the request is within rate limits and increments counters
 func (r *RateLimiter) CheckLimit() error {
+	return r.Reserve(1)
+}
+
+// Reserve checks that n requests fit within the rate limits and counts them
+// all at once, so a call needing several requests gets either all of them or
+// none. Until the reset time of the last Sync, the quota the API reported is
+// also enforced.
+func (r *RateLimiter) Reserve(n int) error {
+	delay, err := r.reserveOrDelay(n)
+	if err != nil {
+		return err
+	}
+	if delay > 0 {
+		return ErrRateLimitExceeded
+	}
+	return nil
+}
+
+// Wait blocks until the per-second limit, and the quota the API reported,
+// allow another request, then counts it. It fails without waiting if the
+// monthly limit is used up, and returns the context's error if the context
+// is done first, or straight away if the wait would outlast its deadline.
+func (r *RateLimiter) Wait(ctx context.Context) error {
+	return r.WaitN(ctx, 1)
+}
+
+// WaitN blocks until the per-second limit allows n requests at once, then
+// counts them all, as Wait does for a single request
+func (r *RateLimiter) WaitN(ctx context.Context, n int) error {
+	for {
+		delay, err := r.reserveOrDelay(n)
+		if err != nil || delay == 0 {
+			return err
+		}
+		// Don't sit out a wait the context won't outlast
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return context.DeadlineExceeded
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserveOrDelay reserves n requests if the bucket holds enough tokens and
+// the quota the API reported allows them, or otherwise returns how long
+// until it will. Limits that waiting can't help
+// with are returned as errors.
+func (r *RateLimiter) reserveOrDelay(n int) (time.Duration, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now()
-	
-	// Reset second counter if it's been more than a second
-	if now.Sub(r.lastReset) > time.Second {
-		r.requestCount.second = 0
-		r.lastReset = now
+	now := r.now()
+	r.refill(now)
+
+	// Forget the API's quota once its window has reset
+	if r.synced && !now.Before(r.syncedReset) {
+		r.synced = false
 	}
 
-	// Check if we're over limits
-	if r.requestCount.second >= r.limits.PerSecond ||
-		r.requestCount.month >= r.limits.PerMonth {
-		return ErrRateLimitExceeded
+	// Check the limits that waiting can't help with
+	if n > r.limits.PerSecond ||
+		r.monthlyCount+n > r.limits.PerMonth {
+		return 0, ErrRateLimitExceeded
 	}
 
-	// Increment counters
-	r.requestCount.second++
-	r.requestCount.month++
+	// The quota the API reported comes back when its window resets
+	if r.synced && n > r.syncedRemaining {
+		return r.syncedReset.Sub(now), nil
+	}
 
-	return nil
+	if missing := float64(n) - r.tokens; missing > 0 {
+		delay := time.Duration(missing / float64(r.limits.PerSecond) * float64(time.Second))
+		if delay <= 0 {
+			delay = time.Millisecond
+		}
+		return delay, nil
+	}
+
+	// Take the tokens and count the requests
+	r.tokens -= float64(n)
+	r.monthlyCount += n
+	if r.synced {
+		r.syncedRemaining -= n
+	}
+	return 0, nil
 }
 
-// ResetMonthlyCounter resets the monthly counter
-// This should be called on a schedule (e.g., first day of month)
-func (r *RateLimiter) ResetMonthlyCounter() {
+// refill adds the tokens earned since the last refill, up to a full bucket
+func (r *RateLimiter) refill(now time.Time) {
+	elapsed := now.Sub(r.lastRefill)
+	if elapsed <= 0 {
+		return
+	}
+	capacity := float64(r.limits.PerSecond)
+	r.tokens += elapsed.Seconds() * capacity
+	if r.tokens > capacity {
+		r.tokens = capacity
+	}
+	r.lastRefill = now
+}
+
+// Sync records the quota the API reported in a response: remaining requests
+// are allowed until reset, after which the local limits alone apply. When
+// remaining is zero every request fails fast until reset.
+func (r *RateLimiter) Sync(remaining int, reset time.Time) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.requestCount.month = 0
+
+	if remaining < 0 {
+		remaining = 0
+	}
+	r.synced = true
+	r.syncedRemaining = remaining
+	r.syncedReset = reset
+}
+
+// Stats is a snapshot of a rate limiter's usage and limits
+type Stats struct {
+	PerSecondCount int // requests made in about the last second
+	PerSecondLimit int
+	MonthlyCount   int
+	MonthlyLimit   int
+	// The quota the API last reported, while its window is open
+	APISynced    bool
+	APIRemaining int
+	APIReset     time.Time
+}
+
+// Stats returns the current usage of the limiter. The per-second count is
+// how many tokens are missing from the bucket, which is the number of
+// requests made in about the last second.
+func (r *RateLimiter) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.refill(now)
+
+	stats := Stats{
+		PerSecondCount: int(math.Ceil(float64(r.limits.PerSecond) - r.tokens)),
+		PerSecondLimit: r.limits.PerSecond,
+		MonthlyCount:   r.monthlyCount,
+		MonthlyLimit:   r.limits.PerMonth,
+	}
+	if r.synced && now.Before(r.syncedReset) {
+		stats.APISynced = true
+		stats.APIRemaining = r.syncedRemaining
+		stats.APIReset = r.syncedReset
+	}
+	return stats
+}
+
+// ResetMonthlyCounter resets the monthly counter and returns the count it
+// held. This should be called on a schedule (e.g., first day of month)
+func (r *RateLimiter) ResetMonthlyCounter() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.monthlyCount
+	r.monthlyCount = 0
+	return previous
+}
+
+// KeyedLimiters holds a separate rate limiter for each API key, so callers
+// using their own keys draw on their own quotas
+type KeyedLimiters struct {
+	limits   RateLimits
+	limiters map[[sha256.Size]byte]*RateLimiter
+	mu       sync.Mutex
+}
+
+// NewKeyedLimiters creates a set of per-key rate limiters sharing the given limits
+func NewKeyedLimiters(limits RateLimits) *KeyedLimiters {
+	return &KeyedLimiters{
+		limits:   limits,
+		limiters: make(map[[sha256.Size]byte]*RateLimiter),
+	}
+}
+
+// Get returns the rate limiter for a key, creating it on first use.
+// Keys are stored hashed rather than in the clear.
+func (k *KeyedLimiters) Get(key string) *RateLimiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	id := sha256.Sum256([]byte(key))
+	limiter, ok := k.limiters[id]
+	if !ok {
+		limiter = NewRateLimiter(k.limits)
+		k.limiters[id] = limiter
+	}
+	return limiter
+}
+
+// ResetMonthlyCounters resets the monthly counter of every per-key limiter
+func (k *KeyedLimiters) ResetMonthlyCounters() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, limiter := range k.limiters {
+		limiter.ResetMonthlyCounter()
+	}
 }
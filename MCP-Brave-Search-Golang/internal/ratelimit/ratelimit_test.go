@@ -0,0 +1,205 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSyncFailsFastWhenQuotaExhausted(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{PerSecond: 100, PerMonth: 1000})
+
+	limiter.Sync(0, time.Now().Add(time.Hour))
+	if err := limiter.CheckLimit(); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Expected an exhausted API quota to fail, got %v", err)
+	}
+
+	// Once the reported window resets only the local limits apply
+	limiter.Sync(0, time.Now().Add(-time.Second))
+	if err := limiter.CheckLimit(); err != nil {
+		t.Errorf("Expected the request to be allowed after the reset, got %v", err)
+	}
+}
+
+func TestSyncCountsDownRemainingQuota(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{PerSecond: 100, PerMonth: 1000})
+
+	limiter.Sync(2, time.Now().Add(time.Hour))
+	if err := limiter.Reserve(3); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Expected reserving more than the remaining quota to fail, got %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := limiter.CheckLimit(); err != nil {
+			t.Fatalf("Expected request %d to be allowed, got %v", i+1, err)
+		}
+	}
+	if err := limiter.CheckLimit(); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Expected the request after the remaining quota to fail, got %v", err)
+	}
+}
+
+// fakeClock returns a settable time for tests of the token bucket
+type fakeClock struct {
+	current time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.current }
+
+// allowed counts how many of n single requests the limiter lets through
+func allowed(limiter *RateLimiter, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if limiter.CheckLimit() == nil {
+			count++
+		}
+	}
+	return count
+}
+
+func TestNoBurstAcrossWindowBoundary(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(RateLimits{PerSecond: 10, PerMonth: 1000})
+	limiter.now = clock.now
+	limiter.lastRefill = clock.current
+
+	// One request opens what a fixed window would treat as a new second
+	if got := allowed(limiter, 1); got != 1 {
+		t.Fatalf("Expected the first request to be allowed, got %d", got)
+	}
+
+	// The rest of the budget is used just before that second ends
+	clock.current = clock.current.Add(990 * time.Millisecond)
+	if got := allowed(limiter, 9); got != 9 {
+		t.Fatalf("Expected 9 requests near the end of the second, got %d", got)
+	}
+
+	// Just after the boundary a fixed window would allow 10 more, 19 in 20ms
+	clock.current = clock.current.Add(20 * time.Millisecond)
+	if got := allowed(limiter, 10); got != 1 {
+		t.Errorf("Expected only 1 request just after the boundary, got %d", got)
+	}
+
+	// A full second later the bucket has refilled
+	clock.current = clock.current.Add(time.Second)
+	if got := allowed(limiter, 20); got != 10 {
+		t.Errorf("Expected 10 requests after a second's refill, got %d", got)
+	}
+}
+
+func TestMonthlyLimitWithTokenBucket(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(RateLimits{PerSecond: 5, PerMonth: 7})
+	limiter.now = clock.now
+	limiter.lastRefill = clock.current
+
+	if got := allowed(limiter, 5); got != 5 {
+		t.Fatalf("Expected a full bucket of 5 requests, got %d", got)
+	}
+	clock.current = clock.current.Add(time.Second)
+	if got := allowed(limiter, 5); got != 2 {
+		t.Errorf("Expected the monthly limit to allow only 2 more, got %d", got)
+	}
+
+	limiter.ResetMonthlyCounter()
+	if got := allowed(limiter, 5); got != 3 {
+		t.Errorf("Expected the tokens left in the bucket after the monthly reset, got %d", got)
+	}
+}
+
+func TestWaitBlocksUntilTokenAvailable(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{PerSecond: 20, PerMonth: 1000})
+	if got := allowed(limiter, 20); got != 20 {
+		t.Fatalf("Expected a full bucket of 20 requests, got %d", got)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected Wait to succeed once a token refilled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("Expected Wait to block for about 50ms, returned after %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a cancelled context to stop the wait, got %v", err)
+	}
+}
+
+func TestWaitFailsWhenMonthlyLimitExhausted(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{PerSecond: 10, PerMonth: 1})
+	if err := limiter.CheckLimit(); err != nil {
+		t.Fatalf("Expected the first request to be allowed, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.Wait(ctx); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Errorf("Expected an exhausted monthly limit to fail, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Expected the monthly limit to fail without waiting, took %v", elapsed)
+	}
+}
+
+func TestWaitNWaitsForSyncedQuotaReset(t *testing.T) {
+	limiter := NewRateLimiter(RateLimits{PerSecond: 10, PerMonth: 1000})
+	limiter.Sync(0, time.Now().Add(time.Second))
+
+	short, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := limiter.WaitN(short, 2); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the wait to outlast a short context, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected a wait past the deadline to fail straight away, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	start = time.Now()
+	if err := limiter.WaitN(ctx, 2); err != nil {
+		t.Fatalf("Expected WaitN to succeed once the API window reset, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 700*time.Millisecond {
+		t.Errorf("Expected WaitN to block until the reset about 1s away, returned after %v", elapsed)
+	}
+}
+
+func TestStats(t *testing.T) {
+	clock := &fakeClock{current: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(RateLimits{PerSecond: 5, PerMonth: 100})
+	limiter.now = clock.now
+	limiter.lastRefill = clock.current
+
+	if got := allowed(limiter, 3); got != 3 {
+		t.Fatalf("Expected 3 requests to be allowed, got %d", got)
+	}
+	limiter.Sync(40, clock.current.Add(time.Hour))
+
+	stats := limiter.Stats()
+	expected := Stats{
+		PerSecondCount: 3,
+		PerSecondLimit: 5,
+		MonthlyCount:   3,
+		MonthlyLimit:   100,
+		APISynced:      true,
+		APIRemaining:   40,
+		APIReset:       clock.current.Add(time.Hour),
+	}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+
+	// The per-second count drains as the bucket refills, and the API
+	// quota is dropped once its window resets
+	clock.current = clock.current.Add(2 * time.Hour)
+	stats = limiter.Stats()
+	if stats.PerSecondCount != 0 || stats.MonthlyCount != 3 || stats.APISynced {
+		t.Errorf("Expected an idle second, 3 monthly requests and no API quota, got %+v", stats)
+	}
+}
@@ -0,0 +1,46 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestToolLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter := NewToolLimiter(map[string]ToolLimit{
+		"grep_files": {Calls: 2, Interval: time.Minute},
+	})
+	limiter.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.Allow("grep_files"); err != nil {
+			t.Fatalf("Expected call %d to be allowed, got %v", i+1, err)
+		}
+	}
+
+	err := limiter.Allow("grep_files")
+	if !errors.Is(err, ErrToolRateLimited) {
+		t.Fatalf("Expected ErrToolRateLimited on the third call, got %v", err)
+	}
+
+	// Other tools are not limited
+	for i := 0; i < 5; i++ {
+		if err := limiter.Allow("read_file"); err != nil {
+			t.Fatalf("Expected unlimited tool to be allowed, got %v", err)
+		}
+	}
+
+	// A new interval restores the allowance
+	now = now.Add(time.Minute)
+	if err := limiter.Allow("grep_files"); err != nil {
+		t.Errorf("Expected a call in the next interval to be allowed, got %v", err)
+	}
+}
+
+func TestNilToolLimiterAllowsAll(t *testing.T) {
+	var limiter *ToolLimiter
+	if err := limiter.Allow("anything"); err != nil {
+		t.Errorf("Expected a nil limiter to allow calls, got %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package brave
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultBaseURL is the Brave Search API host used unless SetBaseURL is called
+const DefaultBaseURL = "https://api.search.brave.com"
+
+// baseURL is the scheme and host, and optionally a path prefix, that API
+// endpoint paths are appended to
+var baseURL = DefaultBaseURL
+
+// SetBaseURL sets where API requests are sent, for example an egress proxy
+// or a mock server in tests. An empty value restores DefaultBaseURL. The URL
+// must be absolute http or https. It should be called at startup.
+func SetBaseURL(raw string) error {
+	if raw == "" {
+		baseURL = DefaultBaseURL
+		return nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid base URL %q: %w", raw, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: must be an absolute http or https URL", raw)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid base URL %q: must not have a query or fragment", raw)
+	}
+
+	baseURL = strings.TrimSuffix(u.String(), "/")
+	return nil
+}
+
+// endpointURL returns the URL of an API endpoint path such as /res/v1/web/search
+func endpointURL(path string) string {
+	return baseURL + path
+}
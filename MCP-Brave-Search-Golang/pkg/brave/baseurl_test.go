@@ -0,0 +1,52 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestWebSearchUsesBaseURL(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotQuery = r.URL.Query().Get("q")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"web": {"results": [{"title": "Mock", "description": "From the mock server", "url": "https://example.com"}]}}`))
+	}))
+	defer server.Close()
+
+	if err := SetBaseURL(server.URL + "/proxy/"); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer SetBaseURL("")
+
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	results, _, err := WebSearch("key", "golang", WebSearchOptions{Count: 10}, limiter)
+	if err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+
+	if gotPath != "/proxy/res/v1/web/search" || gotQuery != "golang" {
+		t.Errorf("Expected a request to /proxy/res/v1/web/search for golang, got %s for %q", gotPath, gotQuery)
+	}
+	if !strings.Contains(results, "From the mock server") {
+		t.Errorf("Expected the mock result, got %q", results)
+	}
+}
+
+func TestSetBaseURLRejectsInvalid(t *testing.T) {
+	defer SetBaseURL("")
+
+	for _, raw := range []string{"api.search.brave.com", "ftp://example.com", "https://", "https://example.com/?key=1", "http://[::1"} {
+		if err := SetBaseURL(raw); err == nil {
+			t.Errorf("Expected %q to be rejected", raw)
+		}
+	}
+	if baseURL != DefaultBaseURL {
+		t.Errorf("Expected a rejected URL to leave the base URL unchanged, got %q", baseURL)
+	}
+}
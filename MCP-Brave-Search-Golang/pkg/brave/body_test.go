@@ -0,0 +1,149 @@
+package brave
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// trackingBody records whether a response body was read to EOF and closed
+type trackingBody struct {
+	reader  io.Reader
+	drained bool
+	closed  bool
+}
+
+func (b *trackingBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+	if err == io.EOF {
+		b.drained = true
+	}
+	return n, err
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestResponseBodiesDrained(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		expectFail bool
+	}{
+		{"error status", http.StatusInternalServerError, "upstream failure", true},
+		{"malformed json", http.StatusOK, `{"web": {"results": [ oops ` + strings.Repeat("x", 4096), true},
+		{"trailing data after json", http.StatusOK, `{"web": {"results": []}}` + strings.Repeat(" ", 4096) + "trailer", false},
+	}
+
+	originalTransport := httpClient.Transport
+	defer func() { httpClient.Transport = originalTransport }()
+
+	// Each case returns a single response, so don't retry the error status
+	SetRetryPolicy(-1, 0)
+	defer SetRetryPolicy(0, 0)
+
+	for _, tt := range tests {
+		body := &trackingBody{reader: strings.NewReader(tt.body)}
+		httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: tt.status,
+				Status:     http.StatusText(tt.status),
+				Header:     make(http.Header),
+				Body:       body,
+				Request:    req,
+			}, nil
+		})
+
+		limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+		_, _, err := WebSearch("key", "query", WebSearchOptions{Count: 10}, limiter)
+		if tt.expectFail && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+		if !tt.expectFail && err != nil {
+			t.Errorf("%s: expected no error, got %v", tt.name, err)
+		}
+		if !body.drained {
+			t.Errorf("%s: expected response body to be drained", tt.name)
+		}
+		if !body.closed {
+			t.Errorf("%s: expected response body to be closed", tt.name)
+		}
+	}
+}
+
+func TestConnectionReuse(t *testing.T) {
+	var connections atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Trailing data after the JSON value is left unread by the decoder, and is
+		// more than net/http discards by itself when a body is closed early
+		io.WriteString(w, `{"web": {"results": [{"title": "Go"}]}}`+strings.Repeat("\n", 512<<10))
+	}))
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	for i := 0; i < 20; i++ {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		var resp WebSearchResponse
+		if err := getJSON(req, &resp); err != nil {
+			t.Fatalf("Request %d failed: %v", i, err)
+		}
+		if len(resp.Web.Results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(resp.Web.Results))
+		}
+	}
+
+	if got := connections.Load(); got != 1 {
+		t.Errorf("Expected all requests to share 1 connection, got %d connections", got)
+	}
+}
+
+func TestGetJSONRejectsUnexpectedContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, "<html><body>Please sign in to the network</body></html>")
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	var resp WebSearchResponse
+	err = getJSON(req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "unexpected content type: text/html; charset=utf-8; body: <html><body>Please sign in") {
+		t.Errorf("Expected an unexpected content type error quoting the body, got %v", err)
+	}
+
+	// With the check disabled the decoder reports the failure instead
+	SetContentTypeCheck(false)
+	defer SetContentTypeCheck(true)
+	err = getJSON(req, &resp)
+	if err == nil || strings.Contains(err.Error(), "unexpected content type") {
+		t.Errorf("Expected a decode error with the check disabled, got %v", err)
+	}
+}
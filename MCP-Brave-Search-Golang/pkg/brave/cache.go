@@ -0,0 +1,57 @@
+package brave
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/cache"
+)
+
+// resultCache holds formatted results of recent searches. It is disabled
+// until SetResultCache is called.
+var resultCache = cache.New(0, 0)
+
+// SetResultCache sets how long formatted WebSearch and LocalSearch results
+// are reused for identical searches and how many are kept, evicting the
+// least recently used. A zero ttl or maxEntries disables the cache. It
+// should be called at startup.
+func SetResultCache(ttl time.Duration, maxEntries int) {
+	resultCache = cache.New(ttl, maxEntries)
+}
+
+// resultCacheKey identifies a search by tool, API key and every argument
+// affecting its results, so callers with their own key never share results
+// with the server's. The key is hashed so API keys are not held in the clear.
+func resultCacheKey(tool, apiKey string, args ...interface{}) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	data, _ := json.Marshal(append([]interface{}{tool, hex.EncodeToString(sum[:])}, args...))
+	return string(data)
+}
+
+// cachedSearch returns the cached results for key, or runs search and caches
+// what it returns. Errors are not cached.
+func cachedSearch(key string, search func() (string, error)) (string, error) {
+	return cachedSearchIfComplete(key, func() (string, bool, error) {
+		results, err := search()
+		return results, true, err
+	})
+}
+
+// cachedSearchIfComplete is like cachedSearch but only caches results the
+// search reports as complete, so results cut short by a limit aren't reused
+func cachedSearchIfComplete(key string, search func() (string, bool, error)) (string, error) {
+	if results, ok := resultCache.Get(key); ok {
+		return results, nil
+	}
+
+	results, complete, err := search()
+	if err != nil {
+		return "", err
+	}
+	if complete {
+		resultCache.Set(key, results)
+	}
+	return results, nil
+}
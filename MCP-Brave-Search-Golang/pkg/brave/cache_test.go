@@ -0,0 +1,69 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestWebSearchUsesResultCache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"web": {"results": [{"title": "Mock", "description": "Cached", "url": "https://example.com"}]}}`))
+	}))
+	defer server.Close()
+
+	if err := SetBaseURL(server.URL); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer SetBaseURL("")
+	SetResultCache(time.Minute, 10)
+	defer SetResultCache(0, 0)
+
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	for i, expected := range []int{1, 0} {
+		_, sent, err := WebSearch("key", "golang", WebSearchOptions{Count: 10}, limiter)
+		if err != nil {
+			t.Fatalf("WebSearch failed: %v", err)
+		}
+		if sent != expected {
+			t.Errorf("Expected search %d to report %d requests sent, got %d", i+1, expected, sent)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("Expected the repeated search to be served from the cache, got %d requests", requests)
+	}
+
+	// Different arguments are a different search
+	if _, _, err := WebSearch("key", "golang", WebSearchOptions{Count: 10, Offset: 1}, limiter); err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected a search with another offset to reach the API, got %d requests", requests)
+	}
+
+	// The same search with another API key is not served from the cache
+	if _, _, err := WebSearch("other-key", "golang", WebSearchOptions{Count: 10}, limiter); err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("Expected a search with another API key to reach the API, got %d requests", requests)
+	}
+}
+
+func TestResultCacheKeySeparatesOptions(t *testing.T) {
+	// Adjacent string options must not run together into the same key
+	a := resultCacheKey("web", "key", "golang", WebSearchOptions{Country: "de", SearchLang: ""})
+	b := resultCacheKey("web", "key", "golang", WebSearchOptions{Country: "", SearchLang: "de"})
+	if a == b {
+		t.Errorf("Expected different options to give different keys, both were %s", a)
+	}
+	if a != resultCacheKey("web", "key", "golang", WebSearchOptions{Country: "de"}) {
+		t.Error("Expected identical options to give the same key")
+	}
+}
@@ -0,0 +1,470 @@
+package brave
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/pool"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// subscriptionTokenHeader carries the Brave API key on every request
+const subscriptionTokenHeader = "X-Subscription-Token"
+
+// Adaptive timeout defaults: each request may take a multiple of the
+// average latency, within these bounds
+const (
+	DefaultMinRequestTimeout = 2 * time.Second
+	DefaultMaxRequestTimeout = 30 * time.Second
+	timeoutLatencyMultiple   = 4
+)
+
+// latencyEMAWeight is the weight given to each new latency sample in the moving average
+const latencyEMAWeight = 0.2
+
+// Client performs searches against the Brave Search API. It satisfies the
+// server's SearchProvider interface. The client tracks an exponential moving
+// average of response latency and uses it to set the timeout of each request.
+type Client struct {
+	mu         sync.Mutex
+	latencyEMA time.Duration // zero until the first response
+	minTimeout time.Duration
+	maxTimeout time.Duration
+}
+
+// defaultClient holds the latency statistics for all requests sent by doRequest
+var defaultClient = &Client{
+	minTimeout: DefaultMinRequestTimeout,
+	maxTimeout: DefaultMaxRequestTimeout,
+}
+
+// NewClient returns the Brave search client. All requests share one HTTP
+// client, so every Client returned shares the same latency statistics.
+func NewClient() *Client {
+	return defaultClient
+}
+
+// SetTimeoutBounds sets the range the adaptive request timeout is kept within.
+// Zero values keep the defaults. It should be called at startup.
+func (c *Client) SetTimeoutBounds(min, max time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if min <= 0 {
+		min = DefaultMinRequestTimeout
+	}
+	if max <= 0 {
+		max = DefaultMaxRequestTimeout
+	}
+	if max < min {
+		max = min
+	}
+	c.minTimeout = min
+	c.maxTimeout = max
+
+	// The shared HTTP client's own timeout backs up the adaptive one
+	if c == defaultClient {
+		httpClient.Timeout = max
+	}
+}
+
+// LatencyEMA returns the moving average of response latency, or zero before
+// the first response
+func (c *Client) LatencyEMA() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latencyEMA
+}
+
+// RequestTimeout returns the timeout for the next request: a multiple of the
+// average latency, bounded by the configured minimum and maximum. Until a
+// latency has been recorded the maximum is used.
+func (c *Client) RequestTimeout() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.latencyEMA == 0 {
+		return c.maxTimeout
+	}
+	timeout := c.latencyEMA * timeoutLatencyMultiple
+	if timeout < c.minTimeout {
+		return c.minTimeout
+	}
+	if timeout > c.maxTimeout {
+		return c.maxTimeout
+	}
+	return timeout
+}
+
+// recordLatency adds a latency sample to the moving average
+func (c *Client) recordLatency(latency time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.latencyEMA == 0 {
+		c.latencyEMA = latency
+		return
+	}
+	c.latencyEMA += time.Duration(latencyEMAWeight * float64(latency-c.latencyEMA))
+}
+
+// WebSearch performs a web search; see the package-level WebSearch
+func (c *Client) WebSearch(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	return WebSearch(apiKey, query, opts, rateLimiter)
+}
+
+// WebSearchStructured performs a web search returning the results themselves
+func (c *Client) WebSearchStructured(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter) ([]WebResult, int, error) {
+	return WebSearchStructured(apiKey, query, opts, rateLimiter)
+}
+
+// WebSearchWithThumbnails performs a web search that also fetches result thumbnails
+func (c *Client) WebSearchWithThumbnails(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, []Thumbnail, int, error) {
+	return WebSearchWithThumbnails(apiKey, query, opts, rateLimiter)
+}
+
+// LocalSearch performs a local search; see the package-level LocalSearch
+func (c *Client) LocalSearch(apiKey, query string, count int, searchLang string, reference *Coordinates, minRating float64, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	return LocalSearch(apiKey, query, count, searchLang, reference, minRating, rateLimiter)
+}
+
+// NewsSearch performs a news search; see the package-level NewsSearch
+func (c *Client) NewsSearch(apiKey, query string, count int, freshness string, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	return NewsSearch(apiKey, query, count, freshness, rateLimiter)
+}
+
+// CombinedSearch performs a blended web and news search; see the package-level CombinedSearch
+func (c *Client) CombinedSearch(apiKey, query string, count int, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	return CombinedSearch(apiKey, query, count, rateLimiter)
+}
+
+// RedirectPolicy controls how the Brave client follows HTTP redirects
+type RedirectPolicy string
+
+const (
+	// RedirectSameHost follows redirects only to the host of the original request
+	RedirectSameHost RedirectPolicy = "same-host"
+	// RedirectStripToken follows any redirect but drops the API key when the host changes
+	RedirectStripToken RedirectPolicy = "strip-token"
+	// RedirectNone never follows redirects
+	RedirectNone RedirectPolicy = "none"
+)
+
+// maxRedirects matches the default limit of net/http
+const maxRedirects = 10
+
+// TransportOptions tunes connection pooling for the shared client
+type TransportOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
+// DefaultTransportOptions keeps enough idle connections to the API host to avoid
+// a TLS handshake per request
+var DefaultTransportOptions = TransportOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// maxDrainBytes bounds how much of an unread body is discarded to keep its
+// connection; beyond this, closing the connection is cheaper
+const maxDrainBytes = 256 << 10
+
+// httpClient is shared by all Brave requests, so connections are reused
+// across searches. Its Timeout is a backstop at the largest adaptive
+// timeout, covering any request not bounded by doRequest.
+var httpClient = &http.Client{
+	Transport:     newTransport(DefaultTransportOptions),
+	CheckRedirect: checkRedirect(RedirectSameHost),
+	Timeout:       DefaultMaxRequestTimeout,
+}
+
+// Connection reuse counters, updated for every request sent by doRequest
+var (
+	newConns    atomic.Int64
+	reusedConns atomic.Int64
+)
+
+// newTransport builds a transport from the defaults of net/http with the given pooling options
+func newTransport(opts TransportOptions) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = opts.MaxIdleConns
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = opts.IdleConnTimeout
+	return transport
+}
+
+// SetTransportOptions replaces the shared transport. Zero fields keep their defaults.
+// It should be called at startup.
+func SetTransportOptions(opts TransportOptions) {
+	if opts.MaxIdleConns <= 0 {
+		opts.MaxIdleConns = DefaultTransportOptions.MaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = DefaultTransportOptions.MaxIdleConnsPerHost
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = DefaultTransportOptions.IdleConnTimeout
+	}
+	httpClient.Transport = newTransport(opts)
+}
+
+// ConnectionStats returns how many requests opened a new connection and how many reused an idle one
+func ConnectionStats() (created, reused int64) {
+	return newConns.Load(), reusedConns.Load()
+}
+
+// doRequest sends a request with the shared client, recording whether its
+// connection was reused. The request is bounded by the adaptive timeout and
+// its latency is added to the moving average; a request that times out
+// counts as taking the whole timeout, so the average rises when the API
+// slows down. The timeout also covers reading the body, and is released
+// when the body is closed.
+func doRequest(req *http.Request) (*http.Response, error) {
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				reusedConns.Add(1)
+			} else {
+				newConns.Add(1)
+			}
+		},
+	}
+
+	timeout := defaultClient.RequestTimeout()
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	start := time.Now()
+	resp, err := httpClient.Do(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+	if err != nil {
+		cancel()
+		if errors.Is(err, context.DeadlineExceeded) {
+			defaultClient.recordLatency(timeout)
+		}
+		return nil, err
+	}
+	defaultClient.recordLatency(time.Since(start))
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a request's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and releases the request context
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// requestCount counts the requests one search sends to Brave, so callers can
+// tell what a single call cost even when the rate limiter is shared. It is
+// safe for concurrent use by the requests of a fan-out.
+type requestCount struct {
+	n atomic.Int64
+}
+
+// add records n more requests sent
+func (c *requestCount) add(n int) {
+	c.n.Add(int64(n))
+}
+
+// value returns the number of requests sent so far
+func (c *requestCount) value() int {
+	return int(c.n.Load())
+}
+
+// getJSON sends a request and decodes the JSON response body into out,
+// handling gzip encoding. Rate limited and server error responses are
+// retried according to the retry policy, and the quota reported by a
+// successful response is passed to the rate limit sync. Responses that
+// aren't JSON are rejected before decoding. The body is always read to the end before closing,
+// even when decoding stops early, so the connection can be reused.
+func getJSON(req *http.Request, out interface{}) error {
+	resp, err := doRequestWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer closeBody(resp.Body)
+
+	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("Brave API error: %d %s\n%s", resp.StatusCode, resp.Status, string(body))
+	}
+
+	// Follow the quota Brave reports
+	syncRateLimit(req, resp.Header, time.Now())
+
+	// Create a reader based on content encoding
+	var reader io.ReadCloser
+	switch resp.Header.Get("Content-Encoding") {
+	case "gzip":
+		reader, err = gzip.NewReader(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to create gzip reader: %w", err)
+		}
+		defer reader.Close()
+	default:
+		reader = resp.Body
+	}
+
+	// A proxy or captive portal may answer with an HTML page instead
+	if err := checkContentType(resp.Header.Get("Content-Type"), reader); err != nil {
+		io.Copy(io.Discard, reader)
+		return err
+	}
+
+	// The decoder stops at the end of the JSON value, leaving any trailing data unread
+	err = json.NewDecoder(reader).Decode(out)
+	io.Copy(io.Discard, reader)
+	if err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// contentTypeSnippetLen is how much of an unexpected response body is quoted in the error
+const contentTypeSnippetLen = 200
+
+// contentTypeCheck rejects responses that aren't JSON before decoding them
+var contentTypeCheck = true
+
+// SetContentTypeCheck sets whether responses whose Content-Type isn't JSON
+// are rejected with an error quoting the start of the body, rather than
+// failing to decode. It is on by default and should be set at startup.
+func SetContentTypeCheck(enabled bool) {
+	contentTypeCheck = enabled
+}
+
+// checkContentType returns an error including the start of body if
+// contentType is set to something other than JSON. A missing Content-Type
+// is allowed, leaving the decoder to judge the body.
+func checkContentType(contentType string, body io.Reader) error {
+	if !contentTypeCheck || contentType == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(body, contentTypeSnippetLen))
+	return fmt.Errorf("unexpected content type: %s; body: %s", contentType, strings.TrimSpace(string(snippet)))
+}
+
+// closeBody drains and closes a response body so its connection can be reused
+func closeBody(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, maxDrainBytes))
+	body.Close()
+}
+
+// maxRateLimitWait bounds how long a search waits for the per-second rate limit
+const maxRateLimitWait = 10 * time.Second
+
+// waitForRateLimit makes searches wait for the per-second rate limit rather than fail
+var waitForRateLimit bool
+
+// SetWaitForRateLimit sets whether a search over the per-second rate limit
+// waits for it to allow the request, rather than failing straight away.
+// Exhausted monthly quota fails either way. It should be called at startup.
+func SetWaitForRateLimit(wait bool) {
+	waitForRateLimit = wait
+}
+
+// reserveRequests takes n requests from the rate limiter, waiting up to
+// maxRateLimitWait for the per-second limit when waiting is enabled
+func reserveRequests(rateLimiter *ratelimit.RateLimiter, n int) error {
+	if !waitForRateLimit {
+		return rateLimiter.Reserve(n)
+	}
+	return waitForRequests(rateLimiter, n)
+}
+
+// waitForRequests takes n requests from the rate limiter, waiting up to
+// maxRateLimitWait for the per-second limit whether or not waiting is
+// enabled. Operations that send several requests in turn use it after the
+// first, so one that has started isn't abandoned halfway by the limit.
+func waitForRequests(rateLimiter *ratelimit.RateLimiter, n int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), maxRateLimitWait)
+	defer cancel()
+	if err := rateLimiter.WaitN(ctx, n); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return ratelimit.ErrRateLimitExceeded
+		}
+		return err
+	}
+	return nil
+}
+
+// DefaultMaxWorkers is the default limit on concurrent Brave requests spawned by fan-out operations
+const DefaultMaxWorkers = 8
+
+// workerPool bounds the goroutines used by parallel operations such as the local search fan-out
+var workerPool = pool.New(DefaultMaxWorkers)
+
+// SetMaxWorkers sets the maximum number of concurrent fan-out requests. It should be called at startup.
+func SetMaxWorkers(max int) {
+	if max > 0 {
+		workerPool = pool.New(max)
+	}
+}
+
+// SetRedirectPolicy configures how the shared client follows redirects.
+// The default policy is RedirectSameHost. It should be called at startup.
+func SetRedirectPolicy(policy RedirectPolicy) error {
+	switch policy {
+	case RedirectSameHost, RedirectStripToken, RedirectNone:
+		httpClient.CheckRedirect = checkRedirect(policy)
+		return nil
+	default:
+		return fmt.Errorf("invalid redirect policy %q: must be one of %s, %s or %s",
+			policy, RedirectSameHost, RedirectStripToken, RedirectNone)
+	}
+}
+
+// checkRedirect returns a redirect check implementing the given policy. The
+// default http.Client forwards custom headers such as X-Subscription-Token to
+// any host it is redirected to, which would leak the API key.
+func checkRedirect(policy RedirectPolicy) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+
+		originalHost := via[0].URL.Host
+		sameHost := req.URL.Host == originalHost
+
+		switch policy {
+		case RedirectNone:
+			return fmt.Errorf("refusing to follow redirect to %s (redirect policy %s)", req.URL.Redacted(), policy)
+		case RedirectStripToken:
+			if !sameHost {
+				req.Header.Del(subscriptionTokenHeader)
+			}
+			return nil
+		default:
+			if !sameHost {
+				return fmt.Errorf("refusing to follow redirect from %s to %s (redirect policy %s)", originalHost, req.URL.Host, policy)
+			}
+			return nil
+		}
+	}
+}
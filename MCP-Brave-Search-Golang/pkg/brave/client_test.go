@@ -0,0 +1,100 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// redirectTestServers starts a target server recording the token it receives
+// and an origin server that redirects to it
+func redirectTestServers(t *testing.T) (origin *httptest.Server, receivedToken *string) {
+	t.Helper()
+
+	token := "unset"
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get(subscriptionTokenHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(target.Close)
+
+	origin = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/same-host" {
+			http.Redirect(w, r, "/final", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/final" {
+			token = r.Header.Get(subscriptionTokenHeader)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Redirect(w, r, target.URL+"/elsewhere", http.StatusFound)
+	}))
+	t.Cleanup(origin.Close)
+
+	return origin, &token
+}
+
+// doWithPolicy issues a GET carrying the API key using a client with the given redirect policy
+func doWithPolicy(t *testing.T, policy RedirectPolicy, url string) (*http.Response, error) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: checkRedirect(policy)}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set(subscriptionTokenHeader, "secret-key")
+
+	resp, err := client.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestRedirectSameHostPolicy(t *testing.T) {
+	origin, token := redirectTestServers(t)
+
+	// Redirects within the same host are followed with the token intact
+	if _, err := doWithPolicy(t, RedirectSameHost, origin.URL+"/same-host"); err != nil {
+		t.Fatalf("Expected same-host redirect to be followed, got: %v", err)
+	}
+	if *token != "secret-key" {
+		t.Errorf("Expected token on same-host redirect, got %q", *token)
+	}
+
+	// Cross-host redirects are refused so the token never leaves
+	*token = "unset"
+	if _, err := doWithPolicy(t, RedirectSameHost, origin.URL+"/cross-host"); err == nil {
+		t.Error("Expected cross-host redirect to be refused, got nil")
+	}
+	if *token != "unset" {
+		t.Errorf("Expected redirect target not to be contacted, got token %q", *token)
+	}
+}
+
+func TestRedirectStripTokenPolicy(t *testing.T) {
+	origin, token := redirectTestServers(t)
+
+	if _, err := doWithPolicy(t, RedirectStripToken, origin.URL+"/cross-host"); err != nil {
+		t.Fatalf("Expected cross-host redirect to be followed, got: %v", err)
+	}
+	if *token != "" {
+		t.Errorf("Expected token to be stripped on cross-host redirect, got %q", *token)
+	}
+}
+
+func TestRedirectNonePolicy(t *testing.T) {
+	origin, _ := redirectTestServers(t)
+
+	if _, err := doWithPolicy(t, RedirectNone, origin.URL+"/same-host"); err == nil {
+		t.Error("Expected redirect to be refused, got nil")
+	}
+}
+
+func TestSetRedirectPolicyRejectsUnknown(t *testing.T) {
+	if err := SetRedirectPolicy("sometimes"); err == nil {
+		t.Error("Expected error for unknown redirect policy, got nil")
+	}
+}
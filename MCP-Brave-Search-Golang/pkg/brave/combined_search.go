@@ -0,0 +1,202 @@
+package brave
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// combinedRecencyWindow is how far back a result's publication date still
+// earns it a recency boost when blending
+const combinedRecencyWindow = 7 * 24 * time.Hour
+
+// pageAgeLayouts are the timestamp formats Brave uses for page_age
+var pageAgeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05"}
+
+// CombinedResult is a web or news result placed in a blended ranking
+type CombinedResult struct {
+	WebResult
+	Kind  string  // "web" or "news"
+	Score float64 // relevance plus recency; higher ranks first
+}
+
+// CombinedSearch runs a web and a news search for the same query and returns
+// a single list blending both, ranked by relevance and recency with
+// duplicate URLs removed. The searches are sent one after the other, each
+// reserved from the rate limiter just before it is sent, so the pair keeps
+// to Brave's per-second limit. Once the web search is sent, the news search
+// waits for the per-second limit rather than abandoning the operation. It
+// also returns the number of requests sent to Brave.
+func CombinedSearch(
+	apiKey string,
+	query string,
+	count int,
+	rateLimiter *ratelimit.RateLimiter,
+) (string, int, error) {
+	// Ensure count is within API limits
+	if count <= 0 {
+		count = 10 // Default value
+	} else if count > 20 {
+		count = 20 // API maximum
+	}
+
+	if err := reserveRequests(rateLimiter, 1); err != nil {
+		return "", 0, err
+	}
+	var sent requestCount
+	webResults, err := fetchWebResults(apiKey, query, WebSearchOptions{Count: count}, &sent)
+	if err != nil {
+		return "", sent.value(), fmt.Errorf("failed to get web results: %w", err)
+	}
+
+	if err := waitForRequests(rateLimiter, 1); err != nil {
+		return "", sent.value(), err
+	}
+	newsResults, err := fetchNewsResults(apiKey, query, count, "", &sent)
+	if err != nil {
+		return "", sent.value(), fmt.Errorf("failed to get news results: %w", err)
+	}
+
+	blended := blendResults(webResults, newsResults, time.Now())
+	if len(blended) > count {
+		blended = blended[:count]
+	}
+
+	return formatCombinedResults(blended), sent.value(), nil
+}
+
+// blendResults merges web and news results into one ranking. Each result
+// scores by its position in its own list, from 1 for the first down towards
+// 0 for the last, plus up to 1 more the more recently it was published within
+// combinedRecencyWindow. Ties keep web results ahead of news. When the same
+// URL appears more than once only the highest ranked entry is kept.
+func blendResults(webResults, newsResults []WebResult, now time.Time) []CombinedResult {
+	var combined []CombinedResult
+	for _, source := range []struct {
+		kind    string
+		results []WebResult
+	}{
+		{"web", webResults},
+		{"news", newsResults},
+	} {
+		for i, result := range source.results {
+			relevance := 1 - float64(i)/float64(len(source.results))
+			combined = append(combined, CombinedResult{
+				WebResult: result,
+				Kind:      source.kind,
+				Score:     relevance + recencyScore(result.PageAge, now),
+			})
+		}
+	}
+
+	sort.SliceStable(combined, func(i, j int) bool {
+		return combined[i].Score > combined[j].Score
+	})
+
+	seen := make(map[string]bool, len(combined))
+	deduped := combined[:0]
+	for _, result := range combined {
+		key := normalizeResultURL(result.URL)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduped = append(deduped, result)
+	}
+
+	return deduped
+}
+
+// recencyScore returns 1 for a result published now, falling linearly to 0
+// at combinedRecencyWindow. Undated results score 0.
+func recencyScore(pageAge string, now time.Time) float64 {
+	published, ok := parsePageAge(pageAge)
+	if !ok {
+		return 0
+	}
+	age := now.Sub(published)
+	if age < 0 {
+		age = 0
+	}
+	if age >= combinedRecencyWindow {
+		return 0
+	}
+	return 1 - float64(age)/float64(combinedRecencyWindow)
+}
+
+// parsePageAge parses a page_age timestamp, reporting false when it is
+// empty or in an unknown format
+func parsePageAge(pageAge string) (time.Time, bool) {
+	for _, layout := range pageAgeLayouts {
+		if published, err := time.Parse(layout, pageAge); err == nil {
+			return published, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// normalizeResultURL reduces a URL to the form used to detect duplicates,
+// ignoring scheme, host case, fragments and a trailing slash
+func normalizeResultURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	key := strings.ToLower(u.Host) + path
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// formatCombinedResults renders blended results as text, noting whether each came from web or news
+func formatCombinedResults(combined []CombinedResult) string {
+	if len(combined) == 0 {
+		return "No results found"
+	}
+
+	var results []string
+	for _, result := range combined {
+		lines := []string{
+			"Title: " + result.Title,
+			"Description: " + result.Description,
+			"URL: " + result.URL,
+			"Type: " + result.Kind,
+		}
+		if result.Age != "" {
+			lines = append(lines, "Age: "+result.Age)
+		}
+		results = append(results, strings.Join(lines, "\n"))
+	}
+
+	return strings.Join(results, "\n\n")
+}
+
+// CombinedSearchTool defines the schema for the brave_combined_search tool
+var CombinedSearchTool = map[string]interface{}{
+	"name": "brave_combined_search",
+	"description": "Searches the web and news together using the Brave Search API and returns one list, " +
+		"ranked by relevance and recency with duplicate URLs removed. " +
+		"Use this for current events, where fresh news and established web pages are both useful. " +
+		"Each result is marked as web or news. Uses two requests of rate-limit quota.",
+	"inputSchema": map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "Search query (max 400 chars, 50 words)",
+			},
+			"count": map[string]interface{}{
+				"type":        "number",
+				"description": "Number of results (1-20, default 10)",
+				"default":     10,
+			},
+		},
+		"required": []string{"query"},
+	},
+}
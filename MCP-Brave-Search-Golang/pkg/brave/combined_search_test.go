@@ -0,0 +1,105 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestCombinedSearchDefaultLimits(t *testing.T) {
+	var mu sync.Mutex
+	var requests []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, time.Now())
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/news/search") {
+			w.Write([]byte(`{"results": [{"title": "Breaking", "description": "News", "url": "https://news.example.com/a"}]}`))
+			return
+		}
+		w.Write([]byte(`{"web": {"results": [{"title": "Reference", "description": "Web", "url": "https://example.com/guide"}]}}`))
+	}))
+	defer server.Close()
+
+	if err := SetBaseURL(server.URL); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer SetBaseURL("")
+
+	// The server's defaults: one request per second, failing fast
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 1, PerMonth: 15000})
+	results, sent, err := CombinedSearch("key", "golang", 10, limiter)
+	if err != nil {
+		t.Fatalf("CombinedSearch failed: %v", err)
+	}
+	if !strings.Contains(results, "Breaking") || !strings.Contains(results, "Reference") {
+		t.Errorf("Expected both web and news results, got %q", results)
+	}
+
+	if len(requests) != 2 || sent != 2 {
+		t.Fatalf("Expected two requests, got %d sent and %d reported", len(requests), sent)
+	}
+	if gap := requests[1].Sub(requests[0]); gap < 900*time.Millisecond {
+		t.Errorf("Expected the requests to be a second apart, got %v", gap)
+	}
+	if count := limiter.Stats().MonthlyCount; count != 2 {
+		t.Errorf("Expected two requests to be counted, got %d", count)
+	}
+}
+
+func TestBlendResults(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	webResults := []WebResult{
+		{Title: "Reference", URL: "https://example.com/guide"},
+		{Title: "Shared", URL: "https://news.example.com/story"},
+	}
+	newsResults := []WebResult{
+		{Title: "Breaking", URL: "https://news.example.com/story/", PageAge: "2024-06-10T10:00:00"},
+		{Title: "Old news", URL: "https://news.example.com/old", PageAge: "2024-05-01T10:00:00"},
+	}
+
+	blended := blendResults(webResults, newsResults, now)
+
+	var titles []string
+	for _, result := range blended {
+		titles = append(titles, result.Title)
+	}
+	expected := []string{"Breaking", "Reference", "Old news"}
+	if len(titles) != len(expected) {
+		t.Fatalf("Expected results %v, got %v", expected, titles)
+	}
+	for i := range expected {
+		if titles[i] != expected[i] {
+			t.Fatalf("Expected results %v, got %v", expected, titles)
+		}
+	}
+
+	if blended[0].Kind != "news" || blended[1].Kind != "web" {
+		t.Errorf("Expected kinds news then web, got %s then %s", blended[0].Kind, blended[1].Kind)
+	}
+}
+
+func TestRecencyScore(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	if score := recencyScore("2024-06-10T12:00:00Z", now); score != 1 {
+		t.Errorf("Expected a result published now to score 1, got %v", score)
+	}
+	if score := recencyScore("2024-06-06T12:00:00", now); score <= 0 || score >= 1 {
+		t.Errorf("Expected a result from this week to score between 0 and 1, got %v", score)
+	}
+	if score := recencyScore("2024-01-01T00:00:00", now); score != 0 {
+		t.Errorf("Expected an old result to score 0, got %v", score)
+	}
+	if score := recencyScore("", now); score != 0 {
+		t.Errorf("Expected an undated result to score 0, got %v", score)
+	}
+}
@@ -0,0 +1,74 @@
+package brave
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// earthRadiusKm is the mean radius of the Earth used for distances
+const earthRadiusKm = 6371.0
+
+// Coordinates is a latitude and longitude in decimal degrees
+type Coordinates struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// UnmarshalJSON accepts coordinates either as an object with latitude and
+// longitude or as the [latitude, longitude] pair Brave returns for POIs
+func (c *Coordinates) UnmarshalJSON(data []byte) error {
+	var pair []float64
+	if err := json.Unmarshal(data, &pair); err == nil {
+		if len(pair) != 2 {
+			return fmt.Errorf("coordinates must have 2 values, got %d", len(pair))
+		}
+		c.Latitude, c.Longitude = pair[0], pair[1]
+		return nil
+	}
+
+	var object struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	c.Latitude, c.Longitude = object.Latitude, object.Longitude
+	return nil
+}
+
+// String formats the coordinates as "latitude, longitude"
+func (c Coordinates) String() string {
+	return fmt.Sprintf("%.6f, %.6f", c.Latitude, c.Longitude)
+}
+
+// ReferenceLocation builds the location local search distances are measured
+// from. Both values must be given, or neither, in which case it returns nil.
+func ReferenceLocation(latitude, longitude *float64) (*Coordinates, error) {
+	if latitude == nil && longitude == nil {
+		return nil, nil
+	}
+	if latitude == nil || longitude == nil {
+		return nil, fmt.Errorf("latitude and longitude must be given together")
+	}
+	if *latitude < -90 || *latitude > 90 {
+		return nil, fmt.Errorf("latitude %v must be between -90 and 90", *latitude)
+	}
+	if *longitude < -180 || *longitude > 180 {
+		return nil, fmt.Errorf("longitude %v must be between -180 and 180", *longitude)
+	}
+	return &Coordinates{Latitude: *latitude, Longitude: *longitude}, nil
+}
+
+// haversineKm returns the great-circle distance between two points in kilometres
+func haversineKm(from, to Coordinates) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLng := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
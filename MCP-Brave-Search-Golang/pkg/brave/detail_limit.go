@@ -0,0 +1,62 @@
+package brave
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// detailLimiter caps the POI and description requests local searches make
+// in each minute, counted in fixed one-minute windows
+type detailLimiter struct {
+	perMinute   int // zero or less disables the cap
+	windowStart time.Time
+	requests    int
+	now         func() time.Time
+	mu          sync.Mutex
+}
+
+// localDetailLimiter limits the detail requests of local searches
+var localDetailLimiter = &detailLimiter{now: time.Now}
+
+// SetLocalDetailLimit caps the POI and description requests made by local
+// searches at perMinute, separately from the API rate limit, since each
+// local search fans out into both. Once the cap is reached, local searches
+// return places without descriptions until the minute is up. Zero or less
+// removes the cap. It should be called at startup.
+func SetLocalDetailLimit(perMinute int) {
+	localDetailLimiter = &detailLimiter{perMinute: perMinute, now: time.Now}
+}
+
+// allow counts a local search's n detail requests and reports whether they
+// all fit. The POIs request is always made, so when they don't fit only it
+// is counted and false is returned to skip the descriptions.
+func (l *detailLimiter) allow(n int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.perMinute <= 0 {
+		return true
+	}
+
+	now := l.now()
+	if now.Sub(l.windowStart) >= time.Minute {
+		l.windowStart = now
+		l.requests = 0
+	}
+
+	if l.requests+n > l.perMinute {
+		l.requests++
+		fmt.Fprintf(os.Stderr, "Warning: local detail limit of %d requests per minute reached, returning places without descriptions\n", l.perMinute)
+		return false
+	}
+
+	l.requests += n
+	return true
+}
+
+// note explains results missing descriptions because of the limit
+func (l *detailLimiter) note() string {
+	return fmt.Sprintf("Note: descriptions were left out because the limit of %d local detail requests per minute was reached", l.perMinute)
+}
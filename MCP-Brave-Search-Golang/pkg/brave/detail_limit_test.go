@@ -0,0 +1,80 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestLocalSearchDetailLimit(t *testing.T) {
+	descriptionRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/res/v1/web/search":
+			w.Write([]byte(`{"locations": {"results": [{"id": "loc1"}]}}`))
+		case "/res/v1/local/pois":
+			w.Write([]byte(`{"results": [{"id": "loc1", "name": "Corner Cafe"}]}`))
+		case "/res/v1/local/descriptions":
+			descriptionRequests++
+			w.Write([]byte(`{"descriptions": {"loc1": "Good coffee"}}`))
+		}
+	}))
+	defer server.Close()
+
+	if err := SetBaseURL(server.URL); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer SetBaseURL("")
+	SetResultCache(time.Minute, 10)
+	defer SetResultCache(0, 0)
+
+	// Room for one search's POIs and descriptions, and then only POIs
+	SetLocalDetailLimit(3)
+	defer SetLocalDetailLimit(0)
+	now := time.Now()
+	localDetailLimiter.now = func() time.Time { return now }
+
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 100, PerMonth: 100})
+	results, sent, err := LocalSearch("key", "cafe", 5, "", nil, 0, limiter)
+	if err != nil {
+		t.Fatalf("LocalSearch failed: %v", err)
+	}
+	if sent != 3 {
+		t.Errorf("Expected the location, POI and description requests to be reported, got %d", sent)
+	}
+	if !strings.Contains(results, "Good coffee") {
+		t.Errorf("Expected a description within the limit, got %q", results)
+	}
+
+	results, sent, err = LocalSearch("key", "coffee", 5, "", nil, 0, limiter)
+	if err != nil {
+		t.Fatalf("Expected the search to degrade rather than fail, got %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("Expected only the location and POI requests to be reported, got %d", sent)
+	}
+	if !strings.Contains(results, "Corner Cafe") || strings.Contains(results, "Good coffee") {
+		t.Errorf("Expected the place without its description, got %q", results)
+	}
+	if !strings.Contains(results, "limit of 3 local detail requests per minute") {
+		t.Errorf("Expected a note about the limit, got %q", results)
+	}
+	if descriptionRequests != 1 {
+		t.Errorf("Expected 1 descriptions request, got %d", descriptionRequests)
+	}
+
+	// Degraded results aren't cached, so descriptions return in the next minute
+	now = now.Add(time.Minute)
+	results, _, err = LocalSearch("key", "coffee", 5, "", nil, 0, limiter)
+	if err != nil {
+		t.Fatalf("LocalSearch failed: %v", err)
+	}
+	if !strings.Contains(results, "Good coffee") {
+		t.Errorf("Expected descriptions once the minute is up, got %q", results)
+	}
+}
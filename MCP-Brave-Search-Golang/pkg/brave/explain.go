@@ -0,0 +1,100 @@
+package brave
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// redactedToken replaces the API key in the headers of a request plan
+const redactedToken = "[redacted]"
+
+// RequestPlan describes an API request a search sends, with the API key
+// redacted, so callers can see exactly what was asked of Brave
+type RequestPlan struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Note    string            `json:"note,omitempty"` // when or how the request is sent, if not always as shown
+}
+
+// newRequestPlan returns the plan of a GET request to u with the headers
+// every search request carries
+func newRequestPlan(u string) RequestPlan {
+	return RequestPlan{
+		Method: http.MethodGet,
+		URL:    u,
+		Headers: map[string]string{
+			"Accept":                "application/json",
+			"Accept-Encoding":       "gzip",
+			subscriptionTokenHeader: redactedToken,
+		},
+	}
+}
+
+// ExplainWebSearch returns the request WebSearch sends to Brave for the
+// given arguments, without sending it
+func ExplainWebSearch(query string, opts WebSearchOptions) ([]RequestPlan, error) {
+	u, err := webSearchURL(query, opts)
+	if err != nil {
+		return nil, err
+	}
+	return []RequestPlan{newRequestPlan(u.String())}, nil
+}
+
+// ExplainLocalSearch returns the requests LocalSearch sends to Brave for the
+// given arguments, without sending them. The POI and description requests
+// depend on the location IDs the first request returns, so their URLs show
+// only the endpoint.
+func ExplainLocalSearch(query string, count int, searchLang string) ([]RequestPlan, error) {
+	u, err := locationSearchURL(query, localResultCount(count), searchLang)
+	if err != nil {
+		return nil, err
+	}
+	plans := []RequestPlan{newRequestPlan(u.String())}
+
+	pois, err := localDetailsURL("/res/v1/local/pois", nil)
+	if err != nil {
+		return nil, err
+	}
+	plan := newRequestPlan(pois.String())
+	plan.Note = "sent with an ids parameter for each location ID found; if none are found a web search is sent instead"
+	plans = append(plans, plan)
+
+	descriptions, err := localDetailsURL("/res/v1/local/descriptions", nil)
+	if err != nil {
+		return nil, err
+	}
+	plan = newRequestPlan(descriptions.String())
+	plan.Note = "sent with the same ids as the POI request, while the local detail limit allows"
+	return append(plans, plan), nil
+}
+
+// FormatExplainPlan formats request plans, and the number of requests the
+// search sent to Brave, as text to show alongside the results
+func FormatExplainPlan(plans []RequestPlan, sent int) string {
+	var b strings.Builder
+	b.WriteString("Debug: request plan\n")
+	for i, plan := range plans {
+		fmt.Fprintf(&b, "\n%d. %s %s\n", i+1, plan.Method, plan.URL)
+
+		names := make([]string, 0, len(plan.Headers))
+		for name := range plan.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "   %s: %s\n", name, plan.Headers[name])
+		}
+		if plan.Note != "" {
+			fmt.Fprintf(&b, "   Note: %s\n", plan.Note)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nRequests sent to Brave: %d", sent)
+	if sent == 0 {
+		b.WriteString(" (served from a cached or repeated result)")
+	}
+	return b.String()
+}
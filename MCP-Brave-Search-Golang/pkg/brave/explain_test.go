@@ -0,0 +1,66 @@
+package brave
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestExplainWebSearch(t *testing.T) {
+	plans, err := ExplainWebSearch("golang generics", WebSearchOptions{Count: 50, Offset: 2, Freshness: "pw", Country: "de", SearchLang: "en"})
+	if err != nil {
+		t.Fatalf("ExplainWebSearch failed: %v", err)
+	}
+	if len(plans) != 1 {
+		t.Fatalf("Expected one request, got %d", len(plans))
+	}
+
+	u, err := url.Parse(plans[0].URL)
+	if err != nil {
+		t.Fatalf("Failed to parse the planned URL: %v", err)
+	}
+	if u.Path != "/res/v1/web/search" {
+		t.Errorf("Expected the web search endpoint, got %s", u.Path)
+	}
+	want := map[string]string{
+		"q":           "golang generics",
+		"count":       "20",
+		"offset":      "2",
+		"safesearch":  "moderate",
+		"freshness":   "pw",
+		"country":     "DE",
+		"search_lang": "en",
+	}
+	for name, value := range want {
+		if got := u.Query().Get(name); got != value {
+			t.Errorf("Expected %s=%s, got %q", name, value, got)
+		}
+	}
+	if got := plans[0].Headers[subscriptionTokenHeader]; got != redactedToken {
+		t.Errorf("Expected the API key to be redacted, got %q", got)
+	}
+}
+
+func TestExplainLocalSearch(t *testing.T) {
+	plans, err := ExplainLocalSearch("pizza", 0, "")
+	if err != nil {
+		t.Fatalf("ExplainLocalSearch failed: %v", err)
+	}
+	if len(plans) != 3 {
+		t.Fatalf("Expected the location, POI and description requests, got %d", len(plans))
+	}
+	if !strings.Contains(plans[0].URL, "result_filter=locations") || !strings.Contains(plans[0].URL, "count=5") {
+		t.Errorf("Expected a location search for 5 results, got %s", plans[0].URL)
+	}
+	if !strings.HasSuffix(plans[1].URL, "/res/v1/local/pois") || !strings.HasSuffix(plans[2].URL, "/res/v1/local/descriptions") {
+		t.Errorf("Expected the POI and description endpoints, got %s and %s", plans[1].URL, plans[2].URL)
+	}
+
+	text := FormatExplainPlan(plans, 3)
+	if !strings.Contains(text, "X-Subscription-Token: [redacted]") || !strings.Contains(text, "Requests sent to Brave: 3") {
+		t.Errorf("Expected the redacted key and request count, got %q", text)
+	}
+	if strings.Contains(FormatExplainPlan(plans, 3), "cached") || !strings.Contains(FormatExplainPlan(plans, 0), "cached") {
+		t.Error("Expected only a search sending no requests to be reported as cached")
+	}
+}
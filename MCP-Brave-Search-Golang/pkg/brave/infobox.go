@@ -0,0 +1,69 @@
+package brave
+
+import (
+	"strings"
+)
+
+// Infobox is the knowledge panel Brave returns for queries about an entity
+// such as a person, place or organisation
+type Infobox struct {
+	Title       string     `json:"title"`
+	Label       string     `json:"label"`
+	Category    string     `json:"category"`
+	Description string     `json:"description"`
+	LongDesc    string     `json:"long_desc"`
+	URL         string     `json:"url"`
+	WebsiteURL  string     `json:"website_url"`
+	Attributes  [][]string `json:"attributes"` // label and value pairs, such as Born and a date
+	Profiles    []struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"profiles"`
+}
+
+// formatInfobox renders an infobox as labeled lines. The long description
+// is preferred to the short one, and attributes without a value are left out.
+func formatInfobox(infobox Infobox) string {
+	var lines []string
+	if infobox.Title != "" {
+		lines = append(lines, "Title: "+infobox.Title)
+	}
+	if category := firstNonEmpty(infobox.Category, infobox.Label); category != "" {
+		lines = append(lines, "Category: "+category)
+	}
+	if description := firstNonEmpty(infobox.LongDesc, infobox.Description); description != "" {
+		lines = append(lines, "Description: "+description)
+	}
+	for _, attribute := range infobox.Attributes {
+		if len(attribute) == 2 && attribute[0] != "" && attribute[1] != "" {
+			lines = append(lines, attribute[0]+": "+attribute[1])
+		}
+	}
+	if infobox.WebsiteURL != "" {
+		lines = append(lines, "Website: "+infobox.WebsiteURL)
+	}
+	if infobox.URL != "" {
+		lines = append(lines, "URL: "+infobox.URL)
+	}
+
+	var profiles []string
+	for _, profile := range infobox.Profiles {
+		if profile.Name != "" && profile.URL != "" {
+			profiles = append(profiles, profile.Name+" ("+profile.URL+")")
+		}
+	}
+	if len(profiles) > 0 {
+		lines = append(lines, "Profiles: "+strings.Join(profiles, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// firstNonEmpty returns the first of values that isn't empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
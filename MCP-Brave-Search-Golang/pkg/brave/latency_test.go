@@ -0,0 +1,68 @@
+package brave
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyEMA(t *testing.T) {
+	client := &Client{minTimeout: time.Second, maxTimeout: 10 * time.Second}
+
+	if timeout := client.RequestTimeout(); timeout != 10*time.Second {
+		t.Errorf("Expected the maximum timeout before any samples, got %v", timeout)
+	}
+
+	client.recordLatency(500 * time.Millisecond)
+	if ema := client.LatencyEMA(); ema != 500*time.Millisecond {
+		t.Errorf("Expected the first sample to seed the average, got %v", ema)
+	}
+	if timeout := client.RequestTimeout(); timeout != 2*time.Second {
+		t.Errorf("Expected a timeout of 4x the average, got %v", timeout)
+	}
+
+	// A single slow response moves the average only part of the way
+	client.recordLatency(1500 * time.Millisecond)
+	if ema := client.LatencyEMA(); ema != 700*time.Millisecond {
+		t.Errorf("Expected average 700ms after one slow sample, got %v", ema)
+	}
+
+	// Sustained fast responses pull the timeout down to the minimum
+	for i := 0; i < 50; i++ {
+		client.recordLatency(50 * time.Millisecond)
+	}
+	if timeout := client.RequestTimeout(); timeout != time.Second {
+		t.Errorf("Expected the minimum timeout while fast, got %v", timeout)
+	}
+
+	// Sustained slow responses push it up to the maximum
+	for i := 0; i < 50; i++ {
+		client.recordLatency(5 * time.Second)
+	}
+	if timeout := client.RequestTimeout(); timeout != 10*time.Second {
+		t.Errorf("Expected the maximum timeout while slow, got %v", timeout)
+	}
+}
+
+func TestSetTimeoutBounds(t *testing.T) {
+	client := &Client{}
+	client.SetTimeoutBounds(0, 0)
+	client.recordLatency(time.Millisecond)
+	if timeout := client.RequestTimeout(); timeout != DefaultMinRequestTimeout {
+		t.Errorf("Expected default minimum %v, got %v", DefaultMinRequestTimeout, timeout)
+	}
+}
+
+func TestSetTimeoutBoundsUpdatesHTTPClient(t *testing.T) {
+	defer defaultClient.SetTimeoutBounds(DefaultMinRequestTimeout, DefaultMaxRequestTimeout)
+
+	defaultClient.SetTimeoutBounds(time.Second, 45*time.Second)
+	if httpClient.Timeout != 45*time.Second {
+		t.Errorf("Expected the shared client timeout to follow the maximum, got %v", httpClient.Timeout)
+	}
+
+	// Other clients don't change the shared HTTP client
+	(&Client{}).SetTimeoutBounds(time.Second, 5*time.Second)
+	if httpClient.Timeout != 45*time.Second {
+		t.Errorf("Expected the shared client timeout to be unchanged, got %v", httpClient.Timeout)
+	}
+}
@@ -1,10 +1,7 @@
 package brave
 
 import (
-	"compress/gzip"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -41,13 +38,14 @@ type Rating struct {
 
 // POI represents a point of interest result
 type POI struct {
-	ID           string   `json:"id"`
-	Name         string   `json:"name"`
-	Address      Address  `json:"address"`
-	Phone        string   `json:"phone"`
-	Rating       Rating   `json:"rating"`
-	PriceRange   string   `json:"priceRange"`
-	OpeningHours []string `json:"openingHours"`
+	ID           string       `json:"id"`
+	Name         string       `json:"name"`
+	Address      Address      `json:"address"`
+	Phone        string       `json:"phone"`
+	Rating       Rating       `json:"rating"`
+	PriceRange   string       `json:"priceRange"`
+	OpeningHours []string     `json:"openingHours"`
+	Coordinates  *Coordinates `json:"coordinates"`
 }
 
 // POIsResponse represents the response from the POIs API
@@ -60,102 +58,142 @@ type DescriptionsResponse struct {
 	Descriptions map[string]string `json:"descriptions"`
 }
 
-// LocalSearch performs a local search using the Brave Search API
+// defaultLocalSearchLang is the language local searches use when none is given
+const defaultLocalSearchLang = "en"
+
+// LocalSearch performs a local search using the Brave Search API. searchLang
+// is the language code to search in; empty means English. When reference is
+// not nil each result shows its distance from that location. When minRating
+// is above zero, places rated below it, or not rated, are left out. Results
+// of a recent identical search are returned from the result cache. Once the
+// local detail limit is reached, places are returned without descriptions.
+// It also returns the number of requests sent to Brave, which is 0 for
+// cached results.
 func LocalSearch(
 	apiKey string,
 	query string,
 	count int,
+	searchLang string,
+	reference *Coordinates,
+	minRating float64,
 	rateLimiter *ratelimit.RateLimiter,
-) (string, error) {
+) (string, int, error) {
+	var sent requestCount
+	results, err := cachedSearchIfComplete(resultCacheKey("local", apiKey, query, count, searchLang, reference, minRating), func() (string, bool, error) {
+		return localSearch(apiKey, query, count, searchLang, reference, minRating, rateLimiter, &sent)
+	})
+	return results, sent.value(), err
+}
+
+// localSearch performs a local search without consulting the result cache,
+// reporting whether the results are complete or lack descriptions. Each
+// request is counted in sent once it is sent.
+func localSearch(
+	apiKey string,
+	query string,
+	count int,
+	searchLang string,
+	reference *Coordinates,
+	minRating float64,
+	rateLimiter *ratelimit.RateLimiter,
+	sent *requestCount,
+) (string, bool, error) {
 	// Check rate limits
-	if err := rateLimiter.CheckLimit(); err != nil {
-		return "", err
+	if err := reserveRequests(rateLimiter, 1); err != nil {
+		return "", false, err
 	}
 
-	// Ensure count is within API limits
-	if count <= 0 {
-		count = 5 // Default value
-	} else if count > 20 {
-		count = 20 // API maximum
-	}
+	count = localResultCount(count)
 
 	// Step 1: Perform initial search to get location IDs
-	locationIDs, err := getLocationIDs(apiKey, query, count, rateLimiter)
+	locationIDs, err := getLocationIDs(apiKey, query, count, searchLang, rateLimiter, sent)
 	if err != nil {
-		return "", err
+		return "", false, err
 	}
 
 	// If no locations found, fall back to web search
 	if len(locationIDs) == 0 {
-		return WebSearch(apiKey, query, count, 0, rateLimiter)
+		results, fallbackSent, err := WebSearch(apiKey, query, WebSearchOptions{Count: count, SearchLang: searchLang}, rateLimiter)
+		sent.add(fallbackSent)
+		return results, true, err
 	}
 
-	// Step 2: Get POIs and descriptions in parallel
-	poisChan := make(chan POIsResponse)
-	poisErrChan := make(chan error)
-	descChan := make(chan DescriptionsResponse)
-	descErrChan := make(chan error)
-
-	go func() {
-		pois, err := getPOIsData(apiKey, locationIDs, rateLimiter)
-		if err != nil {
-			poisErrChan <- err
-			return
-		}
-		poisChan <- pois
-	}()
-
-	go func() {
-		desc, err := getDescriptionsData(apiKey, locationIDs, rateLimiter)
-		if err != nil {
-			descErrChan <- err
-			return
-		}
-		descChan <- desc
-	}()
+	// The POIs are always fetched, but the descriptions only while the local
+	// detail limit allows
+	describe := localDetailLimiter.allow(2)
 
-	// Wait for both goroutines to complete
+	// Step 2: Get POIs and descriptions in parallel, bounded by the worker pool
 	var poisResp POIsResponse
 	var descResp DescriptionsResponse
+	var poisErr, descErr error
+
+	group := workerPool.Group()
+	group.Submit(func() {
+		poisResp, poisErr = getPOIsData(apiKey, locationIDs, rateLimiter, sent)
+	})
+	if describe {
+		group.Submit(func() {
+			descResp, descErr = getDescriptionsData(apiKey, locationIDs, rateLimiter, sent)
+		})
+	}
+	group.Wait()
 
-	select {
-	case poisResp = <-poisChan:
-	case err := <-poisErrChan:
-		return "", fmt.Errorf("failed to get POIs data: %w", err)
+	if poisErr != nil {
+		return "", false, fmt.Errorf("failed to get POIs data: %w", poisErr)
+	}
+	if descErr != nil {
+		return "", false, fmt.Errorf("failed to get descriptions data: %w", descErr)
 	}
 
-	select {
-	case descResp = <-descChan:
-	case err := <-descErrChan:
-		return "", fmt.Errorf("failed to get descriptions data: %w", err)
+	// Drop places rated below the minimum
+	if minRating > 0 {
+		poisResp.Results = filterByRating(poisResp.Results, minRating)
+		if len(poisResp.Results) == 0 {
+			return fmt.Sprintf("No local results rated %.1f or above", minRating), describe, nil
+		}
 	}
 
 	// Format the results
-	return formatLocalResults(poisResp, descResp), nil
+	results := formatLocalResults(poisResp, descResp, reference)
+	if !describe {
+		results += "\n\n" + localDetailLimiter.note()
+	}
+	return results, describe, nil
+}
+
+// localResultCount returns count within the API limits, defaulting to 5
+func localResultCount(count int) int {
+	if count <= 0 {
+		return 5 // Default value
+	} else if count > 20 {
+		return 20 // API maximum
+	}
+	return count
+}
+
+// filterByRating returns the POIs rated at least minRating
+func filterByRating(pois []POI, minRating float64) []POI {
+	var filtered []POI
+	for _, poi := range pois {
+		if poi.Rating.RatingValue >= minRating {
+			filtered = append(filtered, poi)
+		}
+	}
+	return filtered
 }
 
 // getLocationIDs performs the initial search to get location IDs
-func getLocationIDs(apiKey string, query string, count int, rateLimiter *ratelimit.RateLimiter) ([]string, error) {
+func getLocationIDs(apiKey string, query string, count int, searchLang string, rateLimiter *ratelimit.RateLimiter, sent *requestCount) ([]string, error) {
 	// Check rate limits
-	if err := rateLimiter.CheckLimit(); err != nil {
+	if err := reserveRequests(rateLimiter, 1); err != nil {
 		return nil, err
 	}
 
-	// Build the URL
-	baseURL := "https://api.search.brave.com/res/v1/web/search"
-	u, err := url.Parse(baseURL)
+	u, err := locationSearchURL(query, count, searchLang)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse URL: %w", err)
+		return nil, err
 	}
 
-	// Add query parameters
-	q := u.Query()
-	q.Set("q", query)
-	q.Set("search_lang", "en")
-	q.Set("result_filter", "locations")
-	q.Set("count", strconv.Itoa(count))
-	u.RawQuery = q.Encode()
-
 	// Create the request
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
 	if err != nil {
@@ -165,40 +203,13 @@ func getLocationIDs(apiKey string, query string, count int, rateLimiter *ratelim
 	// Set headers
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
-	req.Header.Set("X-Subscription-Token", apiKey)
+	req.Header.Set(subscriptionTokenHeader, apiKey)
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Brave API error: %d %s\n%s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	// Create a reader based on content encoding
-	var reader io.ReadCloser
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		var err error
-		reader, err = gzip.NewReader(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer reader.Close()
-	default:
-		reader = resp.Body
-	}
-
-	// Parse the response
+	// Send the request and parse the response
+	sent.add(1)
 	var locationResp LocationSearchResponse
-	if err := json.NewDecoder(reader).Decode(&locationResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := getJSON(req, &locationResp); err != nil {
+		return nil, err
 	}
 
 	// Extract location IDs
@@ -212,28 +223,39 @@ func getLocationIDs(apiKey string, query string, count int, rateLimiter *ratelim
 	return locationIDs, nil
 }
 
-// getPOIsData gets POI details for the given location IDs
-func getPOIsData(apiKey string, ids []string, rateLimiter *ratelimit.RateLimiter) (POIsResponse, error) {
-	// Check rate limits
-	if err := rateLimiter.CheckLimit(); err != nil {
-		return POIsResponse{}, err
-	}
-
+// locationSearchURL builds the URL of the web search request that finds
+// the location IDs for a local search
+func locationSearchURL(query string, count int, searchLang string) (*url.URL, error) {
 	// Build the URL
-	baseURL := "https://api.search.brave.com/res/v1/local/pois"
-	u, err := url.Parse(baseURL)
+	u, err := url.Parse(endpointURL("/res/v1/web/search"))
 	if err != nil {
-		return POIsResponse{}, fmt.Errorf("failed to parse URL: %w", err)
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	// Add query parameters (multiple IDs)
+	// Add query parameters
 	q := u.Query()
-	for _, id := range ids {
-		if id != "" {
-			q.Add("ids", id)
-		}
+	q.Set("q", query)
+	if searchLang == "" {
+		searchLang = defaultLocalSearchLang
 	}
+	q.Set("search_lang", searchLang)
+	q.Set("result_filter", "locations")
+	q.Set("count", strconv.Itoa(count))
 	u.RawQuery = q.Encode()
+	return u, nil
+}
+
+// getPOIsData gets POI details for the given location IDs
+func getPOIsData(apiKey string, ids []string, rateLimiter *ratelimit.RateLimiter, sent *requestCount) (POIsResponse, error) {
+	// Check rate limits
+	if err := reserveRequests(rateLimiter, 1); err != nil {
+		return POIsResponse{}, err
+	}
+
+	u, err := localDetailsURL("/res/v1/local/pois", ids)
+	if err != nil {
+		return POIsResponse{}, err
+	}
 
 	// Create the request
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
@@ -244,67 +266,29 @@ func getPOIsData(apiKey string, ids []string, rateLimiter *ratelimit.RateLimiter
 	// Set headers
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
-	req.Header.Set("X-Subscription-Token", apiKey)
-
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return POIsResponse{}, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check for errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return POIsResponse{}, fmt.Errorf("Brave API error: %d %s\n%s", resp.StatusCode, resp.Status, string(body))
-	}
-
-	// Create a reader based on content encoding
-	var reader io.ReadCloser
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		var err error
-		reader, err = gzip.NewReader(resp.Body)
-		if err != nil {
-			return POIsResponse{}, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer reader.Close()
-	default:
-		reader = resp.Body
-	}
+	req.Header.Set(subscriptionTokenHeader, apiKey)
 
-	// Parse the response
+	// Send the request and parse the response
+	sent.add(1)
 	var poisResp POIsResponse
-	if err := json.NewDecoder(reader).Decode(&poisResp); err != nil {
-		return POIsResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	if err := getJSON(req, &poisResp); err != nil {
+		return POIsResponse{}, err
 	}
 
 	return poisResp, nil
 }
 
 // getDescriptionsData gets descriptions for the given location IDs
-func getDescriptionsData(apiKey string, ids []string, rateLimiter *ratelimit.RateLimiter) (DescriptionsResponse, error) {
+func getDescriptionsData(apiKey string, ids []string, rateLimiter *ratelimit.RateLimiter, sent *requestCount) (DescriptionsResponse, error) {
 	// Check rate limits
-	if err := rateLimiter.CheckLimit(); err != nil {
+	if err := reserveRequests(rateLimiter, 1); err != nil {
 		return DescriptionsResponse{}, err
 	}
 
-	// Build the URL
-	baseURL := "https://api.search.brave.com/res/v1/local/descriptions"
-	u, err := url.Parse(baseURL)
+	u, err := localDetailsURL("/res/v1/local/descriptions", ids)
 	if err != nil {
-		return DescriptionsResponse{}, fmt.Errorf("failed to parse URL: %w", err)
-	}
-
-	// Add query parameters (multiple IDs)
-	q := u.Query()
-	for _, id := range ids {
-		if id != "" {
-			q.Add("ids", id)
-		}
+		return DescriptionsResponse{}, err
 	}
-	u.RawQuery = q.Encode()
 
 	// Create the request
 	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
@@ -315,47 +299,41 @@ func getDescriptionsData(apiKey string, ids []string, rateLimiter *ratelimit.Rat
 	// Set headers
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Accept-Encoding", "gzip")
-	req.Header.Set("X-Subscription-Token", apiKey)
+	req.Header.Set(subscriptionTokenHeader, apiKey)
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return DescriptionsResponse{}, fmt.Errorf("failed to send request: %w", err)
+	// Send the request and parse the response
+	sent.add(1)
+	var descResp DescriptionsResponse
+	if err := getJSON(req, &descResp); err != nil {
+		return DescriptionsResponse{}, err
 	}
-	defer resp.Body.Close()
 
-	// Check for errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return DescriptionsResponse{}, fmt.Errorf("Brave API error: %d %s\n%s", resp.StatusCode, resp.Status, string(body))
-	}
+	return descResp, nil
+}
 
-	// Create a reader based on content encoding
-	var reader io.ReadCloser
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		var err error
-		reader, err = gzip.NewReader(resp.Body)
-		if err != nil {
-			return DescriptionsResponse{}, fmt.Errorf("failed to create gzip reader: %w", err)
-		}
-		defer reader.Close()
-	default:
-		reader = resp.Body
+// localDetailsURL builds the URL of a POI or descriptions request for the
+// given location IDs
+func localDetailsURL(path string, ids []string) (*url.URL, error) {
+	// Build the URL
+	u, err := url.Parse(endpointURL(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
-	// Parse the response
-	var descResp DescriptionsResponse
-	if err := json.NewDecoder(reader).Decode(&descResp); err != nil {
-		return DescriptionsResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	// Add query parameters (multiple IDs)
+	q := u.Query()
+	for _, id := range ids {
+		if id != "" {
+			q.Add("ids", id)
+		}
 	}
-
-	return descResp, nil
+	u.RawQuery = q.Encode()
+	return u, nil
 }
 
-// formatLocalResults formats the POIs and descriptions into a string
-func formatLocalResults(poisResp POIsResponse, descResp DescriptionsResponse) string {
+// formatLocalResults formats the POIs and descriptions into a string. When
+// reference is not nil, results with coordinates show their distance from it.
+func formatLocalResults(poisResp POIsResponse, descResp DescriptionsResponse, reference *Coordinates) string {
 	if len(poisResp.Results) == 0 {
 		return "No local results found"
 	}
@@ -401,15 +379,29 @@ func formatLocalResults(poisResp POIsResponse, descResp DescriptionsResponse) st
 			description = desc
 		}
 
+		// Format coordinates and the distance from the reference location
+		coordinates := "N/A"
+		if poi.Coordinates != nil {
+			coordinates = poi.Coordinates.String()
+		}
+
 		// Format result
-		result := fmt.Sprintf("Name: %s\nAddress: %s\nPhone: %s\nRating: %s\nPrice Range: %s\nHours: %s\nDescription: %s",
+		result := fmt.Sprintf("Name: %s\nAddress: %s\nCoordinates: %s\nPhone: %s\nRating: %s\nPrice Range: %s\nHours: %s\nDescription: %s",
 			poi.Name,
 			address,
+			coordinates,
 			getNonEmptyString(poi.Phone, "N/A"),
 			rating,
 			getNonEmptyString(poi.PriceRange, "N/A"),
 			hours,
 			description)
+		if reference != nil {
+			distance := "N/A"
+			if poi.Coordinates != nil {
+				distance = fmt.Sprintf("%.2f km", haversineKm(*reference, *poi.Coordinates))
+			}
+			result += "\nDistance: " + distance
+		}
 		
 		results = append(results, result)
 	}
@@ -434,6 +426,7 @@ var LocalSearchTool = map[string]interface{}{
 		"- Business names and addresses\n" +
 		"- Ratings and review counts\n" +
 		"- Phone numbers and opening hours\n" +
+		"- Coordinates, and the distance from a given latitude and longitude\n" +
 		"Use this when the query implies 'near me' or mentions specific locations. " +
 		"Automatically falls back to web search if no local results are found.",
 	"inputSchema": map[string]interface{}{
@@ -448,6 +441,27 @@ var LocalSearchTool = map[string]interface{}{
 				"description": "Number of results (1-20, default 5)",
 				"default":     5,
 			},
+			"search_lang": map[string]interface{}{
+				"type":        "string",
+				"description": "Language code of the results, e.g. de or fr (default en)",
+			},
+			"min_rating": map[string]interface{}{
+				"type":        "number",
+				"description": "Only return places rated at least this highly (0-5); unrated places are left out",
+			},
+			"latitude": map[string]interface{}{
+				"type":        "number",
+				"description": "Latitude of a reference location (-90 to 90); with longitude, each result shows its distance from it",
+			},
+			"longitude": map[string]interface{}{
+				"type":        "number",
+				"description": "Longitude of a reference location (-180 to 180); must be given with latitude",
+			},
+			"debug": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also return the requests sent to Brave, with the API key redacted, and the rate limit tokens the search consumed",
+				"default":     false,
+			},
 		},
 		"required": []string{"query"},
 	},
@@ -0,0 +1,97 @@
+package brave
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPOICoordinates(t *testing.T) {
+	var pois POIsResponse
+	data := `{"results": [
+		{"id": "a", "name": "Array", "coordinates": [51.5007, -0.1246]},
+		{"id": "b", "name": "Object", "coordinates": {"latitude": 48.8584, "longitude": 2.2945}},
+		{"id": "c", "name": "Missing"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &pois); err != nil {
+		t.Fatalf("Failed to decode POIs: %v", err)
+	}
+
+	if c := pois.Results[0].Coordinates; c == nil || c.Latitude != 51.5007 || c.Longitude != -0.1246 {
+		t.Errorf("Expected coordinates from an array, got %+v", c)
+	}
+	if c := pois.Results[1].Coordinates; c == nil || c.Latitude != 48.8584 || c.Longitude != 2.2945 {
+		t.Errorf("Expected coordinates from an object, got %+v", c)
+	}
+	if c := pois.Results[2].Coordinates; c != nil {
+		t.Errorf("Expected no coordinates, got %+v", c)
+	}
+
+	reference := &Coordinates{Latitude: 51.5007, Longitude: -0.1246}
+	results := strings.Split(formatLocalResults(pois, DescriptionsResponse{}, reference), "\n---\n")
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	if !strings.Contains(results[0], "Coordinates: 51.500700, -0.124600") || !strings.Contains(results[0], "Distance: 0.00 km") {
+		t.Errorf("Expected coordinates and a zero distance, got %q", results[0])
+	}
+	if !strings.Contains(results[1], "Distance: 340.") {
+		t.Errorf("Expected a distance of about 340 km, got %q", results[1])
+	}
+	if !strings.Contains(results[2], "Coordinates: N/A") || !strings.Contains(results[2], "Distance: N/A") {
+		t.Errorf("Expected missing coordinates and distance, got %q", results[2])
+	}
+
+	if output := formatLocalResults(pois, DescriptionsResponse{}, nil); strings.Contains(output, "Distance:") {
+		t.Errorf("Expected no distances without a reference location, got %q", output)
+	}
+}
+
+func TestHaversineKm(t *testing.T) {
+	// One degree of longitude along the equator
+	distance := haversineKm(Coordinates{0, 0}, Coordinates{0, 1})
+	if math.Abs(distance-111.19) > 0.01 {
+		t.Errorf("Expected about 111.19 km, got %.4f", distance)
+	}
+}
+
+func TestReferenceLocation(t *testing.T) {
+	latitude, longitude := 40.7829, -73.9654
+
+	reference, err := ReferenceLocation(&latitude, &longitude)
+	if err != nil || reference == nil || reference.Latitude != latitude || reference.Longitude != longitude {
+		t.Errorf("Expected reference location, got %+v and %v", reference, err)
+	}
+
+	if reference, err := ReferenceLocation(nil, nil); reference != nil || err != nil {
+		t.Errorf("Expected no reference location, got %+v and %v", reference, err)
+	}
+
+	if _, err := ReferenceLocation(&latitude, nil); err == nil {
+		t.Error("Expected an error for a latitude without a longitude")
+	}
+
+	outOfRange := 91.0
+	if _, err := ReferenceLocation(&outOfRange, &longitude); err == nil {
+		t.Error("Expected an error for an out of range latitude")
+	}
+}
+
+func TestFilterByRating(t *testing.T) {
+	pois := []POI{
+		{ID: "a", Rating: Rating{RatingValue: 4.6}},
+		{ID: "b", Rating: Rating{RatingValue: 3.9}},
+		{ID: "c"},
+		{ID: "d", Rating: Rating{RatingValue: 4.0}},
+	}
+
+	filtered := filterByRating(pois, 4)
+	if len(filtered) != 2 || filtered[0].ID != "a" || filtered[1].ID != "d" {
+		t.Errorf("Expected places a and d, got %+v", filtered)
+	}
+
+	if filtered := filterByRating(pois, 4.8); len(filtered) != 0 {
+		t.Errorf("Expected no places, got %+v", filtered)
+	}
+}
@@ -0,0 +1,157 @@
+package brave
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+// NewsFreshnessValues are the accepted freshness filters: past day, week, month and year
+var NewsFreshnessValues = []string{"pd", "pw", "pm", "py"}
+
+// NewsSearchResponse represents the response from the Brave news search API.
+// News results carry the same fields as web results.
+type NewsSearchResponse struct {
+	Results []WebResult `json:"results"`
+}
+
+// NewsSearch performs a news search using the Brave Search API. freshness
+// limits results to the past day, week, month or year; empty means any age.
+// It also returns the number of requests sent to Brave.
+func NewsSearch(
+	apiKey string,
+	query string,
+	count int,
+	freshness string,
+	rateLimiter *ratelimit.RateLimiter,
+) (string, int, error) {
+	// Validate the arguments before spending any quota
+	if err := validateNewsFreshness(freshness); err != nil {
+		return "", 0, err
+	}
+
+	// Check rate limits
+	if err := reserveRequests(rateLimiter, 1); err != nil {
+		return "", 0, err
+	}
+
+	var sent requestCount
+	results, err := fetchNewsResults(apiKey, query, count, freshness, &sent)
+	if err != nil {
+		return "", sent.value(), err
+	}
+
+	return formatNewsResults(results), sent.value(), nil
+}
+
+// validateNewsFreshness checks that freshness is empty or a known value
+func validateNewsFreshness(freshness string) error {
+	if freshness == "" {
+		return nil
+	}
+	for _, value := range NewsFreshnessValues {
+		if freshness == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid freshness %q: must be one of %s", freshness, strings.Join(NewsFreshnessValues, ", "))
+}
+
+// formatNewsResults renders news results as text
+func formatNewsResults(newsResults []WebResult) string {
+	if len(newsResults) == 0 {
+		return "No news results found"
+	}
+
+	var results []string
+	for _, result := range newsResults {
+		results = append(results, fmt.Sprintf("Title: %s\nDescription: %s\nURL: %s\nAge: %s\nSource: %s",
+			result.Title,
+			result.Description,
+			result.URL,
+			getNonEmptyString(result.Age, "N/A"),
+			getNonEmptyString(result.Source(), "N/A")))
+	}
+
+	return strings.Join(results, "\n\n")
+}
+
+// fetchNewsResults sends a news search request; the caller is responsible for
+// rate limiting. An empty freshness applies no age filter. The request is
+// counted in sent once it is sent.
+func fetchNewsResults(apiKey string, query string, count int, freshness string, sent *requestCount) ([]WebResult, error) {
+	// Ensure count is within API limits
+	if count <= 0 {
+		count = 10 // Default value
+	} else if count > 20 {
+		count = 20 // API maximum
+	}
+
+	// Build the URL
+	u, err := url.Parse(endpointURL("/res/v1/news/search"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	// Add query parameters
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("count", strconv.Itoa(count))
+	if freshness != "" {
+		q.Set("freshness", freshness)
+	}
+	u.RawQuery = q.Encode()
+
+	// Create the request
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set(subscriptionTokenHeader, apiKey)
+
+	// Send the request and parse the response
+	sent.add(1)
+	var newsResp NewsSearchResponse
+	if err := getJSON(req, &newsResp); err != nil {
+		return nil, err
+	}
+
+	return newsResp.Results, nil
+}
+
+// NewsSearchTool defines the schema for the brave_news_search tool
+var NewsSearchTool = map[string]interface{}{
+	"name": "brave_news_search",
+	"description": "Searches recent news articles using the Brave News Search API. " +
+		"Use this for headlines, breaking news and current events, where web search results may be stale. " +
+		"Returns each article's title, description, URL, age and source. " +
+		"Use freshness to limit results to the past day, week, month or year.",
+	"inputSchema": map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"query": map[string]interface{}{
+				"type":        "string",
+				"description": "News search query (max 400 chars, 50 words)",
+			},
+			"count": map[string]interface{}{
+				"type":        "number",
+				"description": "Number of results (1-20, default 10)",
+				"default":     10,
+			},
+			"freshness": map[string]interface{}{
+				"type":        "string",
+				"enum":        NewsFreshnessValues,
+				"description": "Only return articles from the past day (pd), week (pw), month (pm) or year (py)",
+			},
+		},
+		"required": []string{"query"},
+	},
+}
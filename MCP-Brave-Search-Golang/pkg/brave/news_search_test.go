@@ -0,0 +1,34 @@
+package brave
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatNewsResults(t *testing.T) {
+	result := WebResult{Title: "Go 2 released", Description: "Big news", URL: "https://go.dev/blog", Age: "3 hours ago"}
+	result.MetaURL.Hostname = "go.dev"
+
+	output := formatNewsResults([]WebResult{result})
+	expected := "Title: Go 2 released\nDescription: Big news\nURL: https://go.dev/blog\nAge: 3 hours ago\nSource: go.dev"
+	if output != expected {
+		t.Errorf("Expected output %q, got %q", expected, output)
+	}
+
+	if output := formatNewsResults(nil); output != "No news results found" {
+		t.Errorf("Expected no results message, got %q", output)
+	}
+}
+
+func TestValidateNewsFreshness(t *testing.T) {
+	for _, freshness := range []string{"", "pd", "pw", "pm", "py"} {
+		if err := validateNewsFreshness(freshness); err != nil {
+			t.Errorf("Expected freshness %q to be accepted, got %v", freshness, err)
+		}
+	}
+
+	err := validateNewsFreshness("yesterday")
+	if err == nil || !strings.Contains(err.Error(), `"yesterday"`) {
+		t.Errorf("Expected error naming the invalid freshness, got %v", err)
+	}
+}
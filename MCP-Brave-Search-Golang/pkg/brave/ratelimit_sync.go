@@ -0,0 +1,71 @@
+package brave
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers in which Brave reports the quota left in each of its rate limit
+// windows, as comma-separated lists such as "1, 14999" for the per-second
+// and per-month windows. Reset values are seconds until each window resets.
+const (
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// rateLimitSync receives the quota reported with each successful response,
+// along with the API key the request was made with
+var rateLimitSync func(apiKey string, remaining int, reset time.Time)
+
+// SetRateLimitSync sets a function to call with the quota Brave reports on
+// each successful response, so local rate limiting can follow what the API
+// actually enforces. It should be called at startup.
+func SetRateLimitSync(sync func(apiKey string, remaining int, reset time.Time)) {
+	rateLimitSync = sync
+}
+
+// syncRateLimit passes the quota reported in a response to rateLimitSync
+func syncRateLimit(req *http.Request, header http.Header, now time.Time) {
+	if rateLimitSync == nil {
+		return
+	}
+	remaining, reset, ok := parseRateLimitHeaders(header, now)
+	if !ok {
+		return
+	}
+	rateLimitSync(req.Header.Get(subscriptionTokenHeader), remaining, reset)
+}
+
+// parseRateLimitHeaders returns the quota of the most constrained window
+// reported in the rate limit headers: the one with the fewest requests
+// remaining, or of those the one that resets last
+func parseRateLimitHeaders(header http.Header, now time.Time) (int, time.Time, bool) {
+	remainingValues := strings.Split(header.Get(rateLimitRemainingHeader), ",")
+	resetValues := strings.Split(header.Get(rateLimitResetHeader), ",")
+	if len(remainingValues) != len(resetValues) {
+		return 0, time.Time{}, false
+	}
+
+	found := false
+	var minRemaining int
+	var latestReset time.Time
+	for i := range remainingValues {
+		remaining, err := strconv.Atoi(strings.TrimSpace(remainingValues[i]))
+		if err != nil {
+			return 0, time.Time{}, false
+		}
+		seconds, err := strconv.Atoi(strings.TrimSpace(resetValues[i]))
+		if err != nil || seconds < 0 {
+			return 0, time.Time{}, false
+		}
+		reset := now.Add(time.Duration(seconds) * time.Second)
+
+		if !found || remaining < minRemaining || (remaining == minRemaining && reset.After(latestReset)) {
+			minRemaining, latestReset = remaining, reset
+			found = true
+		}
+	}
+	return minRemaining, latestReset, found
+}
@@ -0,0 +1,29 @@
+package brave
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimitHeaders(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	header := make(http.Header)
+	header.Set(rateLimitRemainingHeader, "1, 14999")
+	header.Set(rateLimitResetHeader, "1, 1728000")
+	remaining, reset, ok := parseRateLimitHeaders(header, now)
+	if !ok || remaining != 1 || !reset.Equal(now.Add(time.Second)) {
+		t.Errorf("Expected the per-second window, got %d until %v (ok %v)", remaining, reset, ok)
+	}
+
+	header.Set(rateLimitRemainingHeader, "0, 0")
+	remaining, reset, ok = parseRateLimitHeaders(header, now)
+	if !ok || remaining != 0 || !reset.Equal(now.Add(1728000*time.Second)) {
+		t.Errorf("Expected an exhausted monthly quota to last until its reset, got %d until %v (ok %v)", remaining, reset, ok)
+	}
+
+	if _, _, ok := parseRateLimitHeaders(make(http.Header), now); ok {
+		t.Error("Expected missing headers not to be parsed")
+	}
+}
@@ -0,0 +1,103 @@
+package brave
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Retry defaults for rate limited and failed API requests
+const (
+	DefaultMaxRetries     = 2
+	DefaultRetryBaseDelay = 500 * time.Millisecond
+	maxRetryDelay         = 30 * time.Second
+)
+
+// Retry policy applied by getJSON; set with SetRetryPolicy
+var (
+	maxRetries     = DefaultMaxRetries
+	retryBaseDelay = DefaultRetryBaseDelay
+)
+
+// SetRetryPolicy sets how many times a request that gets a 429 or 5xx
+// response is retried, and the delay before the first retry, which doubles
+// for each retry after. A negative maxRetries disables retries and zero
+// values keep the defaults. It should be called at startup.
+func SetRetryPolicy(retries int, baseDelay time.Duration) {
+	switch {
+	case retries < 0:
+		maxRetries = 0
+	case retries == 0:
+		maxRetries = DefaultMaxRetries
+	default:
+		maxRetries = retries
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	retryBaseDelay = baseDelay
+}
+
+// doRequestWithRetry sends a request with doRequest, retrying on 429 and
+// 5xx responses with exponential backoff and jitter. A Retry-After header
+// on the response sets the delay instead, up to maxRetryDelay. The response
+// to the last attempt is returned whatever its status. Requests must not
+// have a body, which every Brave API request satisfies.
+func doRequestWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := doRequest(req.Clone(req.Context()))
+		if err != nil || attempt >= maxRetries || !retryableStatus(resp.StatusCode) {
+			return resp, err
+		}
+
+		delay := retryDelay(attempt, resp.Header.Get("Retry-After"), time.Now())
+		closeBody(resp.Body)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// retryableStatus reports whether a response status is worth retrying
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// retryDelay returns how long to wait before the retry following attempt,
+// counting from 0. A Retry-After value, in seconds or as an HTTP date, takes
+// precedence; otherwise the base delay is doubled per attempt and up to half
+// of it again is added as jitter.
+func retryDelay(attempt int, retryAfter string, now time.Time) time.Duration {
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			return capRetryDelay(time.Duration(seconds) * time.Second)
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return capRetryDelay(date.Sub(now))
+		}
+	}
+
+	delay := retryBaseDelay << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+	return capRetryDelay(delay)
+}
+
+// capRetryDelay keeps a delay between zero and maxRetryDelay
+func capRetryDelay(delay time.Duration) time.Duration {
+	if delay < 0 {
+		return 0
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,81 @@
+package brave
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetJSONRetriesTransientErrors(t *testing.T) {
+	SetRetryPolicy(2, time.Millisecond)
+	defer SetRetryPolicy(0, 0)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch attempts.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"web": {"results": [{"title": "Go"}]}}`)
+		}
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	var resp WebSearchResponse
+	if err := getJSON(req, &resp); err != nil {
+		t.Fatalf("Expected the request to succeed after retrying, got %v", err)
+	}
+	if got := attempts.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestGetJSONGivesUpAfterMaxRetries(t *testing.T) {
+	SetRetryPolicy(1, time.Millisecond)
+	defer SetRetryPolicy(0, 0)
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	var resp WebSearchResponse
+	err := getJSON(req, &resp)
+	if err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Expected the last 502 error to be returned, got %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	if delay := retryDelay(0, "3", now); delay != 3*time.Second {
+		t.Errorf("Expected Retry-After seconds to be used, got %v", delay)
+	}
+	if delay := retryDelay(0, now.Add(5*time.Second).Format(http.TimeFormat), now); delay != 5*time.Second {
+		t.Errorf("Expected Retry-After date to be used, got %v", delay)
+	}
+	if delay := retryDelay(0, "3600", now); delay != maxRetryDelay {
+		t.Errorf("Expected a long Retry-After to be capped at %v, got %v", maxRetryDelay, delay)
+	}
+
+	base := retryBaseDelay * 4
+	if delay := retryDelay(2, "", now); delay < base || delay > base+base/2 {
+		t.Errorf("Expected a backoff between %v and %v, got %v", base, base+base/2, delay)
+	}
+}
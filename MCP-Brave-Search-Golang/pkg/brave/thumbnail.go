@@ -0,0 +1,98 @@
+package brave
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/pool"
+)
+
+// MaxThumbnailBytes is the largest thumbnail image that will be embedded in a response
+const MaxThumbnailBytes = 256 * 1024
+
+// maxThumbnailWorkers bounds how many thumbnails are downloaded at once
+const maxThumbnailWorkers = 4
+
+// thumbnailPool limits concurrent thumbnail downloads across all requests
+var thumbnailPool = pool.New(maxThumbnailWorkers)
+
+// Thumbnail is a downloaded result thumbnail, base64 encoded for use as image content
+type Thumbnail struct {
+	URL      string
+	MimeType string
+	Data     string
+}
+
+// fetchThumbnails downloads the thumbnails of the given results, in result order
+func fetchThumbnails(results []WebResult) []Thumbnail {
+	fetched := make([]*Thumbnail, len(results))
+
+	group := thumbnailPool.Group()
+	for i, result := range results {
+		if result.Thumbnail.Src == "" {
+			continue
+		}
+		i, src := i, result.Thumbnail.Src
+		group.Submit(func() {
+			thumbnail, err := fetchThumbnail(src)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Skipping thumbnail: %v\n", err)
+				return
+			}
+			fetched[i] = &thumbnail
+		})
+	}
+	group.Wait()
+
+	var thumbnails []Thumbnail
+	for _, thumbnail := range fetched {
+		if thumbnail != nil {
+			thumbnails = append(thumbnails, *thumbnail)
+		}
+	}
+	return thumbnails
+}
+
+// fetchThumbnail downloads a single image through the shared client. The API
+// key is not sent, as thumbnails are served from a separate image proxy.
+func fetchThumbnail(src string) (Thumbnail, error) {
+	req, err := http.NewRequest(http.MethodGet, src, nil)
+	if err != nil {
+		return Thumbnail{}, fmt.Errorf("invalid thumbnail URL: %w", err)
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := doRequest(req)
+	if err != nil {
+		return Thumbnail{}, fmt.Errorf("failed to fetch thumbnail: %w", err)
+	}
+	defer closeBody(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return Thumbnail{}, fmt.Errorf("thumbnail request failed: %s", resp.Status)
+	}
+
+	mimeType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || !strings.HasPrefix(mimeType, "image/") {
+		return Thumbnail{}, fmt.Errorf("thumbnail is not an image: %q", resp.Header.Get("Content-Type"))
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxThumbnailBytes+1))
+	if err != nil {
+		return Thumbnail{}, fmt.Errorf("failed to read thumbnail: %w", err)
+	}
+	if len(data) > MaxThumbnailBytes {
+		return Thumbnail{}, fmt.Errorf("thumbnail exceeds %d bytes", MaxThumbnailBytes)
+	}
+
+	return Thumbnail{
+		URL:      src,
+		MimeType: mimeType,
+		Data:     base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
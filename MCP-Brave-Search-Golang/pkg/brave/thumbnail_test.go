@@ -0,0 +1,45 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchThumbnails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(subscriptionTokenHeader) != "" {
+			t.Errorf("Expected thumbnail request without the API key")
+		}
+		switch r.URL.Path {
+		case "/small.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("png"))
+		case "/large.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte(strings.Repeat("x", MaxThumbnailBytes+1)))
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	results := make([]WebResult, 5)
+	results[0].Thumbnail.Src = server.URL + "/small.png"
+	results[1].Thumbnail.Src = server.URL + "/large.png"
+	results[2].Thumbnail.Src = server.URL + "/page.html"
+	results[3].Thumbnail.Src = server.URL + "/missing.png"
+	// results[4] has no thumbnail
+
+	thumbnails := fetchThumbnails(results)
+	if len(thumbnails) != 1 {
+		t.Fatalf("Expected only the small image to be kept, got %d thumbnails", len(thumbnails))
+	}
+	if thumbnails[0].MimeType != "image/png" || thumbnails[0].Data != "cG5n" {
+		t.Errorf("Expected base64 encoded png, got %s %q", thumbnails[0].MimeType, thumbnails[0].Data)
+	}
+}
@@ -1,14 +1,13 @@
 package brave
 
 import (
-	"compress/gzip"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
 )
@@ -18,29 +17,220 @@ type WebResult struct {
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	URL         string `json:"url"`
+	Age         string `json:"age"`
+	PageAge     string `json:"page_age"`
+	Profile     struct {
+		Name string `json:"name"`
+	} `json:"profile"`
+	MetaURL struct {
+		Hostname string `json:"hostname"`
+	} `json:"meta_url"`
+	Thumbnail struct {
+		Src string `json:"src"`
+	} `json:"thumbnail"`
 }
 
+// Source returns the name of the site a result came from
+func (r WebResult) Source() string {
+	if r.Profile.Name != "" {
+		return r.Profile.Name
+	}
+	return r.MetaURL.Hostname
+}
+
+// MaxWebOffset is the largest pagination offset the Brave API accepts
+const MaxWebOffset = 9
+
+// WebResultFields lists the fields that can be selected with the fields argument, in output order
+var WebResultFields = []string{"title", "description", "url", "age", "published", "source"}
+
+// defaultWebResultFields are included when no fields are requested. age and
+// published are left out of results that don't have them.
+var defaultWebResultFields = []string{"title", "description", "url", "age", "published"}
+
+// SafeSearchValues are the accepted safesearch levels for web search
+var SafeSearchValues = []string{"off", "moderate", "strict"}
+
+// freshnessDateLayout is the date format of each end of a freshness range
+const freshnessDateLayout = "2006-01-02"
+
+// WebResultFormats are the accepted output formats for web search: formatted text or a JSON array of results
+var WebResultFormats = []string{"text", "json"}
+
+// ageDateLayout is the format of a result age given as a date
+const ageDateLayout = "January 2, 2006"
+
+// defaultSafeSearch is applied when no safesearch level is given
+const defaultSafeSearch = "moderate"
+
 // WebSearchResponse represents the response from the Brave web search API
 type WebSearchResponse struct {
 	Web struct {
 		Results []WebResult `json:"results"`
 	} `json:"web"`
+	Discussions struct {
+		Results []DiscussionResult `json:"results"`
+	} `json:"discussions"`
+	FAQ struct {
+		Results []FAQResult `json:"results"`
+	} `json:"faq"`
+	Infobox struct {
+		Results []Infobox `json:"results"`
+	} `json:"infobox"`
+}
+
+// WebSearchOptions are the arguments of a web search besides the API key and
+// query. The zero value searches with Brave's defaults.
+type WebSearchOptions struct {
+	Count  int      // results to return, up to 20; zero or less means 10
+	Offset int      // page of results to return, up to MaxWebOffset
+	Fields []string // fields to show from WebResultFields; empty means the defaults
+
+	// SafeSearch filters adult content and is one of SafeSearchValues;
+	// empty means moderate
+	SafeSearch string
+	// Freshness limits results by age, as for news search, or to a date
+	// range written YYYY-MM-DDtoYYYY-MM-DD; empty means any age
+	Freshness string
+	// Country is a two-letter country code and SearchLang a language code to
+	// search in; either may be empty to use Brave's default
+	Country    string
+	SearchLang string
+
+	// SortByAge orders results newest first rather than by relevance
+	SortByAge bool
+	// Sections names extra sections of the response, from WebResultSections,
+	// to include under their own headings: the infobox before the web
+	// results and the others after
+	Sections []string
+}
+
+// WebSearch performs a web search using the Brave Search API. Results of a
+// recent identical search are returned from the result cache. It also
+// returns the number of requests sent to Brave, which is 0 for cached
+// results and for searches rejected before sending.
+func WebSearch(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, int, error) {
+	var sent requestCount
+	results, err := cachedSearch(resultCacheKey("web", apiKey, query, opts), func() (string, error) {
+		resp, err := searchWebResponse(apiKey, query, opts, rateLimiter, &sent)
+		if err != nil {
+			return "", err
+		}
+
+		return formatWebResponse(resp, opts.Fields, opts.Sections), nil
+	})
+	return results, sent.value(), err
+}
+
+// WebSearchStructured performs a web search like WebSearch but returns the
+// results themselves rather than formatted text. Fields is validated but
+// only affects formatted output, so every result field is returned, and
+// Sections is ignored.
+func WebSearchStructured(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter) ([]WebResult, int, error) {
+	var sent requestCount
+	opts.Sections = nil
+	resp, err := searchWebResponse(apiKey, query, opts, rateLimiter, &sent)
+	if err != nil {
+		return nil, sent.value(), err
+	}
+	return resp.Web.Results, sent.value(), nil
+}
+
+// WebSearchWithThumbnails performs a web search like WebSearch and also
+// fetches the thumbnail image of each result that has one. Thumbnails that
+// can't be fetched, or are too large, are left out.
+func WebSearchWithThumbnails(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter) (string, []Thumbnail, int, error) {
+	var sent requestCount
+	resp, err := searchWebResponse(apiKey, query, opts, rateLimiter, &sent)
+	if err != nil {
+		return "", nil, sent.value(), err
+	}
+
+	return formatWebResponse(resp, opts.Fields, opts.Sections), fetchThumbnails(resp.Web.Results), sent.value(), nil
 }
 
-// WebSearch performs a web search using the Brave Search API
-func WebSearch(
-	apiKey string,
-	query string,
-	count int,
-	offset int,
-	rateLimiter *ratelimit.RateLimiter,
-) (string, error) {
+// searchWebResponse queries the Brave web search API and returns the raw
+// response, counting the request in sent once it is sent
+func searchWebResponse(apiKey, query string, opts WebSearchOptions, rateLimiter *ratelimit.RateLimiter, sent *requestCount) (WebSearchResponse, error) {
+	// Validate the arguments before spending any quota
+	if opts.Offset > MaxWebOffset {
+		return WebSearchResponse{}, fmt.Errorf("offset %d is out of range: Brave supports a maximum offset of %d (results beyond ~200 unavailable)", opts.Offset, MaxWebOffset)
+	}
+	if err := validateWebResultFields(opts.Fields); err != nil {
+		return WebSearchResponse{}, err
+	}
+	if err := validateSafeSearch(opts.SafeSearch); err != nil {
+		return WebSearchResponse{}, err
+	}
+	if err := validateWebFreshness(opts.Freshness); err != nil {
+		return WebSearchResponse{}, err
+	}
+	if err := validateCountry(opts.Country); err != nil {
+		return WebSearchResponse{}, err
+	}
+	if err := validateWebResultSections(opts.Sections); err != nil {
+		return WebSearchResponse{}, err
+	}
+
 	// Check rate limits
-	if err := rateLimiter.CheckLimit(); err != nil {
-		return "", err
+	if err := reserveRequests(rateLimiter, 1); err != nil {
+		return WebSearchResponse{}, err
 	}
 
+	resp, err := fetchWebResponse(apiKey, query, opts, sent)
+	if err != nil {
+		return WebSearchResponse{}, err
+	}
+	if opts.SortByAge {
+		sortResultsByAge(resp.Web.Results, time.Now())
+	}
+	return resp, nil
+}
+
+// fetchWebResults sends a web search request and returns its web results;
+// the caller is responsible for rate limiting
+func fetchWebResults(apiKey, query string, opts WebSearchOptions, sent *requestCount) ([]WebResult, error) {
+	resp, err := fetchWebResponse(apiKey, query, opts, sent)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Web.Results, nil
+}
+
+// fetchWebResponse sends a web search request; the caller is responsible for
+// rate limiting. Only the options that shape the request are used. The
+// request is counted in sent once it is sent, whether or not it succeeds.
+func fetchWebResponse(apiKey, query string, opts WebSearchOptions, sent *requestCount) (WebSearchResponse, error) {
+	u, err := webSearchURL(query, opts)
+	if err != nil {
+		return WebSearchResponse{}, err
+	}
+
+	// Create the request
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return WebSearchResponse{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Accept-Encoding", "gzip") // Explicitly accept gzip encoding
+	req.Header.Set(subscriptionTokenHeader, apiKey)
+
+	// Send the request and parse the response
+	sent.add(1)
+	var searchResp WebSearchResponse
+	if err := getJSON(req, &searchResp); err != nil {
+		return WebSearchResponse{}, err
+	}
+
+	return searchResp, nil
+}
+
+// webSearchURL builds the URL of a web search request
+func webSearchURL(query string, opts WebSearchOptions) (*url.URL, error) {
 	// Ensure count is within API limits
+	count := opts.Count
 	if count <= 0 {
 		count = 10 // Default value
 	} else if count > 20 {
@@ -48,75 +238,229 @@ func WebSearch(
 	}
 
 	// Build the URL
-	baseURL := "https://api.search.brave.com/res/v1/web/search"
-	u, err := url.Parse(baseURL)
+	u, err := url.Parse(endpointURL("/res/v1/web/search"))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse URL: %w", err)
+		return nil, fmt.Errorf("failed to parse URL: %w", err)
 	}
 
 	// Add query parameters
 	q := u.Query()
 	q.Set("q", query)
 	q.Set("count", strconv.Itoa(count))
-	q.Set("offset", strconv.Itoa(offset))
+	q.Set("offset", strconv.Itoa(opts.Offset))
+	safesearch := opts.SafeSearch
+	if safesearch == "" {
+		safesearch = defaultSafeSearch
+	}
+	q.Set("safesearch", safesearch)
+	if opts.Freshness != "" {
+		q.Set("freshness", opts.Freshness)
+	}
+	if opts.Country != "" {
+		q.Set("country", strings.ToUpper(opts.Country))
+	}
+	if opts.SearchLang != "" {
+		q.Set("search_lang", opts.SearchLang)
+	}
 	u.RawQuery = q.Encode()
+	return u, nil
+}
 
-	// Create the request
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+// sortResultsByAge orders results newest first. Results without a
+// parseable age keep their original order after the dated ones.
+func sortResultsByAge(results []WebResult, now time.Time) {
+	published := make(map[int]time.Time, len(results))
+	order := make([]int, len(results))
+	for i, result := range results {
+		order[i] = i
+		if t, ok := resultPublished(result, now); ok {
+			published[i] = t
+		}
 	}
 
-	// Set headers
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Encoding", "gzip") // Explicitly accept gzip encoding
-	req.Header.Set("X-Subscription-Token", apiKey)
+	sort.SliceStable(order, func(a, b int) bool {
+		ta, datedA := published[order[a]]
+		tb, datedB := published[order[b]]
+		if datedA != datedB {
+			return datedA
+		}
+		return ta.After(tb)
+	})
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+	sorted := make([]WebResult, len(results))
+	for i, index := range order {
+		sorted[i] = results[index]
 	}
-	defer resp.Body.Close()
+	copy(results, sorted)
+}
 
-	// Check for errors
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("Brave API error: %d %s\n%s", resp.StatusCode, resp.Status, string(body))
+// resultPublished returns when a result was published, from its page_age
+// timestamp or failing that its age, which Brave gives either as a date
+// such as "January 2, 2006" or relative to now such as "3 days ago"
+func resultPublished(result WebResult, now time.Time) (time.Time, bool) {
+	if published, ok := parsePageAge(result.PageAge); ok {
+		return published, true
+	}
+	if published, err := time.Parse(ageDateLayout, result.Age); err == nil {
+		return published, true
 	}
 
-	// Create a reader based on content encoding
-	var reader io.ReadCloser
-	switch resp.Header.Get("Content-Encoding") {
-	case "gzip":
-		var err error
-		reader, err = gzip.NewReader(resp.Body)
-		if err != nil {
-			return "", fmt.Errorf("failed to create gzip reader: %w", err)
+	var amount int
+	var unit string
+	if _, err := fmt.Sscanf(result.Age, "%d %s ago", &amount, &unit); err != nil {
+		return time.Time{}, false
+	}
+	switch strings.TrimSuffix(unit, "s") {
+	case "second":
+		return now.Add(-time.Duration(amount) * time.Second), true
+	case "minute":
+		return now.Add(-time.Duration(amount) * time.Minute), true
+	case "hour":
+		return now.Add(-time.Duration(amount) * time.Hour), true
+	case "day":
+		return now.AddDate(0, 0, -amount), true
+	case "week":
+		return now.AddDate(0, 0, -7*amount), true
+	case "month":
+		return now.AddDate(0, -amount, 0), true
+	case "year":
+		return now.AddDate(-amount, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// validateWebResultFields checks that every requested field is known
+func validateWebResultFields(fields []string) error {
+	for _, field := range fields {
+		known := false
+		for _, candidate := range WebResultFields {
+			if field == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown field %q: must be one of %s", field, strings.Join(WebResultFields, ", "))
 		}
-		defer reader.Close()
-	default:
-		reader = resp.Body
 	}
+	return nil
+}
 
-	// Parse the response
-	var searchResp WebSearchResponse
-	if err := json.NewDecoder(reader).Decode(&searchResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+// validateSafeSearch checks that safesearch is empty or a known level
+func validateSafeSearch(safesearch string) error {
+	if safesearch == "" {
+		return nil
+	}
+	for _, value := range SafeSearchValues {
+		if safesearch == value {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid safesearch %q: must be one of %s", safesearch, strings.Join(SafeSearchValues, ", "))
+}
+
+// validateWebFreshness checks that freshness is empty, a known age filter or
+// a date range whose start is not after its end
+func validateWebFreshness(freshness string) error {
+	if freshness == "" {
+		return nil
+	}
+	for _, value := range NewsFreshnessValues {
+		if freshness == value {
+			return nil
+		}
+	}
+
+	invalid := fmt.Errorf("invalid freshness %q: must be one of %s or a date range YYYY-MM-DDtoYYYY-MM-DD",
+		freshness, strings.Join(NewsFreshnessValues, ", "))
+	start, end, ok := strings.Cut(freshness, "to")
+	if !ok {
+		return invalid
+	}
+	startDate, err := time.Parse(freshnessDateLayout, start)
+	if err != nil {
+		return invalid
+	}
+	endDate, err := time.Parse(freshnessDateLayout, end)
+	if err != nil {
+		return invalid
+	}
+	if startDate.After(endDate) {
+		return fmt.Errorf("invalid freshness %q: range starts after it ends", freshness)
+	}
+	return nil
+}
+
+// validateCountry checks that country is empty or a two-letter code
+func validateCountry(country string) error {
+	if country == "" {
+		return nil
+	}
+	if len(country) != 2 || !isASCIILetter(country[0]) || !isASCIILetter(country[1]) {
+		return fmt.Errorf("invalid country %q: must be a two-letter country code such as US or DE", country)
+	}
+	return nil
+}
+
+// isASCIILetter reports whether b is an ASCII letter
+func isASCIILetter(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// formatWebResults renders results as text, including only the requested fields
+func formatWebResults(webResults []WebResult, fields []string) string {
+	if len(fields) == 0 {
+		fields = defaultWebResultFields
+	}
+	include := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		include[field] = true
 	}
 
-	// Format the results
 	var results []string
-	for _, result := range searchResp.Web.Results {
-		formattedResult := fmt.Sprintf("Title: %s\nDescription: %s\nURL: %s",
-			result.Title,
-			result.Description,
-			result.URL)
-		results = append(results, formattedResult)
+	for _, result := range webResults {
+		var lines []string
+		if include["title"] {
+			lines = append(lines, "Title: "+result.Title)
+		}
+		if include["description"] {
+			lines = append(lines, "Description: "+result.Description)
+		}
+		if include["url"] {
+			lines = append(lines, "URL: "+result.URL)
+		}
+		if include["age"] && result.Age != "" {
+			lines = append(lines, "Age: "+result.Age)
+		}
+		if include["published"] && result.PageAge != "" {
+			lines = append(lines, "Published: "+formatPageAge(result.PageAge))
+		}
+		if include["source"] {
+			lines = append(lines, "Source: "+result.Source())
+		}
+		results = append(results, strings.Join(lines, "\n"))
 	}
 
-	return strings.Join(results, "\n\n"), nil
+	return strings.Join(results, "\n\n")
+}
+
+// formatWebResponse renders the web results of a response followed by the
+// requested extra sections
+func formatWebResponse(resp WebSearchResponse, fields, sections []string) string {
+	formatted := formatWebResults(resp.Web.Results, fields)
+	if infobox := formatInfoboxSection(resp, sections); infobox != "" {
+		if formatted != "" {
+			infobox += "\n\n"
+		}
+		formatted = infobox + formatted
+	}
+	if extra := formatWebSections(resp, sections); extra != "" {
+		if formatted != "" {
+			formatted += "\n\n"
+		}
+		formatted += extra
+	}
+	return formatted
 }
 
 // WebSearchTool defines the schema for the brave_web_search tool
@@ -125,6 +469,8 @@ var WebSearchTool = map[string]interface{}{
 	"description": "Performs a web search using the Brave Search API, ideal for general queries, news, articles, and online content. " +
 		"Use this for broad information gathering, recent events, or when you need diverse web sources. " +
 		"Supports pagination, content filtering, and freshness controls. " +
+		"Use freshness to limit results to the past day (pd), week (pw), month (pm) or year (py), " +
+		"or to a date range such as 2024-01-01to2024-03-31. " +
 		"Maximum 20 results per request, with offset for pagination. ",
 	"inputSchema": map[string]interface{}{
 		"type": "object",
@@ -143,7 +489,79 @@ var WebSearchTool = map[string]interface{}{
 				"description": "Pagination offset (max 9, default 0)",
 				"default":     0,
 			},
+			"fields": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "string",
+					"enum": WebResultFields,
+				},
+				"description": "Fields to include for each result (default title, description, url, age and published; age and published only when known). Request only url and title to reduce response size",
+			},
+			"include_thumbnails": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also return result thumbnails as images (default false)",
+				"default":     false,
+			},
+			"safesearch": map[string]interface{}{
+				"type":        "string",
+				"enum":        SafeSearchValues,
+				"description": "Adult content filtering: off, moderate or strict (default moderate)",
+				"default":     defaultSafeSearch,
+			},
+			"freshness": map[string]interface{}{
+				"type":        "string",
+				"description": "Only return results from the past day (pd), week (pw), month (pm) or year (py), or published within a date range written YYYY-MM-DDtoYYYY-MM-DD",
+			},
+			"country": map[string]interface{}{
+				"type":        "string",
+				"description": "Two-letter country code to return results for, e.g. DE (default US)",
+			},
+			"search_lang": map[string]interface{}{
+				"type":        "string",
+				"description": "Language code of the results, e.g. de or fr (default en)",
+			},
+			"sort_by_age": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Order results newest first instead of by relevance. Results without a known age come last (default false)",
+				"default":     false,
+			},
+			"include_infobox": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Put the knowledge panel Brave returns for people, places and other entities, when there is one, under its own heading before the results. Only supported with the text format (default false)",
+				"default":     false,
+			},
+			"include_discussions": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Append forum discussions from the response under their own heading. Only supported with the text format (default false)",
+				"default":     false,
+			},
+			"include_faq": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Append frequently asked questions and answers from the response under their own heading. Only supported with the text format (default false)",
+				"default":     false,
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"enum":        WebResultFormats,
+				"description": "Output format: text (default) or json, an array of result objects with every field. Thumbnails are only returned with text",
+				"default":     "text",
+			},
+			"debug": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Also return the exact requests sent to Brave, with the API key redacted, and the rate limit tokens the search consumed",
+				"default":     false,
+			},
 		},
 		"required": []string{"query"},
 	},
 }
+
+// formatPageAge formats a result's page_age as a date, or returns it
+// unchanged if it isn't in a known format
+func formatPageAge(pageAge string) string {
+	published, ok := parsePageAge(pageAge)
+	if !ok {
+		return pageAge
+	}
+	return published.Format(freshnessDateLayout)
+}
@@ -0,0 +1,143 @@
+package brave
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+func TestFormatWebResultsFields(t *testing.T) {
+	result := WebResult{Title: "Go", Description: "The Go language", URL: "https://go.dev", Age: "2 days ago"}
+	result.MetaURL.Hostname = "go.dev"
+
+	defaultOutput := formatWebResults([]WebResult{result}, nil)
+	expected := "Title: Go\nDescription: The Go language\nURL: https://go.dev\nAge: 2 days ago"
+	if defaultOutput != expected {
+		t.Errorf("Expected default output %q, got %q", expected, defaultOutput)
+	}
+
+	// Results with a page age also show when they were published
+	dated := result
+	dated.PageAge = "2024-05-30T08:15:00"
+	expected = "Title: Go\nDescription: The Go language\nURL: https://go.dev\nAge: 2 days ago\nPublished: 2024-05-30"
+	if output := formatWebResults([]WebResult{dated}, nil); output != expected {
+		t.Errorf("Expected output with the published date %q, got %q", expected, output)
+	}
+
+	// Results without an age leave the lines out
+	undated := result
+	undated.Age = ""
+	expected = "Title: Go\nDescription: The Go language\nURL: https://go.dev"
+	if output := formatWebResults([]WebResult{undated}, []string{"title", "description", "url", "age", "published"}); output != expected {
+		t.Errorf("Expected output without age lines %q, got %q", expected, output)
+	}
+
+	filtered := formatWebResults([]WebResult{result}, []string{"url", "title"})
+	expected = "Title: Go\nURL: https://go.dev"
+	if filtered != expected {
+		t.Errorf("Expected filtered output %q, got %q", expected, filtered)
+	}
+
+	extra := formatWebResults([]WebResult{result}, []string{"age", "source"})
+	expected = "Age: 2 days ago\nSource: go.dev"
+	if extra != expected {
+		t.Errorf("Expected age and source output %q, got %q", expected, extra)
+	}
+}
+
+func TestValidateWebResultFields(t *testing.T) {
+	if err := validateWebResultFields([]string{"title", "url"}); err != nil {
+		t.Errorf("Expected known fields to be accepted, got %v", err)
+	}
+
+	err := validateWebResultFields([]string{"title", "snippet"})
+	if err == nil || !strings.Contains(err.Error(), `"snippet"`) {
+		t.Errorf("Expected error naming the unknown field, got %v", err)
+	}
+}
+
+func TestWebSearchSafeSearch(t *testing.T) {
+	var requested []string
+	originalTransport := httpClient.Transport
+	defer func() { httpClient.Transport = originalTransport }()
+	httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Query().Get("safesearch"))
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       io.NopCloser(strings.NewReader(`{"web": {"results": []}}`)),
+			Request:    req,
+		}, nil
+	})
+
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	for _, level := range []string{"", "strict"} {
+		if _, _, err := WebSearch("key", "query", WebSearchOptions{Count: 10, SafeSearch: level}, limiter); err != nil {
+			t.Fatalf("Expected safesearch %q to be accepted, got %v", level, err)
+		}
+	}
+	if len(requested) != 2 || requested[0] != "moderate" || requested[1] != "strict" {
+		t.Errorf("Expected safesearch parameters [moderate strict], got %v", requested)
+	}
+
+	_, _, err := WebSearch("key", "query", WebSearchOptions{Count: 10, SafeSearch: "none"}, limiter)
+	if err == nil || !strings.Contains(err.Error(), `"none"`) {
+		t.Errorf("Expected error naming the invalid safesearch level, got %v", err)
+	}
+	if len(requested) != 2 {
+		t.Errorf("Expected no request for an invalid safesearch level, got %d requests", len(requested))
+	}
+}
+
+func TestValidateWebFreshness(t *testing.T) {
+	for _, freshness := range []string{"", "pd", "py", "2024-01-01to2024-03-31", "2024-02-29to2024-02-29"} {
+		if err := validateWebFreshness(freshness); err != nil {
+			t.Errorf("Expected freshness %q to be accepted, got %v", freshness, err)
+		}
+	}
+
+	for _, freshness := range []string{"day", "2024-01-01", "2024-01-01to", "2024-13-01to2024-12-31", "2024-03-31to2024-01-01"} {
+		if err := validateWebFreshness(freshness); err == nil {
+			t.Errorf("Expected freshness %q to be rejected", freshness)
+		}
+	}
+}
+
+func TestValidateCountry(t *testing.T) {
+	for _, country := range []string{"", "DE", "gb"} {
+		if err := validateCountry(country); err != nil {
+			t.Errorf("Expected country %q to be accepted, got %v", country, err)
+		}
+	}
+
+	for _, country := range []string{"D", "DEU", "1A", "d-"} {
+		if err := validateCountry(country); err == nil {
+			t.Errorf("Expected country %q to be rejected", country)
+		}
+	}
+}
+
+func TestSortResultsByAge(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	results := []WebResult{
+		{Title: "undated"},
+		{Title: "last week", Age: "1 week ago"},
+		{Title: "yesterday", PageAge: "2024-06-09T12:00:00"},
+		{Title: "unknown age", Age: "recently"},
+		{Title: "an hour ago", Age: "1 hour ago"},
+		{Title: "last year", Age: "June 1, 2023"},
+	}
+
+	sortResultsByAge(results, now)
+
+	expected := []string{"an hour ago", "yesterday", "last week", "last year", "undated", "unknown age"}
+	for i, title := range expected {
+		if results[i].Title != title {
+			t.Errorf("Expected %q at position %d, got %q", title, i, results[i].Title)
+		}
+	}
+}
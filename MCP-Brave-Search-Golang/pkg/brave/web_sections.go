@@ -0,0 +1,125 @@
+package brave
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// WebResultSections are the extra sections of a web search response that
+// can be included with the web results, in output order: the infobox comes
+// before the web results and the others after
+var WebResultSections = []string{"infobox", "discussions", "faq"}
+
+// DiscussionResult is a forum thread from the discussions section of a web search
+type DiscussionResult struct {
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+	Age         string `json:"age"`
+	Data        struct {
+		ForumName  string `json:"forum_name"`
+		NumAnswers int    `json:"num_answers"`
+		Question   string `json:"question"`
+		TopComment string `json:"top_comment"`
+	} `json:"data"`
+}
+
+// FAQResult is a question and answer from the faq section of a web search
+type FAQResult struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Title    string `json:"title"`
+	URL      string `json:"url"`
+}
+
+// validateWebResultSections checks that every requested section is known
+func validateWebResultSections(sections []string) error {
+	for _, section := range sections {
+		known := false
+		for _, candidate := range WebResultSections {
+			if section == candidate {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown section %q: must be one of %s", section, strings.Join(WebResultSections, ", "))
+		}
+	}
+	return nil
+}
+
+// formatInfoboxSection renders the infobox of a response under its own
+// heading when it was requested, or returns an empty string when it wasn't
+// or the response has none
+func formatInfoboxSection(resp WebSearchResponse, sections []string) string {
+	for _, section := range sections {
+		if section == "infobox" && len(resp.Infobox.Results) > 0 {
+			if infobox := formatInfobox(resp.Infobox.Results[0]); infobox != "" {
+				return "=== Infobox ===\n\n" + infobox
+			}
+		}
+	}
+	return ""
+}
+
+// formatWebSections renders the requested extra sections of a response that
+// follow the web results, each under its own heading, or returns an empty
+// string when none of them has results
+func formatWebSections(resp WebSearchResponse, sections []string) string {
+	include := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		include[section] = true
+	}
+
+	var formatted []string
+	if include["discussions"] && len(resp.Discussions.Results) > 0 {
+		formatted = append(formatted, "=== Discussions ===\n\n"+formatDiscussions(resp.Discussions.Results))
+	}
+	if include["faq"] && len(resp.FAQ.Results) > 0 {
+		formatted = append(formatted, "=== FAQ ===\n\n"+formatFAQ(resp.FAQ.Results))
+	}
+	return strings.Join(formatted, "\n\n")
+}
+
+// formatDiscussions renders forum threads as text
+func formatDiscussions(discussions []DiscussionResult) string {
+	var results []string
+	for _, discussion := range discussions {
+		lines := []string{"Title: " + discussion.Title}
+		if discussion.Data.Question != "" {
+			lines = append(lines, "Question: "+discussion.Data.Question)
+		}
+		if discussion.Data.TopComment != "" {
+			lines = append(lines, "Top comment: "+discussion.Data.TopComment)
+		} else if discussion.Description != "" {
+			lines = append(lines, "Description: "+discussion.Description)
+		}
+		lines = append(lines, "URL: "+discussion.URL)
+		if discussion.Data.ForumName != "" {
+			lines = append(lines, "Forum: "+discussion.Data.ForumName)
+		}
+		if discussion.Data.NumAnswers > 0 {
+			lines = append(lines, "Answers: "+strconv.Itoa(discussion.Data.NumAnswers))
+		}
+		if discussion.Age != "" {
+			lines = append(lines, "Age: "+discussion.Age)
+		}
+		results = append(results, strings.Join(lines, "\n"))
+	}
+	return strings.Join(results, "\n\n")
+}
+
+// formatFAQ renders questions and answers as text
+func formatFAQ(faqs []FAQResult) string {
+	var results []string
+	for _, faq := range faqs {
+		lines := []string{"Question: " + faq.Question, "Answer: " + faq.Answer}
+		if faq.URL != "" {
+			lines = append(lines, "URL: "+faq.URL)
+		}
+		results = append(results, strings.Join(lines, "\n"))
+	}
+	return strings.Join(results, "\n\n")
+}
@@ -0,0 +1,122 @@
+package brave
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
+)
+
+const sectionsResponse = `{
+	"web": {"results": [{"title": "Go", "description": "The Go language", "url": "https://go.dev"}]},
+	"discussions": {"results": [{
+		"title": "Why learn Go?",
+		"url": "https://forum.example.com/t/1",
+		"description": "A thread about Go",
+		"age": "3 days ago",
+		"data": {"forum_name": "Example Forum", "num_answers": 12, "question": "Is Go worth learning?", "top_comment": "Yes, for services."}
+	}]},
+	"faq": {"results": [{"question": "Who made Go?", "answer": "Google", "title": "Go FAQ", "url": "https://go.dev/doc/faq"}]},
+	"infobox": {"type": "graph", "results": [{
+		"type": "infobox",
+		"title": "Go",
+		"category": "Programming language",
+		"description": "Language",
+		"long_desc": "Go is a statically typed, compiled language designed at Google.",
+		"url": "https://en.wikipedia.org/wiki/Go_(programming_language)",
+		"website_url": "https://go.dev",
+		"attributes": [["Designed by", "Robert Griesemer, Rob Pike, Ken Thompson"], ["First appeared", "2009"], ["Empty", ""]],
+		"profiles": [{"name": "GitHub", "url": "https://github.com/golang"}]
+	}]}
+}`
+
+func TestWebSearchSections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(sectionsResponse))
+	}))
+	defer server.Close()
+
+	if err := SetBaseURL(server.URL); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer SetBaseURL("")
+
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+
+	// The sections are left out unless asked for
+	results, _, err := WebSearch("key", "sections default", WebSearchOptions{Count: 10}, limiter)
+	if err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+	if strings.Contains(results, "Discussions") || strings.Contains(results, "FAQ") {
+		t.Errorf("Expected no discussions or FAQ by default, got %q", results)
+	}
+
+	results, _, err = WebSearch("key", "sections included", WebSearchOptions{Count: 10, Sections: []string{"discussions", "faq"}}, limiter)
+	if err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+	expected := "Title: Go\nDescription: The Go language\nURL: https://go.dev\n\n" +
+		"=== Discussions ===\n\n" +
+		"Title: Why learn Go?\nQuestion: Is Go worth learning?\nTop comment: Yes, for services.\n" +
+		"URL: https://forum.example.com/t/1\nForum: Example Forum\nAnswers: 12\nAge: 3 days ago\n\n" +
+		"=== FAQ ===\n\n" +
+		"Question: Who made Go?\nAnswer: Google\nURL: https://go.dev/doc/faq"
+	if results != expected {
+		t.Errorf("Expected output %q, got %q", expected, results)
+	}
+
+	results, _, err = WebSearch("key", "sections faq", WebSearchOptions{Count: 10, Sections: []string{"faq"}}, limiter)
+	if err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+	if strings.Contains(results, "Discussions") || !strings.Contains(results, "=== FAQ ===") {
+		t.Errorf("Expected only the FAQ section, got %q", results)
+	}
+}
+
+func TestValidateWebResultSections(t *testing.T) {
+	if err := validateWebResultSections([]string{"discussions", "faq"}); err != nil {
+		t.Errorf("Expected known sections to be accepted, got %v", err)
+	}
+
+	err := validateWebResultSections([]string{"videos"})
+	if err == nil || !strings.Contains(err.Error(), `"videos"`) {
+		t.Errorf("Expected error naming the unknown section, got %v", err)
+	}
+}
+
+func TestWebSearchInfobox(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(sectionsResponse))
+	}))
+	defer server.Close()
+
+	if err := SetBaseURL(server.URL); err != nil {
+		t.Fatalf("SetBaseURL failed: %v", err)
+	}
+	defer SetBaseURL("")
+
+	limiter := ratelimit.NewRateLimiter(ratelimit.RateLimits{PerSecond: 10, PerMonth: 100})
+	results, _, err := WebSearch("key", "infobox included", WebSearchOptions{Count: 10, Sections: []string{"faq", "infobox"}}, limiter)
+	if err != nil {
+		t.Fatalf("WebSearch failed: %v", err)
+	}
+
+	// The infobox comes first, whatever order the sections are asked for in
+	expected := "=== Infobox ===\n\n" +
+		"Title: Go\nCategory: Programming language\n" +
+		"Description: Go is a statically typed, compiled language designed at Google.\n" +
+		"Designed by: Robert Griesemer, Rob Pike, Ken Thompson\nFirst appeared: 2009\n" +
+		"Website: https://go.dev\nURL: https://en.wikipedia.org/wiki/Go_(programming_language)\n" +
+		"Profiles: GitHub (https://github.com/golang)\n\n" +
+		"Title: Go\nDescription: The Go language\nURL: https://go.dev\n\n" +
+		"=== FAQ ==="
+	if !strings.HasPrefix(results, expected) {
+		t.Errorf("Expected output starting %q, got %q", expected, results)
+	}
+}
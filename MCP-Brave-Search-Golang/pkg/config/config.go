@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-Brave-Search-Golang/internal/ratelimit"
 )
 
 // Config holds the application configuration
@@ -15,6 +18,68 @@ type Config struct {
 		PerSecond int `json:"perSecond"`
 		PerMonth  int `json:"perMonth"`
 	} `json:"rateLimit"`
+	IdleTimeout int  `json:"idleTimeout,omitempty"` // in seconds, 0 disables
+	DebugTiming bool `json:"debugTiming,omitempty"`
+	// RedirectPolicy is one of "same-host" (default), "strip-token" or "none"
+	RedirectPolicy string `json:"redirectPolicy,omitempty"`
+	// MaxWorkers caps concurrent requests made by fan-out operations such as local search
+	MaxWorkers int `json:"maxWorkers,omitempty"`
+	// LocalDetailRequestsPerMinute caps the POI and description requests local
+	// searches make; past it places are returned without descriptions. 0 disables
+	LocalDetailRequestsPerMinute int `json:"localDetailRequestsPerMinute,omitempty"`
+	// Connection pool tuning for the shared HTTP client
+	MaxIdleConns        int `json:"maxIdleConns,omitempty"`
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"`
+	IdleConnTimeout     int `json:"idleConnTimeout,omitempty"` // in seconds
+	// MinQueryInterval reuses the previous result for an identical query
+	// repeated within this many seconds; 0 disables
+	MinQueryInterval int `json:"minQueryInterval,omitempty"`
+	// Bounds for the adaptive request timeout, which follows the average API latency
+	MinRequestTimeout int `json:"minRequestTimeout,omitempty"` // in seconds
+	MaxRequestTimeout int `json:"maxRequestTimeout,omitempty"` // in seconds
+	// AllowedSearchLangs limits the search_lang argument to these languages; empty allows any
+	AllowedSearchLangs []string `json:"allowedSearchLangs,omitempty"`
+	// StrictJSONRPC rejects messages whose jsonrpc field is missing or not "2.0"
+	StrictJSONRPC bool `json:"strictJsonRpc,omitempty"`
+	// ToolRateLimits limits how often individual tools may be called, keyed by
+	// tool name, independently of the Brave API rate limit
+	ToolRateLimits map[string]ToolRateLimit `json:"toolRateLimits,omitempty"`
+	// MaxRetries is how many times a request getting a 429 or 5xx response
+	// is retried; -1 disables retries
+	MaxRetries     int `json:"maxRetries,omitempty"`
+	RetryBaseDelay int `json:"retryBaseDelay,omitempty"` // in milliseconds
+	// WaitForRateLimit makes searches over the per-second limit wait instead of failing
+	WaitForRateLimit bool `json:"waitForRateLimit,omitempty"`
+	// CheckContentType rejects API responses that aren't JSON; defaults to true when unset
+	CheckContentType *bool `json:"checkContentType,omitempty"`
+	// BaseURL is where API requests are sent, such as an egress proxy or a mock server
+	BaseURL string `json:"baseUrl,omitempty"`
+	// Web and local search results are reused for identical searches within
+	// CacheTTL; -1 disables the cache
+	CacheTTL        int `json:"cacheTtl,omitempty"` // in seconds
+	CacheMaxEntries int `json:"cacheMaxEntries,omitempty"`
+	// MaxConcurrentToolCalls limits how many tool calls run at once; 0 is unlimited
+	MaxConcurrentToolCalls int `json:"maxConcurrentToolCalls,omitempty"`
+	// BusyPolicy says what happens to a call over MaxConcurrentToolCalls:
+	// "reject" (the default) fails it, "queue" waits for a free slot
+	BusyPolicy string `json:"busyPolicy,omitempty"`
+	// MaxQueuedToolCalls bounds how many calls may wait under the queue
+	// policy; defaults to MaxConcurrentToolCalls
+	MaxQueuedToolCalls int `json:"maxQueuedToolCalls,omitempty"`
+	// CostBudget caps the total cost of searches per day and per month (UTC),
+	// where each tool has a cost in OperationCosts; 0 leaves a period unlimited
+	CostBudget struct {
+		Daily   int `json:"daily,omitempty"`
+		Monthly int `json:"monthly,omitempty"`
+	} `json:"costBudget,omitempty"`
+	// OperationCosts overrides the cost of tools, keyed by tool name
+	OperationCosts map[string]int `json:"operationCosts,omitempty"`
+}
+
+// ToolRateLimit allows at most Calls calls to a tool in each Interval
+type ToolRateLimit struct {
+	Calls    int `json:"calls"`
+	Interval int `json:"interval"` // in seconds
 }
 
 // Default config file name
@@ -88,10 +153,137 @@ func LoadConfig() (*Config, error) {
 		config.RateLimit.PerMonth = 15000
 	}
 
+	// Only follow redirects to the API host by default
+	if config.RedirectPolicy == "" {
+		config.RedirectPolicy = "same-host"
+	}
+	if config.MaxWorkers <= 0 {
+		config.MaxWorkers = 8
+	}
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost <= 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+	if config.IdleConnTimeout <= 0 {
+		config.IdleConnTimeout = 90
+	}
+	if config.MinRequestTimeout <= 0 {
+		config.MinRequestTimeout = 2
+	}
+	if config.MaxRequestTimeout <= 0 {
+		config.MaxRequestTimeout = 30
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 2
+	}
+	if config.RetryBaseDelay <= 0 {
+		config.RetryBaseDelay = 500
+	}
+	if config.CheckContentType == nil {
+		checkContentType := true
+		config.CheckContentType = &checkContentType
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = "https://api.search.brave.com"
+	}
+	if config.CacheTTL == 0 {
+		config.CacheTTL = 300
+	}
+	if config.CacheMaxEntries <= 0 {
+		config.CacheMaxEntries = 100
+	}
+
+	// Validate tool rate limits
+	for tool, limit := range config.ToolRateLimits {
+		if limit.Calls <= 0 || limit.Interval <= 0 {
+			return nil, fmt.Errorf("invalid rate limit for tool %s: calls and interval must be positive", tool)
+		}
+	}
+
+	// Validate the cost budget
+	if config.CostBudget.Daily < 0 || config.CostBudget.Monthly < 0 {
+		return nil, fmt.Errorf("invalid costBudget: daily and monthly must not be negative")
+	}
+	for tool, cost := range config.OperationCosts {
+		if cost < 0 {
+			return nil, fmt.Errorf("invalid cost for tool %s: must not be negative", tool)
+		}
+	}
+
+	// Validate the concurrency limit
+	switch config.BusyPolicy {
+	case "":
+		config.BusyPolicy = ratelimit.BusyReject
+	case ratelimit.BusyReject, ratelimit.BusyQueue:
+	default:
+		return nil, fmt.Errorf("invalid busyPolicy %q: must be %q or %q", config.BusyPolicy, ratelimit.BusyReject, ratelimit.BusyQueue)
+	}
+	if config.MaxQueuedToolCalls <= 0 {
+		config.MaxQueuedToolCalls = config.MaxConcurrentToolCalls
+	}
+
 	fmt.Fprintf(os.Stderr, "Configuration loaded successfully\n")
 	return config, nil
 }
 
+// GetIdleTimeout returns the idle shutdown timeout as a duration
+func (c *Config) GetIdleTimeout() time.Duration {
+	return time.Duration(c.IdleTimeout) * time.Second
+}
+
+// GetIdleConnTimeout returns how long idle API connections are kept open
+func (c *Config) GetIdleConnTimeout() time.Duration {
+	return time.Duration(c.IdleConnTimeout) * time.Second
+}
+
+// GetMinQueryInterval returns the minimum interval between identical queries
+func (c *Config) GetMinQueryInterval() time.Duration {
+	return time.Duration(c.MinQueryInterval) * time.Second
+}
+
+// GetMinRequestTimeout returns the shortest timeout applied to an API request
+func (c *Config) GetMinRequestTimeout() time.Duration {
+	return time.Duration(c.MinRequestTimeout) * time.Second
+}
+
+// GetMaxRequestTimeout returns the longest timeout applied to an API request
+func (c *Config) GetMaxRequestTimeout() time.Duration {
+	return time.Duration(c.MaxRequestTimeout) * time.Second
+}
+
+// GetCacheTTL returns how long search results are cached as a duration, zero when disabled
+func (c *Config) GetCacheTTL() time.Duration {
+	if c.CacheTTL < 0 {
+		return 0
+	}
+	return time.Duration(c.CacheTTL) * time.Second
+}
+
+// GetRetryBaseDelay returns the delay before the first retry of a failed request
+func (c *Config) GetRetryBaseDelay() time.Duration {
+	return time.Duration(c.RetryBaseDelay) * time.Millisecond
+}
+
+// GetToolRateLimits returns the per-tool rate limits keyed by tool name
+func (c *Config) GetToolRateLimits() map[string]ratelimit.ToolLimit {
+	limits := make(map[string]ratelimit.ToolLimit, len(c.ToolRateLimits))
+	for tool, limit := range c.ToolRateLimits {
+		limits[tool] = ratelimit.ToolLimit{
+			Calls:    limit.Calls,
+			Interval: time.Duration(limit.Interval) * time.Second,
+		}
+	}
+	return limits
+}
+
+// NewConcurrencyLimiter returns the limiter enforcing MaxConcurrentToolCalls,
+// or nil if it is unset
+func (c *Config) NewConcurrencyLimiter() *ratelimit.ConcurrencyLimiter {
+	return ratelimit.NewConcurrencyLimiter(c.MaxConcurrentToolCalls, c.BusyPolicy, c.MaxQueuedToolCalls)
+}
+
 // createDefaultConfig creates a default config file with empty API key
 func createDefaultConfig(configFilePath string) (*Config, error) {
 	config := &Config{
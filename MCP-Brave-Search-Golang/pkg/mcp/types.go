@@ -78,6 +78,7 @@ type ListToolsResponse struct {
 type CallToolRequest struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments"`
+	Meta      json.RawMessage `json:"_meta,omitempty"` // e.g. progressToken, preserved verbatim
 }
 
 // ContentItem represents an item in the content array
@@ -89,7 +90,10 @@ type ContentItem struct {
 // CallToolResponse represents a response from calling a tool
 type CallToolResponse struct {
 	Content []ContentItem `json:"content"`
-	IsError bool          `json:"isError"`
+	// StructuredContent is a machine-readable JSON object with the same
+	// result as Content, for tools that have structured data
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError"`
 }
 
 // WebSearchArgs represents arguments for brave_web_search
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/ratelimit"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/config"
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/editor"
 	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/filesystem"
@@ -33,8 +38,25 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Reject misspelled or unknown tool arguments when configured to
+	toolargs.SetStrict(cfg.StrictArguments)
+
 	// Create the file manager with allowed directories from config
 	fileManager := filesystem.NewFileManager(cfg.AllowedDirectories)
+	fileManager.SetFileLocking(cfg.FileLocking)
+	fileManager.SetMinFreeBytes(cfg.MinFreeBytes)
+	fileManager.SetSafeContent(cfg.SafeContent)
+	fileManager.SetPreserveOwnership(cfg.PreserveOwnership)
+	if cfg.RetrySharingViolations != nil {
+		fileManager.SetRetrySharingViolations(*cfg.RetrySharingViolations)
+	}
+	fileManager.SetDefaultMaxWalkDepth(cfg.DefaultMaxWalkDepth)
+	fileManager.SetGrepOptions(filesystem.GrepOptions{
+		MaxLinesPerFile: cfg.GrepMaxLines,
+		Timeout:         cfg.GetGrepTimeout(),
+	})
+	snapshotDir := filepath.Join(os.TempDir(), "mcp-filesystem-snapshots")
+	fileManager.SetSnapshotDir(snapshotDir)
 
 	// Create the edit manager for undo functionality
 	backupDir := filepath.Join(os.TempDir(), "mcp-filesystem-backups")
@@ -43,6 +65,9 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error creating edit manager: %v\n", err)
 		os.Exit(1)
 	}
+	editManager.SetFileLocking(cfg.FileLocking)
+	editManager.SetPreserveOwnership(cfg.PreserveOwnership)
+	editManager.SetTrimTrailingWhitespace(cfg.TrimTrailingWhitespace)
 
 	// Create and configure the MCP server
 	server := mcp.NewServer(
@@ -56,18 +81,30 @@ func main() {
 					"list": true,
 					"call": true,
 				},
+				Features: serverFeatures(cfg, fileManager),
 			},
 		},
 	)
 
+	server.SetRejectDuplicateIDs(cfg.RejectDuplicateRequestIDs)
+	server.SetStrictJSONRPC(cfg.StrictJSONRPC)
+
 	// Set up handlers
-	setupServerHandlers(server, fileManager, editManager)
+	setupServerHandlers(server, cfg, fileManager, editManager)
 
 	// Start the server with stdio transport
 	transport := mcp.NewStdioTransport()
+	transport.SetIdleTimeout(cfg.GetIdleTimeout())
 	fmt.Fprintf(os.Stderr, "Secure MCP Filesystem Server v0.3.0 starting on stdin/stdout\n")
 	fmt.Fprintf(os.Stderr, "Allowed directories: %v\n", cfg.AllowedDirectories)
+	if cfg.NoAllowedDirectories() {
+		fmt.Fprintf(os.Stderr, "Warning: no allowed directories configured; every tool call will fail until config.json lists at least one\n")
+	}
 	fmt.Fprintf(os.Stderr, "Edit backup directory: %s\n", backupDir)
+	fmt.Fprintf(os.Stderr, "Snapshot directory: %s\n", snapshotDir)
+	if cfg.IdleTimeout > 0 {
+		fmt.Fprintf(os.Stderr, "Idle timeout: %v\n", cfg.GetIdleTimeout())
+	}
 	
 	err = server.Connect(transport)
 	if err != nil {
@@ -76,47 +113,118 @@ func main() {
 	}
 
 	// The server is now running and processing requests via the transport
-	// It will continue running until stdin is closed or the process is terminated
-	select {} // Wait forever
+	// It will continue running until the process is terminated or, if an idle
+	// timeout is configured, until no request has arrived for that long
+	<-transport.Idle()
+	fmt.Fprintf(os.Stderr, "No requests for %v, shutting down...\n", cfg.GetIdleTimeout())
+	os.Exit(0)
+}
+
+// serverFeatures describes the optional features enabled by the effective configuration
+func serverFeatures(cfg *config.Config, fileManager *filesystem.FileManager) *mcp.ServerFeatures {
+	tools := make([]string, 0, len(filesystem.FilesystemTools)+len(editor.EditorTools))
+	for name := range filesystem.FilesystemTools {
+		tools = append(tools, name)
+	}
+	for name := range editor.EditorTools {
+		tools = append(tools, name)
+	}
+	sort.Strings(tools)
+
+	return &mcp.ServerFeatures{
+		ReadOnly: cfg.NoAllowedDirectories(),
+		Tools:    tools,
+		Cache:    false,
+		Retry: map[string]interface{}{
+			"sharingViolations": fileManager.RetrySharingViolations(),
+			"maxAttempts":       filesystem.DefaultRetryAttempts,
+		},
+		Limits: map[string]interface{}{
+			"minFreeBytes":        cfg.MinFreeBytes,
+			"grepTimeoutSeconds":  cfg.GrepTimeout,
+			"grepMaxLines":        cfg.GrepMaxLines,
+			"maxSnapshotFiles":    filesystem.MaxSnapshotFiles,
+			"idleTimeoutSeconds":  cfg.IdleTimeout,
+			"toolRateLimits":      cfg.ToolRateLimits,
+			"maxExposedTools":     cfg.MaxExposedTools,
+			"defaultMaxWalkDepth": cfg.DefaultMaxWalkDepth,
+		},
+		Options: map[string]interface{}{
+			"fileLocking":               cfg.FileLocking,
+			"debugTiming":               cfg.DebugTiming,
+			"rejectDuplicateRequestIds": cfg.RejectDuplicateRequestIDs,
+			"strictJsonRpc":             cfg.StrictJSONRPC,
+			"safeContent":               cfg.SafeContent,
+			"preserveOwnership":         cfg.PreserveOwnership,
+			"trimTrailingWhitespace":    cfg.TrimTrailingWhitespace,
+		},
+	}
+}
+
+// defaultToolPriority orders the tools listed first when maxExposedTools
+// caps the tools/list response, after any configured toolPriority
+var defaultToolPriority = []string{
+	"read_file",
+	"write_file",
+	"str_replace",
+	"list_directory",
+	"search_files",
+	"grep_files",
+	"glob",
+	"read_multiple_files",
+	"create_directory",
+	"move_file",
+	"get_file_info",
+	"insert",
+	"undo_edit",
+	"list_allowed_directories",
 }
 
 // setupServerHandlers sets up the request handlers for the server
-func setupServerHandlers(server *mcp.Server, fileManager *filesystem.FileManager, editManager *editor.EditManager) {
-	// Handler for tools/list
-	server.SetRequestHandler("tools/list", func(params json.RawMessage) (json.RawMessage, error) {
-		// Combine filesystem and editor tools
-		allTools := make([]mcp.Tool, 0, len(filesystem.FilesystemTools)+len(editor.EditorTools))
-		
-		// Add filesystem tools
-		for _, toolDef := range filesystem.FilesystemTools {
-			inputSchema, err := json.Marshal(toolDef.InputSchema)
-			if err != nil {
-				continue
-			}
-			
-			allTools = append(allTools, mcp.Tool{
-				Name:        toolDef.Name,
-				Description: toolDef.Description,
-				InputSchema: inputSchema,
-			})
+func setupServerHandlers(server *mcp.Server, cfg *config.Config, fileManager *filesystem.FileManager, editManager *editor.EditManager) {
+	// Combine filesystem and editor tools
+	allTools := make([]mcp.Tool, 0, len(filesystem.FilesystemTools)+len(editor.EditorTools))
+	
+	// Add filesystem tools
+	for _, toolDef := range filesystem.FilesystemTools {
+		inputSchema, err := json.Marshal(toolDef.InputSchema)
+		if err != nil {
+			continue
 		}
 		
-		// Add editor tools
-		for _, toolDef := range editor.EditorTools {
-			inputSchema, err := json.Marshal(toolDef.InputSchema)
-			if err != nil {
-				continue
-			}
-			
-			allTools = append(allTools, mcp.Tool{
-				Name:        toolDef.Name,
-				Description: toolDef.Description,
-				InputSchema: inputSchema,
-			})
+		allTools = append(allTools, mcp.Tool{
+			Name:        toolDef.Name,
+			Description: toolDef.Description,
+			InputSchema: inputSchema,
+		})
+	}
+	
+	// Add editor tools
+	for _, toolDef := range editor.EditorTools {
+		inputSchema, err := json.Marshal(toolDef.InputSchema)
+		if err != nil {
+			continue
 		}
 		
+		allTools = append(allTools, mcp.Tool{
+			Name:        toolDef.Name,
+			Description: toolDef.Description,
+			InputSchema: inputSchema,
+		})
+	}
+	
+	// List only the highest priority tools when capped; hidden tools can still be called
+	priority := append(append([]string(nil), cfg.ToolPriority...), defaultToolPriority...)
+	exposedTools, hiddenTools := mcp.LimitTools(allTools, priority, cfg.MaxExposedTools)
+	if len(hiddenTools) > 0 {
+		fmt.Fprintf(os.Stderr, "Listing %d of %d tools, hidden from tools/list: %s\n",
+			len(exposedTools), len(allTools), strings.Join(hiddenTools, ", "))
+	}
+
+	// Handler for tools/list
+	server.SetRequestHandler("tools/list", func(params json.RawMessage) (json.RawMessage, error) {
 		response := mcp.ListToolsResponse{
-			Tools: allTools,
+			Tools: exposedTools,
 		}
 		
 		return json.Marshal(response)
@@ -128,6 +236,12 @@ func setupServerHandlers(server *mcp.Server, fileManager *filesystem.FileManager
 		return handler(params)
 	})
 	
+	// Per-tool rate limits, checked before a call is dispatched
+	toolLimiter := ratelimit.NewToolLimiter(cfg.GetToolRateLimits())
+	
+	// Limit on tool calls in flight at once, nil when unlimited
+	concurrencyLimiter := cfg.NewConcurrencyLimiter()
+
 	// Handler for tools/call
 	server.SetRequestHandler("tools/call", func(params json.RawMessage) (json.RawMessage, error) {
 		var request mcp.CallToolRequest
@@ -135,8 +249,29 @@ func setupServerHandlers(server *mcp.Server, fileManager *filesystem.FileManager
 			return nil, fmt.Errorf("invalid call parameters: %w", err)
 		}
 		
+		// Without allowed directories every call would be denied, so say why
+		if cfg.NoAllowedDirectories() {
+			return createErrorResponse(config.ErrNoAllowedDirectories.Error() + " (allowNone is set, so the server is running without access to any files)")
+		}
+		
+		if err := toolLimiter.Allow(request.Name); err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		release, err := concurrencyLimiter.Acquire()
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		defer release()
+		
 		// Process the tool call
-		return handleToolCall(request, fileManager, editManager)
+		start := time.Now()
+		result, err := handleToolCall(request, fileManager, editManager)
+		if err != nil || !cfg.DebugTiming {
+			return result, err
+		}
+
+		return addTimingMeta(result, time.Since(start))
 	})
 
 	// Handler for call_tool (backward compatibility)
@@ -144,6 +279,52 @@ func setupServerHandlers(server *mcp.Server, fileManager *filesystem.FileManager
 		handler := server.GetHandler("tools/call")
 		return handler(params)
 	})
+
+	// Handler for tools/call_batch, which runs several tool calls in order
+	server.SetRequestHandler("tools/call_batch", func(params json.RawMessage) (json.RawMessage, error) {
+		var request mcp.CallToolBatchRequest
+		if err := json.Unmarshal(params, &request); err != nil {
+			return nil, fmt.Errorf("invalid batch call parameters: %w", err)
+		}
+
+		if len(request.Calls) == 0 {
+			return nil, fmt.Errorf("calls parameter is required and must not be empty")
+		}
+
+		handler := server.GetHandler("tools/call")
+		results := make([]json.RawMessage, 0, len(request.Calls))
+		for _, call := range request.Calls {
+			callParams, err := json.Marshal(call)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal call %s: %w", call.Name, err)
+			}
+
+			// Reuse the single-call handler so behavior matches tools/call
+			result, err := handler(callParams)
+			if err != nil {
+				result, err = createErrorResponse(err.Error())
+				if err != nil {
+					return nil, err
+				}
+			}
+			results = append(results, result)
+
+			if request.StopOnError && isErrorResponse(result) {
+				break
+			}
+		}
+
+		return json.Marshal(mcp.CallToolBatchResponse{Results: results})
+	})
+}
+
+// isErrorResponse reports whether a tool call result is flagged as an error
+func isErrorResponse(result json.RawMessage) bool {
+	var response mcp.CallToolResponse
+	if err := json.Unmarshal(result, &response); err != nil {
+		return true
+	}
+	return response.IsError
 }
 
 // handleToolCall handles a tool call request
@@ -154,12 +335,113 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 	switch request.Name {
 	// Filesystem tools
 	case "read_file":
-		path, err := filesystem.ParseReadFileArgs(request.Arguments)
+		path, strictUTF8, safeContent, outlineIfLarge, err := filesystem.ParseReadFileArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		content, err := fileManager.ReadFile(path)
+		// A file too large to read whole is summarized when the caller asks
+		var outline *filesystem.FileOutline
+		if outlineIfLarge {
+			outline, err = fileManager.OutlineIfLarge(path)
+			if err != nil {
+				return createErrorResponse(err.Error())
+			}
+		}
+		
+		var content string
+		if outline != nil {
+			content = outline.String()
+		} else {
+			content, err = fileManager.ReadFile(path, strictUTF8)
+			if err != nil {
+				return createErrorResponse(err.Error())
+			}
+		}
+		
+		if fileManager.UseSafeContent(safeContent) {
+			content = filesystem.WrapSafeContent(path, content)
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: content},
+			},
+		}
+	
+	case "open_file":
+		path, maxBytes, err := filesystem.ParseOpenFileArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		opened, err := fileManager.OpenFile(path, maxBytes)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: opened.String()},
+			},
+		}
+	
+	case "detect_file_type":
+		path, err := filesystem.ParseDetectFileTypeArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		detected, err := fileManager.DetectFileType(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: detected.String()},
+			},
+		}
+	
+	case "file_stats":
+		path, maxBytes, err := filesystem.ParseFileStatsArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		stats, err := fileManager.FileStats(path, maxBytes)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(stats.String(), stats)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
+	case "assert_file_content":
+		path, assertion, err := filesystem.ParseAssertFileContentArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		result, err := fileManager.AssertFileContent(path, assertion)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(result.String(), result)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
+	case "read_file_at":
+		path, offset, length, err := filesystem.ParseReadFileAtArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		content, atEOF, err := fileManager.ReadFileAt(path, offset, length)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
@@ -167,16 +449,40 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 		response = mcp.CallToolResponse{
 			Content: []mcp.ContentItem{
 				{Type: "text", Text: content},
+				{Type: "text", Text: fmt.Sprintf("offset: %d\nbytesRead: %d\natEOF: %t", offset, len(content), atEOF)},
+			},
+		}
+	
+	case "read_file_tail_bytes":
+		path, n, err := filesystem.ParseReadFileTailBytesArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		content, isBase64, err := fileManager.ReadFileTailBytes(path, n)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		encoding := "utf-8"
+		if isBase64 {
+			encoding = "base64"
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: content},
+				{Type: "text", Text: fmt.Sprintf("encoding: %s", encoding)},
 			},
 		}
 	
 	case "read_multiple_files":
-		paths, err := filesystem.ParseReadMultipleFilesArgs(request.Arguments)
+		paths, format, safeContent, err := filesystem.ParseReadMultipleFilesArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		content, err := fileManager.ReadMultipleFiles(paths)
+		content, err := fileManager.ReadMultipleFiles(paths, format, fileManager.UseSafeContent(safeContent))
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
@@ -188,12 +494,12 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 		}
 	
 	case "write_file":
-		path, content, err := filesystem.ParseWriteFileArgs(request.Arguments)
+		path, content, verify, err := filesystem.ParseWriteFileArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		err = fileManager.WriteFile(path, content)
+		err = fileManager.WriteFile(path, content, verify)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
@@ -204,40 +510,143 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			},
 		}
 	
-	case "create_directory":
-		path, err := filesystem.ParseCreateDirectoryArgs(request.Arguments)
+	case "append_file":
+		path, content, err := filesystem.ParseAppendFileArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		err = fileManager.CreateDirectory(path)
+		size, err := fileManager.AppendFile(path, content)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
 		response = mcp.CallToolResponse{
 			Content: []mcp.ContentItem{
-				{Type: "text", Text: fmt.Sprintf("Successfully created directory %s", path)},
+				{Type: "text", Text: fmt.Sprintf("Successfully appended %d bytes to %s (now %d bytes)", len(content), path, size)},
 			},
 		}
 	
-	case "list_directory":
-		path, err := filesystem.ParseListDirectoryArgs(request.Arguments)
+	case "write_multiple_files":
+		files, planOnly, err := filesystem.ParseWriteMultipleFilesArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		listing, err := fileManager.ListDirectory(path)
+		plan, err := fileManager.WriteMultipleFiles(files, planOnly)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
 		response = mcp.CallToolResponse{
 			Content: []mcp.ContentItem{
-				{Type: "text", Text: listing},
+				{Type: "text", Text: plan.String()},
 			},
 		}
 	
+	case "search_and_replace_across_files":
+		path, search, replace, planOnly, err := filesystem.ParseSearchAndReplaceAcrossFilesArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		plan, err := fileManager.SearchAndReplaceAcrossFiles(path, search, replace, planOnly)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: plan.String()},
+			},
+		}
+	
+	case "create_directory":
+		path, failIfExists, err := filesystem.ParseCreateDirectoryArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		created, err := fileManager.CreateDirectory(path, failIfExists)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		resultText := fmt.Sprintf("Directory %s already exists; nothing created", path)
+		if len(created) > 0 {
+			resultText = fmt.Sprintf("Successfully created directory %s\nCreated:\n%s", path, strings.Join(created, "\n"))
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: resultText},
+			},
+		}
+	
+	case "list_directory":
+		path, includeHidden, err := filesystem.ParseListDirectoryArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		listing, err := fileManager.ReadDirectory(path, includeHidden)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(listing.String(), listing)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
+	case "directory_tree":
+		path, maxDepth, err := filesystem.ParseDirectoryTreeArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		tree, err := fileManager.DirectoryTree(path, maxDepth)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(tree.String(), tree)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
+	case "get_directory_size":
+		path, err := filesystem.ParseGetDirectorySizeArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		size, err := fileManager.DirectorySize(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(size.String(), size)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
+	case "watch_directory":
+		path, duration, err := filesystem.ParseWatchDirectoryArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		changes, err := fileManager.WatchDirectory(context.Background(), path, duration)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(changes.String(), changes)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
 	case "move_file":
 		source, destination, err := filesystem.ParseMoveFileArgs(request.Arguments)
 		if err != nil {
@@ -255,13 +664,64 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			},
 		}
 	
+	case "copy_file":
+		source, destination, overwrite, err := filesystem.ParseCopyFileArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		err = fileManager.CopyFile(source, destination, overwrite)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Successfully copied %s to %s", source, destination)},
+			},
+		}
+	
+	case "delete_file":
+		path, err := filesystem.ParseDeleteFileArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		err = fileManager.DeleteFile(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Successfully deleted %s", path)},
+			},
+		}
+	
+	case "delete_directory":
+		path, recursive, err := filesystem.ParseDeleteDirectoryArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		err = fileManager.DeleteDirectory(path, recursive)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Successfully deleted directory %s", path)},
+			},
+		}
+	
 	case "search_files":
-		path, pattern, err := filesystem.ParseSearchFilesArgs(request.Arguments)
+		path, pattern, query, err := filesystem.ParseSearchFilesArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		results, err := filesystem.SearchFiles(fileManager, path, pattern)
+		results, err := filesystem.SearchFiles(fileManager, path, pattern, query)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
@@ -279,20 +739,121 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			},
 		}
 	
+	case "glob":
+		pattern, exclude, relative, maxResults, maxDepth, err := filesystem.ParseGlobArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		result, err := fileManager.Glob(pattern, exclude, relative, maxResults, maxDepth)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: result.String()},
+			},
+		}
+	
+	case "grep_files":
+		path, pattern, query, err := filesystem.ParseGrepFilesArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		result, err := filesystem.GrepFiles(context.Background(), fileManager, path, pattern, query)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: result.String()},
+			},
+		}
+	
 	case "get_file_info":
-		path, err := filesystem.ParseGetFileInfoArgs(request.Arguments)
+		path, format, err := filesystem.ParseGetFileInfoArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		info, err := fileManager.StatFile(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		text, err := filesystem.FormatFileInfo(info, format)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response, err = structuredResponse(text, info)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+	
+	case "create_snapshot":
+		path, name, err := filesystem.ParseCreateSnapshotArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		count, err := fileManager.CreateSnapshot(path, name)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Created snapshot %q of %s with %d files", name, path, count)},
+			},
+		}
+	
+	case "diff_snapshot":
+		path, name, err := filesystem.ParseDiffSnapshotArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		diff, err := fileManager.DiffSnapshot(path, name)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Changes in %s since snapshot %q:\n%s", path, name, diff)},
+			},
+		}
+	
+	case "get_working_directory":
+		workingDir, err := fileManager.GetWorkingDirectory()
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Working directory: %s", workingDir)},
+			},
+		}
+	
+	case "set_working_directory":
+		path, err := filesystem.ParseSetWorkingDirectoryArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
-		info, err := fileManager.GetFileInfo(path)
+		workingDir, err := fileManager.SetWorkingDirectory(path)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
 		response = mcp.CallToolResponse{
 			Content: []mcp.ContentItem{
-				{Type: "text", Text: info},
+				{Type: "text", Text: fmt.Sprintf("Working directory set to %s", workingDir)},
 			},
 		}
 	
@@ -303,9 +864,42 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			},
 		}
 	
+	case "allowed_directories_info":
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fileManager.AllowedDirectoriesInfo()},
+			},
+		}
+	
+	case "validate_path":
+		path, verbose, err := filesystem.ParseValidatePathArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		// Only verbose mode pays for the explanation
+		if verbose {
+			explanation := fileManager.ExplainPath(path)
+			response, err = structuredResponse(explanation.String(), explanation)
+			if err != nil {
+				return createErrorResponse(err.Error())
+			}
+		} else {
+			validPath, err := fileManager.ValidatePath(path)
+			if err != nil {
+				return createErrorResponse(err.Error())
+			}
+			
+			response = mcp.CallToolResponse{
+				Content: []mcp.ContentItem{
+					{Type: "text", Text: fmt.Sprintf("Allowed: %s", validPath)},
+				},
+			}
+		}
+	
 	// Editor tools
 	case "str_replace":
-		path, oldStr, newStr, err := editor.ParseStrReplaceArgs(request.Arguments)
+		path, oldStr, newStr, trimTrailing, err := editor.ParseStrReplaceArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
@@ -316,19 +910,42 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			return createErrorResponse(err.Error())
 		}
 		
-		err = editManager.StrReplace(validPath, oldStr, newStr)
+		trimmed, err := editManager.StrReplace(validPath, oldStr, newStr, editManager.UseTrimTrailingWhitespace(trimTrailing))
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
 		response = mcp.CallToolResponse{
 			Content: []mcp.ContentItem{
-				{Type: "text", Text: fmt.Sprintf("Successfully replaced text in %s", path)},
+				{Type: "text", Text: fmt.Sprintf("Successfully replaced text in %s", path) + trimmedLinesNote(trimmed)},
 			},
 		}
 	
 	case "insert":
-		path, lineNumber, text, err := editor.ParseInsertArgs(request.Arguments)
+		path, lineNumber, text, trimTrailing, err := editor.ParseInsertArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		// Validate path first
+		validPath, err := fileManager.ValidatePath(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		trimmed, err := editManager.Insert(validPath, lineNumber, text, editManager.UseTrimTrailingWhitespace(trimTrailing))
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Successfully inserted text at line %d in %s", lineNumber, path) + trimmedLinesNote(trimmed)},
+			},
+		}
+	
+	case "trim_trailing_whitespace":
+		path, err := editor.ParseTrimTrailingWhitespaceArgs(request.Arguments)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
@@ -339,14 +956,14 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			return createErrorResponse(err.Error())
 		}
 		
-		err = editManager.Insert(validPath, lineNumber, text)
+		trimmed, err := editManager.TrimTrailingWhitespace(validPath)
 		if err != nil {
 			return createErrorResponse(err.Error())
 		}
 		
 		response = mcp.CallToolResponse{
 			Content: []mcp.ContentItem{
-				{Type: "text", Text: fmt.Sprintf("Successfully inserted text at line %d in %s", lineNumber, path)},
+				{Type: "text", Text: fmt.Sprintf("Trimmed trailing whitespace from %d line(s) in %s", trimmed, path)},
 			},
 		}
 	
@@ -373,6 +990,52 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 			},
 		}
 	
+	case "read_json_path":
+		path, expression, err := editor.ParseReadJSONPathArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		// Validate path first
+		validPath, err := fileManager.ValidatePath(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		value, err := editor.ReadJSONPath(validPath, expression)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: value},
+			},
+		}
+	
+	case "set_json_path":
+		path, expression, value, err := editor.ParseSetJSONPathArgs(request.Arguments)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		// Validate path first
+		validPath, err := fileManager.ValidatePath(path)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		err = editManager.SetJSONPath(validPath, expression, value)
+		if err != nil {
+			return createErrorResponse(err.Error())
+		}
+		
+		response = mcp.CallToolResponse{
+			Content: []mcp.ContentItem{
+				{Type: "text", Text: fmt.Sprintf("Successfully set %s in %s", expression, path)},
+			},
+		}
+	
 	default:
 		return createErrorResponse(fmt.Sprintf("Unknown tool: %s", request.Name))
 	}
@@ -380,6 +1043,55 @@ func handleToolCall(request mcp.CallToolRequest, fileManager *filesystem.FileMan
 	return json.Marshal(response)
 }
 
+// addTimingMeta records how long a tool call took in the response _meta
+func addTimingMeta(result json.RawMessage, duration time.Duration) (json.RawMessage, error) {
+	var response mcp.CallToolResponse
+	if err := json.Unmarshal(result, &response); err != nil {
+		return nil, fmt.Errorf("failed to add timing to response: %w", err)
+	}
+
+	meta := make(map[string]interface{})
+	if len(response.Meta) > 0 {
+		if err := json.Unmarshal(response.Meta, &meta); err != nil {
+			return nil, fmt.Errorf("failed to add timing to response: %w", err)
+		}
+	}
+	meta["durationMs"] = float64(duration.Microseconds()) / 1000
+
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add timing to response: %w", err)
+	}
+	response.Meta = metaJSON
+
+	return json.Marshal(response)
+}
+
+// trimmedLinesNote describes how many lines an edit stripped trailing
+// whitespace from, or is empty when it stripped none
+func trimmedLinesNote(trimmed int) string {
+	if trimmed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("; trimmed trailing whitespace from %d line(s)", trimmed)
+}
+
+// structuredResponse creates a tool response with text for display and
+// data, which must encode as a JSON object, as its structuredContent
+func structuredResponse(text string, data interface{}) (mcp.CallToolResponse, error) {
+	structured, err := json.Marshal(data)
+	if err != nil {
+		return mcp.CallToolResponse{}, fmt.Errorf("failed to encode structured content: %w", err)
+	}
+	
+	return mcp.CallToolResponse{
+		Content: []mcp.ContentItem{
+			{Type: "text", Text: text},
+		},
+		StructuredContent: structured,
+	}, nil
+}
+
 // createErrorResponse creates an error response for a tool call
 func createErrorResponse(message string) (json.RawMessage, error) {
 	response := mcp.CallToolResponse{
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/editor"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/filesystem"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/mcp"
+)
+
+// callTool calls a tool against a file manager for dir and decodes the response
+func callTool(t *testing.T, dir, name string, arguments interface{}) mcp.CallToolResponse {
+	t.Helper()
+
+	editManager, err := editor.NewEditManager(filepath.Join(t.TempDir(), "backups"))
+	if err != nil {
+		t.Fatalf("Failed to create edit manager: %v", err)
+	}
+	args, err := json.Marshal(arguments)
+	if err != nil {
+		t.Fatalf("Failed to encode arguments: %v", err)
+	}
+
+	result, err := handleToolCall(mcp.CallToolRequest{Name: name, Arguments: args}, filesystem.NewFileManager([]string{dir}), editManager)
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+
+	var response mcp.CallToolResponse
+	if err := json.Unmarshal(result, &response); err != nil {
+		t.Fatalf("Failed to decode %s response: %v", name, err)
+	}
+	if response.IsError {
+		t.Fatalf("%s returned an error: %s", name, response.Content[0].Text)
+	}
+	return response
+}
+
+func TestStructuredContent(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	file := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(file, []byte("one two\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	response := callTool(t, dir, "list_directory", map[string]string{"path": dir})
+	var listing filesystem.DirectoryListing
+	if err := json.Unmarshal(response.StructuredContent, &listing); err != nil {
+		t.Fatalf("Failed to decode structured listing: %v", err)
+	}
+	if len(listing.Entries) != 2 || listing.Entries[0] != (filesystem.DirectoryEntry{Name: "notes.txt", Type: "file"}) ||
+		listing.Entries[1] != (filesystem.DirectoryEntry{Name: "sub", Type: "directory"}) {
+		t.Errorf("Expected notes.txt and sub entries, got %+v", listing.Entries)
+	}
+	if len(response.Content) != 1 || response.Content[0].Text != "[FILE] notes.txt\n[DIR] sub" {
+		t.Errorf("Expected text listing, got %+v", response.Content)
+	}
+
+	response = callTool(t, dir, "get_file_info", map[string]string{"path": file})
+	var info filesystem.FileInfo
+	if err := json.Unmarshal(response.StructuredContent, &info); err != nil {
+		t.Fatalf("Failed to decode structured file info: %v", err)
+	}
+	if info.Size != 8 || !info.IsFile {
+		t.Errorf("Expected an 8 byte file, got %+v", info)
+	}
+	if len(response.Content) != 1 || response.Content[0].Text == "" {
+		t.Errorf("Expected text file info, got %+v", response.Content)
+	}
+
+	response = callTool(t, dir, "file_stats", map[string]string{"path": file})
+	var stats filesystem.FileStats
+	if err := json.Unmarshal(response.StructuredContent, &stats); err != nil {
+		t.Fatalf("Failed to decode structured file stats: %v", err)
+	}
+	if stats.Lines != 1 || stats.Words != 2 || len(response.Content) != 1 {
+		t.Errorf("Expected 1 line and 2 words with text content, got %+v and %+v", stats, response.Content)
+	}
+}
+
+func TestStructuredContentOmitted(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+
+	response := callTool(t, dir, "list_allowed_directories", map[string]string{})
+	if response.StructuredContent != nil {
+		t.Errorf("Expected no structured content, got %s", response.StructuredContent)
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrServerBusy is returned when a tool call can't start because the limit
+// on concurrent calls has been reached
+var ErrServerBusy = errors.New("server busy")
+
+// What happens to a call arriving while the concurrency limit is reached
+const (
+	BusyReject = "reject" // fail the call with ErrServerBusy
+	BusyQueue  = "queue"  // wait for a free slot, if the queue has room
+)
+
+// ConcurrencyLimiter bounds how many tool calls are in flight at once.
+// Calls over the limit are rejected, or with the queue policy wait for a
+// slot, up to a bounded number waiting at a time.
+type ConcurrencyLimiter struct {
+	slots  chan struct{}
+	queue  chan struct{}
+	policy string
+}
+
+// NewConcurrencyLimiter creates a limiter allowing max calls in flight.
+// With BusyQueue up to maxQueued further calls wait for a slot; any other
+// policy rejects them. A max of 0 or less returns nil, which never limits.
+func NewConcurrencyLimiter(max int, policy string, maxQueued int) *ConcurrencyLimiter {
+	if max <= 0 {
+		return nil
+	}
+
+	limiter := &ConcurrencyLimiter{
+		slots:  make(chan struct{}, max),
+		policy: policy,
+	}
+	if policy == BusyQueue && maxQueued > 0 {
+		limiter.queue = make(chan struct{}, maxQueued)
+	}
+	return limiter
+}
+
+// Acquire takes a slot for a call, returning the function that releases it
+// when the call finishes, or an error wrapping ErrServerBusy if no slot is
+// free and the call can't wait for one. A nil limiter allows every call.
+func (l *ConcurrencyLimiter) Acquire() (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+
+	if l.queue == nil {
+		return nil, fmt.Errorf("%w: %d tool calls already in progress, try again later", ErrServerBusy, cap(l.slots))
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return nil, fmt.Errorf("%w: %d tool calls in progress and %d waiting, try again later", ErrServerBusy, cap(l.slots), cap(l.queue))
+	}
+	l.slots <- struct{}{}
+	<-l.queue
+	return l.release, nil
+}
+
+// release frees the slot taken by Acquire
+func (l *ConcurrencyLimiter) release() {
+	<-l.slots
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fireCalls starts calls goroutines that each acquire a slot from limiter
+// and hold it briefly, returning the peak number in flight and the number
+// rejected
+func fireCalls(t *testing.T, limiter *ConcurrencyLimiter, calls int) (int64, int64) {
+	t.Helper()
+
+	var inFlight, peak, rejected atomic.Int64
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			release, err := limiter.Acquire()
+			if err != nil {
+				if !errors.Is(err, ErrServerBusy) {
+					t.Errorf("Expected ErrServerBusy, got %v", err)
+				}
+				rejected.Add(1)
+				return
+			}
+			defer release()
+
+			current := inFlight.Add(1)
+			for {
+				highest := peak.Load()
+				if current <= highest || peak.CompareAndSwap(highest, current) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return peak.Load(), rejected.Load()
+}
+
+func TestConcurrencyLimiterReject(t *testing.T) {
+	limiter := NewConcurrencyLimiter(2, BusyReject, 0)
+
+	peak, rejected := fireCalls(t, limiter, 10)
+	if peak > 2 {
+		t.Errorf("Expected at most 2 calls in flight, got %d", peak)
+	}
+	if rejected == 0 {
+		t.Errorf("Expected some of 10 concurrent calls to be rejected")
+	}
+
+	// Slots are released once the calls finish
+	release, err := limiter.Acquire()
+	if err != nil {
+		t.Fatalf("Expected a call after the others finished to be allowed, got %v", err)
+	}
+	release()
+}
+
+func TestConcurrencyLimiterQueue(t *testing.T) {
+	// Two run and eight wait, so every call completes
+	peak, rejected := fireCalls(t, NewConcurrencyLimiter(2, BusyQueue, 8), 10)
+	if peak > 2 {
+		t.Errorf("Expected at most 2 calls in flight, got %d", peak)
+	}
+	if rejected != 0 {
+		t.Errorf("Expected every call to be queued, got %d rejected", rejected)
+	}
+
+	// With room for only one waiting call the rest are rejected
+	peak, rejected = fireCalls(t, NewConcurrencyLimiter(2, BusyQueue, 1), 10)
+	if peak > 2 {
+		t.Errorf("Expected at most 2 calls in flight, got %d", peak)
+	}
+	if rejected == 0 {
+		t.Errorf("Expected calls beyond the queue to be rejected")
+	}
+}
+
+func TestConcurrencyLimiterUnlimited(t *testing.T) {
+	limiter := NewConcurrencyLimiter(0, BusyReject, 0)
+	if limiter != nil {
+		t.Fatalf("Expected a limit of 0 to disable the limiter")
+	}
+
+	if _, rejected := fireCalls(t, limiter, 10); rejected != 0 {
+		t.Errorf("Expected no calls to be rejected, got %d", rejected)
+	}
+}
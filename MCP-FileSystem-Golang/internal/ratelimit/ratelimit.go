@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrToolRateLimited is returned when a tool is called more often than its limit allows
+var ErrToolRateLimited = errors.New("tool rate limit exceeded")
+
+// ToolLimit allows at most Calls calls to a tool in each Interval
+type ToolLimit struct {
+	Calls    int
+	Interval time.Duration
+}
+
+// ToolLimiter limits how often individual tools may be called, each against
+// its own limit, to protect the server from runaway callers. Tools without a
+// limit are never limited.
+type ToolLimiter struct {
+	limits  map[string]ToolLimit
+	windows map[string]*toolWindow
+	now     func() time.Time
+	mu      sync.Mutex
+}
+
+// toolWindow counts the calls to one tool in the current interval
+type toolWindow struct {
+	start time.Time
+	calls int
+}
+
+// NewToolLimiter creates a limiter enforcing the given per-tool limits
+func NewToolLimiter(limits map[string]ToolLimit) *ToolLimiter {
+	return &ToolLimiter{
+		limits:  limits,
+		windows: make(map[string]*toolWindow),
+		now:     time.Now,
+	}
+}
+
+// Allow records a call to tool, returning an error wrapping
+// ErrToolRateLimited if the tool has used up its calls for the current
+// interval. A nil limiter allows every call.
+func (l *ToolLimiter) Allow(tool string) error {
+	if l == nil {
+		return nil
+	}
+
+	limit, ok := l.limits[tool]
+	if !ok || limit.Calls <= 0 || limit.Interval <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	window, ok := l.windows[tool]
+	if !ok || now.Sub(window.start) >= limit.Interval {
+		window = &toolWindow{start: now}
+		l.windows[tool] = window
+	}
+
+	if window.calls >= limit.Calls {
+		retryAfter := limit.Interval - now.Sub(window.start)
+		return fmt.Errorf("%w: %s allows %d calls per %v, try again in %v",
+			ErrToolRateLimited, tool, limit.Calls, limit.Interval, retryAfter.Round(time.Millisecond))
+	}
+
+	window.calls++
+	return nil
+}
@@ -0,0 +1,48 @@
+package toolargs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+// strict makes Decode reject unknown arguments
+var strict atomic.Bool
+
+// SetStrict sets whether Decode rejects arguments that don't match a field
+// of the value being decoded into. Lenient decoding, the default, ignores
+// them, which hides client mistakes such as sending file_path for path.
+func SetStrict(enabled bool) {
+	strict.Store(enabled)
+}
+
+// Decode unmarshals tool arguments into v. In strict mode an argument v has
+// no field for is an error naming it.
+func Decode(data []byte, v interface{}) error {
+	if !strict.Load() {
+		return json.Unmarshal(data, v)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unexpected argument %s", field)
+		}
+		if errors.Is(err, io.EOF) {
+			// Report empty input the same way as lenient mode
+			return json.Unmarshal(data, v)
+		}
+		return err
+	}
+
+	// Anything after the object is invalid, as json.Unmarshal would report
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return json.Unmarshal(data, v)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package toolargs
+
+import (
+	"strings"
+	"testing"
+)
+
+type readArgs struct {
+	Path  string `json:"path"`
+	Edits []struct {
+		OldText string `json:"oldText"`
+	} `json:"edits"`
+}
+
+func TestDecodeLenient(t *testing.T) {
+	SetStrict(false)
+
+	var args readArgs
+	if err := Decode([]byte(`{"path": "a.txt", "file_path": "b.txt"}`), &args); err != nil {
+		t.Fatalf("Expected unknown arguments to be ignored, got %v", err)
+	}
+	if args.Path != "a.txt" {
+		t.Errorf("Expected path a.txt, got %q", args.Path)
+	}
+}
+
+func TestDecodeStrict(t *testing.T) {
+	SetStrict(true)
+	t.Cleanup(func() { SetStrict(false) })
+
+	var args readArgs
+	if err := Decode([]byte(`{"path": "a.txt", "edits": [{"oldText": "x"}]}`), &args); err != nil {
+		t.Fatalf("Expected known arguments to decode, got %v", err)
+	}
+	if args.Path != "a.txt" || len(args.Edits) != 1 || args.Edits[0].OldText != "x" {
+		t.Errorf("Expected the arguments to be decoded, got %+v", args)
+	}
+
+	err := Decode([]byte(`{"file_path": "a.txt"}`), &readArgs{})
+	if err == nil || err.Error() != `unexpected argument "file_path"` {
+		t.Errorf("Expected an error naming file_path, got %v", err)
+	}
+
+	// Nested objects are checked too
+	err = Decode([]byte(`{"path": "a.txt", "edits": [{"old_text": "x"}]}`), &readArgs{})
+	if err == nil || !strings.Contains(err.Error(), `"old_text"`) {
+		t.Errorf("Expected an error naming old_text, got %v", err)
+	}
+
+	// Malformed input fails as it does in lenient mode
+	for _, input := range []string{``, `{"path": "a.txt"} x`, `{"path": 1}`} {
+		if err := Decode([]byte(input), &readArgs{}); err == nil {
+			t.Errorf("Expected %q to fail", input)
+		}
+	}
+}
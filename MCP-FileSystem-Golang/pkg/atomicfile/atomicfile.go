@@ -0,0 +1,99 @@
+// Package atomicfile replaces file contents atomically: content is written
+// to a temporary file in the same directory, flushed to disk and renamed
+// over the target, so readers see either the old or the new content and a
+// crash never leaves a partly written file.
+//
+// Platform notes:
+//   - The replacement keeps the permission bits of the file it replaces.
+//   - On Linux, macOS and the BSDs the replacement can also keep the owner
+//     and group of the original, which matters when the server runs as root
+//     and edits files belonging to other users. Changing ownership needs
+//     privileges the server may not have; when it fails the write still
+//     succeeds and a warning is logged.
+//   - Elsewhere ownership is left to the platform's defaults.
+//   - Renaming gives the path a new inode. Other hard links to the file keep
+//     the old content, and an advisory lock another process holds on the old
+//     file no longer covers the path. WriteInPlace rewrites the existing file
+//     instead, keeping both, at the cost of atomicity.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultPerm is the permission given to files that don't exist yet
+const defaultPerm = 0644
+
+// WriteFile atomically replaces the content of path, creating the file if
+// needed. With preserveOwner set, a replaced file keeps its owner and group
+// where the platform supports it.
+func WriteFile(path string, content []byte, preserveOwner bool) (err error) {
+	original, statErr := os.Stat(path)
+	exists := statErr == nil
+
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	defer func() {
+		if err != nil {
+			temp.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	if _, err = temp.Write(content); err != nil {
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		return err
+	}
+
+	perm := os.FileMode(defaultPerm)
+	if exists {
+		perm = original.Mode().Perm()
+	}
+	if err = temp.Chmod(perm); err != nil {
+		return err
+	}
+
+	if exists && preserveOwner {
+		if chownErr := copyOwner(temp, original); chownErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not preserve the owner of %s: %v\n", path, chownErr)
+		}
+	}
+
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
+
+// WriteInPlace replaces the content of path by rewriting the existing file,
+// creating it if needed, rather than renaming a new file over it. The file
+// keeps its inode, so hard links to it and advisory locks on it stay valid,
+// and its mode and owner are untouched. It isn't atomic: a reader that
+// doesn't take the lock can see a partly written file, and a crash can leave
+// one behind.
+func WriteInPlace(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, defaultPerm)
+	if err != nil {
+		return err
+	}
+
+	// Truncate after writing so the file is never seen empty
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Truncate(int64(len(content)))
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestWriteFileReplacesContentAndKeepsMode(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(path, []byte("old"), 0755); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(path, 0755); err != nil {
+		t.Fatalf("Failed to set mode: %v", err)
+	}
+
+	if err := WriteFile(path, []byte("new"), false); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "new" {
+		t.Errorf("Expected content %q, got %q (%v)", "new", content, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Errorf("Expected mode 0755 to be kept, got %o", info.Mode().Perm())
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected only the target file in the directory, got %d entries (%v)", len(entries), err)
+	}
+}
+
+func TestWriteInPlaceKeepsHardLinks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := os.WriteFile(path, []byte("old content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Link(path, link); err != nil {
+		t.Skipf("Hard links not supported: %v", err)
+	}
+
+	if err := WriteInPlace(path, []byte("new")); err != nil {
+		t.Fatalf("WriteInPlace failed: %v", err)
+	}
+
+	for _, name := range []string{path, link} {
+		content, err := os.ReadFile(name)
+		if err != nil || string(content) != "new" {
+			t.Errorf("Expected %s to hold %q, got %q (%v)", filepath.Base(name), "new", content, err)
+		}
+	}
+
+	// A new file is created when needed
+	created := filepath.Join(dir, "new.txt")
+	if err := WriteInPlace(created, []byte("fresh")); err != nil {
+		t.Fatalf("WriteInPlace failed for a new file: %v", err)
+	}
+	if content, err := os.ReadFile(created); err != nil || string(content) != "fresh" {
+		t.Errorf("Expected the new file to hold %q, got %q (%v)", "fresh", content, err)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package atomicfile
+
+import "os"
+
+// copyOwner is not supported on this platform, where new files get the platform's default owner
+func copyOwner(file *os.File, original os.FileInfo) error {
+	return nil
+}
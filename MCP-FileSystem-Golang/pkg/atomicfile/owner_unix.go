@@ -0,0 +1,24 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package atomicfile
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyOwner gives file the owner and group recorded in original, unless it already has them
+func copyOwner(file *os.File, original os.FileInfo) error {
+	stat, ok := original.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if current, ok := info.Sys().(*syscall.Stat_t); ok && current.Uid == stat.Uid && current.Gid == stat.Gid {
+		return nil
+	}
+	return file.Chown(int(stat.Uid), int(stat.Gid))
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestWriteFilePreservesOwner(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing file ownership requires root")
+	}
+
+	path := filepath.Join(t.TempDir(), "user.txt")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chown(path, 1234, 5678); err != nil {
+		t.Fatalf("Failed to change owner: %v", err)
+	}
+
+	if err := WriteFile(path, []byte("new"), true); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	stat := info.Sys().(*syscall.Stat_t)
+	if stat.Uid != 1234 || stat.Gid != 5678 {
+		t.Errorf("Expected owner 1234:5678 to be kept, got %d:%d", stat.Uid, stat.Gid)
+	}
+}
@@ -6,18 +6,69 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/ratelimit"
 )
 
 // Config holds the application configuration
 type Config struct {
 	AllowedDirectories []string `json:"allowedDirectories"`
+	IdleTimeout        int      `json:"idleTimeout,omitempty"` // in seconds, 0 disables
+	DebugTiming        bool     `json:"debugTiming,omitempty"`
+	FileLocking        bool     `json:"fileLocking,omitempty"`
+	MinFreeBytes       int64    `json:"minFreeBytes,omitempty"` // 0 disables
+	GrepTimeout        int      `json:"grepTimeout,omitempty"`  // in seconds
+	GrepMaxLines       int      `json:"grepMaxLines,omitempty"` // per file
+	// AllowNone starts the server with no allowed directories instead of
+	// failing; every tool call then reports the misconfiguration
+	AllowNone bool `json:"allowNone,omitempty"`
+	// DefaultMaxWalkDepth limits tree walks that aren't given max_depth; 0 is unlimited
+	DefaultMaxWalkDepth int `json:"defaultMaxWalkDepth,omitempty"`
+	// RetrySharingViolations retries writes and moves blocked by another
+	// process; defaults to true on Windows when unset
+	RetrySharingViolations *bool `json:"retrySharingViolations,omitempty"`
+	// RejectDuplicateRequestIDs rejects a request reusing the id of one still in progress
+	RejectDuplicateRequestIDs bool `json:"rejectDuplicateRequestIds,omitempty"`
+	// StrictJSONRPC rejects messages whose jsonrpc field is missing or not "2.0"
+	StrictJSONRPC bool `json:"strictJsonRpc,omitempty"`
+	// StrictArguments rejects tool calls with arguments the tool doesn't define
+	StrictArguments bool `json:"strictArguments,omitempty"`
+	// SafeContent wraps read_file and read_multiple_files output in
+	// <file-content> markers unless the caller sets safe_content
+	SafeContent bool `json:"safeContent,omitempty"`
+	// PreserveOwnership keeps the owner and group of files the server rewrites (Unix only)
+	PreserveOwnership bool `json:"preserveOwnership,omitempty"`
+	// TrimTrailingWhitespace strips trailing whitespace from the lines
+	// str_replace and insert change unless the caller says otherwise
+	TrimTrailingWhitespace bool `json:"trimTrailingWhitespace,omitempty"`
+	// ToolRateLimits limits how often individual tools may be called, keyed by tool name
+	ToolRateLimits map[string]ToolRateLimit `json:"toolRateLimits,omitempty"`
+	// MaxExposedTools caps how many tools tools/list returns; 0 lists every tool
+	MaxExposedTools int `json:"maxExposedTools,omitempty"`
+	// ToolPriority names the tools to list first when MaxExposedTools applies
+	ToolPriority []string `json:"toolPriority,omitempty"`
+	// MaxConcurrentToolCalls limits how many tool calls run at once; 0 is unlimited
+	MaxConcurrentToolCalls int `json:"maxConcurrentToolCalls,omitempty"`
+	// BusyPolicy says what happens to a call over MaxConcurrentToolCalls:
+	// "reject" (the default) fails it, "queue" waits for a free slot
+	BusyPolicy string `json:"busyPolicy,omitempty"`
+	// MaxQueuedToolCalls bounds how many calls may wait under the queue
+	// policy; defaults to MaxConcurrentToolCalls
+	MaxQueuedToolCalls int `json:"maxQueuedToolCalls,omitempty"`
+}
+
+// ToolRateLimit allows at most Calls calls to a tool in each Interval
+type ToolRateLimit struct {
+	Calls    int `json:"calls"`
+	Interval int `json:"interval"` // in seconds
 }
 
 // Default config file name
 const configFileName = "config.json"
 
 // ErrNoAllowedDirectories is returned when no allowed directories are specified
-var ErrNoAllowedDirectories = errors.New("at least one allowed directory must be specified in config.json")
+var ErrNoAllowedDirectories = errors.New("no allowed directories configured: at least one allowed directory must be specified in config.json")
 
 // LoadConfig loads the configuration from a JSON file in the executable directory
 func LoadConfig() (*Config, error) {
@@ -65,14 +116,25 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse the config file
+	config, err := parseConfig(file)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Configuration loaded successfully\n")
+	return config, nil
+}
+
+// parseConfig parses and validates the contents of a config file, resolving
+// the allowed directories and applying defaults
+func parseConfig(data []byte) (*Config, error) {
 	config := &Config{}
-	if err := json.Unmarshal(file, config); err != nil {
+	if err := json.Unmarshal(data, config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	// Validate the config
-	if len(config.AllowedDirectories) == 0 {
+	// Validate the config; an empty list is only accepted when explicitly allowed
+	if len(config.AllowedDirectories) == 0 && !config.AllowNone {
 		return nil, ErrNoAllowedDirectories
 	}
 
@@ -100,10 +162,70 @@ func LoadConfig() (*Config, error) {
 	// Update the config with resolved paths
 	config.AllowedDirectories = resolvedDirs
 
-	fmt.Fprintf(os.Stderr, "Configuration loaded successfully\n")
+	// Set default grep limits if not specified
+	if config.GrepTimeout <= 0 {
+		config.GrepTimeout = 30
+	}
+	if config.GrepMaxLines <= 0 {
+		config.GrepMaxLines = 100000
+	}
+
+	// Validate tool rate limits
+	for tool, limit := range config.ToolRateLimits {
+		if limit.Calls <= 0 || limit.Interval <= 0 {
+			return nil, fmt.Errorf("invalid rate limit for tool %s: calls and interval must be positive", tool)
+		}
+	}
+
+	// Validate the concurrency limit
+	switch config.BusyPolicy {
+	case "":
+		config.BusyPolicy = ratelimit.BusyReject
+	case ratelimit.BusyReject, ratelimit.BusyQueue:
+	default:
+		return nil, fmt.Errorf("invalid busyPolicy %q: must be %q or %q", config.BusyPolicy, ratelimit.BusyReject, ratelimit.BusyQueue)
+	}
+	if config.MaxQueuedToolCalls <= 0 {
+		config.MaxQueuedToolCalls = config.MaxConcurrentToolCalls
+	}
+
 	return config, nil
 }
 
+// NoAllowedDirectories reports whether the server is running without any
+// allowed directories, which AllowNone permits
+func (c *Config) NoAllowedDirectories() bool {
+	return len(c.AllowedDirectories) == 0
+}
+
+// GetIdleTimeout returns the idle shutdown timeout as a duration
+func (c *Config) GetIdleTimeout() time.Duration {
+	return time.Duration(c.IdleTimeout) * time.Second
+}
+
+// GetGrepTimeout returns the time limit for a single grep_files search
+func (c *Config) GetGrepTimeout() time.Duration {
+	return time.Duration(c.GrepTimeout) * time.Second
+}
+
+// GetToolRateLimits returns the per-tool rate limits keyed by tool name
+func (c *Config) GetToolRateLimits() map[string]ratelimit.ToolLimit {
+	limits := make(map[string]ratelimit.ToolLimit, len(c.ToolRateLimits))
+	for tool, limit := range c.ToolRateLimits {
+		limits[tool] = ratelimit.ToolLimit{
+			Calls:    limit.Calls,
+			Interval: time.Duration(limit.Interval) * time.Second,
+		}
+	}
+	return limits
+}
+
+// NewConcurrencyLimiter returns the limiter enforcing MaxConcurrentToolCalls,
+// or nil if it is unset
+func (c *Config) NewConcurrencyLimiter() *ratelimit.ConcurrencyLimiter {
+	return ratelimit.NewConcurrencyLimiter(c.MaxConcurrentToolCalls, c.BusyPolicy, c.MaxQueuedToolCalls)
+}
+
 // createDefaultConfig creates a default config file with example allowed directories
 func createDefaultConfig(configFilePath string) (*Config, error) {
 	// Get current directory as an example
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseConfigNoAllowedDirectories(t *testing.T) {
+	for _, data := range []string{`{}`, `{"allowedDirectories": []}`} {
+		if _, err := parseConfig([]byte(data)); !errors.Is(err, ErrNoAllowedDirectories) {
+			t.Errorf("Expected ErrNoAllowedDirectories for %s, got %v", data, err)
+		}
+	}
+
+	cfg, err := parseConfig([]byte(`{"allowedDirectories": [], "allowNone": true}`))
+	if err != nil {
+		t.Fatalf("Expected allowNone to accept an empty list, got %v", err)
+	}
+	if !cfg.NoAllowedDirectories() {
+		t.Error("Expected the config to report no allowed directories")
+	}
+}
+
+func TestParseConfigAllowedDirectories(t *testing.T) {
+	dir := t.TempDir()
+	data, err := json.Marshal(Config{AllowedDirectories: []string{dir}})
+	if err != nil {
+		t.Fatalf("Failed to encode config: %v", err)
+	}
+
+	cfg, err := parseConfig(data)
+	if err != nil {
+		t.Fatalf("parseConfig failed: %v", err)
+	}
+	if cfg.NoAllowedDirectories() || len(cfg.AllowedDirectories) != 1 {
+		t.Errorf("Expected one allowed directory, got %v", cfg.AllowedDirectories)
+	}
+	if cfg.GrepTimeout != 30 || cfg.GrepMaxLines != 100000 {
+		t.Errorf("Expected default grep limits, got %d and %d", cfg.GrepTimeout, cfg.GrepMaxLines)
+	}
+}
@@ -2,6 +2,8 @@ package editor
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,12 +11,18 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/atomicfile"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/flock"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/pathlock"
 )
 
 // EditHistory tracks file edits for undo functionality
 type EditHistory struct {
 	FilePath     string
-	OriginalHash string
+	OriginalHash string // SHA-256 of the content saved in the backup
+	OriginalSize int64  // length of the content saved in the backup
 	BackupPath   string
 	Timestamp    time.Time
 }
@@ -24,6 +32,13 @@ type EditManager struct {
 	history      []EditHistory
 	historyMutex sync.RWMutex
 	backupDir    string
+	locks        *pathlock.Manager
+	fileLocking  bool
+	// preserveOwnership keeps the owner and group of rewritten files
+	preserveOwnership bool
+	// trimTrailingWhitespace is the default for stripping trailing
+	// whitespace from the lines an edit changes
+	trimTrailingWhitespace bool
 }
 
 // NewEditManager creates a new EditManager
@@ -41,14 +56,56 @@ func NewEditManager(backupDir string) (*EditManager, error) {
 	return &EditManager{
 		history:   make([]EditHistory, 0),
 		backupDir: backupDir,
+		locks:     pathlock.Default(),
+	}, nil
+}
+
+// SetFileLocking enables OS-level advisory locks around read-modify-write edits
+func (em *EditManager) SetFileLocking(enabled bool) {
+	em.fileLocking = enabled
+}
+
+// SetPreserveOwnership makes edited files keep their original owner and
+// group, on platforms that support it
+func (em *EditManager) SetPreserveOwnership(enabled bool) {
+	em.preserveOwnership = enabled
+}
+
+// writeFile atomically replaces the content of an edited file, or rewrites
+// it in place when file locking is enabled so the lock held on it stays valid
+func (em *EditManager) writeFile(filePath string, content []byte) error {
+	if em.fileLocking {
+		return atomicfile.WriteInPlace(filePath, content)
+	}
+	return atomicfile.WriteFile(filePath, content, em.preserveOwnership)
+}
+
+// lockFile serializes an edit with other operations on the same file, both
+// within this process and, when file locking is enabled, with other processes
+func (em *EditManager) lockFile(filePath string) (func(), error) {
+	unlock := em.locks.Lock(filePath)
+	if !em.fileLocking {
+		return unlock, nil
+	}
+
+	release, err := flock.AcquireBestEffort(filePath, flock.DefaultTimeout)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return func() {
+		release()
+		unlock()
 	}, nil
 }
 
-// createBackup creates a backup of a file before editing
-func (em *EditManager) createBackup(filePath string) (string, error) {
+// createBackup creates a backup of a file before editing, recording its
+// hash and length so the backup can be verified before it is restored
+func (em *EditManager) createBackup(filePath string) (EditHistory, error) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read file for backup: %w", err)
+		return EditHistory{}, fmt.Errorf("failed to read file for backup: %w", err)
 	}
 
 	// Create a unique backup filename
@@ -56,23 +113,69 @@ func (em *EditManager) createBackup(filePath string) (string, error) {
 	backupName := fmt.Sprintf("%s_%d.bak", filepath.Base(filePath), timestamp)
 	backupPath := filepath.Join(em.backupDir, backupName)
 
-	if err := os.WriteFile(backupPath, content, 0644); err != nil {
-		return "", fmt.Errorf("failed to write backup: %w", err)
+	// Write to a temporary file and rename it into place, so an interrupted
+	// write never leaves a partial backup under the final name
+	tempPath := backupPath + ".tmp"
+	if err := writeFileSync(tempPath, content); err != nil {
+		os.Remove(tempPath)
+		return EditHistory{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := os.Rename(tempPath, backupPath); err != nil {
+		os.Remove(tempPath)
+		return EditHistory{}, fmt.Errorf("failed to write backup: %w", err)
 	}
 
-	return backupPath, nil
+	return EditHistory{
+		FilePath:     filePath,
+		OriginalHash: hashContent(content),
+		OriginalSize: int64(len(content)),
+		BackupPath:   backupPath,
+	}, nil
+}
+
+// writeFileSync writes content to a file and flushes it to disk
+func writeFileSync(path string, content []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// hashContent returns the hex SHA-256 of content
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyBackup checks that a backup's content matches the hash and length
+// recorded when it was made
+func verifyBackup(entry EditHistory, content []byte) error {
+	if int64(len(content)) != entry.OriginalSize {
+		return fmt.Errorf("backup %s is truncated or corrupt: expected %d bytes, found %d; the file was left unchanged",
+			entry.BackupPath, entry.OriginalSize, len(content))
+	}
+	if hashContent(content) != entry.OriginalHash {
+		return fmt.Errorf("backup %s is corrupt: its content does not match the hash recorded when it was made; the file was left unchanged",
+			entry.BackupPath)
+	}
+	return nil
 }
 
 // addToHistory adds an edit to the history
-func (em *EditManager) addToHistory(filePath, backupPath string) {
+func (em *EditManager) addToHistory(entry EditHistory) {
 	em.historyMutex.Lock()
 	defer em.historyMutex.Unlock()
 
-	entry := EditHistory{
-		FilePath:   filePath,
-		BackupPath: backupPath,
-		Timestamp:  time.Now(),
-	}
+	entry.Timestamp = time.Now()
 
 	em.history = append(em.history, entry)
 
@@ -87,53 +190,77 @@ func (em *EditManager) addToHistory(filePath, backupPath string) {
 	}
 }
 
-// StrReplace performs an exact string match and replace in a file
-func (em *EditManager) StrReplace(filePath, oldStr, newStr string) error {
+// StrReplace performs an exact string match and replace in a file. When
+// trimTrailing is set, trailing whitespace is stripped from the lines the
+// replacement touches, and the number of lines trimmed is returned.
+func (em *EditManager) StrReplace(filePath, oldStr, newStr string, trimTrailing bool) (int, error) {
+	// Serialize with other operations on the same file
+	unlock, err := em.lockFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
 	// Read the entire file
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
+		return 0, fmt.Errorf("failed to read file: %w", err)
 	}
 
 	fileContent := string(content)
 
 	// Check if old string exists
-	if !strings.Contains(fileContent, oldStr) {
-		return fmt.Errorf("string not found in file: %q", oldStr)
+	index := strings.Index(fileContent, oldStr)
+	if index < 0 {
+		return 0, fmt.Errorf("string not found in file: %q", oldStr)
 	}
 
 	// Count occurrences
 	count := strings.Count(fileContent, oldStr)
 	if count > 1 {
-		return fmt.Errorf("string appears %d times in file; it must appear exactly once for str_replace", count)
+		return 0, fmt.Errorf("string appears %d times in file; it must appear exactly once for str_replace", count)
 	}
 
 	// Create backup before modifying
-	backupPath, err := em.createBackup(filePath)
+	backup, err := em.createBackup(filePath)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
 	// Perform replacement
-	newContent := strings.Replace(fileContent, oldStr, newStr, 1)
+	newContent := fileContent[:index] + newStr + fileContent[index+len(oldStr):]
+
+	trimmed := 0
+	if trimTrailing {
+		newContent, trimmed = trimChangedLines(newContent, index, index+len(newStr))
+	}
 
 	// Write the modified content
-	if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := em.writeFile(filePath, []byte(newContent)); err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// Add to history
-	em.addToHistory(filePath, backupPath)
+	em.addToHistory(backup)
 
-	return nil
+	return trimmed, nil
 }
 
-// Insert inserts text after a specified line number
-func (em *EditManager) Insert(filePath string, lineNumber int, text string) error {
+// Insert inserts text after a specified line number. When trimTrailing is
+// set, trailing whitespace is stripped from the inserted lines, and the
+// number of lines trimmed is returned.
+func (em *EditManager) Insert(filePath string, lineNumber int, text string, trimTrailing bool) (int, error) {
+	// Serialize with other operations on the same file
+	unlock, err := em.lockFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
 	// Read file line by line
 	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open file: %w", err)
+		return 0, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
@@ -144,19 +271,24 @@ func (em *EditManager) Insert(filePath string, lineNumber int, text string) erro
 	}
 
 	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("error reading file: %w", err)
+		return 0, fmt.Errorf("error reading file: %w", err)
 	}
 
 	// Validate line number (1-indexed)
 	if lineNumber < 0 || lineNumber > len(lines) {
-		return fmt.Errorf("invalid line number %d; file has %d lines (use 0 to insert at beginning, %d to append)", 
+		return 0, fmt.Errorf("invalid line number %d; file has %d lines (use 0 to insert at beginning, %d to append)", 
 			lineNumber, len(lines), len(lines))
 	}
 
 	// Create backup
-	backupPath, err := em.createBackup(filePath)
+	backup, err := em.createBackup(filePath)
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	trimmed := 0
+	if trimTrailing {
+		text, trimmed = trimLines(text)
 	}
 
 	// Insert text after the specified line
@@ -167,18 +299,25 @@ func (em *EditManager) Insert(filePath string, lineNumber int, text string) erro
 
 	// Write back to file
 	newContent := strings.Join(newLines, "\n")
-	if err := os.WriteFile(filePath, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := em.writeFile(filePath, []byte(newContent)); err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// Add to history
-	em.addToHistory(filePath, backupPath)
+	em.addToHistory(backup)
 
-	return nil
+	return trimmed, nil
 }
 
 // UndoEdit undoes the last edit made to a specific file
 func (em *EditManager) UndoEdit(filePath string) error {
+	// Serialize with other operations on the same file
+	unlock, err := em.lockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	em.historyMutex.Lock()
 	defer em.historyMutex.Unlock()
 
@@ -203,7 +342,12 @@ func (em *EditManager) UndoEdit(filePath string) error {
 		return fmt.Errorf("failed to read backup file: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, backupContent, 0644); err != nil {
+	// Refuse to replace the current file with a damaged backup
+	if err := verifyBackup(entry, backupContent); err != nil {
+		return err
+	}
+
+	if err := em.writeFile(filePath, backupContent); err != nil {
 		return fmt.Errorf("failed to restore file: %w", err)
 	}
 
@@ -251,6 +395,10 @@ var StrReplaceSchema = map[string]interface{}{
 			"type":        "string",
 			"description": "The string to replace it with (can be empty to delete)",
 		},
+		"trim_trailing_whitespace": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Strip trailing whitespace from the lines the replacement touches (defaults to the server setting)",
+		},
 	},
 	"required": []string{"path", "old_str"},
 }
@@ -271,6 +419,10 @@ var InsertSchema = map[string]interface{}{
 			"type":        "string",
 			"description": "Text to insert",
 		},
+		"trim_trailing_whitespace": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Strip trailing whitespace from the inserted lines (defaults to the server setting)",
+		},
 	},
 	"required": []string{"path", "line_number", "text"},
 }
@@ -314,58 +466,84 @@ var EditorTools = map[string]EditorTool{
 	"undo_edit": {
 		Name: "undo_edit",
 		Description: "Undo the last edit made to a specific file. This will restore the file to its state " +
-			"before the last str_replace or insert operation. Can be called multiple times to undo multiple " +
+			"before the last str_replace, insert, set_json_path or trim_trailing_whitespace operation. Can be called multiple times to undo multiple " +
 			"edits. Only works within allowed directories.",
 		InputSchema: UndoEditSchema,
 	},
+	"read_json_path": {
+		Name: "read_json_path",
+		Description: "Read a single value from a JSON or YAML file by path, such as $.servers[0].port, " +
+			"instead of reading the whole file. Returns the value as indented JSON, including for a .yaml " +
+			"or .yml file. YAML anchors, aliases, tags, multiple documents, multi-line quoted or plain " +
+			"scalars and unquoted values containing \": \" are refused. Only works within allowed directories.",
+		InputSchema: ReadJSONPathSchema,
+	},
+	"set_json_path": {
+		Name: "set_json_path",
+		Description: "Set a single value in a JSON or YAML file by path, such as $.servers[0].port, leaving " +
+			"the rest of the file exactly as it was. The final key is added if its parent object exists. " +
+			"In a YAML file the value is written in JSON form, which YAML reads as a flow collection. " +
+			"A backup is automatically created before the edit, so it can be reverted with undo_edit. " +
+			"Only works within allowed directories.",
+		InputSchema: SetJSONPathSchema,
+	},
+	"trim_trailing_whitespace": {
+		Name: "trim_trailing_whitespace",
+		Description: "Strip trailing spaces and tabs from every line of a file and report how many lines " +
+			"changed. A backup is automatically created before the edit, so it can be reverted with " +
+			"undo_edit. Only works within allowed directories.",
+		InputSchema: TrimTrailingWhitespaceSchema,
+	},
 }
 
 // Argument parsing functions
 
 // ParseStrReplaceArgs parses arguments for str_replace
-func ParseStrReplaceArgs(args json.RawMessage) (path, oldStr, newStr string, err error) {
+func ParseStrReplaceArgs(args json.RawMessage) (path, oldStr, newStr string, trimTrailing *bool, err error) {
 	var params struct {
-		Path   string `json:"path"`
-		OldStr string `json:"old_str"`
-		NewStr string `json:"new_str"`
+		Path         string `json:"path"`
+		OldStr       string `json:"old_str"`
+		NewStr       string `json:"new_str"`
+		TrimTrailing *bool  `json:"trim_trailing_whitespace"`
 	}
 
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", "", "", fmt.Errorf("invalid arguments for str_replace: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", "", nil, fmt.Errorf("invalid arguments for str_replace: %w", err)
 	}
 
 	if params.Path == "" {
-		return "", "", "", fmt.Errorf("path parameter is required")
+		return "", "", "", nil, fmt.Errorf("path parameter is required")
 	}
 
 	if params.OldStr == "" {
-		return "", "", "", fmt.Errorf("old_str parameter is required")
+		return "", "", "", nil, fmt.Errorf("old_str parameter is required")
 	}
 
-	return params.Path, params.OldStr, params.NewStr, nil
+	return params.Path, params.OldStr, params.NewStr, params.TrimTrailing, nil
 }
 
 // ParseInsertArgs parses arguments for insert
-func ParseInsertArgs(args json.RawMessage) (path string, lineNumber int, text string, err error) {
+func ParseInsertArgs(args json.RawMessage) (path string, lineNumber int, text string, trimTrailing *bool, err error) {
 	var params struct {
-		Path       string `json:"path"`
-		LineNumber int    `json:"line_number"`
-		Text       string `json:"text"`
+		Path         string `json:"path"`
+		LineNumber   int    `json:"line_number"`
+		Text         string `json:"text"`
+		TrimTrailing *bool  `json:"trim_trailing_whitespace"`
 	}
 
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", 0, "", fmt.Errorf("invalid arguments for insert: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, "", nil, fmt.Errorf("invalid arguments for insert: %w", err)
 	}
 
 	if params.Path == "" {
-		return "", 0, "", fmt.Errorf("path parameter is required")
+		return "", 0, "", nil, fmt.Errorf("path parameter is required")
 	}
 
 	if params.Text == "" {
-		return "", 0, "", fmt.Errorf("text parameter is required")
+		return "", 0, "", nil, fmt.Errorf("text parameter is required")
 	}
 
-	return params.Path, params.LineNumber, params.Text, nil
+	return params.Path, params.LineNumber, params.Text, params.TrimTrailing, nil
 }
 
 // ParseUndoEditArgs parses arguments for undo_edit
@@ -374,7 +552,7 @@ func ParseUndoEditArgs(args json.RawMessage) (path string, err error) {
 		Path string `json:"path"`
 	}
 
-	if err := json.Unmarshal(args, &params); err != nil {
+	if err := toolargs.Decode(args, &params); err != nil {
 		return "", fmt.Errorf("invalid arguments for undo_edit: %w", err)
 	}
 
@@ -1,8 +1,11 @@
 package editor
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -28,7 +31,7 @@ func TestStrReplace(t *testing.T) {
 	}
 
 	// Test successful replacement
-	err = em.StrReplace(testFile, "This is a test", "This is modified")
+	_, err = em.StrReplace(testFile, "This is a test", "This is modified", false)
 	if err != nil {
 		t.Errorf("StrReplace failed: %v", err)
 	}
@@ -44,7 +47,7 @@ func TestStrReplace(t *testing.T) {
 	}
 
 	// Test string not found
-	err = em.StrReplace(testFile, "nonexistent", "replacement")
+	_, err = em.StrReplace(testFile, "nonexistent", "replacement", false)
 	if err == nil {
 		t.Error("Expected error for nonexistent string, got nil")
 	}
@@ -54,7 +57,7 @@ func TestStrReplace(t *testing.T) {
 	if err := os.WriteFile(testFile, []byte(multiContent), 0644); err != nil {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
-	err = em.StrReplace(testFile, "foo", "baz")
+	_, err = em.StrReplace(testFile, "foo", "baz", false)
 	if err == nil {
 		t.Error("Expected error for multiple occurrences, got nil")
 	}
@@ -82,7 +85,7 @@ func TestInsert(t *testing.T) {
 	}
 
 	// Test insert after line 1
-	err = em.Insert(testFile, 1, "Inserted Line")
+	_, err = em.Insert(testFile, 1, "Inserted Line", false)
 	if err != nil {
 		t.Errorf("Insert failed: %v", err)
 	}
@@ -102,7 +105,7 @@ func TestInsert(t *testing.T) {
 	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
-	err = em.Insert(testFile, 0, "First Line")
+	_, err = em.Insert(testFile, 0, "First Line", false)
 	if err != nil {
 		t.Errorf("Insert at beginning failed: %v", err)
 	}
@@ -121,7 +124,7 @@ func TestInsert(t *testing.T) {
 	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
-	err = em.Insert(testFile, 3, "Last Line")
+	_, err = em.Insert(testFile, 3, "Last Line", false)
 	if err != nil {
 		t.Errorf("Insert at end failed: %v", err)
 	}
@@ -136,7 +139,7 @@ func TestInsert(t *testing.T) {
 	}
 
 	// Test invalid line number
-	err = em.Insert(testFile, 100, "Invalid")
+	_, err = em.Insert(testFile, 100, "Invalid", false)
 	if err == nil {
 		t.Error("Expected error for invalid line number, got nil")
 	}
@@ -164,7 +167,7 @@ func TestUndoEdit(t *testing.T) {
 	}
 
 	// Make an edit
-	err = em.StrReplace(testFile, "Original Content", "Modified Content")
+	_, err = em.StrReplace(testFile, "Original Content", "Modified Content", false)
 	if err != nil {
 		t.Fatalf("StrReplace failed: %v", err)
 	}
@@ -200,6 +203,56 @@ func TestUndoEdit(t *testing.T) {
 	}
 }
 
+func TestUndoEditRejectsCorruptBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	em, err := NewEditManager(filepath.Join(tmpDir, "backups"))
+	if err != nil {
+		t.Fatalf("Failed to create edit manager: %v", err)
+	}
+
+	testFile := filepath.Join(tmpDir, "test.txt")
+	originalContent := "Original Content\nLine 2\nLine 3"
+	if err := os.WriteFile(testFile, []byte(originalContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := em.StrReplace(testFile, "Original Content", "Modified Content", false); err != nil {
+		t.Fatalf("StrReplace failed: %v", err)
+	}
+
+	// Truncate the backup, as an interrupted write would
+	history := em.GetEditHistory(testFile)
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 history entry, got %d", len(history))
+	}
+	if err := os.WriteFile(history[0].BackupPath, []byte("Original"), 0644); err != nil {
+		t.Fatalf("Failed to truncate backup: %v", err)
+	}
+
+	err = em.UndoEdit(testFile)
+	if err == nil || !strings.Contains(err.Error(), "truncated or corrupt") {
+		t.Errorf("Expected an error reporting the truncated backup, got %v", err)
+	}
+
+	// The current file must not be replaced by the damaged backup
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "Modified Content\nLine 2\nLine 3" {
+		t.Errorf("Expected the file to be left unchanged, got:\n%s", string(content))
+	}
+
+	// A backup of the right length but different content is also rejected
+	if err := os.WriteFile(history[0].BackupPath, []byte(strings.ToUpper(originalContent)), 0644); err != nil {
+		t.Fatalf("Failed to corrupt backup: %v", err)
+	}
+	err = em.UndoEdit(testFile)
+	if err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Expected an error reporting the hash mismatch, got %v", err)
+	}
+}
+
 func TestMultipleEditsAndUndo(t *testing.T) {
 	// Create a temporary directory for testing
 	tmpDir, err := os.MkdirTemp("", "editor-test-*")
@@ -222,17 +275,17 @@ func TestMultipleEditsAndUndo(t *testing.T) {
 	}
 
 	// Make multiple edits
-	err = em.StrReplace(testFile, "Line 1", "Modified Line 1")
+	_, err = em.StrReplace(testFile, "Line 1", "Modified Line 1", false)
 	if err != nil {
 		t.Fatalf("First StrReplace failed: %v", err)
 	}
 
-	err = em.Insert(testFile, 1, "Inserted Line")
+	_, err = em.Insert(testFile, 1, "Inserted Line", false)
 	if err != nil {
 		t.Fatalf("Insert failed: %v", err)
 	}
 
-	err = em.StrReplace(testFile, "Line 2", "Modified Line 2")
+	_, err = em.StrReplace(testFile, "Line 2", "Modified Line 2", false)
 	if err != nil {
 		t.Fatalf("Second StrReplace failed: %v", err)
 	}
@@ -280,6 +333,70 @@ func TestMultipleEditsAndUndo(t *testing.T) {
 	}
 }
 
+func TestConcurrentEditsSameFile(t *testing.T) {
+	// Create a temporary directory for testing
+	tmpDir, err := os.MkdirTemp("", "editor-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Create an edit manager
+	em, err := NewEditManager(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to create edit manager: %v", err)
+	}
+
+	// Create a test file
+	testFile := filepath.Join(tmpDir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("Header"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Apply many edits concurrently; each must see the result of the previous one
+	const edits = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, edits)
+	for i := 0; i < edits; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			if _, err := em.Insert(testFile, 1, fmt.Sprintf("Line %d", n), false); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Concurrent insert failed: %v", err)
+	}
+
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if len(lines) != edits+1 {
+		t.Fatalf("Expected %d lines after concurrent edits, got %d", edits+1, len(lines))
+	}
+	if lines[0] != "Header" {
+		t.Errorf("Expected first line to be preserved, got %q", lines[0])
+	}
+
+	seen := make(map[string]bool)
+	for _, line := range lines[1:] {
+		seen[line] = true
+	}
+	for i := 0; i < edits; i++ {
+		if !seen[fmt.Sprintf("Line %d", i)] {
+			t.Errorf("Edit %d was lost", i)
+		}
+	}
+}
+
 func containsString(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && 
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || 
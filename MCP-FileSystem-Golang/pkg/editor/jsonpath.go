@@ -0,0 +1,434 @@
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// jsonPathSegment is one step of a JSON path: an object key or an array index
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// String formats the segment as it would appear in a path
+func (s jsonPathSegment) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return "." + s.key
+}
+
+// jsonSpan locates a value within a JSON document by byte offsets
+type jsonSpan struct {
+	start, end int
+}
+
+// ReadJSONPath returns the value at expr in a JSON file, indented, leaving
+// the order and form of its keys as they are in the file. A YAML file, by
+// its .yaml or .yml extension, is read as YAML and the value returned as
+// indented JSON in the same way, with its keys in file order.
+func ReadJSONPath(filePath, expr string) (string, error) {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return "", err
+	}
+
+	if isYAMLFile(filePath) {
+		data, err := readYAMLFile(filePath)
+		if err != nil {
+			return "", err
+		}
+		return readYAMLPath(data, segments)
+	}
+
+	data, err := readJSONFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	span, err := findJSONPath(data, segments)
+	if err != nil {
+		return "", err
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, data[span.start:span.end], "", "  "); err != nil {
+		return "", fmt.Errorf("failed to format value: %w", err)
+	}
+	return indented.String(), nil
+}
+
+// SetJSONPath replaces the value at expr in a JSON file with value, or adds
+// the final key when its parent object exists but the key does not. The rest
+// of the file is left byte for byte as it was. YAML files are edited the
+// same way, with value written in JSON form, which YAML reads as a flow
+// collection or scalar. A backup is made first so the change can be undone
+// with UndoEdit.
+func (em *EditManager) SetJSONPath(filePath, expr string, value json.RawMessage) error {
+	segments, err := parseJSONPath(expr)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("expression must name a key or index below the root")
+	}
+
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, value); err != nil {
+		return fmt.Errorf("value is not valid JSON: %w", err)
+	}
+
+	// Serialize with other operations on the same file
+	unlock, err := em.lockFile(filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	read, update := readJSONFile, setJSONPath
+	if isYAMLFile(filePath) {
+		read, update = readYAMLFile, setYAMLPath
+	}
+
+	data, err := read(filePath)
+	if err != nil {
+		return err
+	}
+
+	updated, err := update(data, segments, compacted.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// Create backup before modifying
+	backup, err := em.createBackup(filePath)
+	if err != nil {
+		return err
+	}
+
+	if err := em.writeFile(filePath, updated); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// Add to history
+	em.addToHistory(backup)
+
+	return nil
+}
+
+// readJSONFile reads a file and checks that it holds a single JSON document
+func readJSONFile(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("%s is not valid JSON", filePath)
+	}
+	return data, nil
+}
+
+// parseJSONPath parses a path such as $.servers[0].port or servers[0].port.
+// Keys containing dots or brackets can be quoted: $["key.with.dots"]. An
+// empty path or "$" names the whole document.
+func parseJSONPath(expr string) ([]jsonPathSegment, error) {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid JSON path %q: %s", expr, reason)
+	}
+
+	rest := strings.TrimPrefix(strings.TrimSpace(expr), "$")
+	var segments []jsonPathSegment
+	for i := 0; i < len(rest); {
+		switch {
+		case rest[i] == '[':
+			end := strings.IndexByte(rest[i:], ']')
+			if end < 0 {
+				return nil, invalid("unclosed '['")
+			}
+			inner := rest[i+1 : i+end]
+			if len(inner) >= 2 && (inner[0] == '"' || inner[0] == '\'') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1]})
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, invalid(fmt.Sprintf("%q is not an array index or quoted key", inner))
+				}
+				segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			}
+			i += end + 1
+		case rest[i] == '.' || i == 0:
+			if rest[i] == '.' {
+				i++
+			}
+			end := i
+			for end < len(rest) && rest[end] != '.' && rest[end] != '[' {
+				end++
+			}
+			if end == i {
+				return nil, invalid("empty key")
+			}
+			segments = append(segments, jsonPathSegment{key: rest[i:end]})
+			i = end
+		default:
+			return nil, invalid(fmt.Sprintf("unexpected %q", rest[i]))
+		}
+	}
+	return segments, nil
+}
+
+// findJSONPath returns the span of the value at segments within data
+func findJSONPath(data []byte, segments []jsonPathSegment) (jsonSpan, error) {
+	span := trimJSONSpan(data, jsonSpan{0, len(data)})
+	for i, segment := range segments {
+		child, _, err := findJSONChild(data, span, segment)
+		if err != nil {
+			return jsonSpan{}, err
+		}
+		if child == nil {
+			return jsonSpan{}, fmt.Errorf("%s not found", formatJSONPath(segments[:i+1]))
+		}
+		span = *child
+	}
+	return span, nil
+}
+
+// setJSONPath returns data with the value at segments replaced by value, or
+// with the final key added to its parent object when missing
+func setJSONPath(data []byte, segments []jsonPathSegment, value []byte) ([]byte, error) {
+	parent, err := findJSONPath(data, segments[:len(segments)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := segments[len(segments)-1]
+	child, insertion, err := findJSONChild(data, parent, last)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated bytes.Buffer
+	if child != nil {
+		updated.Write(data[:child.start])
+		updated.Write(value)
+		updated.Write(data[child.end:])
+		return updated.Bytes(), nil
+	}
+
+	if last.isIndex || insertion == nil {
+		return nil, fmt.Errorf("%s not found", formatJSONPath(segments))
+	}
+
+	key, err := json.Marshal(last.key)
+	if err != nil {
+		return nil, err
+	}
+	updated.Write(data[:insertion.at])
+	updated.WriteString(insertion.prefix)
+	updated.Write(key)
+	updated.WriteString(": ")
+	updated.Write(value)
+	updated.Write(data[insertion.at:])
+	return updated.Bytes(), nil
+}
+
+// jsonInsertion is where a new key can be added to an object, and the
+// separator to write before it to match the object's existing layout
+type jsonInsertion struct {
+	at     int
+	prefix string
+}
+
+// findJSONChild looks up segment in the object or array at span. It returns
+// the child's span, or nil when it doesn't exist; for a missing object key it
+// also returns where the key could be inserted.
+func findJSONChild(data []byte, span jsonSpan, segment jsonPathSegment) (*jsonSpan, *jsonInsertion, error) {
+	value := data[span.start:span.end]
+	if len(value) == 0 || (value[0] != '{' && value[0] != '[') {
+		return nil, nil, fmt.Errorf("cannot look up %s in a %s", segment, jsonKind(value))
+	}
+	if segment.isIndex && value[0] != '[' {
+		return nil, nil, fmt.Errorf("cannot index an object with %s", segment)
+	}
+	if !segment.isIndex && value[0] != '{' {
+		return nil, nil, fmt.Errorf("cannot look up key %q in an array", segment.key)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(value))
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, err
+	}
+
+	separator := ""
+	lastEnd := 1
+	for index := 0; dec.More(); index++ {
+		memberStart := int(dec.InputOffset())
+		var key string
+		if !segment.isIndex {
+			token, err := dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			key, _ = token.(string)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+		end := int(dec.InputOffset())
+
+		if (segment.isIndex && index == segment.index) || (!segment.isIndex && key == segment.key) {
+			return &jsonSpan{span.start + end - len(raw), span.start + end}, nil, nil
+		}
+
+		// Keep the whitespace before the member to lay out an inserted key the same way
+		between := value[memberStart:]
+		trimmed := bytes.TrimLeft(between, ", \t\r\n")
+		separator = strings.TrimLeft(string(between[:len(between)-len(trimmed)]), ",")
+		lastEnd = end
+	}
+
+	if separator == "" && lastEnd > 1 {
+		separator = " "
+	}
+	prefix := separator
+	if lastEnd > 1 {
+		prefix = "," + separator
+	}
+	return nil, &jsonInsertion{at: span.start + lastEnd, prefix: prefix}, nil
+}
+
+// trimJSONSpan narrows span to exclude surrounding whitespace
+func trimJSONSpan(data []byte, span jsonSpan) jsonSpan {
+	for span.start < span.end && isJSONSpace(data[span.start]) {
+		span.start++
+	}
+	for span.end > span.start && isJSONSpace(data[span.end-1]) {
+		span.end--
+	}
+	return span
+}
+
+// isJSONSpace reports whether b is JSON insignificant whitespace
+func isJSONSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\r' || b == '\n'
+}
+
+// jsonKind names the type of a JSON value for error messages
+func jsonKind(value []byte) string {
+	if len(value) == 0 {
+		return "empty value"
+	}
+	switch value[0] {
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// formatJSONPath formats segments as a path rooted at $
+func formatJSONPath(segments []jsonPathSegment) string {
+	var path strings.Builder
+	path.WriteString("$")
+	for _, segment := range segments {
+		path.WriteString(segment.String())
+	}
+	return path.String()
+}
+
+// ReadJSONPathSchema defines the schema for read_json_path tool input
+var ReadJSONPathSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the JSON or YAML file to read",
+		},
+		"expression": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the value, e.g. $.servers[0].port; quote keys containing dots as $[\"a.b\"]. Use $ for the whole document",
+		},
+	},
+	"required": []string{"path", "expression"},
+}
+
+// SetJSONPathSchema defines the schema for set_json_path tool input
+var SetJSONPathSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the JSON or YAML file to edit",
+		},
+		"expression": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the value to set, e.g. $.servers[0].port. The final key is added if its parent object exists",
+		},
+		"value": map[string]interface{}{
+			"description": "The new value as JSON; YAML files get it in flow style, e.g. {\"a\": 1}",
+		},
+	},
+	"required": []string{"path", "expression", "value"},
+}
+
+// ParseReadJSONPathArgs parses arguments for read_json_path
+func ParseReadJSONPathArgs(args json.RawMessage) (path, expression string, err error) {
+	var params struct {
+		Path       string `json:"path"`
+		Expression string `json:"expression"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", fmt.Errorf("invalid arguments for read_json_path: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", "", fmt.Errorf("path parameter is required")
+	}
+
+	if params.Expression == "" {
+		return "", "", fmt.Errorf("expression parameter is required")
+	}
+
+	return params.Path, params.Expression, nil
+}
+
+// ParseSetJSONPathArgs parses arguments for set_json_path
+func ParseSetJSONPathArgs(args json.RawMessage) (path, expression string, value json.RawMessage, err error) {
+	var params struct {
+		Path       string          `json:"path"`
+		Expression string          `json:"expression"`
+		Value      json.RawMessage `json:"value"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", nil, fmt.Errorf("invalid arguments for set_json_path: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", "", nil, fmt.Errorf("path parameter is required")
+	}
+
+	if params.Expression == "" {
+		return "", "", nil, fmt.Errorf("expression parameter is required")
+	}
+
+	if len(params.Value) == 0 {
+		return "", "", nil, fmt.Errorf("value parameter is required")
+	}
+
+	return params.Path, params.Expression, params.Value, nil
+}
@@ -0,0 +1,105 @@
+package editor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testJSONConfig = `{
+  "name": "demo",
+  "servers": [
+    {"host": "a.example", "port": 80},
+    {"host": "b.example", "port": 81}
+  ],
+  "dotted.key": true
+}
+`
+
+// writeJSONConfig creates an edit manager and a JSON file to edit
+func writeJSONConfig(t *testing.T) (*EditManager, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	em, err := NewEditManager(filepath.Join(tmpDir, "backups"))
+	if err != nil {
+		t.Fatalf("Failed to create edit manager: %v", err)
+	}
+
+	testFile := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(testFile, []byte(testJSONConfig), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return em, testFile
+}
+
+func TestReadJSONPath(t *testing.T) {
+	_, testFile := writeJSONConfig(t)
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"$.name", `"demo"`},
+		{"servers[1].port", "81"},
+		{`$["dotted.key"]`, "true"},
+		{"$.servers[0]", "{\n  \"host\": \"a.example\",\n  \"port\": 80\n}"},
+	}
+	for _, tt := range tests {
+		value, err := ReadJSONPath(testFile, tt.expression)
+		if err != nil {
+			t.Errorf("ReadJSONPath(%q) failed: %v", tt.expression, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.expression, value)
+		}
+	}
+
+	for _, expression := range []string{"$.missing", "$.servers[5]", "$.name.first", "$.servers[x]", "$..name"} {
+		if _, err := ReadJSONPath(testFile, expression); err == nil {
+			t.Errorf("Expected an error for %q", expression)
+		}
+	}
+
+	_, err := ReadJSONPath(testFile, "$.servers[0].user")
+	if err == nil || !strings.Contains(err.Error(), "$.servers[0].user not found") {
+		t.Errorf("Expected an error naming the missing key, got %v", err)
+	}
+}
+
+func TestSetJSONPath(t *testing.T) {
+	em, testFile := writeJSONConfig(t)
+
+	if err := em.SetJSONPath(testFile, "$.servers[1].port", json.RawMessage(`8081`)); err != nil {
+		t.Fatalf("SetJSONPath failed: %v", err)
+	}
+	if err := em.SetJSONPath(testFile, "$.timeout", json.RawMessage(`{ "seconds": 30 }`)); err != nil {
+		t.Fatalf("SetJSONPath adding a key failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(testFile)
+	expected := strings.Replace(testJSONConfig, `"port": 81`, `"port": 8081`, 1)
+	expected = strings.Replace(expected, `"dotted.key": true`, "\"dotted.key\": true,\n  \"timeout\": {\"seconds\":30}", 1)
+	if string(content) != expected {
+		t.Errorf("Expected content:\n%s\ngot:\n%s", expected, string(content))
+	}
+
+	// A missing parent is an error and leaves the file untouched
+	if err := em.SetJSONPath(testFile, "$.limits.max", json.RawMessage(`1`)); err == nil {
+		t.Error("Expected an error setting a key below a missing object")
+	}
+
+	// Each change can be undone
+	for i := 0; i < 2; i++ {
+		if err := em.UndoEdit(testFile); err != nil {
+			t.Fatalf("UndoEdit failed: %v", err)
+		}
+	}
+	content, _ = os.ReadFile(testFile)
+	if string(content) != testJSONConfig {
+		t.Errorf("Expected undo to restore the original content, got:\n%s", string(content))
+	}
+}
@@ -0,0 +1,132 @@
+package editor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// trailingWhitespace is the whitespace trimmed from the end of lines; a
+// carriage return ending a CRLF line is kept
+const trailingWhitespace = " \t\f\v"
+
+// SetTrimTrailingWhitespace sets whether str_replace and insert strip
+// trailing whitespace from the lines they change when the caller doesn't say
+func (em *EditManager) SetTrimTrailingWhitespace(enabled bool) {
+	em.trimTrailingWhitespace = enabled
+}
+
+// UseTrimTrailingWhitespace reports whether an edit should strip trailing
+// whitespace: as requested by the caller, or the configured default when
+// requested is nil
+func (em *EditManager) UseTrimTrailingWhitespace(requested *bool) bool {
+	if requested != nil {
+		return *requested
+	}
+	return em.trimTrailingWhitespace
+}
+
+// TrimTrailingWhitespace strips trailing spaces and tabs from every line of
+// a file and returns how many lines changed. The file is backed up first so
+// the change can be undone; it is left untouched when no line changes.
+func (em *EditManager) TrimTrailingWhitespace(filePath string) (int, error) {
+	// Serialize with other operations on the same file
+	unlock, err := em.lockFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	trimmed, count := trimLines(string(content))
+	if count == 0 {
+		return 0, nil
+	}
+
+	// Create backup before modifying
+	backup, err := em.createBackup(filePath)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := em.writeFile(filePath, []byte(trimmed)); err != nil {
+		return 0, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	// Add to history
+	em.addToHistory(backup)
+
+	return count, nil
+}
+
+// trimLines strips trailing whitespace from each line of text and returns
+// the result along with how many lines changed
+func trimLines(text string) (string, int) {
+	lines := strings.Split(text, "\n")
+	count := 0
+	for i, line := range lines {
+		trimmed := trimLine(line)
+		if trimmed != line {
+			lines[i] = trimmed
+			count++
+		}
+	}
+	return strings.Join(lines, "\n"), count
+}
+
+// trimLine strips trailing whitespace from a line, keeping a final carriage return
+func trimLine(line string) string {
+	if strings.HasSuffix(line, "\r") {
+		return strings.TrimRight(line[:len(line)-1], trailingWhitespace) + "\r"
+	}
+	return strings.TrimRight(line, trailingWhitespace)
+}
+
+// trimChangedLines strips trailing whitespace from the lines of content
+// that overlap content[start:end], leaving every other line as it is
+func trimChangedLines(content string, start, end int) (string, int) {
+	lineStart := strings.LastIndex(content[:start], "\n") + 1
+	lineEnd := len(content)
+	if i := strings.Index(content[end:], "\n"); i >= 0 {
+		lineEnd = end + i
+	}
+
+	trimmed, count := trimLines(content[lineStart:lineEnd])
+	return content[:lineStart] + trimmed + content[lineEnd:], count
+}
+
+// TrimTrailingWhitespaceSchema defines the schema for trim_trailing_whitespace tool input
+var TrimTrailingWhitespaceSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the file to strip trailing whitespace from",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseTrimTrailingWhitespaceArgs parses arguments for trim_trailing_whitespace
+func ParseTrimTrailingWhitespaceArgs(args json.RawMessage) (path string, err error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", fmt.Errorf("invalid arguments for trim_trailing_whitespace: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", fmt.Errorf("path parameter is required")
+	}
+
+	return params.Path, nil
+}
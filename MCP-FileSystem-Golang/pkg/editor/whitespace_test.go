@@ -0,0 +1,115 @@
+package editor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// whitespaceFixture has trailing spaces, tabs and a CRLF line
+const whitespaceFixture = "keep  \nfirst line \t\nsecond\t\t\nthird\r\nlast  "
+
+func newWhitespaceFixture(t *testing.T) (*EditManager, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	em, err := NewEditManager(filepath.Join(tmpDir, "backups"))
+	if err != nil {
+		t.Fatalf("Failed to create edit manager: %v", err)
+	}
+
+	testFile := filepath.Join(tmpDir, "fixture.txt")
+	if err := os.WriteFile(testFile, []byte(whitespaceFixture), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return em, testFile
+}
+
+func assertFileContent(t *testing.T, path, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != expected {
+		t.Errorf("Content mismatch. Expected:\n%q\nGot:\n%q", expected, string(content))
+	}
+}
+
+func TestTrimTrailingWhitespace(t *testing.T) {
+	em, testFile := newWhitespaceFixture(t)
+
+	trimmed, err := em.TrimTrailingWhitespace(testFile)
+	if err != nil {
+		t.Fatalf("TrimTrailingWhitespace failed: %v", err)
+	}
+	if trimmed != 4 {
+		t.Errorf("Expected 4 lines trimmed, got %d", trimmed)
+	}
+	assertFileContent(t, testFile, "keep\nfirst line\nsecond\nthird\r\nlast")
+
+	// A clean file is left alone and not added to the history
+	if trimmed, err := em.TrimTrailingWhitespace(testFile); err != nil || trimmed != 0 {
+		t.Errorf("Expected nothing to trim, got %d and %v", trimmed, err)
+	}
+	if history := em.GetEditHistory(testFile); len(history) != 1 {
+		t.Errorf("Expected 1 history entry, got %d", len(history))
+	}
+
+	// The trim can be undone
+	if err := em.UndoEdit(testFile); err != nil {
+		t.Fatalf("UndoEdit failed: %v", err)
+	}
+	assertFileContent(t, testFile, whitespaceFixture)
+}
+
+func TestStrReplaceTrimsChangedLines(t *testing.T) {
+	em, testFile := newWhitespaceFixture(t)
+
+	trimmed, err := em.StrReplace(testFile, "first line", "first  \n  middle\t", true)
+	if err != nil {
+		t.Fatalf("StrReplace failed: %v", err)
+	}
+	if trimmed != 2 {
+		t.Errorf("Expected 2 lines trimmed, got %d", trimmed)
+	}
+	// Lines outside the replacement keep their whitespace
+	assertFileContent(t, testFile, "keep  \nfirst\n  middle\nsecond\t\t\nthird\r\nlast  ")
+
+	// Without trimming the replacement is written as given
+	if trimmed, err := em.StrReplace(testFile, "middle", "middle ", false); err != nil || trimmed != 0 {
+		t.Errorf("Expected no trimming, got %d and %v", trimmed, err)
+	}
+	assertFileContent(t, testFile, "keep  \nfirst\n  middle \nsecond\t\t\nthird\r\nlast  ")
+}
+
+func TestInsertTrimsInsertedLines(t *testing.T) {
+	em, testFile := newWhitespaceFixture(t)
+	if err := os.WriteFile(testFile, []byte("keep  \nfirst line \t"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	trimmed, err := em.Insert(testFile, 1, "new \t\nline", true)
+	if err != nil {
+		t.Fatalf("Insert failed: %v", err)
+	}
+	if trimmed != 1 {
+		t.Errorf("Expected 1 line trimmed, got %d", trimmed)
+	}
+	// Existing lines keep their whitespace
+	assertFileContent(t, testFile, "keep  \nnew\nline\nfirst line \t")
+}
+
+func TestUseTrimTrailingWhitespace(t *testing.T) {
+	em, _ := newWhitespaceFixture(t)
+	enabled, disabled := true, false
+
+	if em.UseTrimTrailingWhitespace(nil) {
+		t.Error("Expected trimming to be off by default")
+	}
+	em.SetTrimTrailingWhitespace(true)
+	if !em.UseTrimTrailingWhitespace(nil) || em.UseTrimTrailingWhitespace(&disabled) || !em.UseTrimTrailingWhitespace(&enabled) {
+		t.Error("Expected the configured default to apply only when the caller doesn't say")
+	}
+}
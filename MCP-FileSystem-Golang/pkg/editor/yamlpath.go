@@ -0,0 +1,866 @@
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML support covers what configuration files use: block mappings and
+// sequences, including the compact "- key: value" form, single-line plain
+// and quoted scalars, single-line flow collections and | and > block
+// scalars. Anchors, aliases, tags, complex keys, directives, tab
+// indentation, multi-line quoted or plain scalars, plain scalars holding
+// ": " and files holding several documents are refused rather than misread.
+// Plain scalars are typed by the YAML 1.2 core schema.
+
+// yamlNodeKind is the type of a YAML node
+type yamlNodeKind int
+
+const (
+	yamlScalar yamlNodeKind = iota
+	yamlMapping
+	yamlSequence
+)
+
+// yamlNode is a value within a YAML document, located by byte offsets
+type yamlNode struct {
+	kind       yamlNodeKind
+	start, end int  // the node's text
+	valueAt    int  // just after the "key:" or "-" introducing the node
+	inline     bool // written on one line, so replacing start to end replaces it
+	flow       bool // a {...} or [...] collection
+	column     int  // column of a block collection's keys or dashes
+	keys       []string
+	children   []*yamlNode // mapping values or sequence items
+	value      interface{} // a scalar's decoded value; nil is null
+}
+
+// yamlLine is a line of a YAML document
+type yamlLine struct {
+	start, end int // excluding the line break
+	indent     int
+	blank      bool // empty or only a comment
+}
+
+// yamlParser parses a YAML document into nodes
+type yamlParser struct {
+	data  []byte
+	lines []yamlLine
+}
+
+// isYAMLFile reports whether a file is YAML by its extension
+func isYAMLFile(filePath string) bool {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// readYAMLFile reads a file and checks that it holds a YAML document the
+// server can navigate
+func readYAMLFile(filePath string) ([]byte, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if _, err := parseYAML(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", filePath, err)
+	}
+	return data, nil
+}
+
+// readYAMLPath returns the value at segments as indented JSON, with mapping
+// keys in the order they have in the file
+func readYAMLPath(data []byte, segments []jsonPathSegment) (string, error) {
+	root, err := parseYAML(data)
+	if err != nil {
+		return "", err
+	}
+	node, err := findYAMLPath(root, segments)
+	if err != nil {
+		return "", err
+	}
+
+	var compact, indented bytes.Buffer
+	if err := writeYAMLJSON(&compact, data, node); err != nil {
+		return "", err
+	}
+	if err := json.Indent(&indented, compact.Bytes(), "", "  "); err != nil {
+		return "", fmt.Errorf("failed to format value: %w", err)
+	}
+	return indented.String(), nil
+}
+
+// writeYAMLJSON writes node as compact JSON
+func writeYAMLJSON(b *bytes.Buffer, data []byte, node *yamlNode) error {
+	switch node.kind {
+	case yamlMapping:
+		b.WriteByte('{')
+		for i, key := range node.keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeJSONValue(b, key); err != nil {
+				return err
+			}
+			b.WriteByte(':')
+			if err := writeYAMLJSON(b, data, node.children[i]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case yamlSequence:
+		b.WriteByte('[')
+		for i, child := range node.children {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeYAMLJSON(b, data, child); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		if err := writeJSONValue(b, node.value); err != nil {
+			return fmt.Errorf("%s can't be represented in JSON", data[node.start:node.end])
+		}
+	}
+	return nil
+}
+
+// writeJSONValue writes value as compact JSON, leaving <, > and & unescaped
+func writeJSONValue(b *bytes.Buffer, value interface{}) error {
+	var encoded bytes.Buffer
+	encoder := json.NewEncoder(&encoded)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return err
+	}
+	b.Write(bytes.TrimSuffix(encoded.Bytes(), []byte("\n")))
+	return nil
+}
+
+// setYAMLPath returns data with the value at segments replaced by value, or
+// with the final key added to its parent mapping when missing. value is
+// compact JSON, which YAML reads as a flow collection or scalar.
+func setYAMLPath(data []byte, segments []jsonPathSegment, value []byte) ([]byte, error) {
+	root, err := parseYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := findYAMLPath(root, segments[:len(segments)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := segments[len(segments)-1]
+	child, err := findYAMLChild(parent, last)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated bytes.Buffer
+	switch {
+	case child != nil && child.inline && child.start < child.end:
+		updated.Write(data[:child.start])
+		updated.Write(value)
+		updated.Write(data[child.end:])
+	case child != nil:
+		// Block values and empty ones are replaced from just after their key
+		updated.Write(data[:child.valueAt])
+		updated.WriteString(" ")
+		updated.Write(value)
+		updated.Write(data[child.end:])
+	case last.isIndex:
+		return nil, fmt.Errorf("%s not found", formatJSONPath(segments))
+	case parent.flow:
+		at, separator := parent.end-1, ""
+		if len(parent.children) > 0 {
+			at, separator = parent.children[len(parent.children)-1].end, ", "
+		}
+		updated.Write(data[:at])
+		updated.WriteString(separator + formatYAMLKey(last.key) + ": ")
+		updated.Write(value)
+		updated.Write(data[at:])
+	default:
+		lineBreak := "\n"
+		if bytes.Contains(data, []byte("\r\n")) {
+			lineBreak = "\r\n"
+		}
+		updated.Write(data[:parent.end])
+		updated.WriteString(lineBreak + strings.Repeat(" ", parent.column) + formatYAMLKey(last.key) + ": ")
+		updated.Write(value)
+		updated.Write(data[parent.end:])
+	}
+
+	if _, err := parseYAML(updated.Bytes()); err != nil {
+		return nil, fmt.Errorf("the change would leave invalid YAML: %w", err)
+	}
+	return updated.Bytes(), nil
+}
+
+// findYAMLPath returns the node at segments below root
+func findYAMLPath(root *yamlNode, segments []jsonPathSegment) (*yamlNode, error) {
+	node := root
+	for i, segment := range segments {
+		child, err := findYAMLChild(node, segment)
+		if err != nil {
+			return nil, err
+		}
+		if child == nil {
+			return nil, fmt.Errorf("%s not found", formatJSONPath(segments[:i+1]))
+		}
+		node = child
+	}
+	return node, nil
+}
+
+// findYAMLChild looks up segment in a mapping or sequence, returning nil
+// when it doesn't exist
+func findYAMLChild(node *yamlNode, segment jsonPathSegment) (*yamlNode, error) {
+	switch {
+	case node.kind == yamlScalar:
+		return nil, fmt.Errorf("cannot look up %s in a scalar", segment)
+	case segment.isIndex && node.kind != yamlSequence:
+		return nil, fmt.Errorf("cannot index a mapping with %s", segment)
+	case !segment.isIndex && node.kind != yamlMapping:
+		return nil, fmt.Errorf("cannot look up key %q in a sequence", segment.key)
+	}
+
+	if segment.isIndex {
+		if segment.index < len(node.children) {
+			return node.children[segment.index], nil
+		}
+		return nil, nil
+	}
+	for i, key := range node.keys {
+		if key == segment.key {
+			return node.children[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// plainYAMLKey matches keys that can be written without quotes
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_./-]*$`)
+
+// formatYAMLKey writes a mapping key, quoting it when needed
+func formatYAMLKey(key string) string {
+	if plainYAMLKey.MatchString(key) {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+// parseYAML parses a single YAML document
+func parseYAML(data []byte) (*yamlNode, error) {
+	p := &yamlParser{data: data}
+	if err := p.splitLines(); err != nil {
+		return nil, err
+	}
+
+	first := p.nextLine(0)
+	if first < len(p.lines) && p.isDocumentStart(first) {
+		first = p.nextLine(first + 1)
+	}
+	if first == len(p.lines) {
+		// An empty document is null
+		return &yamlNode{kind: yamlScalar, inline: true}, nil
+	}
+
+	line := p.lines[first]
+	root, next, err := p.parseBlockNode(first, line.indent, -1)
+	if err != nil {
+		return nil, err
+	}
+	root.valueAt = root.start
+
+	if next = p.nextLine(next); next < len(p.lines) {
+		return nil, p.errorAt(next, "unexpected content after the document")
+	}
+	return root, nil
+}
+
+// splitLines records the lines of the document and refuses what the parser
+// doesn't support
+func (p *yamlParser) splitLines() error {
+	for start := 0; start <= len(p.data); {
+		end := bytes.IndexByte(p.data[start:], '\n')
+		next := start + end + 1
+		if end < 0 {
+			end, next = len(p.data)-start, len(p.data)+1
+		}
+		end += start
+		if end > start && p.data[end-1] == '\r' {
+			end--
+		}
+
+		line := yamlLine{start: start, end: end}
+		for line.indent < end-start && p.data[start+line.indent] == ' ' {
+			line.indent++
+		}
+		rest := p.data[start+line.indent : end]
+		line.blank = len(bytes.TrimSpace(rest)) == 0 || rest[0] == '#'
+		if !line.blank && rest[0] == '\t' {
+			return fmt.Errorf("line %d: tabs can't be used for indentation", len(p.lines)+1)
+		}
+		p.lines = append(p.lines, line)
+		start = next
+	}
+
+	// Only a leading --- is allowed, and no directives
+	seenContent := false
+	for i, line := range p.lines {
+		if line.blank {
+			continue
+		}
+		if line.indent == 0 && p.data[line.start] == '%' {
+			return p.errorAt(i, "directives are not supported")
+		}
+		if p.isDocumentStart(i) && seenContent || p.isDocumentEnd(i) {
+			return p.errorAt(i, "files with several documents are not supported")
+		}
+		seenContent = true
+	}
+	return nil
+}
+
+// isDocumentStart reports whether line i is a --- marker
+func (p *yamlParser) isDocumentStart(i int) bool {
+	return p.isMarker(i, "---")
+}
+
+// isDocumentEnd reports whether line i is a ... marker
+func (p *yamlParser) isDocumentEnd(i int) bool {
+	return p.isMarker(i, "...")
+}
+
+// isMarker reports whether line i starts with marker followed by a space or nothing
+func (p *yamlParser) isMarker(i int, marker string) bool {
+	text := string(p.data[p.lines[i].start:p.lines[i].end])
+	return strings.HasPrefix(text, marker) && (len(text) == 3 || text[3] == ' ' || text[3] == '\t')
+}
+
+// nextLine returns the index of the first non-blank line from i, or the
+// number of lines when there is none
+func (p *yamlParser) nextLine(i int) int {
+	for i < len(p.lines) && p.lines[i].blank {
+		i++
+	}
+	return i
+}
+
+// errorAt returns an error about line i
+func (p *yamlParser) errorAt(i int, message string) error {
+	return fmt.Errorf("line %d: %s", i+1, message)
+}
+
+// parseBlockNode parses the node starting at column col of line i, within a
+// parent whose keys or dashes are at parentCol. It returns the node and the
+// index of the line after it.
+func (p *yamlParser) parseBlockNode(i, col, parentCol int) (*yamlNode, int, error) {
+	line := p.lines[i]
+	pos := line.start + col
+	switch {
+	case p.isDash(pos, line.end):
+		return p.parseSequence(i, col)
+	case p.isMappingEntry(pos, line.end):
+		return p.parseMapping(i, col)
+	default:
+		return p.parseValue(i, pos, parentCol)
+	}
+}
+
+// parseSequence parses a block sequence whose first dash is at column col of line i
+func (p *yamlParser) parseSequence(i, col int) (*yamlNode, int, error) {
+	node := &yamlNode{kind: yamlSequence, start: p.lines[i].start + col, column: col}
+	for {
+		line := p.lines[i]
+		valueAt := line.start + col + 1
+		item, next, err := p.parseEntryValue(i, valueAt, col, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		node.children = append(node.children, item)
+		node.end = item.end
+
+		i = p.nextLine(next)
+		if i == len(p.lines) || p.lines[i].indent < col {
+			return node, i, nil
+		}
+		if p.lines[i].indent > col {
+			return nil, 0, p.errorAt(i, "unexpected indentation")
+		}
+		if !p.isDash(p.lines[i].start+col, p.lines[i].end) {
+			return node, i, nil
+		}
+	}
+}
+
+// parseMapping parses a block mapping whose first key is at column col of line i
+func (p *yamlParser) parseMapping(i, col int) (*yamlNode, int, error) {
+	node := &yamlNode{kind: yamlMapping, start: p.lines[i].start + col, column: col}
+	for {
+		line := p.lines[i]
+		key, valueAt, err := p.parseKey(i, line.start+col, line.end)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, next, err := p.parseEntryValue(i, valueAt, col, false)
+		if err != nil {
+			return nil, 0, err
+		}
+		node.keys = append(node.keys, key)
+		node.children = append(node.children, value)
+		node.end = value.end
+
+		i = p.nextLine(next)
+		if i == len(p.lines) || p.lines[i].indent < col {
+			return node, i, nil
+		}
+		if p.lines[i].indent > col {
+			return nil, 0, p.errorAt(i, "unexpected indentation; multi-line plain scalars are not supported")
+		}
+		if !p.isMappingEntry(p.lines[i].start+col, p.lines[i].end) {
+			return nil, 0, p.errorAt(i, "unexpected content in a mapping")
+		}
+	}
+}
+
+// parseEntryValue parses the value after a key's colon or a dash at valueAt
+// on line i, where the key or dash is at column col. The value is on the
+// same line, or on the lines below when that is empty. Only a dash can be
+// followed by a block collection on the same line.
+func (p *yamlParser) parseEntryValue(i, valueAt, col int, afterDash bool) (*yamlNode, int, error) {
+	line := p.lines[i]
+	pos := skipYAMLSpace(p.data, valueAt, line.end)
+	if pos < line.end && p.data[pos] != '#' {
+		parse := p.parseValue
+		if afterDash {
+			parse = func(i, pos, parentCol int) (*yamlNode, int, error) {
+				return p.parseBlockNode(i, pos-p.lines[i].start, parentCol)
+			}
+		}
+		value, next, err := parse(i, pos, col)
+		if err != nil {
+			return nil, 0, err
+		}
+		value.valueAt = valueAt
+		return value, next, nil
+	}
+
+	// A nested collection on the following lines, or else null
+	next := p.nextLine(i + 1)
+	if next < len(p.lines) {
+		below := p.lines[next]
+		sameIndentSequence := !afterDash && below.indent == col && p.isDash(below.start+col, below.end)
+		if below.indent > col || sameIndentSequence {
+			value, after, err := p.parseBlockNode(next, below.indent, col)
+			if err != nil {
+				return nil, 0, err
+			}
+			value.valueAt = valueAt
+			value.inline = false
+			return value, after, nil
+		}
+	}
+	return &yamlNode{kind: yamlScalar, start: valueAt, end: valueAt, valueAt: valueAt, inline: true}, i + 1, nil
+}
+
+// parseValue parses a scalar or flow collection at pos on line i, or a
+// block scalar starting there, where the parent is at column parentCol
+func (p *yamlParser) parseValue(i, pos, parentCol int) (*yamlNode, int, error) {
+	line := p.lines[i]
+	switch p.data[pos] {
+	case '&', '*', '!':
+		return nil, 0, p.errorAt(i, "anchors, aliases and tags are not supported")
+	case '|', '>':
+		return p.parseBlockScalar(i, pos, parentCol)
+	}
+
+	node, end, err := p.parseFlow(i, pos, line.end, false)
+	if err != nil {
+		return nil, 0, err
+	}
+	if rest := skipYAMLSpace(p.data, end, line.end); rest < line.end && (p.data[rest] != '#' || rest == end) {
+		return nil, 0, p.errorAt(i, "unexpected text after a value")
+	}
+	node.inline = true
+	return node, i + 1, nil
+}
+
+// parseFlow parses a scalar or flow collection at pos, ending by end on
+// line i, and returns it with the offset just after it. With inFlow set,
+// inside a flow collection, plain scalars also end at a comma or bracket.
+func (p *yamlParser) parseFlow(i, pos, end int, inFlow bool) (*yamlNode, int, error) {
+	if pos >= end {
+		return nil, 0, p.errorAt(i, "missing value")
+	}
+
+	switch c := p.data[pos]; c {
+	case '&', '*', '!':
+		return nil, 0, p.errorAt(i, "anchors, aliases and tags are not supported")
+	case '"', '\'':
+		value, close, err := p.parseQuoted(i, pos, end)
+		if err != nil {
+			return nil, 0, err
+		}
+		return &yamlNode{kind: yamlScalar, start: pos, end: close, valueAt: pos, value: value}, close, nil
+	case '[', '{':
+		node := &yamlNode{kind: yamlSequence, start: pos, valueAt: pos, flow: true}
+		closing := byte(']')
+		if c == '{' {
+			node.kind, closing = yamlMapping, '}'
+		}
+		pos = skipYAMLSpace(p.data, pos+1, end)
+		for pos < end && p.data[pos] != closing {
+			if node.kind == yamlMapping {
+				key, valueAt, err := p.parseFlowKey(i, pos, end)
+				if err != nil {
+					return nil, 0, err
+				}
+				node.keys = append(node.keys, key)
+				pos = skipYAMLSpace(p.data, valueAt, end)
+			}
+			child, after, err := p.parseFlow(i, pos, end, true)
+			if err != nil {
+				return nil, 0, err
+			}
+			child.inline = true
+			node.children = append(node.children, child)
+
+			pos = skipYAMLSpace(p.data, after, end)
+			if pos < end && p.data[pos] == ',' {
+				pos = skipYAMLSpace(p.data, pos+1, end)
+			} else if pos < end && p.data[pos] != closing {
+				return nil, 0, p.errorAt(i, fmt.Sprintf("expected ',' or '%c' in a flow collection", closing))
+			}
+		}
+		if pos >= end {
+			return nil, 0, p.errorAt(i, "flow collections must close on the line they start")
+		}
+		node.end = pos + 1
+		return node, node.end, nil
+	}
+
+	// A plain scalar can't start with an indicator, nor hold a "key: value"
+	// pair, which YAML would read as a nested mapping or reject
+	c := p.data[pos]
+	indicator := pos+1 == end || isYAMLSpace(p.data[pos+1])
+	if c == '?' && indicator {
+		return nil, 0, p.errorAt(i, "complex keys are not supported")
+	}
+	if strings.IndexByte("@`%|>", c) >= 0 || (c == '-' || c == ':') && indicator {
+		return nil, 0, p.errorAt(i, fmt.Sprintf("a plain value can't start with %q; quote the value", c))
+	}
+
+	// A plain scalar runs to a comment, or in a flow collection to a comma or bracket
+	stop := pos
+	for stop < end {
+		c := p.data[stop]
+		if c == '#' && stop > pos && isYAMLSpace(p.data[stop-1]) {
+			break
+		}
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if c == ':' && (stop+1 == end || isYAMLSpace(p.data[stop+1]) || inFlow && isFlowIndicator(p.data[stop+1])) {
+			return nil, 0, p.errorAt(i, "mapping values are not allowed here; quote a value containing \": \"")
+		}
+		stop++
+	}
+	for stop > pos && isYAMLSpace(p.data[stop-1]) {
+		stop--
+	}
+	if stop == pos {
+		return nil, 0, p.errorAt(i, "missing value")
+	}
+	value := resolveYAMLPlain(string(p.data[pos:stop]))
+	return &yamlNode{kind: yamlScalar, start: pos, end: stop, valueAt: pos, value: value}, stop, nil
+}
+
+// Plain scalars the YAML 1.2 core schema reads as numbers
+var (
+	yamlDecimal = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlOctal   = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHex     = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+	yamlFloat   = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolveYAMLPlain returns the value of a plain scalar under the YAML 1.2
+// core schema: null, a boolean, an integer, a float or else the text itself.
+// Integers are kept exact however large they are.
+func resolveYAMLPlain(text string) interface{} {
+	switch text {
+	case "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	n := new(big.Int)
+	switch {
+	case yamlDecimal.MatchString(text):
+		n.SetString(strings.TrimPrefix(text, "+"), 10)
+	case yamlOctal.MatchString(text):
+		n.SetString(text[2:], 8)
+	case yamlHex.MatchString(text):
+		n.SetString(text[2:], 16)
+	case yamlFloat.MatchString(text):
+		f, _ := strconv.ParseFloat(text, 64)
+		return f
+	default:
+		return text
+	}
+	return json.Number(n.String())
+}
+
+// parseBlockScalar parses a | or > block scalar whose header is at pos on
+// line i, where the parent is at column parentCol. Its content is the
+// following lines indented past the parent, and its value is decoded with
+// the header's folding, chomping and indentation indicators.
+func (p *yamlParser) parseBlockScalar(i, pos, parentCol int) (*yamlNode, int, error) {
+	line := p.lines[i]
+	literal := p.data[pos] == '|'
+	var chomp byte
+	indent := 0
+	j := pos + 1
+	for ; j < line.end; j++ {
+		c := p.data[j]
+		if chomp == 0 && (c == '-' || c == '+') {
+			chomp = c
+		} else if indent == 0 && c >= '1' && c <= '9' {
+			indent = int(c - '0')
+		} else {
+			break
+		}
+	}
+	if rest := skipYAMLSpace(p.data, j, line.end); rest < line.end && (p.data[rest] != '#' || rest == j) {
+		return nil, 0, p.errorAt(i, "unexpected text after a block scalar indicator")
+	}
+
+	// The content indentation is given by the header or the first non-empty line
+	if indent > 0 && parentCol > 0 {
+		indent += parentCol
+	}
+	for next := i + 1; indent == 0 && next < len(p.lines); next++ {
+		if l := p.lines[next]; l.indent < l.end-l.start {
+			indent = l.indent
+			if indent <= parentCol {
+				indent = parentCol + 1
+			}
+		}
+	}
+
+	node := &yamlNode{kind: yamlScalar, start: pos, end: line.end}
+	var content []string
+	lastContent, lastLine := -1, i
+	next := i + 1
+	for ; next < len(p.lines) && p.lines[next].start < len(p.data); next++ {
+		l := p.lines[next]
+		spacesOnly := l.indent == l.end-l.start
+		if !spacesOnly && l.indent < indent {
+			break
+		}
+		text := ""
+		if l.end-l.start > indent {
+			text = string(p.data[l.start+indent : l.end])
+		}
+		content = append(content, text)
+		if !spacesOnly {
+			node.end = l.end
+			lastContent, lastLine = len(content)-1, next
+		}
+	}
+
+	body := content[:lastContent+1]
+	var value string
+	if literal {
+		value = strings.Join(body, "\n")
+	} else {
+		value = foldYAMLLines(body)
+	}
+	finalBreak := lastContent >= 0 && lastLine+1 < len(p.lines)
+	switch {
+	case chomp == '-':
+	case chomp == '+':
+		if finalBreak {
+			value += "\n"
+		}
+		value += strings.Repeat("\n", len(content)-len(body))
+	case finalBreak:
+		value += "\n"
+	}
+	node.value = value
+	return node, next, nil
+}
+
+// foldYAMLLines joins the lines of a folded block scalar: line breaks
+// between lines of text become spaces, empty lines become line breaks, and
+// the breaks around more indented lines are kept
+func foldYAMLLines(lines []string) string {
+	var b strings.Builder
+	started, prevIndented, breaks := false, false, 0
+	for _, line := range lines {
+		if line == "" {
+			breaks++
+			continue
+		}
+		indented := isYAMLSpace(line[0])
+		switch {
+		case !started:
+			b.WriteString(strings.Repeat("\n", breaks))
+		case prevIndented || indented:
+			b.WriteString(strings.Repeat("\n", breaks+1))
+		case breaks > 0:
+			b.WriteString(strings.Repeat("\n", breaks))
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(line)
+		started, prevIndented, breaks = true, indented, 0
+	}
+	return b.String()
+}
+
+// parseFlowKey parses a key and its colon in a flow mapping, returning the
+// key and the offset after the colon
+func (p *yamlParser) parseFlowKey(i, pos, end int) (string, int, error) {
+	var key string
+	if c := p.data[pos]; c == '"' || c == '\'' {
+		value, close, err := p.parseQuoted(i, pos, end)
+		if err != nil {
+			return "", 0, err
+		}
+		key, pos = value, skipYAMLSpace(p.data, close, end)
+	} else {
+		colon := bytes.IndexByte(p.data[pos:end], ':')
+		if colon < 0 {
+			return "", 0, p.errorAt(i, "expected ':' after a key in a flow mapping")
+		}
+		key = strings.TrimSpace(string(p.data[pos : pos+colon]))
+		pos += colon
+	}
+	if pos >= end || p.data[pos] != ':' {
+		return "", 0, p.errorAt(i, "expected ':' after a key in a flow mapping")
+	}
+	return key, pos + 1, nil
+}
+
+// parseKey parses the key of a block mapping entry at pos on line i and
+// returns it with the offset just after its colon
+func (p *yamlParser) parseKey(i, pos, end int) (string, int, error) {
+	switch p.data[pos] {
+	case '?', '&', '*', '!', '[', '{':
+		return "", 0, p.errorAt(i, "complex keys, anchors, aliases and tags are not supported")
+	case '"', '\'':
+		key, close, err := p.parseQuoted(i, pos, end)
+		if err != nil {
+			return "", 0, err
+		}
+		colon := skipYAMLSpace(p.data, close, end)
+		return key, colon + 1, nil
+	}
+
+	colon := p.keyColon(pos, end)
+	return strings.TrimSpace(string(p.data[pos:colon])), colon + 1, nil
+}
+
+// parseQuoted parses a quoted scalar at pos, returning its value and the
+// offset after the closing quote
+func (p *yamlParser) parseQuoted(i, pos, end int) (string, int, error) {
+	quote := p.data[pos]
+	for j := pos + 1; j < end; j++ {
+		switch {
+		case quote == '"' && p.data[j] == '\\':
+			j++
+		case p.data[j] == quote && quote == '\'' && j+1 < end && p.data[j+1] == '\'':
+			j++
+		case p.data[j] == quote:
+			text := string(p.data[pos : j+1])
+			if quote == '\'' {
+				return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), j + 1, nil
+			}
+			var value string
+			if err := json.Unmarshal([]byte(text), &value); err != nil {
+				return "", 0, p.errorAt(i, fmt.Sprintf("unsupported escape in %s", text))
+			}
+			return value, j + 1, nil
+		}
+	}
+	return "", 0, p.errorAt(i, "quoted scalars must close on the line they start")
+}
+
+// isDash reports whether pos starts a block sequence item
+func (p *yamlParser) isDash(pos, end int) bool {
+	return pos < end && p.data[pos] == '-' && (pos+1 == end || isYAMLSpace(p.data[pos+1]))
+}
+
+// isMappingEntry reports whether pos starts a "key: value" entry
+func (p *yamlParser) isMappingEntry(pos, end int) bool {
+	if pos >= end {
+		return false
+	}
+	switch p.data[pos] {
+	case '[', '{', '#':
+		return false
+	case '"', '\'':
+		_, close, err := p.parseQuoted(0, pos, end)
+		if err != nil {
+			return false
+		}
+		colon := skipYAMLSpace(p.data, close, end)
+		return colon < end && p.data[colon] == ':' && (colon+1 == end || isYAMLSpace(p.data[colon+1]))
+	}
+	return p.keyColon(pos, end) < end
+}
+
+// keyColon returns the offset of the colon ending a plain key at pos, or
+// end when the text isn't a key
+func (p *yamlParser) keyColon(pos, end int) int {
+	for j := pos; j < end; j++ {
+		switch {
+		case p.data[j] == '#' && j > pos && isYAMLSpace(p.data[j-1]):
+			return end
+		case p.data[j] == ':' && (j+1 == end || isYAMLSpace(p.data[j+1])):
+			return j
+		}
+	}
+	return end
+}
+
+// skipYAMLSpace returns the offset of the first non-space at or after pos
+func skipYAMLSpace(data []byte, pos, end int) int {
+	for pos < end && isYAMLSpace(data[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// isFlowIndicator reports whether b ends an entry of a flow collection
+func isFlowIndicator(b byte) bool {
+	return b == ',' || b == '[' || b == ']' || b == '{' || b == '}'
+}
+
+// isYAMLSpace reports whether b separates tokens on a line
+func isYAMLSpace(b byte) bool {
+	return b == ' ' || b == '\t'
+}
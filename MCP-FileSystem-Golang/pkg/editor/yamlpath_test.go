@@ -0,0 +1,285 @@
+package editor
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testYAMLConfig = `# Demo service
+name: demo
+servers:
+  - host: a.example
+    port: 80 # plain HTTP
+  - host: b.example
+    port: 81
+tags: [web, "edge, public"]
+limits:
+  cpu: 2
+motd: |
+  Welcome
+  to demo
+"dotted.key": true
+`
+
+// writeYAMLConfig creates an edit manager and a YAML file to edit
+func writeYAMLConfig(t *testing.T) (*EditManager, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	em, err := NewEditManager(filepath.Join(tmpDir, "backups"))
+	if err != nil {
+		t.Fatalf("Failed to create edit manager: %v", err)
+	}
+
+	testFile := filepath.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(testFile, []byte(testYAMLConfig), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	return em, testFile
+}
+
+func TestReadYAMLPath(t *testing.T) {
+	_, testFile := writeYAMLConfig(t)
+
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"$.name", `"demo"`},
+		{"servers[1].port", "81"},
+		{"$.servers[0].port", "80"},
+		{"$.servers[1]", "{\n  \"host\": \"b.example\",\n  \"port\": 81\n}"},
+		{"$.limits", "{\n  \"cpu\": 2\n}"},
+		{"$.tags", "[\n  \"web\",\n  \"edge, public\"\n]"},
+		{"$.tags[1]", `"edge, public"`},
+		{"$.motd", `"Welcome\nto demo\n"`},
+		{`$["dotted.key"]`, "true"},
+	}
+	for _, tt := range tests {
+		value, err := ReadJSONPath(testFile, tt.expression)
+		if err != nil {
+			t.Errorf("ReadJSONPath(%q) failed: %v", tt.expression, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.expression, value)
+		}
+	}
+
+	for _, expression := range []string{"$.missing", "$.servers[5]", "$.name.first", "$.servers.host", "$.limits[0]"} {
+		if _, err := ReadJSONPath(testFile, expression); err == nil {
+			t.Errorf("Expected an error for %q", expression)
+		}
+	}
+
+	_, err := ReadJSONPath(testFile, "$.servers[0].user")
+	if err == nil || !strings.Contains(err.Error(), "$.servers[0].user not found") {
+		t.Errorf("Expected an error naming the missing key, got %v", err)
+	}
+}
+
+func TestSetYAMLPath(t *testing.T) {
+	em, testFile := writeYAMLConfig(t)
+
+	changes := []struct {
+		expression string
+		value      string
+	}{
+		{"$.servers[1].port", `8081`},
+		{"$.servers[0].port", `8080`},
+		{"$.limits", `{ "cpu": 4 }`},
+		{"$.tags[0]", `"www"`},
+		{"$.tags[2]", `"internal"`},
+		{"$.servers[1].tls", `true`},
+		{"$.timeout", `30`},
+	}
+	for _, change := range changes {
+		err := em.SetJSONPath(testFile, change.expression, json.RawMessage(change.value))
+		if change.expression == "$.tags[2]" {
+			if err == nil {
+				t.Error("Expected an error setting an index past the end of a sequence")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("SetJSONPath(%q) failed: %v", change.expression, err)
+		}
+	}
+
+	content, _ := os.ReadFile(testFile)
+	expected := `# Demo service
+name: demo
+servers:
+  - host: a.example
+    port: 8080 # plain HTTP
+  - host: b.example
+    port: 8081
+    tls: true
+tags: ["www", "edge, public"]
+limits: {"cpu":4}
+motd: |
+  Welcome
+  to demo
+"dotted.key": true
+timeout: 30
+`
+	if string(content) != expected {
+		t.Errorf("Expected content:\n%s\ngot:\n%s", expected, string(content))
+	}
+
+	// The written values read back, including inside the new flow mapping
+	if value, err := ReadJSONPath(testFile, "$.limits.cpu"); err != nil || value != "4" {
+		t.Errorf("Expected the new limit to read back as 4, got %q (%v)", value, err)
+	}
+
+	// Each change can be undone
+	for i := 0; i < 6; i++ {
+		if err := em.UndoEdit(testFile); err != nil {
+			t.Fatalf("UndoEdit failed: %v", err)
+		}
+	}
+	content, _ = os.ReadFile(testFile)
+	if string(content) != testYAMLConfig {
+		t.Errorf("Expected undo to restore the original content, got:\n%s", string(content))
+	}
+}
+
+func TestSetYAMLPathBlockValues(t *testing.T) {
+	em, _ := writeYAMLConfig(t)
+	testFile := filepath.Join(t.TempDir(), "ci.yml")
+	original := "jobs:\n  build:\n    steps:\n    - run: make\n    env:\n  test: {}\n"
+	if err := os.WriteFile(testFile, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if err := em.SetJSONPath(testFile, "$.jobs.build.steps", json.RawMessage(`[{"run": "make all"}]`)); err != nil {
+		t.Fatalf("SetJSONPath replacing a block sequence failed: %v", err)
+	}
+	if err := em.SetJSONPath(testFile, "$.jobs.build.env", json.RawMessage(`{"CI": "1"}`)); err != nil {
+		t.Fatalf("SetJSONPath setting an empty value failed: %v", err)
+	}
+	if err := em.SetJSONPath(testFile, "$.jobs.test.name", json.RawMessage(`"unit tests"`)); err != nil {
+		t.Fatalf("SetJSONPath adding to a flow mapping failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(testFile)
+	expected := "jobs:\n  build:\n    steps: [{\"run\":\"make all\"}]\n    env: {\"CI\":\"1\"}\n  test: {name: \"unit tests\"}\n"
+	if string(content) != expected {
+		t.Errorf("Expected content:\n%s\ngot:\n%s", expected, string(content))
+	}
+
+	// Added keys keep the file's line endings
+	if err := os.WriteFile(testFile, []byte("a: 1\r\nb:\r\n  c: 2\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := em.SetJSONPath(testFile, "$.b.d", json.RawMessage(`3`)); err != nil {
+		t.Fatalf("SetJSONPath failed: %v", err)
+	}
+	content, _ = os.ReadFile(testFile)
+	if string(content) != "a: 1\r\nb:\r\n  c: 2\r\n  d: 3\r\n" {
+		t.Errorf("Expected the new key on its own CRLF line, got %q", string(content))
+	}
+}
+
+func TestReadYAMLPathScalarTypes(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "types.yml")
+	content := "nothing:\ntilde: ~\nyes: True\nint: +42\nbig: 123456789012345678901234567890\n" +
+		"octal: 0o17\nhex: 0xff\nfloat: 1.5e3\ntext: 1.2.3\nurl: http://example.com/a#b\n" +
+		"version: \"1.10\"\nsingle: 'it''s'\nhtml: <b>&</b>\n" +
+		"literal: |-\n  one\n    two\nkeep: |+\n  kept\n\n" +
+		"folded: >\n  a\n  b\n\n  c\n    d\n  e\nindented: |2\n    x\n"
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := map[string]string{
+		"nothing":  "null",
+		"tilde":    "null",
+		"yes":      "true",
+		"int":      "42",
+		"big":      "123456789012345678901234567890",
+		"octal":    "15",
+		"hex":      "255",
+		"float":    "1500",
+		"text":     `"1.2.3"`,
+		"url":      `"http://example.com/a#b"`,
+		"version":  `"1.10"`,
+		"single":   `"it's"`,
+		"html":     `"<b>&</b>"`,
+		"literal":  `"one\n  two"`,
+		"keep":     `"kept\n\n"`,
+		"folded":   `"a b\nc\n  d\ne\n"`,
+		"indented": `"  x\n"`,
+	}
+	for key, expected := range tests {
+		value, err := ReadJSONPath(testFile, "$."+key)
+		if err != nil {
+			t.Errorf("ReadJSONPath(%q) failed: %v", key, err)
+			continue
+		}
+		if value != expected {
+			t.Errorf("Expected %s for %q, got %s", expected, key, value)
+		}
+	}
+
+	// Values JSON can't hold are reported rather than changed
+	if err := os.WriteFile(testFile, []byte("limit: .inf\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if _, err := ReadJSONPath(testFile, "$.limit"); err == nil || !strings.Contains(err.Error(), ".inf can't be represented in JSON") {
+		t.Errorf("Expected an error for an infinite value, got %v", err)
+	}
+}
+
+func TestYAMLPathRejectsUnsupportedYAML(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"anchor", "base: &base\n  a: 1\n", "anchors, aliases and tags are not supported"},
+		{"alias", "a: *base\n", "anchors, aliases and tags are not supported"},
+		{"tag", "a: !!str 1\n", "anchors, aliases and tags are not supported"},
+		{"tagged key", "!!str a: 1\n", "complex keys, anchors, aliases and tags are not supported"},
+		{"complex key", "? a\n: 1\n", "line 1: complex keys are not supported"},
+		{"nested complex key", "a:\n  ? b\n  : 1\n", "line 2: complex keys are not supported"},
+		{"documents", "a: 1\n---\nb: 2\n", "line 2: files with several documents are not supported"},
+		{"document end", "a: 1\n...\n", "line 2: files with several documents are not supported"},
+		{"directive", "%YAML 1.2\n---\na: 1\n", "line 1: directives are not supported"},
+		{"tabs", "a:\n\tb: 1\n", "line 2: tabs can't be used for indentation"},
+		{"multi-line quoted", "a: \"one\n  two\"\n", "quoted scalars must close on the line they start"},
+		{"multi-line single quoted", "a: 'one\n  two'\n", "quoted scalars must close on the line they start"},
+		{"multi-line plain", "a: one\n  two\n", "line 2: unexpected indentation; multi-line plain scalars are not supported"},
+		{"multi-line plain item", "- one\n  two\n", "line 2: unexpected indentation"},
+		{"multi-line flow", "a: [1,\n  2]\n", "flow collections must close on the line they start"},
+		{"nested value", "a: b: c\n", "line 1: mapping values are not allowed here"},
+		{"trailing colon", "a: b:\n", "line 1: mapping values are not allowed here"},
+		{"nested flow value", "a: {b: c: d}\n", "line 1: mapping values are not allowed here"},
+		{"sequence value", "a: - b\n", "a plain value can't start with '-'"},
+		{"reserved indicator", "a: @b\n", "a plain value can't start with '@'"},
+		{"block scalar header", "a: |x\n  b\n", "unexpected text after a block scalar indicator"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".yaml")
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		_, err := ReadJSONPath(path, "$.a")
+		if err == nil || !strings.Contains(err.Error(), tt.message) {
+			t.Errorf("Expected %s to be refused with %q, got %v", tt.name, tt.message, err)
+		}
+	}
+
+	// Quoting makes the same text a plain string
+	path := filepath.Join(dir, "quoted.yaml")
+	if err := os.WriteFile(path, []byte("a: \"b: c\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if value, err := ReadJSONPath(path, "$.a"); err != nil || value != `"b: c"` {
+		t.Errorf("Expected the quoted value to read back, got %q (%v)", value, err)
+	}
+}
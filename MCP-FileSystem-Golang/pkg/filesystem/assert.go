@@ -0,0 +1,196 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// Checks assert_file_content can make
+const (
+	AssertExpectedContent = "expected_content"
+	AssertExpectedHash    = "expected_hash"
+	AssertContains        = "contains"
+)
+
+// FileAssertion is what a file is checked against; exactly one of its
+// fields is set
+type FileAssertion struct {
+	ExpectedContent *string
+	ExpectedHash    string
+	Contains        *string
+}
+
+// AssertResult reports whether a file matched an assertion
+type AssertResult struct {
+	Path   string `json:"path"`
+	Check  string `json:"check"`
+	Match  bool   `json:"match"`
+	Exists bool   `json:"exists"`
+	// ActualHash is the SHA-256 hash of the file when checking a hash
+	ActualHash string `json:"actualHash,omitempty"`
+	// Diff is a unified diff from the expected to the actual content when
+	// expected_content doesn't match
+	Diff string `json:"diff,omitempty"`
+	// Message explains a mismatch that a diff can't show
+	Message string `json:"message,omitempty"`
+}
+
+// AssertFileContent checks a file against expected content, a SHA-256
+// hash, or a substring it must contain. A file that doesn't exist is
+// reported as a mismatch rather than an error.
+func (fm *FileManager) AssertFileContent(path string, assertion FileAssertion) (AssertResult, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return AssertResult{}, err
+	}
+
+	result := AssertResult{Path: validPath, Check: assertion.check()}
+
+	content, err := os.ReadFile(validPath)
+	if errors.Is(err, os.ErrNotExist) {
+		result.Message = fmt.Sprintf("file %s does not exist", path)
+		return result, nil
+	}
+	if err != nil {
+		return AssertResult{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	result.Exists = true
+
+	switch result.Check {
+	case AssertExpectedHash:
+		sum := sha256.Sum256(content)
+		result.ActualHash = hex.EncodeToString(sum[:])
+		result.Match = result.ActualHash == normalizeHash(assertion.ExpectedHash)
+		if !result.Match {
+			result.Message = fmt.Sprintf("expected SHA-256 %s, got %s", normalizeHash(assertion.ExpectedHash), result.ActualHash)
+		}
+
+	case AssertContains:
+		result.Match = strings.Contains(string(content), *assertion.Contains)
+		if !result.Match {
+			result.Message = fmt.Sprintf("file does not contain %q", *assertion.Contains)
+		}
+
+	default:
+		expected := *assertion.ExpectedContent
+		result.Match = string(content) == expected
+		if result.Match {
+			break
+		}
+		sample := content
+		if len(sample) > binarySniffLen {
+			sample = sample[:binarySniffLen]
+		}
+		if IsBinary(sample) || IsBinary([]byte(expected)) {
+			result.Message = fmt.Sprintf("binary content differs (expected %d bytes, got %d)", len(expected), len(content))
+			break
+		}
+		result.Diff = unifiedDiff("expected", path, expected, string(content))
+	}
+
+	return result, nil
+}
+
+// check names the check an assertion makes
+func (a FileAssertion) check() string {
+	switch {
+	case a.ExpectedContent != nil:
+		return AssertExpectedContent
+	case a.ExpectedHash != "":
+		return AssertExpectedHash
+	default:
+		return AssertContains
+	}
+}
+
+// normalizeHash lowercases a hex hash and drops an optional "sha256:" prefix
+func normalizeHash(hash string) string {
+	hash = strings.ToLower(strings.TrimSpace(hash))
+	return strings.TrimPrefix(hash, "sha256:")
+}
+
+// String formats the result as a PASS or FAIL line, followed by the diff
+// or reason for a mismatch
+func (r AssertResult) String() string {
+	if r.Match {
+		return fmt.Sprintf("PASS: %s matches %s", r.Path, strings.ReplaceAll(r.Check, "_", " "))
+	}
+
+	lines := []string{fmt.Sprintf("FAIL: %s does not match %s", r.Path, strings.ReplaceAll(r.Check, "_", " "))}
+	if r.Message != "" {
+		lines = append(lines, r.Message)
+	}
+	if r.Diff != "" {
+		lines = append(lines, "", r.Diff)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AssertFileContentSchema defines the input schema for assert_file_content
+var AssertFileContentSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"expected_content": map[string]interface{}{
+			"type":        "string",
+			"description": "Exact content the file should have; a unified diff is returned when it differs",
+		},
+		"expected_hash": map[string]interface{}{
+			"type":        "string",
+			"description": "Hex SHA-256 hash the file content should have, optionally prefixed with sha256:",
+		},
+		"contains": map[string]interface{}{
+			"type":        "string",
+			"description": "Text the file should contain",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseAssertFileContentArgs parses arguments for assert_file_content
+func ParseAssertFileContentArgs(args json.RawMessage) (string, FileAssertion, error) {
+	var params struct {
+		Path            string  `json:"path"`
+		ExpectedContent *string `json:"expected_content"`
+		ExpectedHash    *string `json:"expected_hash"`
+		Contains        *string `json:"contains"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", FileAssertion{}, fmt.Errorf("invalid arguments for assert_file_content: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", FileAssertion{}, fmt.Errorf("path parameter is required")
+	}
+
+	given := 0
+	for _, set := range []bool{params.ExpectedContent != nil, params.ExpectedHash != nil, params.Contains != nil} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return "", FileAssertion{}, fmt.Errorf("exactly one of expected_content, expected_hash or contains is required")
+	}
+
+	assertion := FileAssertion{ExpectedContent: params.ExpectedContent, Contains: params.Contains}
+	if params.ExpectedHash != nil {
+		hash := normalizeHash(*params.ExpectedHash)
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != sha256.Size {
+			return "", FileAssertion{}, fmt.Errorf("expected_hash must be a hex SHA-256 hash")
+		}
+		assertion.ExpectedHash = hash
+	}
+
+	return params.Path, assertion, nil
+}
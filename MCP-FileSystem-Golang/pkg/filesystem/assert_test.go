@@ -0,0 +1,106 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssertFileContent(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	path := filepath.Join(dir, "config.txt")
+	if err := os.WriteFile(path, []byte("name: app\nport: 8080\ndebug: false\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	expected := "name: app\nport: 8080\ndebug: false\n"
+	result, err := fm.AssertFileContent(path, FileAssertion{ExpectedContent: &expected})
+	if err != nil {
+		t.Fatalf("AssertFileContent failed: %v", err)
+	}
+	if !result.Match || result.Diff != "" || !strings.HasPrefix(result.String(), "PASS:") {
+		t.Errorf("Expected a match, got %+v", result)
+	}
+
+	expected = "name: app\nport: 9090\ndebug: false\n"
+	result, err = fm.AssertFileContent(path, FileAssertion{ExpectedContent: &expected})
+	if err != nil {
+		t.Fatalf("AssertFileContent failed: %v", err)
+	}
+	expectedDiff := "--- expected\n+++ " + path + "\n@@ -1,3 +1,3 @@\n name: app\n-port: 9090\n+port: 8080\n debug: false"
+	if result.Match || result.Diff != expectedDiff {
+		t.Errorf("Expected a mismatch with diff:\n%s\ngot %+v", expectedDiff, result)
+	}
+	if !strings.HasPrefix(result.String(), "FAIL:") || !strings.Contains(result.String(), "+port: 8080") {
+		t.Errorf("Expected a FAIL report with the diff, got:\n%s", result)
+	}
+
+	hash := "sha256:" + strings.Repeat("0", 64)
+	result, err = fm.AssertFileContent(path, FileAssertion{ExpectedHash: normalizeHash(hash)})
+	if err != nil {
+		t.Fatalf("AssertFileContent failed: %v", err)
+	}
+	if result.Match || len(result.ActualHash) != 64 {
+		t.Errorf("Expected a hash mismatch reporting the actual hash, got %+v", result)
+	}
+	result, err = fm.AssertFileContent(path, FileAssertion{ExpectedHash: result.ActualHash})
+	if err != nil || !result.Match {
+		t.Errorf("Expected the actual hash to match, got %+v and %v", result, err)
+	}
+
+	for substring, match := range map[string]bool{"port: 8080": true, "port: 9090": false} {
+		substring := substring
+		result, err = fm.AssertFileContent(path, FileAssertion{Contains: &substring})
+		if err != nil || result.Match != match {
+			t.Errorf("Expected contains %q to match %v, got %+v and %v", substring, match, result, err)
+		}
+	}
+}
+
+func TestAssertFileContentMissingFile(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	expected := "anything"
+	result, err := fm.AssertFileContent(filepath.Join(dir, "missing.txt"), FileAssertion{ExpectedContent: &expected})
+	if err != nil {
+		t.Fatalf("Expected a missing file to be a failed assertion, got error %v", err)
+	}
+	if result.Match || result.Exists || !strings.Contains(result.String(), "does not exist") {
+		t.Errorf("Expected a failure reporting the missing file, got %+v", result)
+	}
+}
+
+func TestParseAssertFileContentArgs(t *testing.T) {
+	if _, assertion, err := ParseAssertFileContentArgs(json.RawMessage(`{"path": "a.txt", "expected_content": ""}`)); err != nil || assertion.ExpectedContent == nil {
+		t.Errorf("Expected empty expected_content to be accepted, got %+v and %v", assertion, err)
+	}
+
+	for _, args := range []string{
+		`{"path": "a.txt"}`,
+		`{"path": "a.txt", "expected_content": "x", "contains": "x"}`,
+		`{"path": "a.txt", "expected_hash": "abc"}`,
+	} {
+		if _, _, err := ParseAssertFileContentArgs(json.RawMessage(args)); err == nil {
+			t.Errorf("Expected an error for %s", args)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	from := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+	to := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17"
+
+	expected := "--- a\n+++ b\n" +
+		"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+		"@@ -14,3 +14,4 @@\n 14\n 15\n 16\n+17\n\\ No newline at end of file"
+	if diff := unifiedDiff("a", "b", from, to); diff != expected {
+		t.Errorf("Expected diff:\n%s\ngot:\n%s", expected, diff)
+	}
+
+	if diff := unifiedDiff("a", "b", from, from); diff != "" {
+		t.Errorf("Expected no diff for equal content, got:\n%s", diff)
+	}
+}
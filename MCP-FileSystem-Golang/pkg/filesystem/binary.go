@@ -0,0 +1,52 @@
+package filesystem
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// binarySniffLen is how many leading bytes of a file are sampled to decide whether it is binary
+const binarySniffLen = 8000
+
+// maxNonPrintableRatio is the fraction of non-printable bytes above which a sample is binary
+const maxNonPrintableRatio = 0.3
+
+// IsBinary reports whether a sample of file content looks binary rather than
+// text. Samples with a UTF-8 or UTF-16 byte order mark are text; otherwise a
+// NUL byte, or a high proportion of control characters and invalid UTF-8,
+// marks the sample as binary. An empty sample is text.
+func IsBinary(sample []byte) bool {
+	if len(sample) == 0 {
+		return false
+	}
+
+	// UTF-16 text is full of NUL bytes, so trust an explicit byte order mark
+	if bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}) ||
+		bytes.HasPrefix(sample, []byte{0xFF, 0xFE}) ||
+		bytes.HasPrefix(sample, []byte{0xFE, 0xFF}) {
+		return false
+	}
+
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+
+	nonPrintable := 0
+	for i := 0; i < len(sample); {
+		r, size := utf8.DecodeRune(sample[i:])
+		if r == utf8.RuneError && size == 1 {
+			// A multi-byte sequence cut off at the end of the sample isn't evidence of binary data
+			if !utf8.FullRune(sample[i:]) {
+				break
+			}
+			nonPrintable++
+		} else if r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f' && r != '\v' && r != 0x1b {
+			nonPrintable++
+		} else if r == 0x7f {
+			nonPrintable++
+		}
+		i += size
+	}
+
+	return float64(nonPrintable)/float64(len(sample)) > maxNonPrintableRatio
+}
@@ -0,0 +1,43 @@
+package filesystem
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16LE encodes a string as UTF-16LE with a byte order mark
+func encodeUTF16LE(s string) []byte {
+	out := []byte{0xFF, 0xFE}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(unit), byte(unit>>8))
+	}
+	return out
+}
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		sample   []byte
+		expected bool
+	}{
+		{"empty", []byte{}, false},
+		{"ascii", []byte("package main\n\nfunc main() {}\n"), false},
+		{"utf8 multibyte", []byte("héllo wörld — 你好 🌍\n"), false},
+		{"utf8 with bom", append([]byte{0xEF, 0xBB, 0xBF}, "text"...), false},
+		{"ansi colors", []byte("\x1b[31mred\x1b[0m\n"), false},
+		{"utf16le with bom", encodeUTF16LE("hello world\r\n"), false},
+		{"utf16be with bom", []byte{0xFE, 0xFF, 0x00, 'h', 0x00, 'i'}, false},
+		{"truncated utf8 at end", []byte("caf\xc3"), false},
+		{"nul byte", []byte("text\x00more text"), true},
+		{"elf header", []byte{0x7f, 'E', 'L', 'F', 0x02, 0x01, 0x01, 0x00}, true},
+		{"png header", []byte{0x89, 'P', 'N', 'G', 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00}, true},
+		{"control characters", []byte{0x01, 0x02, 0x03, 0x04, 'a', 0x05, 0x06}, true},
+		{"invalid utf8", []byte{0x80, 0x81, 0xfd, 0xfc, 0xff, 0xc0, 'a'}, true},
+	}
+
+	for _, tt := range tests {
+		if got := IsBinary(tt.sample); got != tt.expected {
+			t.Errorf("IsBinary(%s) = %t, expected %t", tt.name, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// CopyFile copies a file, keeping its permission bits and modification
+// time. The content is streamed into a temporary file beside the
+// destination and renamed into place, so a failed copy never leaves a
+// partial file. An existing destination is an error unless overwrite is set.
+func (fm *FileManager) CopyFile(source, destination string, overwrite bool) error {
+	validSource, err := fm.ValidatePath(source)
+	if err != nil {
+		return err
+	}
+
+	validDest, err := fm.ValidatePath(destination)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(validSource)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("source file does not exist: %s", source)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a file", source)
+	}
+
+	if err := fm.checkFreeSpace(validDest, info.Size()); err != nil {
+		return err
+	}
+
+	unlock := fm.locks.LockAll(validSource, validDest)
+	defer unlock()
+
+	destInfo, err := os.Stat(validDest)
+	switch {
+	case err == nil && destInfo.IsDir():
+		return fmt.Errorf("destination %s is a directory", destination)
+	case err == nil && os.SameFile(info, destInfo):
+		return fmt.Errorf("source and destination are the same file")
+	case err == nil && !overwrite:
+		return fmt.Errorf("destination already exists: %s; set overwrite to replace it", destination)
+	case err != nil && !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	err = fm.withRetry(func() error {
+		return copyFileContent(validSource, validDest, info)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+
+	return nil
+}
+
+// copyFileContent streams source into a temporary file in the destination's
+// directory, applies the source's mode and modification time, and renames
+// it over destination
+func copyFileContent(source, destination string, info os.FileInfo) (err error) {
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	temp, err := os.CreateTemp(filepath.Dir(destination), "."+filepath.Base(destination)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tempPath := temp.Name()
+	defer func() {
+		if err != nil {
+			temp.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	if _, err = io.Copy(temp, in); err != nil {
+		return err
+	}
+	if err = temp.Sync(); err != nil {
+		return err
+	}
+	if err = temp.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chtimes(tempPath, info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, destination)
+}
+
+// CopyFileSchema defines the schema for copy_file tool input
+var CopyFileSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"source": map[string]interface{}{
+			"type": "string",
+		},
+		"destination": map[string]interface{}{
+			"type": "string",
+		},
+		"overwrite": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Replace the destination if it already exists (default false)",
+			"default":     false,
+		},
+	},
+	"required": []string{"source", "destination"},
+}
+
+// ParseCopyFileArgs parses arguments for copy_file
+func ParseCopyFileArgs(args json.RawMessage) (string, string, bool, error) {
+	var params struct {
+		Source      string `json:"source"`
+		Destination string `json:"destination"`
+		Overwrite   bool   `json:"overwrite"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", false, fmt.Errorf("invalid arguments for copy_file: %w", err)
+	}
+
+	if params.Source == "" || params.Destination == "" {
+		return "", "", false, fmt.Errorf("source and destination parameters are required")
+	}
+
+	return params.Source, params.Destination, params.Overwrite, nil
+}
@@ -0,0 +1,80 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCopyFile(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	source := filepath.Join(dir, "source.sh")
+	if err := os.WriteFile(source, []byte("#!/bin/sh\necho hi\n"), 0755); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	modTime := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC)
+	if err := os.Chtimes(source, modTime, modTime); err != nil {
+		t.Fatalf("Failed to set modification time: %v", err)
+	}
+
+	destination := filepath.Join(dir, "copy.sh")
+	if err := fm.CopyFile(source, destination, false); err != nil {
+		t.Fatalf("CopyFile failed: %v", err)
+	}
+
+	content, err := os.ReadFile(destination)
+	if err != nil || string(content) != "#!/bin/sh\necho hi\n" {
+		t.Errorf("Expected the copied content, got %q and %v", string(content), err)
+	}
+	info, err := os.Stat(destination)
+	if err != nil {
+		t.Fatalf("Failed to stat copy: %v", err)
+	}
+	if !info.ModTime().Equal(modTime) {
+		t.Errorf("Expected modification time %v, got %v", modTime, info.ModTime())
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Errorf("Expected mode 0755, got %o", info.Mode().Perm())
+	}
+}
+
+func TestCopyFileExistingDestination(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	source := filepath.Join(dir, "source.txt")
+	destination := filepath.Join(dir, "destination.txt")
+	if err := os.WriteFile(source, []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(destination, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := fm.CopyFile(source, destination, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected an existing destination to be refused, got %v", err)
+	}
+	if content, _ := os.ReadFile(destination); string(content) != "old" {
+		t.Errorf("Expected the destination to be unchanged, got %q", string(content))
+	}
+
+	if err := fm.CopyFile(source, destination, true); err != nil {
+		t.Fatalf("CopyFile with overwrite failed: %v", err)
+	}
+	if content, _ := os.ReadFile(destination); string(content) != "new" {
+		t.Errorf("Expected the destination to be replaced, got %q", string(content))
+	}
+
+	if err := fm.CopyFile(source, source, true); err == nil {
+		t.Error("Expected copying a file onto itself to be refused")
+	}
+	if err := fm.CopyFile(dir, filepath.Join(dir, "dircopy"), false); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("Expected a directory source to be refused, got %v", err)
+	}
+	if err := fm.CopyFile(filepath.Join(dir, "missing.txt"), destination, true); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing source to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// DirectorySize holds the total size of the files below a directory
+type DirectorySize struct {
+	Path        string `json:"path"`
+	Bytes       int64  `json:"bytes"`
+	Files       int64  `json:"files"`
+	Directories int64  `json:"directories"`
+	Skipped     int64  `json:"skipped"` // entries that couldn't be read
+}
+
+// DirectorySize walks the tree below path and totals the size of its
+// regular files. Symlinks are not followed, so a link out of the allowed
+// directories never counts towards the total; only the files actually
+// below path do. Entries that can't be read are counted as skipped.
+func (fm *FileManager) DirectorySize(path string) (DirectorySize, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return DirectorySize{}, err
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return DirectorySize{}, fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return DirectorySize{}, fmt.Errorf("path is not a directory: %s", validPath)
+	}
+
+	size := DirectorySize{Path: validPath}
+	err = filepath.WalkDir(validPath, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if walkPath == validPath {
+				return err
+			}
+			// Skip unreadable entries and keep walking
+			size.Skipped++
+			return nil
+		}
+		if walkPath == validPath {
+			return nil
+		}
+
+		if d.IsDir() {
+			size.Directories++
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		fileInfo, err := d.Info()
+		if err != nil {
+			size.Skipped++
+			return nil
+		}
+		size.Files++
+		size.Bytes += fileInfo.Size()
+		return nil
+	})
+	if err != nil {
+		return DirectorySize{}, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return size, nil
+}
+
+// String formats the size in human-readable units with the exact counts
+func (s DirectorySize) String() string {
+	text := fmt.Sprintf("%s: %s (%d bytes) in %d files and %d directories",
+		s.Path, formatBytes(uint64(s.Bytes)), s.Bytes, s.Files, s.Directories)
+	if s.Skipped > 0 {
+		text += fmt.Sprintf("; %d entries couldn't be read", s.Skipped)
+	}
+	return text
+}
+
+// GetDirectorySizeSchema defines the input schema for get_directory_size
+var GetDirectorySizeSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseGetDirectorySizeArgs parses arguments for get_directory_size
+func ParseGetDirectorySizeArgs(args json.RawMessage) (string, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", fmt.Errorf("invalid arguments for get_directory_size: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", fmt.Errorf("path parameter is required")
+	}
+
+	return params.Path, nil
+}
@@ -0,0 +1,45 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectorySize(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	for name, size := range map[string]int{"a.txt": 1000, "sub/b.txt": 2000, "sub/deeper/c.bin": 48} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// A symlink to a large file outside the allowed directory must not count
+	outside := filepath.Join(t.TempDir(), "big")
+	if err := os.WriteFile(outside, make([]byte, 1<<20), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	size, err := fm.DirectorySize(dir)
+	if err != nil {
+		t.Fatalf("DirectorySize failed: %v", err)
+	}
+	if size.Bytes != 3048 || size.Files != 3 || size.Directories != 2 {
+		t.Errorf("Expected 3048 bytes in 3 files and 2 directories, got %+v", size)
+	}
+	if !strings.Contains(size.String(), "3.0 KiB (3048 bytes) in 3 files and 2 directories") {
+		t.Errorf("Expected a human-readable size, got %q", size.String())
+	}
+
+	if _, err := fm.DirectorySize(filepath.Join(dir, "a.txt")); err == nil {
+		t.Error("Expected a file to be rejected")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package filesystem
+
+// diskFreeBytes is not supported on this platform
+func diskFreeBytes(path string) (uint64, error) {
+	return 0, errFreeSpaceUnsupported
+}
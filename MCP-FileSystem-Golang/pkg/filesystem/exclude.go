@@ -0,0 +1,68 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// excludeSchema describes the exclude argument of the search tools
+var excludeSchema = map[string]interface{}{
+	"type": "array",
+	"items": map[string]interface{}{
+		"type": "string",
+	},
+	"description": "Glob patterns of files and directories to skip, e.g. [\"node_modules\", \"*.min.js\", \"build/**\"]. " +
+		"A pattern without '/' matches names at any depth; one with '/' matches paths relative to the search path. " +
+		"Excluded directories are not descended into",
+}
+
+// walkExcluder decides which entries a search walk skips. Patterns without
+// a '/' match an entry's name at any depth, like node_modules or *.log;
+// patterns with one match the path relative to the walk's root, with '**'
+// matching any number of segments.
+type walkExcluder struct {
+	names [][]string // single-segment patterns
+	paths [][]string // multi-segment patterns
+}
+
+// newWalkExcluder validates the exclude patterns and returns an excluder
+// for them, or nil if there are none
+func newWalkExcluder(patterns []string) (*walkExcluder, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	excluder := &walkExcluder{}
+	for _, pattern := range patterns {
+		if err := validateGlobPattern(pattern); err != nil {
+			return nil, err
+		}
+		segments := strings.Split(strings.Trim(pattern, "/"), "/")
+		if len(segments) == 1 {
+			excluder.names = append(excluder.names, segments)
+		} else {
+			excluder.paths = append(excluder.paths, segments)
+		}
+	}
+	return excluder, nil
+}
+
+// excluded reports whether the entry at path, below root, should be skipped.
+// A nil excluder skips nothing, and the root itself is never skipped.
+func (e *walkExcluder) excluded(root, path string, isDir bool) bool {
+	if e == nil || path == root {
+		return false
+	}
+
+	if isGlobExcluded(e.names, []string{filepath.Base(path)}, isDir) {
+		return true
+	}
+	if len(e.paths) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return isGlobExcluded(e.paths, strings.Split(filepath.ToSlash(rel), "/"), isDir)
+}
@@ -0,0 +1,67 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSearchExclude(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	for _, name := range []string{
+		"app.js",
+		"app.min.js",
+		"node_modules/lib/index.js",
+		"src/node_modules/dep.js",
+		"build/out/app.js",
+		"src/build/keep.js",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("needle\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// Names are excluded at any depth; paths only relative to the search root
+	exclude := []string{"node_modules", "*.min.js", "build/**"}
+	want := "app.js,src/build/keep.js"
+
+	relative := func(paths []string) string {
+		for i, path := range paths {
+			rel, _ := filepath.Rel(dir, path)
+			paths[i] = filepath.ToSlash(rel)
+		}
+		sort.Strings(paths)
+		return strings.Join(paths, ",")
+	}
+
+	results, err := SearchFiles(fm, dir, "*.js", SearchQuery{Mode: SearchModeGlob, Exclude: exclude})
+	if err != nil {
+		t.Fatalf("SearchFiles failed: %v", err)
+	}
+	if got := relative(results); got != want {
+		t.Errorf("Expected search_files to find %s, got %s", want, got)
+	}
+
+	grep, err := GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{Exclude: exclude})
+	if err != nil {
+		t.Fatalf("GrepFiles failed: %v", err)
+	}
+	var matched []string
+	for _, match := range grep.Matches {
+		matched = append(matched, match.Path)
+	}
+	if got := relative(matched); got != want {
+		t.Errorf("Expected grep_files to find %s, got %s", want, got)
+	}
+
+	if _, err := SearchFiles(fm, dir, "x", SearchQuery{Exclude: []string{"[a-"}}); err == nil {
+		t.Error("Expected an invalid exclude pattern to be reported")
+	}
+}
@@ -0,0 +1,135 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// PathExplanation describes how a path was checked against the allowed
+// directories, for debugging why it was allowed or denied
+type PathExplanation struct {
+	Path string `json:"path"`
+	// AbsolutePath is the path after expanding ~ and resolving it against
+	// the working directory, before following symlinks
+	AbsolutePath string `json:"absolutePath,omitempty"`
+	// ResolvedPath is the path with symlinks followed, which is what
+	// operations actually use
+	ResolvedPath string `json:"resolvedPath,omitempty"`
+	Exists       bool   `json:"exists"`
+	// MatchedDirectory is the most specific allowed directory containing
+	// the absolute path
+	MatchedDirectory string `json:"matchedDirectory,omitempty"`
+	// ResolvedMatchedDirectory is the most specific allowed directory
+	// containing the resolved path, when following symlinks changed it
+	ResolvedMatchedDirectory string `json:"resolvedMatchedDirectory,omitempty"`
+	Allowed                  bool   `json:"allowed"`
+	// Reason is why the path was denied
+	Reason string `json:"reason,omitempty"`
+}
+
+// ExplainPath validates a path as ValidatePath does and reports each step:
+// the absolute and resolved paths and which allowed directory each fell
+// under. It does extra work, so ValidatePath itself stays lean.
+func (fm *FileManager) ExplainPath(path string) PathExplanation {
+	explanation := PathExplanation{Path: path}
+
+	absolute, err := fm.absolutePath(path)
+	if err != nil {
+		explanation.Reason = err.Error()
+		return explanation
+	}
+	explanation.AbsolutePath = absolute
+	if index := fm.matchAllowedDirectory(normalizePath(absolute)); index >= 0 {
+		explanation.MatchedDirectory = fm.allowedRoots[index]
+	}
+
+	resolved, err := fm.ValidatePath(path)
+	if err != nil {
+		explanation.Reason = err.Error()
+		// Show where a symlink leads even when that is why it was denied
+		if real, evalErr := filepath.EvalSymlinks(absolute); evalErr == nil {
+			explanation.ResolvedPath = real
+			explanation.Exists = true
+		}
+		return explanation
+	}
+	explanation.Allowed = true
+	explanation.ResolvedPath = resolved
+
+	if _, err := os.Lstat(resolved); err == nil {
+		explanation.Exists = true
+	}
+	if resolved != absolute {
+		if index := fm.matchAllowedDirectory(normalizePath(resolved)); index >= 0 {
+			explanation.ResolvedMatchedDirectory = fm.allowedRoots[index]
+		}
+	}
+
+	return explanation
+}
+
+// String formats the explanation as "key: value" lines
+func (e PathExplanation) String() string {
+	lines := []string{"path: " + e.Path}
+	if e.AbsolutePath != "" {
+		lines = append(lines, "absolute path: "+e.AbsolutePath)
+	}
+	if e.ResolvedPath != "" {
+		lines = append(lines, "resolved path: "+e.ResolvedPath)
+	}
+	lines = append(lines, fmt.Sprintf("exists: %t", e.Exists))
+
+	matched := e.MatchedDirectory
+	if matched == "" {
+		matched = "none"
+	}
+	lines = append(lines, "matched allowed directory: "+matched)
+	if e.ResolvedMatchedDirectory != "" {
+		lines = append(lines, "resolved path matched allowed directory: "+e.ResolvedMatchedDirectory)
+	}
+
+	lines = append(lines, fmt.Sprintf("allowed: %t", e.Allowed))
+	if e.Reason != "" {
+		lines = append(lines, "reason: "+e.Reason)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ValidatePathSchema defines the schema for validate_path tool input
+var ValidatePathSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"verbose": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Report the absolute and resolved paths and which allowed directory each matched, rather than just the verdict (default false)",
+			"default":     false,
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseValidatePathArgs parses arguments for validate_path
+func ParseValidatePathArgs(args json.RawMessage) (string, bool, error) {
+	var params struct {
+		Path    string `json:"path"`
+		Verbose bool   `json:"verbose"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", false, fmt.Errorf("invalid arguments for validate_path: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", false, fmt.Errorf("path parameter is required")
+	}
+
+	return params.Path, params.Verbose, nil
+}
@@ -0,0 +1,62 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestExplainPathNestedAllowedDirectories(t *testing.T) {
+	_, dir := newTestFileManager(t)
+	inner := filepath.Join(dir, "projects", "inner")
+	if err := os.MkdirAll(inner, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	fm := NewFileManager([]string{dir, inner})
+
+	// The most specific allowed directory is reported
+	explanation := fm.ExplainPath(filepath.Join(inner, "notes.txt"))
+	if !explanation.Allowed || explanation.MatchedDirectory != inner {
+		t.Errorf("Expected the path to be allowed by %s, got %+v", inner, explanation)
+	}
+	if explanation.Exists {
+		t.Errorf("Expected a new file not to exist, got %+v", explanation)
+	}
+
+	explanation = fm.ExplainPath(filepath.Join(dir, "projects"))
+	if !explanation.Allowed || explanation.MatchedDirectory != dir || !explanation.Exists {
+		t.Errorf("Expected the path to be allowed by %s, got %+v", dir, explanation)
+	}
+	if !strings.Contains(explanation.String(), "matched allowed directory: "+dir) {
+		t.Errorf("Expected the text to name the matched directory, got %q", explanation.String())
+	}
+
+	explanation = fm.ExplainPath(filepath.Join(t.TempDir(), "outside.txt"))
+	if explanation.Allowed || explanation.MatchedDirectory != "" || !strings.Contains(explanation.Reason, "outside allowed directories") {
+		t.Errorf("Expected the path to be denied with no match, got %+v", explanation)
+	}
+}
+
+func TestExplainPathSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need Unix")
+	}
+	fm, dir := newTestFileManager(t)
+
+	outside := t.TempDir()
+	link := filepath.Join(dir, "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	// The link itself is inside, but where it leads isn't
+	explanation := fm.ExplainPath(link)
+	if explanation.Allowed || explanation.MatchedDirectory != dir {
+		t.Errorf("Expected a denial despite matching %s, got %+v", dir, explanation)
+	}
+	if real, _ := filepath.EvalSymlinks(outside); explanation.ResolvedPath != real {
+		t.Errorf("Expected resolved path %s, got %+v", real, explanation)
+	}
+}
@@ -0,0 +1,144 @@
+package filesystem
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// DefaultFileStatsMaxBytes is the most of a file file_stats scans when no limit is given
+const DefaultFileStatsMaxBytes = 64 * 1024 * 1024
+
+// FileStats holds wc-style counts for a file
+type FileStats struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	Lines     int64  `json:"lines"`
+	Words     int64  `json:"words"`
+	Bytes     int64  `json:"bytes"`
+	Chars     int64  `json:"chars"`
+	Binary    bool   `json:"binary"`
+	Truncated bool   `json:"truncated"`
+}
+
+// FileStats counts the lines, words, bytes and characters of a file in a
+// single streaming pass, like wc: lines counts newline characters, words
+// are runs of non-space characters, and characters are UTF-8 code points,
+// with each invalid byte counted as one. At most maxBytes are scanned;
+// Truncated is set and the counts cover only that prefix when the file is
+// longer. Binary files are reported with binary set and no counts.
+func (fm *FileManager) FileStats(path string, maxBytes int) (FileStats, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return FileStats{}, err
+	}
+
+	file, err := os.Open(validPath)
+	if err != nil {
+		return FileStats{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return FileStats{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.IsDir() {
+		return FileStats{}, fmt.Errorf("%s is a directory", path)
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = DefaultFileStatsMaxBytes
+	}
+
+	stats := FileStats{
+		Path:      validPath,
+		Size:      info.Size(),
+		Truncated: info.Size() > int64(maxBytes),
+	}
+
+	reader := bufio.NewReaderSize(io.LimitReader(file, int64(maxBytes)), 64*1024)
+
+	// Peek returns what it could read along with any error, which is all the sample needs
+	sample, _ := reader.Peek(binarySniffLen)
+	if IsBinary(sample) {
+		stats.Binary = true
+		return stats, nil
+	}
+
+	inWord := false
+	for {
+		r, size, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return FileStats{}, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		stats.Bytes += int64(size)
+		stats.Chars++
+		if r == '\n' {
+			stats.Lines++
+		}
+		if unicode.IsSpace(r) {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			stats.Words++
+		}
+	}
+
+	return stats, nil
+}
+
+// String formats the file stats as indented JSON
+func (s FileStats) String() string {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("failed to encode file stats: %v", err)
+	}
+	return string(data)
+}
+
+// FileStatsSchema defines the input schema for file_stats
+var FileStatsSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"max_bytes": map[string]interface{}{
+			"type":        "integer",
+			"description": "Maximum number of bytes to scan (default 67108864); counts for longer files cover only this prefix",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseFileStatsArgs parses arguments for file_stats
+func ParseFileStatsArgs(args json.RawMessage) (string, int, error) {
+	var params struct {
+		Path     string `json:"path"`
+		MaxBytes int    `json:"max_bytes"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, fmt.Errorf("invalid arguments for file_stats: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", 0, fmt.Errorf("path parameter is required")
+	}
+
+	if params.MaxBytes < 0 {
+		return "", 0, fmt.Errorf("max_bytes parameter must not be negative")
+	}
+
+	return params.Path, params.MaxBytes, nil
+}
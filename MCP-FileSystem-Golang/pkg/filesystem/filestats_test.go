@@ -0,0 +1,78 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStats(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	tests := []struct {
+		name    string
+		content string
+		lines   int64
+		words   int64
+		bytes   int64
+		chars   int64
+	}{
+		{"plain.txt", "hello world\nsecond line\n", 2, 4, 24, 24},
+		{"no-newline.txt", "one two  three", 0, 3, 14, 14},
+		{"utf8.txt", "café naïve\n", 1, 2, 13, 11},
+		{"empty.txt", "", 0, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		stats, err := fm.FileStats(path, 0)
+		if err != nil {
+			t.Fatalf("%s: FileStats failed: %v", tt.name, err)
+		}
+		if stats.Lines != tt.lines || stats.Words != tt.words || stats.Bytes != tt.bytes || stats.Chars != tt.chars {
+			t.Errorf("%s: Expected %d lines, %d words, %d bytes and %d chars, got %d, %d, %d and %d",
+				tt.name, tt.lines, tt.words, tt.bytes, tt.chars, stats.Lines, stats.Words, stats.Bytes, stats.Chars)
+		}
+		if stats.Binary || stats.Truncated {
+			t.Errorf("%s: Expected a complete text file, got binary %v and truncated %v", tt.name, stats.Binary, stats.Truncated)
+		}
+	}
+}
+
+func TestFileStatsMaxBytes(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	path := filepath.Join(dir, "long.txt")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	stats, err := fm.FileStats(path, 8)
+	if err != nil {
+		t.Fatalf("FileStats failed: %v", err)
+	}
+	if !stats.Truncated || stats.Size != 14 || stats.Bytes != 8 || stats.Lines != 2 {
+		t.Errorf("Expected the scan to stop after 8 of 14 bytes and 2 lines, got %+v", stats)
+	}
+}
+
+func TestFileStatsBinary(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	path := filepath.Join(dir, "program")
+	if err := os.WriteFile(path, []byte("\x7fELF\x02\x01\x01\x00\x00\x00\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	stats, err := fm.FileStats(path, 0)
+	if err != nil {
+		t.Fatalf("FileStats failed: %v", err)
+	}
+	if !stats.Binary || stats.Lines != 0 || stats.Chars != 0 {
+		t.Errorf("Expected a binary file with no counts, got %+v", stats)
+	}
+}
@@ -1,13 +1,28 @@
 package filesystem
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/atomicfile"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/flock"
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/pkg/pathlock"
 )
 
 // FileInfo represents metadata about a file
@@ -18,25 +33,135 @@ type FileInfo struct {
 	Accessed    time.Time `json:"accessed"`
 	IsDirectory bool      `json:"isDirectory"`
 	IsFile      bool      `json:"isFile"`
-	Permissions string    `json:"permissions"`
+	Permissions string    `json:"permissions"` // octal, e.g. 755
+	Mode        string    `json:"mode"`        // rwx form, e.g. -rwxr-xr-x
+	Executable  bool      `json:"executable"`
+	// SymlinkTarget is where the path points when it is a symbolic link
+	SymlinkTarget string `json:"symlinkTarget,omitempty"`
+	// Owner and Group are resolved from the file's uid and gid on Unix,
+	// falling back to the numeric ids when they have no name
+	Owner string `json:"owner,omitempty"`
+	Group string `json:"group,omitempty"`
+	// ExtendedAttributes lists the names of the file's extended attributes
+	// on platforms that support reading them
+	ExtendedAttributes []string `json:"extendedAttributes,omitempty"`
+	// MimeType is sniffed from the first 512 bytes of a regular file, or of
+	// the file a symbolic link points to, and Content classifies the same
+	// bytes as text or binary
+	MimeType string `json:"mimeType,omitempty"`
+	Content  string `json:"content,omitempty"`
 }
 
 // FileManager handles filesystem operations with security checks
 type FileManager struct {
-	allowedDirectories []string
+	allowedDirectories []string // normalized for comparison
+	allowedRoots       []string // cleaned, original case, for filesystem access
+	locks              *pathlock.Manager
+	fileLocking        bool
+	minFreeBytes       int64
+	snapshotDir        string
+	grepOptions        GrepOptions
+	safeContent        bool
+	preserveOwnership  bool
+
+	defaultMaxWalkDepth    int // used when a walk tool isn't given max_depth; 0 is unlimited
+	retrySharingViolations bool
+
+	workingDirMu sync.RWMutex
+	workingDir   string // relative paths resolve against this when set
 }
 
 // NewFileManager creates a new FileManager with the given allowed directories
 func NewFileManager(allowedDirs []string) *FileManager {
 	// Normalize all paths consistently
 	normalizedDirs := make([]string, len(allowedDirs))
+	roots := make([]string, len(allowedDirs))
 	for i, dir := range allowedDirs {
-		normalizedDirs[i] = normalizePath(filepath.Clean(dir))
+		roots[i] = filepath.Clean(dir)
+		normalizedDirs[i] = normalizePath(roots[i])
 	}
 
 	return &FileManager{
 		allowedDirectories: normalizedDirs,
+		allowedRoots:       roots,
+		locks:              pathlock.Default(),
+		snapshotDir:        filepath.Join(os.TempDir(), "mcp-filesystem-snapshots"),
+
+		retrySharingViolations: DefaultRetrySharingViolations(),
+	}
+}
+
+// SetMinFreeBytes sets the free space that must remain on the destination
+// filesystem after a write; zero disables the check
+func (fm *FileManager) SetMinFreeBytes(minFreeBytes int64) {
+	fm.minFreeBytes = minFreeBytes
+}
+
+// freeSpaceFunc queries the free space for a path; replaced in tests
+var freeSpaceFunc = diskFreeBytes
+
+// checkFreeSpace refuses a write of incoming bytes to path if it would drop the
+// destination filesystem below the configured minimum free space. Platforms
+// without a free-space query skip the check.
+func (fm *FileManager) checkFreeSpace(path string, incoming int64) error {
+	if fm.minFreeBytes <= 0 {
+		return nil
+	}
+
+	free, err := freeSpaceFunc(filepath.Dir(path))
+	if err != nil {
+		if errors.Is(err, errFreeSpaceUnsupported) {
+			return nil
+		}
+		return fmt.Errorf("failed to check free disk space: %w", err)
+	}
+
+	if int64(free)-incoming < fm.minFreeBytes {
+		return fmt.Errorf("insufficient disk space: writing %d bytes to %s would leave less than the configured minimum of %s free (%s available)",
+			incoming, path, formatBytes(uint64(fm.minFreeBytes)), formatBytes(free))
+	}
+
+	return nil
+}
+
+// SetFileLocking enables OS-level advisory locks around file writes
+func (fm *FileManager) SetFileLocking(enabled bool) {
+	fm.fileLocking = enabled
+}
+
+// SetPreserveOwnership makes rewritten files keep their original owner and
+// group, on platforms that support it
+func (fm *FileManager) SetPreserveOwnership(enabled bool) {
+	fm.preserveOwnership = enabled
+}
+
+// lockFile serializes a write with other operations on the same file, both
+// within this process and, when file locking is enabled, with other processes
+func (fm *FileManager) lockFile(path string) (func(), error) {
+	unlock := fm.locks.Lock(path)
+	if !fm.fileLocking {
+		return unlock, nil
+	}
+
+	release, err := flock.AcquireBestEffort(path, flock.DefaultTimeout)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return func() {
+		release()
+		unlock()
+	}, nil
+}
+
+// writeFile atomically replaces the content of a file, or rewrites it in
+// place when file locking is enabled so the lock held on it stays valid
+func (fm *FileManager) writeFile(path string, content []byte) error {
+	if fm.fileLocking {
+		return atomicfile.WriteInPlace(path, content)
 	}
+	return atomicfile.WriteFile(path, content, fm.preserveOwnership)
 }
 
 // normalizePath normalizes a path for secure comparison
@@ -68,38 +193,22 @@ func expandHomePath(path string) (string, error) {
 
 // ValidatePath checks if a path is allowed and returns its absolute path
 func (fm *FileManager) ValidatePath(requestedPath string) (string, error) {
-	// Expand home path if needed
-	expandedPath, err := expandHomePath(requestedPath)
+	absolute, err := fm.absolutePath(requestedPath)
 	if err != nil {
 		return "", err
 	}
 
-	// Get absolute path - FIX: Properly handle relative paths
-	var absolute string
-	if !filepath.IsAbs(expandedPath) {
-		// For relative paths, convert to absolute using current working directory
-		cwd, err := os.Getwd()
-		if err != nil {
-			return "", fmt.Errorf("failed to get current working directory: %w", err)
-		}
-		absolute = filepath.Join(cwd, filepath.Clean(expandedPath))
-	} else {
-		absolute = filepath.Clean(expandedPath)
-	}
-
 	// Check if path is within allowed directories
 	normalizedRequested := normalizePath(absolute)
-	isAllowed := false
+	rootIndex := fm.matchAllowedDirectory(normalizedRequested)
 
-	for _, dir := range fm.allowedDirectories {
-		if strings.HasPrefix(normalizedRequested, dir) {
-			isAllowed = true
-			break
-		}
+	if rootIndex < 0 {
+		return "", fmt.Errorf("access denied - path outside allowed directories: %s", absolute)
 	}
 
-	if !isAllowed {
-		return "", fmt.Errorf("access denied - path outside allowed directories: %s", absolute)
+	// Make sure the allowed directory itself hasn't been removed or unmounted
+	if err := fm.checkAllowedRootAvailable(rootIndex); err != nil {
+		return "", err
 	}
 
 	// Handle symlinks by checking their real path
@@ -156,6 +265,95 @@ func (fm *FileManager) ValidatePath(requestedPath string) (string, error) {
 	return realPath, nil
 }
 
+// absolutePath expands a leading ~ and resolves a relative path against the
+// working directory, without following symlinks or checking access
+func (fm *FileManager) absolutePath(requestedPath string) (string, error) {
+	// Expand home path if needed
+	expandedPath, err := expandHomePath(requestedPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Get absolute path - FIX: Properly handle relative paths
+	if !filepath.IsAbs(expandedPath) {
+		// For relative paths, convert to absolute using the working directory
+		cwd, err := fm.GetWorkingDirectory()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(cwd, filepath.Clean(expandedPath)), nil
+	}
+	return filepath.Clean(expandedPath), nil
+}
+
+// GetWorkingDirectory returns the directory relative paths are resolved
+// against: the one set with SetWorkingDirectory, or else the process
+// working directory
+func (fm *FileManager) GetWorkingDirectory() (string, error) {
+	fm.workingDirMu.RLock()
+	workingDir := fm.workingDir
+	fm.workingDirMu.RUnlock()
+
+	if workingDir != "" {
+		return workingDir, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current working directory: %w", err)
+	}
+	return cwd, nil
+}
+
+// SetWorkingDirectory sets the directory relative paths are resolved
+// against. It must be an existing directory within the allowed directories.
+func (fm *FileManager) SetWorkingDirectory(path string) (string, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%s is not a directory", validPath)
+	}
+
+	fm.workingDirMu.Lock()
+	fm.workingDir = validPath
+	fm.workingDirMu.Unlock()
+
+	return validPath, nil
+}
+
+// matchAllowedDirectory returns the index of the most specific allowed
+// directory containing a normalized path, or -1 if none does
+func (fm *FileManager) matchAllowedDirectory(normalizedPath string) int {
+	match := -1
+	for i, dir := range fm.allowedDirectories {
+		if strings.HasPrefix(normalizedPath, dir) {
+			if match < 0 || len(dir) > len(fm.allowedDirectories[match]) {
+				match = i
+			}
+		}
+	}
+	return match
+}
+
+// checkAllowedRootAvailable verifies that an allowed directory still exists,
+// so operations fail with a clear message instead of a cryptic OS error when
+// a directory is deleted or unmounted after startup
+func (fm *FileManager) checkAllowedRootAvailable(index int) error {
+	root := fm.allowedRoots[index]
+	info, err := os.Stat(root)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("allowed directory %s is no longer available", root)
+	}
+	return nil
+}
+
 // ReadFileSchema defines the schema for read_file tool input
 var ReadFileSchema = map[string]interface{}{
 	"type": "object",
@@ -163,6 +361,18 @@ var ReadFileSchema = map[string]interface{}{
 		"path": map[string]interface{}{
 			"type": "string",
 		},
+		"strict_utf8": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Fail if the file is not valid UTF-8 instead of replacing invalid bytes with U+FFFD (default false)",
+		},
+		"safe_content": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Wrap content in <file-content path=\"...\"> markers and strip control characters, to mark untrusted content as data (default from server config)",
+		},
+		"outline_if_large": map[string]interface{}{
+			"type":        "boolean",
+			"description": "For files over 256 KiB, return the first and last lines and an outline of the top-level declarations (Go) or section headers (text) instead of the whole content (default false)",
+		},
 	},
 	"required": []string{"path"},
 }
@@ -177,6 +387,15 @@ var ReadMultipleFilesSchema = map[string]interface{}{
 				"type": "string",
 			},
 		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{MultiFileFormatText, MultiFileFormatJSON},
+			"description": "Output format (default text). Use json for an array of {path, content} objects that parses unambiguously even when files contain the text separator",
+		},
+		"safe_content": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Wrap content in <file-content path=\"...\"> markers and strip control characters, to mark untrusted content as data (default from server config)",
+		},
 	},
 	"required": []string{"paths"},
 }
@@ -191,6 +410,25 @@ var WriteFileSchema = map[string]interface{}{
 		"content": map[string]interface{}{
 			"type": "string",
 		},
+		"verify": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Read the file back after writing and verify its hash matches the content (default false)",
+		},
+	},
+	"required": []string{"path", "content"},
+}
+
+// AppendFileSchema defines the schema for append_file tool input
+var AppendFileSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"content": map[string]interface{}{
+			"type":        "string",
+			"description": "Text to add to the end of the file; include a trailing newline to end a line",
+		},
 	},
 	"required": []string{"path", "content"},
 }
@@ -202,6 +440,10 @@ var CreateDirectorySchema = map[string]interface{}{
 		"path": map[string]interface{}{
 			"type": "string",
 		},
+		"fail_if_exists": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Fail if the directory already exists, for callers that need a fresh directory (default false)",
+		},
 	},
 	"required": []string{"path"},
 }
@@ -213,6 +455,22 @@ var ListDirectorySchema = map[string]interface{}{
 		"path": map[string]interface{}{
 			"type": "string",
 		},
+		"include_hidden": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Include entries whose names start with a dot (default true)",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// DirectoryTreeSchema defines the schema for directory_tree tool input
+var DirectoryTreeSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"max_depth": maxDepthSchema,
 	},
 	"required": []string{"path"},
 }
@@ -231,6 +489,33 @@ var MoveFileSchema = map[string]interface{}{
 	"required": []string{"source", "destination"},
 }
 
+// DeleteFileSchema defines the schema for delete_file tool input
+var DeleteFileSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// DeleteDirectorySchema defines the schema for delete_directory tool input
+var DeleteDirectorySchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"recursive": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Delete the directory's contents too; without it only an empty directory is deleted (default false)",
+			"default":     false,
+		},
+	},
+	"required": []string{"path"},
+}
+
 // SearchFilesSchema defines the schema for search_files tool input
 var SearchFilesSchema = map[string]interface{}{
 	"type": "object",
@@ -241,6 +526,17 @@ var SearchFilesSchema = map[string]interface{}{
 		"pattern": map[string]interface{}{
 			"type": "string",
 		},
+		"mode": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{SearchModeSubstring, SearchModeGlob, SearchModeRegex},
+			"description": "How pattern is matched against names: substring, glob (e.g. *.go) or regex; case is ignored in each (default substring)",
+		},
+		"include_hidden": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Include hidden files and descend into hidden directories (default true)",
+		},
+		"exclude":   excludeSchema,
+		"max_depth": maxDepthSchema,
 	},
 	"required": []string{"path", "pattern"},
 }
@@ -252,10 +548,49 @@ var GetFileInfoSchema = map[string]interface{}{
 		"path": map[string]interface{}{
 			"type": "string",
 		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{FileInfoFormatText, FileInfoFormatJSON},
+			"description": "Output format (default text). Use json for a single object with the same fields",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ReadFileAtSchema defines the schema for read_file_at tool input
+var ReadFileAtSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"offset": map[string]interface{}{
+			"type":        "integer",
+			"description": "Byte offset to start reading from (default 0)",
+		},
+		"length": map[string]interface{}{
+			"type":        "integer",
+			"description": "Maximum number of bytes to read (default 65536)",
+		},
 	},
 	"required": []string{"path"},
 }
 
+// ReadFileTailBytesSchema defines the schema for read_file_tail_bytes tool input
+var ReadFileTailBytesSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"bytes": map[string]interface{}{
+			"type":        "integer",
+			"description": "Number of bytes to read from the end of the file (clamped to the file size)",
+		},
+	},
+	"required": []string{"path", "bytes"},
+}
+
 // ListAllowedDirectoriesSchema defines the schema for list_allowed_directories tool input
 var ListAllowedDirectoriesSchema = map[string]interface{}{
 	"type": "object",
@@ -263,6 +598,31 @@ var ListAllowedDirectoriesSchema = map[string]interface{}{
 	"required": []string{},
 }
 
+// GetWorkingDirectorySchema defines the schema for get_working_directory tool input
+var GetWorkingDirectorySchema = map[string]interface{}{
+	"type":       "object",
+	"properties": map[string]interface{}{},
+	"required":   []string{},
+}
+
+// SetWorkingDirectorySchema defines the schema for set_working_directory tool input
+var SetWorkingDirectorySchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// AllowedDirectoriesInfoSchema defines the schema for allowed_directories_info tool input
+var AllowedDirectoriesInfoSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{},
+	"required": []string{},
+}
+
 // FilesystemTool defines the schema for a filesystem tool
 type FilesystemTool struct {
 	Name        string
@@ -277,7 +637,9 @@ var FilesystemTools = map[string]FilesystemTool{
 		Description: "Read the complete contents of a file from the file system. " +
 			"Handles various text encodings and provides detailed error messages " +
 			"if the file cannot be read. Use this tool when you need to examine " +
-			"the contents of a single file. Only works within allowed directories.",
+			"the contents of a single file. Set outline_if_large to get the head, tail " +
+			"and an outline of a very large file instead of its full content. " +
+			"Only works within allowed directories.",
 		InputSchema: ReadFileSchema,
 	},
 	"read_multiple_files": {
@@ -286,22 +648,49 @@ var FilesystemTools = map[string]FilesystemTool{
 			"efficient than reading files one by one when you need to analyze " +
 			"or compare multiple files. Each file's content is returned with its " +
 			"path as a reference. Failed reads for individual files won't stop " +
-			"the entire operation. Only works within allowed directories.",
+			"the entire operation. Set format to json to get an array of {path, content} " +
+			"objects, which parses reliably even when file contents resemble the text " +
+			"separators. Only works within allowed directories.",
 		InputSchema: ReadMultipleFilesSchema,
 	},
 	"write_file": {
 		Name: "write_file",
 		Description: "Create a new file or completely overwrite an existing file with new content. " +
 			"Use with caution as it will overwrite existing files without warning. " +
-			"Handles text content with proper encoding. Only works within allowed directories.",
+			"Handles text content with proper encoding. Set verify to read the file back and " +
+			"confirm it was written correctly. Only works within allowed directories.",
 		InputSchema: WriteFileSchema,
 	},
+	"append_file": {
+		Name: "append_file",
+		Description: "Add content to the end of a file without touching what is already there, " +
+			"creating the file if it doesn't exist. Useful for log lines and accumulating output. " +
+			"Returns the file's new size in bytes. Only works within allowed directories.",
+		InputSchema: AppendFileSchema,
+	},
+	"write_multiple_files": {
+		Name: "write_multiple_files",
+		Description: "Create or overwrite several files in one operation. Every path is checked " +
+			"before anything is written. Returns the actions taken, each a create or overwrite with " +
+			"its byte count. Set plan_only to get the same list without writing anything, to review " +
+			"the changes first. Only works within allowed directories.",
+		InputSchema: WriteMultipleFilesSchema,
+	},
+	"search_and_replace_across_files": {
+		Name: "search_and_replace_across_files",
+		Description: "Replace every occurrence of an exact string in all text files under a directory. " +
+			"Binary and hidden files are skipped. Returns each modified file with its replacement count. " +
+			"Set plan_only to get the same list without changing anything, to review the changes first. " +
+			"Only works within allowed directories.",
+		InputSchema: SearchAndReplaceAcrossFilesSchema,
+	},
 	"create_directory": {
 		Name: "create_directory",
 		Description: "Create a new directory or ensure a directory exists. Can create multiple " +
-			"nested directories in one operation. If the directory already exists, " +
-			"this operation will succeed silently. Perfect for setting up directory " +
-			"structures for projects or ensuring required paths exist. Only works within allowed directories.",
+			"nested directories in one operation, and lists the directories it actually created. " +
+			"If the directory already exists, this operation succeeds without changes unless " +
+			"fail_if_exists is set. Perfect for setting up directory structures for projects or " +
+			"ensuring required paths exist. Only works within allowed directories.",
 		InputSchema: CreateDirectorySchema,
 	},
 	"list_directory": {
@@ -312,6 +701,33 @@ var FilesystemTools = map[string]FilesystemTool{
 			"finding specific files within a directory. Only works within allowed directories.",
 		InputSchema: ListDirectorySchema,
 	},
+	"directory_tree": {
+		Name: "directory_tree",
+		Description: "Get a recursive tree of all files and directories below a path, as indented " +
+			"text and as nested {name, type, children} objects. Symlinked directories are followed " +
+			"unless they loop back, VCS and dependency directories such as .git and node_modules " +
+			"are not descended into, and the tree stops after 1000 entries. Use max_depth to limit " +
+			"how deep it goes. Only works within allowed directories.",
+		InputSchema: DirectoryTreeSchema,
+	},
+	"get_directory_size": {
+		Name: "get_directory_size",
+		Description: "Get the total size of the files below a directory, in human-readable units and " +
+			"bytes, with the number of files and directories. Symlinks are not followed, so only files " +
+			"actually inside the directory count. Useful before copying, archiving or deleting a folder. " +
+			"Only works within allowed directories.",
+		InputSchema: GetDirectorySizeSchema,
+	},
+	"watch_directory": {
+		Name: "watch_directory",
+		Description: "Watch a directory tree for up to 60 seconds and return the files and directories " +
+			"created, modified and deleted in that time, in the order they were seen. Blocks until the " +
+			"duration is over, so start the build or process to observe first. On Linux changes are " +
+			"reported by inotify as they happen; on other platforms the tree is polled, so a file " +
+			"created and removed within a quarter second may be missed. Returns at most 500 events. " +
+			"Only works within allowed directories.",
+		InputSchema: WatchDirectorySchema,
+	},
 	"move_file": {
 		Name: "move_file",
 		Description: "Move or rename files and directories. Can move files between directories " +
@@ -320,32 +736,165 @@ var FilesystemTools = map[string]FilesystemTool{
 			"for simple renaming within the same directory. Both source and destination must be within allowed directories.",
 		InputSchema: MoveFileSchema,
 	},
+	"copy_file": {
+		Name: "copy_file",
+		Description: "Copy a file, keeping its permissions and modification time. If the destination " +
+			"exists the operation fails unless overwrite is set. Directories can't be copied. " +
+			"Both source and destination must be within allowed directories.",
+		InputSchema: CopyFileSchema,
+	},
+	"delete_file": {
+		Name: "delete_file",
+		Description: "Delete a file. Directories are refused rather than removed. The deletion " +
+			"can't be undone, so check the path first. Only works within allowed directories.",
+		InputSchema: DeleteFileSchema,
+	},
+	"delete_directory": {
+		Name: "delete_directory",
+		Description: "Delete a directory. Only an empty directory is deleted unless recursive is set, " +
+			"which deletes everything beneath it as well. The allowed directories themselves can't be " +
+			"deleted, and the deletion can't be undone. Only works within allowed directories.",
+		InputSchema: DeleteDirectorySchema,
+	},
 	"search_files": {
 		Name: "search_files",
 		Description: "Recursively search for files and directories matching a pattern. " +
 			"Searches through all subdirectories from the starting path. The search " +
-			"is case-insensitive and matches partial names. Returns full paths to all " +
+			"is case-insensitive and matches partial names, or with mode set, glob patterns " +
+			"such as *.go or regular expressions. Returns full paths to all " +
 			"matching items. Great for finding files when you don't know their exact location. " +
+			"Set exclude to skip directories such as node_modules. " +
 			"Only searches within allowed directories.",
 		InputSchema: SearchFilesSchema,
 	},
+	"glob": {
+		Name: "glob",
+		Description: "List the files and directories matching a glob pattern such as src/**/*.go. " +
+			"'*', '?' and '[...]' match within one path segment and '**' matches any number of " +
+			"directories. Relative patterns resolve against the working directory. Supports exclude " +
+			"patterns and returning relative paths; results are capped, and .git and node_modules are " +
+			"skipped. Only searches within allowed directories.",
+		InputSchema: GlobSchema,
+	},
+	"grep_files": {
+		Name: "grep_files",
+		Description: "Recursively search the contents of text files for lines matching a regular " +
+			"expression. Returns each match as path:line: text. Binary and hidden files are skipped, " +
+			"as are files with too many lines, which are listed as warnings. Set ignore_case for a " +
+			"case-insensitive search and exclude to skip directories such as node_modules. Searches " +
+			"stop at max_results matches or a time limit and return the matches found so far. " +
+			"Only searches within allowed directories.",
+		InputSchema: GrepFilesSchema,
+	},
 	"get_file_info": {
 		Name: "get_file_info",
 		Description: "Retrieve detailed metadata about a file or directory. Returns comprehensive " +
-			"information including size, creation time, last modified time, permissions, " +
-			"and type. This tool is perfect for understanding file characteristics " +
+			"information including size, creation time, last modified time, permissions in octal " +
+			"and rwx form, whether the file is executable, the target of a symbolic link, the " +
+			"owner and group, extended attribute names where supported, and type. For files it also reports " +
+			"the MIME type sniffed from the first 512 bytes and whether the content is text or binary, " +
+			"to help decide whether read_file is useful. This tool is perfect for understanding file characteristics " +
 			"without reading the actual content. Only works within allowed directories.",
 		InputSchema: GetFileInfoSchema,
 	},
+	"open_file": {
+		Name: "open_file",
+		Description: "Open a file, returning its metadata and content together as JSON: path, size, " +
+			"modifiedTime, mimeType, lineCount and content. Saves a separate get_file_info call. " +
+			"Content beyond max_bytes is cut off with truncated set to true; binary files are reported " +
+			"with binary set and no content. Only works within allowed directories.",
+		InputSchema: OpenFileSchema,
+	},
+	"detect_file_type": {
+		Name: "detect_file_type",
+		Description: "Guess what kind of file a path is from its first 512 bytes, without reading the " +
+			"rest: magic numbers identify binaries and archives such as elf-binary, zip or png, a shebang " +
+			"names the script language such as python or bash, and otherwise the content and extension " +
+			"decide, such as json or go. Returns JSON with the type, mimeType, encoding, binary flag and " +
+			"any shebang line. A cheap first step before deciding how to read a file. " +
+			"Only works within allowed directories.",
+		InputSchema: DetectFileTypeSchema,
+	},
+	"file_stats": {
+		Name: "file_stats",
+		Description: "Count the lines, words, bytes and characters of a file, like wc, in one streaming " +
+			"pass without returning its content. Lines counts newline characters and characters are " +
+			"UTF-8 aware. Files longer than max_bytes are counted up to that point with truncated set; " +
+			"binary files are reported with binary set and no counts. Only works within allowed directories.",
+		InputSchema: FileStatsSchema,
+	},
+	"assert_file_content": {
+		Name: "assert_file_content",
+		Description: "Check a file against exactly one of expected_content, expected_hash (SHA-256) or a " +
+			"contains substring, returning PASS or FAIL. When expected_content differs, the result " +
+			"includes a unified diff from the expected to the actual content. A missing file is " +
+			"reported as a failure. Only works within allowed directories.",
+		InputSchema: AssertFileContentSchema,
+	},
+	"read_file_at": {
+		Name: "read_file_at",
+		Description: "Read a chunk of a file starting at a byte offset. Returns the data followed by " +
+			"the number of bytes read and whether the read reached end-of-file, so large files " +
+			"can be read in a loop until atEOF is true. Only works within allowed directories.",
+		InputSchema: ReadFileAtSchema,
+	},
+	"read_file_tail_bytes": {
+		Name: "read_file_tail_bytes",
+		Description: "Read the last N bytes of a file without scanning the whole file. Useful for " +
+			"checking file trailers, footers, or the end of large logs. Returns text if the bytes are " +
+			"valid UTF-8, otherwise base64. Only works within allowed directories.",
+		InputSchema: ReadFileTailBytesSchema,
+	},
+	"create_snapshot": {
+		Name: "create_snapshot",
+		Description: "Record the files in a directory tree along with their sizes and content hashes " +
+			"under a name, so the directory can later be compared with diff_snapshot. Version control " +
+			"and dependency directories such as .git and node_modules are skipped. Snapshots are limited " +
+			"to 20000 files. Only works within allowed directories.",
+		InputSchema: CreateSnapshotSchema,
+	},
+	"diff_snapshot": {
+		Name: "diff_snapshot",
+		Description: "Compare a directory tree against a snapshot previously taken with create_snapshot " +
+			"and report which files were added, removed, or modified since. Use this to see exactly what " +
+			"a build or other operation produced. Only works within allowed directories.",
+		InputSchema: DiffSnapshotSchema,
+	},
+	"get_working_directory": {
+		Name: "get_working_directory",
+		Description: "Returns the directory that relative paths in other tools are resolved against.",
+		InputSchema: GetWorkingDirectorySchema,
+	},
+	"set_working_directory": {
+		Name: "set_working_directory",
+		Description: "Set the directory that relative paths in subsequent tool calls are resolved against, " +
+			"such as a project root. The directory must exist and be within allowed directories.",
+		InputSchema: SetWorkingDirectorySchema,
+	},
 	"list_allowed_directories": {
 		Name: "list_allowed_directories",
 		Description: "Returns the list of directories that this server is allowed to access. " +
 			"Use this to understand which directories are available before trying to access files.",
 		InputSchema: ListAllowedDirectoriesSchema,
 	},
+	"allowed_directories_info": {
+		Name: "allowed_directories_info",
+		Description: "Returns each directory this server is allowed to access along with whether it " +
+			"currently exists, whether it is writable, and how much free disk space is available. " +
+			"Use this to decide where to write large outputs.",
+		InputSchema: AllowedDirectoriesInfoSchema,
+	},
+	"validate_path": {
+		Name: "validate_path",
+		Description: "Check whether a path is within the allowed directories without touching it. " +
+			"Set verbose to see the absolute and symlink-resolved paths and which allowed directory " +
+			"each matched, to debug why a path is allowed or denied.",
+		InputSchema: ValidatePathSchema,
+	},
 }
 
-// GetFileStats returns file metadata
+// GetFileStats returns file metadata. A symbolic link is described by its
+// target, with SymlinkTarget set to where it points.
 func GetFileStats(filePath string) (FileInfo, error) {
 	info, err := os.Stat(filePath)
 	if err != nil {
@@ -368,7 +917,7 @@ func GetFileStats(filePath string) (FileInfo, error) {
 	// Get file permissions in octal format
 	permissions := fmt.Sprintf("%o", info.Mode().Perm())
 
-	return FileInfo{
+	fileInfo := FileInfo{
 		Size:        info.Size(),
 		Created:     created,
 		Modified:    modified,
@@ -376,11 +925,110 @@ func GetFileStats(filePath string) (FileInfo, error) {
 		IsDirectory: info.IsDir(),
 		IsFile:      !info.IsDir(),
 		Permissions: permissions,
-	}, nil
+		Mode:        info.Mode().String(),
+		Executable:  isExecutable(filePath, info),
+	}
+	fileInfo.Owner, fileInfo.Group = fileOwner(info)
+	fileInfo.ExtendedAttributes = listExtendedAttributes(filePath)
+
+	if linkInfo, err := os.Lstat(filePath); err == nil && linkInfo.Mode()&os.ModeSymlink != 0 {
+		if target, err := os.Readlink(filePath); err == nil {
+			fileInfo.SymlinkTarget = target
+		}
+	}
+
+	return fileInfo, nil
+}
+
+// windowsExecutableExtensions are the extensions Windows runs directly, since
+// it has no executable permission bit
+var windowsExecutableExtensions = map[string]bool{
+	".exe": true,
+	".com": true,
+	".bat": true,
+	".cmd": true,
+	".ps1": true,
+}
+
+// isExecutable reports whether a regular file can be run: any execute bit
+// is set, or on Windows it has an executable extension
+func isExecutable(path string, info os.FileInfo) bool {
+	if !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return windowsExecutableExtensions[strings.ToLower(filepath.Ext(path))]
+	}
+	return info.Mode().Perm()&0111 != 0
+}
+
+// isHidden reports whether a file or directory name is hidden by the dotfile convention
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
+
+// Name matching modes for SearchFiles
+const (
+	SearchModeSubstring = "substring" // the name contains the pattern
+	SearchModeGlob      = "glob"      // the name matches a filepath.Match pattern
+	SearchModeRegex     = "regex"     // the name matches a regular expression
+)
+
+// nameMatcher returns a case-insensitive matcher of names against pattern
+// in the given mode, or an error describing why the pattern is invalid
+func nameMatcher(pattern, mode string) (func(name string) bool, error) {
+	switch mode {
+	case SearchModeSubstring, "":
+		pattern = strings.ToLower(pattern)
+		return func(name string) bool {
+			return strings.Contains(strings.ToLower(name), pattern)
+		}, nil
+	case SearchModeGlob:
+		pattern = strings.ToLower(pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+		}
+		return func(name string) bool {
+			ok, _ := filepath.Match(pattern, strings.ToLower(name))
+			return ok
+		}, nil
+	case SearchModeRegex:
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("invalid mode %q: must be %s, %s or %s", mode, SearchModeSubstring, SearchModeGlob, SearchModeRegex)
+	}
+}
+
+// SearchQuery holds the options of a SearchFiles search
+type SearchQuery struct {
+	Mode          string   // substring (the default), glob or regex
+	IncludeHidden bool     // include hidden files and descend into hidden directories
+	MaxDepth      int      // levels below the root to descend; 0 uses the default
+	Exclude       []string // glob patterns of entries to skip
 }
 
-// SearchFiles searches for files matching a pattern in a directory tree
-func SearchFiles(fm *FileManager, rootPath, pattern string) ([]string, error) {
+// SearchFiles searches for files whose names match a pattern in a directory
+// tree. The query's mode says how the pattern is matched: as a substring,
+// a glob or a regular expression, ignoring case in each.
+// Unless IncludeHidden is set, hidden files are skipped and hidden
+// directories are not descended into, and the same goes for entries
+// matching an exclude pattern. The walk goes at most MaxDepth levels below
+// rootPath, or the configured default depth when MaxDepth is 0.
+func SearchFiles(fm *FileManager, rootPath, pattern string, query SearchQuery) ([]string, error) {
+	matches, err := nameMatcher(pattern, query.Mode)
+	if err != nil {
+		return nil, err
+	}
+	
+	excluder, err := newWalkExcluder(query.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	
 	// Validate the root path
 	validRootPath, err := fm.ValidatePath(rootPath)
 	if err != nil {
@@ -388,7 +1036,7 @@ func SearchFiles(fm *FileManager, rootPath, pattern string) ([]string, error) {
 	}
 
 	var results []string
-	pattern = strings.ToLower(pattern)
+	maxDepth := fm.walkDepth(query.MaxDepth)
 
 	err = filepath.WalkDir(validRootPath, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -396,6 +1044,15 @@ func SearchFiles(fm *FileManager, rootPath, pattern string) ([]string, error) {
 			return nil
 		}
 
+		// Skip hidden entries below the root if requested, and excluded ones
+		if (!query.IncludeHidden && path != validRootPath && isHidden(d.Name())) ||
+			excluder.excluded(validRootPath, path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		// Try to validate each path
 		_, validateErr := fm.ValidatePath(path)
 		if validateErr != nil {
@@ -407,10 +1064,13 @@ func SearchFiles(fm *FileManager, rootPath, pattern string) ([]string, error) {
 		}
 
 		// Check if the name matches the pattern
-		if strings.Contains(strings.ToLower(d.Name()), pattern) {
+		if matches(d.Name()) {
 			results = append(results, path)
 		}
 
+		if d.IsDir() && reachedMaxDepth(validRootPath, path, maxDepth) {
+			return filepath.SkipDir
+		}
 		return nil
 	})
 
@@ -421,8 +1081,10 @@ func SearchFiles(fm *FileManager, rootPath, pattern string) ([]string, error) {
 	return results, nil
 }
 
-// ReadFile reads the contents of a file
-func (fm *FileManager) ReadFile(path string) (string, error) {
+// ReadFile reads the contents of a text file. Invalid UTF-8 sequences are
+// replaced with U+FFFD so the content can always be encoded as JSON, unless
+// strictUTF8 is set, in which case they are an error.
+func (fm *FileManager) ReadFile(path string, strictUTF8 bool) (string, error) {
 	validPath, err := fm.ValidatePath(path)
 	if err != nil {
 		return "", err
@@ -433,77 +1095,421 @@ func (fm *FileManager) ReadFile(path string) (string, error) {
 		return "", fmt.Errorf("failed to read file: %w", err)
 	}
 
-	return string(content), nil
-}
-
-// ReadMultipleFiles reads the contents of multiple files
-func (fm *FileManager) ReadMultipleFiles(paths []string) (string, error) {
-	var results []string
+	// Binary content can't be returned meaningfully as text
+	sample := content
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if IsBinary(sample) {
+		return "", fmt.Errorf("file appears to be binary (%d bytes); use read_file_tail_bytes to read raw bytes as base64", len(content))
+	}
 
-	for _, filePath := range paths {
-		content, err := fm.ReadFile(filePath)
-		if err != nil {
-			results = append(results, fmt.Sprintf("%s: Error - %s", filePath, err.Error()))
-		} else {
-			results = append(results, fmt.Sprintf("%s:\n%s", filePath, content))
+	if !utf8.Valid(content) {
+		if strictUTF8 {
+			return "", fmt.Errorf("file is not valid UTF-8")
 		}
+		return strings.ToValidUTF8(string(content), string(utf8.RuneError)), nil
 	}
 
-	return strings.Join(results, "\n---\n"), nil
+	return string(content), nil
 }
 
-// WriteFile writes content to a file
-func (fm *FileManager) WriteFile(path, content string) error {
+// DefaultReadAtLength is the number of bytes read by ReadFileAt when no length is given
+const DefaultReadAtLength = 64 * 1024
+
+// ReadFileAt reads up to length bytes starting at offset and reports whether
+// the read reached end-of-file. An offset at or beyond the end of the file
+// returns empty content with atEOF set.
+func (fm *FileManager) ReadFileAt(path string, offset int64, length int) (string, bool, error) {
 	validPath, err := fm.ValidatePath(path)
 	if err != nil {
-		return err
+		return "", false, err
 	}
 
-	err = os.WriteFile(validPath, []byte(content), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if offset < 0 {
+		return "", false, fmt.Errorf("offset must not be negative")
+	}
+	if length <= 0 {
+		length = DefaultReadAtLength
 	}
 
-	return nil
+	file, err := os.Open(validPath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to stat file: %w", err)
+	}
+
+	size := info.Size()
+	if offset >= size {
+		return "", true, nil
+	}
+
+	remaining := size - offset
+	if int64(length) > remaining {
+		length = int(remaining)
+	}
+
+	buf := make([]byte, length)
+	n, err := file.ReadAt(buf, offset)
+	if err != nil && err != io.EOF {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	atEOF := offset+int64(n) >= size
+	return string(buf[:n]), atEOF, nil
 }
 
-// CreateDirectory creates a directory
-func (fm *FileManager) CreateDirectory(path string) error {
+// ReadFileTailBytes reads the last n bytes of a file, clamped to the file size.
+// The bytes are returned as text if they are valid UTF-8, otherwise as base64
+// with isBase64 set.
+func (fm *FileManager) ReadFileTailBytes(path string, n int64) (content string, isBase64 bool, err error) {
 	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	if n < 0 {
+		return "", false, fmt.Errorf("bytes must not be negative")
+	}
+
+	file, err := os.Open(validPath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	// Find the file size by seeking to the end rather than reading it
+	size, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to seek file: %w", err)
+	}
+
+	if n > size {
+		n = size
+	}
+
+	buf := make([]byte, n)
+	read, err := file.ReadAt(buf, size-n)
+	if err != nil && err != io.EOF {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	buf = buf[:read]
+
+	if utf8.Valid(buf) {
+		return string(buf), false, nil
+	}
+	return base64.StdEncoding.EncodeToString(buf), true, nil
+}
+
+// Output formats for ReadMultipleFiles
+const (
+	MultiFileFormatText = "text"
+	MultiFileFormatJSON = "json"
+)
+
+// MultiFileEntry is one file in the JSON output of ReadMultipleFiles
+type MultiFileEntry struct {
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ReadMultipleFiles reads the contents of multiple files. The text format
+// separates files with "---" lines, which is ambiguous if a file contains
+// such a line; the json format returns an array of {path, content} objects
+// that can always be parsed unambiguously.
+func (fm *FileManager) ReadMultipleFiles(paths []string, format string, safeContent bool) (string, error) {
+	if format == MultiFileFormatJSON {
+		return fm.readMultipleFilesJSON(paths, safeContent)
+	}
+
+	var results []string
+
+	for _, filePath := range paths {
+		content, err := fm.ReadFile(filePath, false)
+		if err == nil && safeContent {
+			content = WrapSafeContent(filePath, content)
+		}
+		if err != nil {
+			results = append(results, fmt.Sprintf("%s: Error - %s", filePath, err.Error()))
+		} else {
+			results = append(results, fmt.Sprintf("%s:\n%s", filePath, content))
+		}
+	}
+
+	return strings.Join(results, "\n---\n"), nil
+}
+
+// readMultipleFilesJSON reads multiple files into a JSON array, recording per-file errors
+func (fm *FileManager) readMultipleFilesJSON(paths []string, safeContent bool) (string, error) {
+	entries := make([]MultiFileEntry, 0, len(paths))
+	for _, filePath := range paths {
+		content, err := fm.ReadFile(filePath, false)
+		if err == nil && safeContent {
+			content = WrapSafeContent(filePath, content)
+		}
+		if err != nil {
+			entries = append(entries, MultiFileEntry{Path: filePath, Error: err.Error()})
+		} else {
+			entries = append(entries, MultiFileEntry{Path: filePath, Content: content})
+		}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode files: %w", err)
+	}
+	return string(data), nil
+}
+
+// WriteFile writes content to a file. When verify is set the file is read
+// back and its hash compared to the intended content to catch silent write
+// failures on flaky storage.
+func (fm *FileManager) WriteFile(path, content string, verify bool) error {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return err
+	}
+
+	if err := fm.checkFreeSpace(validPath, int64(len(content))); err != nil {
+		return err
+	}
+
+	unlock, err := fm.lockFile(validPath)
 	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	err = os.MkdirAll(validPath, 0755)
+	err = fm.withRetry(func() error {
+		return fm.writeFile(validPath, []byte(content))
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if verify {
+		if err := verifyFileContent(validPath, []byte(content)); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// ListDirectory lists the contents of a directory
-func (fm *FileManager) ListDirectory(path string) (string, error) {
+// AppendFile adds content to the end of a file, creating it if needed, and
+// returns the file's new size in bytes
+func (fm *FileManager) AppendFile(path, content string) (int64, error) {
 	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := fm.checkFreeSpace(validPath, int64(len(content))); err != nil {
+		return 0, err
+	}
+
+	unlock, err := fm.lockFile(validPath)
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	// Only opening is retried, since retrying a partial write would repeat content
+	var file *os.File
+	err = fm.withRetry(func() error {
+		var openErr error
+		file, openErr = os.OpenFile(validPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		return openErr
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file for appending: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		return 0, fmt.Errorf("failed to append to file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// verifyFileContent reads a file back and checks that it matches the expected content
+func verifyFileContent(path string, expected []byte) error {
+	written, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file back for verification: %w", err)
+	}
+
+	expectedHash := sha256.Sum256(expected)
+	writtenHash := sha256.Sum256(written)
+	if !bytes.Equal(expectedHash[:], writtenHash[:]) {
+		return fmt.Errorf("write verification failed for %s: wrote %d bytes but read back %d bytes with a different hash",
+			path, len(expected), len(written))
+	}
+
+	return nil
+}
+
+// CreateDirectory creates a directory along with any missing parents and
+// returns the directories it created, outermost first, which is empty if
+// the directory already existed. With failIfExists set an existing
+// directory is an error instead; the final directory is created with a
+// single mkdir, so of several concurrent calls only one succeeds.
+func (fm *FileManager) CreateDirectory(path string, failIfExists bool) ([]string, error) {
+	target, missing, err := fm.validateNewDirectory(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(missing) == 0 {
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("failed to create directory: %s exists and is not a directory", path)
+		}
+		if failIfExists {
+			return nil, fmt.Errorf("directory already exists: %s", path)
+		}
+		return nil, nil
+	}
+
+	var created []string
+	for i, dir := range missing {
+		err := os.Mkdir(dir, 0755)
+		if errors.Is(err, fs.ErrExist) && (i < len(missing)-1 || !failIfExists) {
+			// Created by someone else in the meantime
+			continue
+		}
+		if errors.Is(err, fs.ErrExist) {
+			return created, fmt.Errorf("directory already exists: %s", path)
+		}
+		if err != nil {
+			return created, fmt.Errorf("failed to create directory: %w", err)
+		}
+		created = append(created, dir)
+	}
+
+	return created, nil
+}
+
+// validateNewDirectory checks that a directory to be created, and each
+// missing directory leading to it, is within the allowed directories. It
+// returns the directory's real path and the missing directories from the
+// outermost in. The nearest existing ancestor is resolved through any
+// symlinks and must itself be allowed; the directories below it don't exist
+// yet, so they can't be symlinks leading elsewhere.
+func (fm *FileManager) validateNewDirectory(path string) (string, []string, error) {
+	absolute, err := fm.absolutePath(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if fm.matchAllowedDirectory(normalizePath(absolute)) < 0 {
+		return "", nil, fmt.Errorf("access denied - path outside allowed directories: %s", absolute)
+	}
+
+	// Find the nearest ancestor that exists
+	existing := absolute
+	var missingNames []string
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", nil, fmt.Errorf("failed to check %s: %w", existing, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return "", nil, fmt.Errorf("no existing parent directory for %s", absolute)
+		}
+		missingNames = append([]string{filepath.Base(existing)}, missingNames...)
+		existing = parent
+	}
+
+	realExisting, err := fm.ValidatePath(existing)
+	if err != nil {
+		return "", nil, err
+	}
+
+	target := realExisting
+	missing := make([]string, 0, len(missingNames))
+	for _, name := range missingNames {
+		target = filepath.Join(target, name)
+		missing = append(missing, target)
+	}
+	return target, missing, nil
+}
+
+// DirectoryEntry is one entry of a directory listing
+type DirectoryEntry struct {
+	Name string `json:"name"`
+	Type string `json:"type"` // file or directory
+}
+
+// DirectoryListing is the contents of a directory
+type DirectoryListing struct {
+	Path    string           `json:"path"`
+	Entries []DirectoryEntry `json:"entries"`
+}
+
+// String formats the listing as one "[FILE] name" or "[DIR] name" line per entry
+func (l DirectoryListing) String() string {
+	result := make([]string, 0, len(l.Entries))
+	for _, entry := range l.Entries {
+		prefix := "[FILE]"
+		if entry.Type == "directory" {
+			prefix = "[DIR]"
+		}
+		result = append(result, fmt.Sprintf("%s %s", prefix, entry.Name))
+	}
+	return strings.Join(result, "\n")
+}
+
+// ListDirectory lists the contents of a directory, optionally omitting hidden entries
+func (fm *FileManager) ListDirectory(path string, includeHidden bool) (string, error) {
+	listing, err := fm.ReadDirectory(path, includeHidden)
 	if err != nil {
 		return "", err
 	}
+	return listing.String(), nil
+}
+
+// ReadDirectory returns the entries of a directory, optionally omitting hidden ones
+func (fm *FileManager) ReadDirectory(path string, includeHidden bool) (DirectoryListing, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return DirectoryListing{}, err
+	}
 
 	entries, err := os.ReadDir(validPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read directory: %w", err)
+		return DirectoryListing{}, fmt.Errorf("failed to read directory: %w", err)
 	}
 
-	var result []string
+	listing := DirectoryListing{Path: validPath, Entries: []DirectoryEntry{}}
 	for _, entry := range entries {
-		prefix := "[FILE]"
+		if !includeHidden && isHidden(entry.Name()) {
+			continue
+		}
+
+		entryType := "file"
 		if entry.IsDir() {
-			prefix = "[DIR]"
+			entryType = "directory"
 		}
-		result = append(result, fmt.Sprintf("%s %s", prefix, entry.Name()))
+		listing.Entries = append(listing.Entries, DirectoryEntry{Name: entry.Name(), Type: entryType})
 	}
 
-	return strings.Join(result, "\n"), nil
+	return listing, nil
 }
 
 // MoveFile moves or renames a file or directory
@@ -518,7 +1524,12 @@ func (fm *FileManager) MoveFile(source, destination string) error {
 		return err
 	}
 
-	err = os.Rename(validSource, validDest)
+	unlock := fm.locks.LockAll(validSource, validDest)
+	defer unlock()
+
+	err = fm.withRetry(func() error {
+		return os.Rename(validSource, validDest)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to move file: %w", err)
 	}
@@ -526,16 +1537,241 @@ func (fm *FileManager) MoveFile(source, destination string) error {
 	return nil
 }
 
-// GetFileInfo gets information about a file
-func (fm *FileManager) GetFileInfo(path string) (string, error) {
-	validPath, err := fm.ValidatePath(path)
+// DeleteFile deletes a file. Directories are refused, so a mistaken path
+// can't remove a whole tree. A symbolic link is deleted itself, never the
+// file it points to, even when that target is missing.
+func (fm *FileManager) DeleteFile(path string) error {
+	target, err := fm.deletionTarget(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(target)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("file does not exist: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a file", path)
+	}
+
+	// Only a regular file has content another process could be writing
+	if info.Mode()&os.ModeSymlink != 0 {
+		unlock := fm.locks.Lock(target)
+		defer unlock()
+	} else {
+		unlock, err := fm.lockFile(target)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	err = fm.withRetry(func() error {
+		return os.Remove(target)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteDirectory deletes a directory, which must be empty unless recursive
+// is set, in which case everything beneath it is deleted too. An allowed
+// directory, or a directory containing one, is never deleted.
+func (fm *FileManager) DeleteDirectory(path string, recursive bool) error {
+	validPath, err := fm.deletionTarget(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(validPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("directory does not exist: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete directory: %w", err)
+	}
+	link := info.Mode()&os.ModeSymlink != 0
+	if link {
+		if targetInfo, err := os.Stat(validPath); err != nil || !targetInfo.IsDir() {
+			return fmt.Errorf("%s is a symbolic link to a file, not a directory", path)
+		}
+	} else if !info.IsDir() {
+		return fmt.Errorf("%s is a file, not a directory", path)
+	}
+	if root, ok := fm.containsAllowedRoot(validPath, info); ok {
+		return fmt.Errorf("access denied - cannot delete allowed directory %s", root)
+	}
+
+	// A symbolic link to a directory is removed itself, leaving the
+	// directory it points to and its contents alone
+	if link {
+		unlock := fm.locks.Lock(validPath)
+		defer unlock()
+
+		if err := fm.withRetry(func() error { return os.Remove(validPath) }); err != nil {
+			return fmt.Errorf("failed to delete directory link: %w", err)
+		}
+		return nil
+	}
+
+	if !recursive {
+		entries, err := os.ReadDir(validPath)
+		if err != nil {
+			return fmt.Errorf("failed to delete directory: %w", err)
+		}
+		if len(entries) > 0 {
+			return fmt.Errorf("directory is not empty: %s; set recursive to delete its contents", path)
+		}
+	}
+
+	unlock := fm.locks.Lock(validPath)
+	defer unlock()
+
+	err = fm.withRetry(func() error {
+		if recursive {
+			return os.RemoveAll(validPath)
+		}
+		return os.Remove(validPath)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete directory: %w", err)
+	}
+
+	return nil
+}
+
+// deletionTarget returns the path to delete for path. When path names a
+// symbolic link, the link's parent directory is resolved and checked against
+// the allowed directories but the link itself is not followed, so deleting
+// it removes the link rather than its target. Any other path is validated
+// with ValidatePath.
+func (fm *FileManager) deletionTarget(path string) (string, error) {
+	absolute, err := fm.absolutePath(path)
 	if err != nil {
 		return "", err
 	}
 
-	info, err := GetFileStats(validPath)
+	info, err := os.Lstat(absolute)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return fm.ValidatePath(path)
+	}
+
+	parent, err := fm.ValidatePath(filepath.Dir(absolute))
 	if err != nil {
-		return "", fmt.Errorf("failed to get file info: %w", err)
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(absolute)), nil
+}
+
+// containsAllowedRoot reports the allowed directory that dir is, or that
+// lies beneath dir, if any
+func (fm *FileManager) containsAllowedRoot(dir string, info os.FileInfo) (string, bool) {
+	normalizedDir := normalizePath(dir)
+	for _, root := range fm.allowedRoots {
+		if rootInfo, err := os.Stat(root); err == nil && os.SameFile(info, rootInfo) {
+			return root, true
+		}
+
+		resolved := root
+		if real, err := filepath.EvalSymlinks(root); err == nil {
+			resolved = real
+		}
+		for _, candidate := range []string{root, resolved} {
+			normalizedRoot := normalizePath(candidate)
+			if normalizedRoot == normalizedDir || strings.HasPrefix(normalizedRoot, normalizedDir+string(filepath.Separator)) {
+				return root, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Output formats for GetFileInfo
+const (
+	FileInfoFormatText = "text"
+	FileInfoFormatJSON = "json"
+)
+
+// GetFileInfo gets information about a file as "key: value" lines, or as a
+// JSON object when format is json. A symbolic link is described by its
+// target, which must also be within the allowed directories, along with the
+// path it points to.
+func (fm *FileManager) GetFileInfo(path, format string) (string, error) {
+	info, err := fm.StatFile(path)
+	if err != nil {
+		return "", err
+	}
+	return FormatFileInfo(info, format)
+}
+
+// StatFile returns the metadata GetFileInfo reports for a file
+func (fm *FileManager) StatFile(path string) (FileInfo, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	// Stat the path as given, so a symbolic link is reported as one
+	absolute, err := fm.absolutePath(path)
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	info, err := GetFileStats(absolute)
+	if err != nil {
+		return FileInfo{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+	info.MimeType, info.Content = sniffContentType(validPath)
+	return info, nil
+}
+
+// mimeSniffLen is how many leading bytes http.DetectContentType considers
+const mimeSniffLen = 512
+
+// sniffContentType returns the MIME type of a regular file's first bytes and
+// whether they are text or binary. An empty file is text. Both are empty for
+// anything that isn't a regular file or can't be read, since the content
+// type is only a best-effort hint.
+func sniffContentType(path string) (string, string) {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", ""
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer file.Close()
+
+	sample := make([]byte, mimeSniffLen)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", ""
+	}
+	sample = sample[:n]
+
+	content := "text"
+	if IsBinary(sample) {
+		content = "binary"
+	}
+	return http.DetectContentType(sample), content
+}
+
+// FormatFileInfo formats file metadata as "key: value" lines, or as a JSON
+// object when format is json
+func FormatFileInfo(info FileInfo, format string) (string, error) {
+	if format == FileInfoFormatJSON {
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to encode file info: %w", err)
+		}
+		return string(data), nil
 	}
 
 	// Format the file info
@@ -547,6 +1783,24 @@ func (fm *FileManager) GetFileInfo(path string) (string, error) {
 		fmt.Sprintf("isDirectory: %t", info.IsDirectory),
 		fmt.Sprintf("isFile: %t", info.IsFile),
 		fmt.Sprintf("permissions: %s", info.Permissions),
+		fmt.Sprintf("mode: %s", info.Mode),
+		fmt.Sprintf("executable: %t", info.Executable),
+	}
+	if info.SymlinkTarget != "" {
+		result = append(result, fmt.Sprintf("symlinkTarget: %s", info.SymlinkTarget))
+	}
+	if info.Owner != "" {
+		result = append(result, fmt.Sprintf("owner: %s", info.Owner))
+	}
+	if info.Group != "" {
+		result = append(result, fmt.Sprintf("group: %s", info.Group))
+	}
+	if len(info.ExtendedAttributes) > 0 {
+		result = append(result, fmt.Sprintf("extendedAttributes: %s", strings.Join(info.ExtendedAttributes, ", ")))
+	}
+	if info.MimeType != "" {
+		result = append(result, fmt.Sprintf("mimeType: %s", info.MimeType))
+		result = append(result, fmt.Sprintf("content: %s", info.Content))
 	}
 
 	return strings.Join(result, "\n"), nil
@@ -557,49 +1811,140 @@ func (fm *FileManager) ListAllowedDirectories() string {
 	return fmt.Sprintf("Allowed directories:\n%s", strings.Join(fm.allowedDirectories, "\n"))
 }
 
+// errFreeSpaceUnsupported is returned on platforms without a free-space query
+var errFreeSpaceUnsupported = errors.New("free space query not supported on this platform")
+
+// AllowedDirectoriesInfo returns the allowed directories with their existence,
+// writability and free disk space
+func (fm *FileManager) AllowedDirectoriesInfo() string {
+	var result []string
+	for _, dir := range fm.allowedRoots {
+		exists := false
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			exists = true
+		}
+
+		writable := exists && isDirWritable(dir)
+
+		freeSpace := "unknown"
+		if exists {
+			free, err := diskFreeBytes(dir)
+			if err == nil {
+				freeSpace = fmt.Sprintf("%s (%d bytes)", formatBytes(free), free)
+			} else if errors.Is(err, errFreeSpaceUnsupported) {
+				freeSpace = "unavailable on this platform"
+			}
+		}
+
+		result = append(result, fmt.Sprintf("%s\n  exists: %t\n  writable: %t\n  freeSpace: %s",
+			dir, exists, writable, freeSpace))
+	}
+
+	return fmt.Sprintf("Allowed directories:\n%s", strings.Join(result, "\n"))
+}
+
+// isDirWritable checks whether a file can be created in a directory
+func isDirWritable(dir string) bool {
+	file, err := os.CreateTemp(dir, ".mcp-write-check-*")
+	if err != nil {
+		return false
+	}
+	name := file.Name()
+	file.Close()
+	os.Remove(name)
+	return true
+}
+
+// formatBytes formats a byte count in human-readable binary units
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
 // ParseReadFileArgs parses arguments for read_file
-func ParseReadFileArgs(args json.RawMessage) (string, error) {
+func ParseReadFileArgs(args json.RawMessage) (string, bool, *bool, bool, error) {
 	var params struct {
-		Path string `json:"path"`
+		Path           string `json:"path"`
+		StrictUTF8     bool   `json:"strict_utf8"`
+		SafeContent    *bool  `json:"safe_content"`
+		OutlineIfLarge bool   `json:"outline_if_large"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", fmt.Errorf("invalid arguments for read_file: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", false, nil, false, fmt.Errorf("invalid arguments for read_file: %w", err)
 	}
 	
 	if params.Path == "" {
-		return "", fmt.Errorf("path parameter is required")
+		return "", false, nil, false, fmt.Errorf("path parameter is required")
 	}
 	
-	return params.Path, nil
+	return params.Path, params.StrictUTF8, params.SafeContent, params.OutlineIfLarge, nil
 }
 
 // ParseReadMultipleFilesArgs parses arguments for read_multiple_files
-func ParseReadMultipleFilesArgs(args json.RawMessage) ([]string, error) {
+func ParseReadMultipleFilesArgs(args json.RawMessage) ([]string, string, *bool, error) {
 	var params struct {
-		Paths []string `json:"paths"`
+		Paths       []string `json:"paths"`
+		Format      string   `json:"format"`
+		SafeContent *bool    `json:"safe_content"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return nil, fmt.Errorf("invalid arguments for read_multiple_files: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return nil, "", nil, fmt.Errorf("invalid arguments for read_multiple_files: %w", err)
 	}
 	
 	if len(params.Paths) == 0 {
-		return nil, fmt.Errorf("paths parameter is required and must not be empty")
+		return nil, "", nil, fmt.Errorf("paths parameter is required and must not be empty")
+	}
+	
+	switch params.Format {
+	case "":
+		params.Format = MultiFileFormatText
+	case MultiFileFormatText, MultiFileFormatJSON:
+	default:
+		return nil, "", nil, fmt.Errorf("format parameter must be %q or %q", MultiFileFormatText, MultiFileFormatJSON)
 	}
 	
-	return params.Paths, nil
+	return params.Paths, params.Format, params.SafeContent, nil
 }
 
 // ParseWriteFileArgs parses arguments for write_file
-func ParseWriteFileArgs(args json.RawMessage) (string, string, error) {
+func ParseWriteFileArgs(args json.RawMessage) (string, string, bool, error) {
 	var params struct {
 		Path    string `json:"path"`
 		Content string `json:"content"`
+		Verify  bool   `json:"verify"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", "", fmt.Errorf("invalid arguments for write_file: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", false, fmt.Errorf("invalid arguments for write_file: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", "", false, fmt.Errorf("path parameter is required")
+	}
+	
+	return params.Path, params.Content, params.Verify, nil
+}
+
+// ParseAppendFileArgs parses arguments for append_file
+func ParseAppendFileArgs(args json.RawMessage) (string, string, error) {
+	var params struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", fmt.Errorf("invalid arguments for append_file: %w", err)
 	}
 	
 	if params.Path == "" {
@@ -610,37 +1955,68 @@ func ParseWriteFileArgs(args json.RawMessage) (string, string, error) {
 }
 
 // ParseCreateDirectoryArgs parses arguments for create_directory
-func ParseCreateDirectoryArgs(args json.RawMessage) (string, error) {
+func ParseCreateDirectoryArgs(args json.RawMessage) (string, bool, error) {
 	var params struct {
-		Path string `json:"path"`
+		Path         string `json:"path"`
+		FailIfExists bool   `json:"fail_if_exists"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", fmt.Errorf("invalid arguments for create_directory: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", false, fmt.Errorf("invalid arguments for create_directory: %w", err)
 	}
 	
 	if params.Path == "" {
-		return "", fmt.Errorf("path parameter is required")
+		return "", false, fmt.Errorf("path parameter is required")
 	}
 	
-	return params.Path, nil
+	return params.Path, params.FailIfExists, nil
 }
 
 // ParseListDirectoryArgs parses arguments for list_directory
-func ParseListDirectoryArgs(args json.RawMessage) (string, error) {
+func ParseListDirectoryArgs(args json.RawMessage) (string, bool, error) {
 	var params struct {
-		Path string `json:"path"`
+		Path          string `json:"path"`
+		IncludeHidden *bool  `json:"include_hidden"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", fmt.Errorf("invalid arguments for list_directory: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", false, fmt.Errorf("invalid arguments for list_directory: %w", err)
 	}
 	
 	if params.Path == "" {
-		return "", fmt.Errorf("path parameter is required")
+		return "", false, fmt.Errorf("path parameter is required")
 	}
 	
-	return params.Path, nil
+	return params.Path, boolOrDefault(params.IncludeHidden, true), nil
+}
+
+// ParseDirectoryTreeArgs parses arguments for directory_tree
+func ParseDirectoryTreeArgs(args json.RawMessage) (string, int, error) {
+	var params struct {
+		Path     string `json:"path"`
+		MaxDepth int    `json:"max_depth"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, fmt.Errorf("invalid arguments for directory_tree: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", 0, fmt.Errorf("path parameter is required")
+	}
+	if params.MaxDepth < 0 {
+		return "", 0, fmt.Errorf("max_depth parameter must not be negative")
+	}
+	
+	return params.Path, params.MaxDepth, nil
+}
+
+// boolOrDefault returns the value of an optional boolean argument or a default when omitted
+func boolOrDefault(value *bool, defaultValue bool) bool {
+	if value == nil {
+		return defaultValue
+	}
+	return *value
 }
 
 // ParseMoveFileArgs parses arguments for move_file
@@ -650,7 +2026,7 @@ func ParseMoveFileArgs(args json.RawMessage) (string, string, error) {
 		Destination string `json:"destination"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
+	if err := toolargs.Decode(args, &params); err != nil {
 		return "", "", fmt.Errorf("invalid arguments for move_file: %w", err)
 	}
 	
@@ -661,32 +2037,114 @@ func ParseMoveFileArgs(args json.RawMessage) (string, string, error) {
 	return params.Source, params.Destination, nil
 }
 
+// ParseDeleteFileArgs parses arguments for delete_file
+func ParseDeleteFileArgs(args json.RawMessage) (string, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", fmt.Errorf("invalid arguments for delete_file: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", fmt.Errorf("path parameter is required")
+	}
+	
+	return params.Path, nil
+}
+
+// ParseDeleteDirectoryArgs parses arguments for delete_directory
+func ParseDeleteDirectoryArgs(args json.RawMessage) (string, bool, error) {
+	var params struct {
+		Path      string `json:"path"`
+		Recursive bool   `json:"recursive"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", false, fmt.Errorf("invalid arguments for delete_directory: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", false, fmt.Errorf("path parameter is required")
+	}
+	
+	return params.Path, params.Recursive, nil
+}
+
 // ParseSearchFilesArgs parses arguments for search_files
-func ParseSearchFilesArgs(args json.RawMessage) (string, string, error) {
+func ParseSearchFilesArgs(args json.RawMessage) (string, string, SearchQuery, error) {
 	var params struct {
-		Path    string `json:"path"`
-		Pattern string `json:"pattern"`
+		Path          string   `json:"path"`
+		Pattern       string   `json:"pattern"`
+		Mode          string   `json:"mode"`
+		IncludeHidden *bool    `json:"include_hidden"`
+		MaxDepth      int      `json:"max_depth"`
+		Exclude       []string `json:"exclude"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", "", fmt.Errorf("invalid arguments for search_files: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", SearchQuery{}, fmt.Errorf("invalid arguments for search_files: %w", err)
 	}
 	
 	if params.Path == "" || params.Pattern == "" {
-		return "", "", fmt.Errorf("path and pattern parameters are required")
+		return "", "", SearchQuery{}, fmt.Errorf("path and pattern parameters are required")
+	}
+	
+	if params.Mode == "" {
+		params.Mode = SearchModeSubstring
+	}
+	if params.Mode != SearchModeSubstring && params.Mode != SearchModeGlob && params.Mode != SearchModeRegex {
+		return "", "", SearchQuery{}, fmt.Errorf("mode must be %s, %s or %s", SearchModeSubstring, SearchModeGlob, SearchModeRegex)
+	}
+	
+	if params.MaxDepth < 0 {
+		return "", "", SearchQuery{}, fmt.Errorf("max_depth parameter must not be negative")
 	}
 	
-	return params.Path, params.Pattern, nil
+	query := SearchQuery{
+		Mode:          params.Mode,
+		IncludeHidden: boolOrDefault(params.IncludeHidden, true),
+		MaxDepth:      params.MaxDepth,
+		Exclude:       params.Exclude,
+	}
+	return params.Path, params.Pattern, query, nil
 }
 
 // ParseGetFileInfoArgs parses arguments for get_file_info
-func ParseGetFileInfoArgs(args json.RawMessage) (string, error) {
+func ParseGetFileInfoArgs(args json.RawMessage) (string, string, error) {
+	var params struct {
+		Path   string `json:"path"`
+		Format string `json:"format"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", fmt.Errorf("invalid arguments for get_file_info: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", "", fmt.Errorf("path parameter is required")
+	}
+	
+	switch params.Format {
+	case "":
+		params.Format = FileInfoFormatText
+	case FileInfoFormatText, FileInfoFormatJSON:
+	default:
+		return "", "", fmt.Errorf("format parameter must be %q or %q", FileInfoFormatText, FileInfoFormatJSON)
+	}
+	
+	return params.Path, params.Format, nil
+}
+
+// ParseSetWorkingDirectoryArgs parses arguments for set_working_directory
+func ParseSetWorkingDirectoryArgs(args json.RawMessage) (string, error) {
 	var params struct {
 		Path string `json:"path"`
 	}
 	
-	if err := json.Unmarshal(args, &params); err != nil {
-		return "", fmt.Errorf("invalid arguments for get_file_info: %w", err)
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", fmt.Errorf("invalid arguments for set_working_directory: %w", err)
 	}
 	
 	if params.Path == "" {
@@ -695,3 +2153,48 @@ func ParseGetFileInfoArgs(args json.RawMessage) (string, error) {
 	
 	return params.Path, nil
 }
+
+// ParseReadFileAtArgs parses arguments for read_file_at
+func ParseReadFileAtArgs(args json.RawMessage) (string, int64, int, error) {
+	var params struct {
+		Path   string `json:"path"`
+		Offset int64  `json:"offset"`
+		Length int    `json:"length"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, 0, fmt.Errorf("invalid arguments for read_file_at: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", 0, 0, fmt.Errorf("path parameter is required")
+	}
+	
+	if params.Offset < 0 {
+		return "", 0, 0, fmt.Errorf("offset parameter must not be negative")
+	}
+	
+	return params.Path, params.Offset, params.Length, nil
+}
+
+// ParseReadFileTailBytesArgs parses arguments for read_file_tail_bytes
+func ParseReadFileTailBytesArgs(args json.RawMessage) (string, int64, error) {
+	var params struct {
+		Path  string `json:"path"`
+		Bytes int64  `json:"bytes"`
+	}
+	
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, fmt.Errorf("invalid arguments for read_file_tail_bytes: %w", err)
+	}
+	
+	if params.Path == "" {
+		return "", 0, fmt.Errorf("path parameter is required")
+	}
+	
+	if params.Bytes <= 0 {
+		return "", 0, fmt.Errorf("bytes parameter must be a positive integer")
+	}
+	
+	return params.Path, params.Bytes, nil
+}
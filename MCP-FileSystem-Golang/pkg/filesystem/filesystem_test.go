@@ -0,0 +1,863 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// newTestFileManager creates a temporary allowed directory and a FileManager for it
+func newTestFileManager(t *testing.T) (*FileManager, string) {
+	t.Helper()
+
+	tmpDir, err := os.MkdirTemp("", "filesystem-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	// Resolve symlinks so paths compare cleanly against the allowed directory
+	realDir, err := filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+
+	return NewFileManager([]string{realDir}), realDir
+}
+
+func TestReadFileAt(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	testFile := filepath.Join(dir, "test.txt")
+	if err := os.WriteFile(testFile, []byte("0123456789"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Read from the middle of the file
+	content, atEOF, err := fm.ReadFileAt(testFile, 2, 3)
+	if err != nil {
+		t.Fatalf("ReadFileAt failed: %v", err)
+	}
+	if content != "234" || atEOF {
+		t.Errorf("Expected %q with atEOF=false, got %q with atEOF=%t", "234", content, atEOF)
+	}
+
+	// Read ending exactly at EOF
+	content, atEOF, err = fm.ReadFileAt(testFile, 7, 3)
+	if err != nil {
+		t.Fatalf("ReadFileAt failed: %v", err)
+	}
+	if content != "789" || !atEOF {
+		t.Errorf("Expected %q with atEOF=true, got %q with atEOF=%t", "789", content, atEOF)
+	}
+
+	// Read with a length running past EOF
+	content, atEOF, err = fm.ReadFileAt(testFile, 8, 100)
+	if err != nil {
+		t.Fatalf("ReadFileAt failed: %v", err)
+	}
+	if content != "89" || !atEOF {
+		t.Errorf("Expected %q with atEOF=true, got %q with atEOF=%t", "89", content, atEOF)
+	}
+
+	// Offset exactly at EOF
+	content, atEOF, err = fm.ReadFileAt(testFile, 10, 5)
+	if err != nil {
+		t.Fatalf("ReadFileAt failed: %v", err)
+	}
+	if content != "" || !atEOF {
+		t.Errorf("Expected empty content with atEOF=true, got %q with atEOF=%t", content, atEOF)
+	}
+
+	// Offset beyond EOF
+	content, atEOF, err = fm.ReadFileAt(testFile, 50, 5)
+	if err != nil {
+		t.Fatalf("ReadFileAt failed: %v", err)
+	}
+	if content != "" || !atEOF {
+		t.Errorf("Expected empty content with atEOF=true, got %q with atEOF=%t", content, atEOF)
+	}
+
+	// Negative offsets are rejected
+	if _, _, err := fm.ReadFileAt(testFile, -1, 5); err == nil {
+		t.Error("Expected error for negative offset, got nil")
+	}
+}
+
+func TestValidatePathAllowedDirectoryRemoved(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	testFile := filepath.Join(dir, "test.txt")
+	if _, err := fm.ValidatePath(testFile); err != nil {
+		t.Fatalf("ValidatePath failed before removal: %v", err)
+	}
+
+	// Remove the allowed directory after the FileManager was created
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Failed to remove allowed directory: %v", err)
+	}
+
+	_, err := fm.ValidatePath(testFile)
+	if err == nil {
+		t.Fatal("Expected error after allowed directory was removed, got nil")
+	}
+	if !strings.Contains(err.Error(), "is no longer available") {
+		t.Errorf("Expected a clear unavailable-directory error, got: %v", err)
+	}
+}
+
+func TestWriteFileMinFreeBytes(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	// Mock the free-space query so the test doesn't depend on the real disk
+	originalFreeSpace := freeSpaceFunc
+	defer func() { freeSpaceFunc = originalFreeSpace }()
+	freeSpaceFunc = func(path string) (uint64, error) {
+		return 1000, nil
+	}
+
+	testFile := filepath.Join(dir, "test.txt")
+
+	// Disabled by default
+	if err := fm.WriteFile(testFile, strings.Repeat("x", 900), false); err != nil {
+		t.Fatalf("WriteFile failed with check disabled: %v", err)
+	}
+
+	fm.SetMinFreeBytes(200)
+
+	// Leaves 900 bytes free, above the minimum
+	if err := fm.WriteFile(testFile, strings.Repeat("x", 100), false); err != nil {
+		t.Errorf("WriteFile failed with enough free space: %v", err)
+	}
+
+	// Would leave 100 bytes free, below the minimum
+	err := fm.WriteFile(testFile, strings.Repeat("x", 900), false)
+	if err == nil {
+		t.Fatal("Expected error when write would drop below minimum free space, got nil")
+	}
+	if !strings.Contains(err.Error(), "insufficient disk space") {
+		t.Errorf("Expected insufficient disk space error, got: %v", err)
+	}
+
+	// The refused write must not have touched the file
+	content, err := os.ReadFile(testFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if len(content) != 100 {
+		t.Errorf("Expected file to keep its previous 100 bytes, got %d", len(content))
+	}
+}
+
+func TestReadFileTailBytes(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	textFile := filepath.Join(dir, "text.txt")
+	if err := os.WriteFile(textFile, []byte("header\nbody\ntrailer"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	content, isBase64, err := fm.ReadFileTailBytes(textFile, 7)
+	if err != nil {
+		t.Fatalf("ReadFileTailBytes failed: %v", err)
+	}
+	if content != "trailer" || isBase64 {
+		t.Errorf("Expected %q as text, got %q (base64=%t)", "trailer", content, isBase64)
+	}
+
+	// Requests larger than the file are clamped
+	content, _, err = fm.ReadFileTailBytes(textFile, 1000)
+	if err != nil {
+		t.Fatalf("ReadFileTailBytes failed: %v", err)
+	}
+	if content != "header\nbody\ntrailer" {
+		t.Errorf("Expected whole file when n exceeds size, got %q", content)
+	}
+
+	// Invalid UTF-8 is returned as base64
+	binaryFile := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(binaryFile, []byte{0x00, 0x01, 0xff, 0xfe}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	content, isBase64, err = fm.ReadFileTailBytes(binaryFile, 2)
+	if err != nil {
+		t.Fatalf("ReadFileTailBytes failed: %v", err)
+	}
+	if content != "//4=" || !isBase64 {
+		t.Errorf("Expected %q as base64, got %q (base64=%t)", "//4=", content, isBase64)
+	}
+}
+
+func TestIncludeHidden(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	for _, sub := range []string{".git", "src"} {
+		if err := os.Mkdir(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+	}
+	for _, file := range []string{".env", "main.go", ".git/config.go", "src/util.go"} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	listing, err := fm.ListDirectory(dir, false)
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if strings.Contains(listing, ".env") || strings.Contains(listing, ".git") {
+		t.Errorf("Expected hidden entries to be omitted, got:\n%s", listing)
+	}
+
+	listing, err = fm.ListDirectory(dir, true)
+	if err != nil {
+		t.Fatalf("ListDirectory failed: %v", err)
+	}
+	if !strings.Contains(listing, ".env") || !strings.Contains(listing, ".git") {
+		t.Errorf("Expected hidden entries to be listed, got:\n%s", listing)
+	}
+
+	results, err := SearchFiles(fm, dir, ".go", SearchQuery{})
+	if err != nil {
+		t.Fatalf("SearchFiles failed: %v", err)
+	}
+	for _, result := range results {
+		if strings.Contains(result, ".git") {
+			t.Errorf("Expected hidden directory to be skipped, got %s", result)
+		}
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected 2 visible matches, got %d: %v", len(results), results)
+	}
+}
+
+func TestSearchFilesModes(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	for _, name := range []string{"main.go", "main_test.go", "README.md", "gopher.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		mode    string
+		pattern string
+		want    []string
+	}{
+		{SearchModeSubstring, "go", []string{"gopher.txt", "main.go", "main_test.go"}},
+		{SearchModeGlob, "*.GO", []string{"main.go", "main_test.go"}},
+		{SearchModeGlob, "main?go", []string{"main.go"}},
+		{SearchModeRegex, `^main(_test)?\.go$`, []string{"main.go", "main_test.go"}},
+		{SearchModeRegex, `^readme`, []string{"README.md"}},
+	}
+	for _, tt := range tests {
+		results, err := SearchFiles(fm, dir, tt.pattern, SearchQuery{Mode: tt.mode, IncludeHidden: true})
+		if err != nil {
+			t.Errorf("SearchFiles(%q, %s) failed: %v", tt.pattern, tt.mode, err)
+			continue
+		}
+		var names []string
+		for _, result := range results {
+			names = append(names, filepath.Base(result))
+		}
+		sort.Strings(names)
+		if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("SearchFiles(%q, %s) = %v, expected %v", tt.pattern, tt.mode, names, tt.want)
+		}
+	}
+
+	// Invalid patterns are reported instead of matching nothing
+	if _, err := SearchFiles(fm, dir, "[a-", SearchQuery{Mode: SearchModeGlob}); err == nil || !strings.Contains(err.Error(), "invalid glob pattern") {
+		t.Errorf("Expected an invalid glob pattern error, got %v", err)
+	}
+	if _, err := SearchFiles(fm, dir, "main(", SearchQuery{Mode: SearchModeRegex}); err == nil || !strings.Contains(err.Error(), "invalid regex pattern") {
+		t.Errorf("Expected an invalid regex pattern error, got %v", err)
+	}
+	if _, _, _, err := ParseSearchFilesArgs(json.RawMessage(`{"path": "a", "pattern": "b", "mode": "fuzzy"}`)); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
+
+func TestSnapshotDiff(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	fm.SetSnapshotDir(filepath.Join(t.TempDir(), "snapshots"))
+
+	files := map[string]string{
+		"keep.txt":          "unchanged",
+		"change.txt":        "before",
+		"remove.txt":        "going away",
+		"sub/nested.txt":    "nested",
+		".git/HEAD":         "ref: refs/heads/main",
+		"node_modules/x.js": "ignored",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	count, err := fm.CreateSnapshot(dir, "before-build")
+	if err != nil {
+		t.Fatalf("CreateSnapshot failed: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("Expected 4 files in snapshot (ignored directories skipped), got %d", count)
+	}
+
+	// Same size, different content, so only the hash can detect the change
+	if err := os.WriteFile(filepath.Join(dir, "change.txt"), []byte("after!"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "remove.txt")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "added.txt"), []byte("new"), 0644); err != nil {
+		t.Fatalf("Failed to add file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "HEAD"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to modify ignored file: %v", err)
+	}
+
+	diff, err := fm.DiffSnapshot(dir, "before-build")
+	if err != nil {
+		t.Fatalf("DiffSnapshot failed: %v", err)
+	}
+
+	if strings.Join(diff.Added, ",") != "sub/added.txt" {
+		t.Errorf("Expected added [sub/added.txt], got %v", diff.Added)
+	}
+	if strings.Join(diff.Removed, ",") != "remove.txt" {
+		t.Errorf("Expected removed [remove.txt], got %v", diff.Removed)
+	}
+	if strings.Join(diff.Modified, ",") != "change.txt" {
+		t.Errorf("Expected modified [change.txt], got %v", diff.Modified)
+	}
+
+	if _, err := fm.DiffSnapshot(dir, "missing"); err == nil {
+		t.Error("Expected error for a snapshot that does not exist")
+	}
+	if _, err := fm.CreateSnapshot(dir, "../escape"); err == nil {
+		t.Error("Expected error for a snapshot name containing a path separator")
+	}
+}
+
+func TestReadFileInvalidUTF8(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	testFile := filepath.Join(dir, "latin1.txt")
+	if err := os.WriteFile(testFile, []byte("caf\xe9 cr\xe8me\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	// Invalid sequences are replaced by default
+	content, err := fm.ReadFile(testFile, false)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if content != "caf� cr�me\n" {
+		t.Errorf("Expected invalid bytes replaced with U+FFFD, got %q", content)
+	}
+
+	// Strict mode reports the problem instead
+	if _, err := fm.ReadFile(testFile, true); err == nil || !strings.Contains(err.Error(), "not valid UTF-8") {
+		t.Errorf("Expected UTF-8 error in strict mode, got %v", err)
+	}
+
+	// Valid files are unaffected by strict mode
+	validFile := filepath.Join(dir, "utf8.txt")
+	if err := os.WriteFile(validFile, []byte("café crème\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if content, err := fm.ReadFile(validFile, true); err != nil || content != "café crème\n" {
+		t.Errorf("Expected valid file to be read unchanged, got %q, %v", content, err)
+	}
+}
+
+func TestSetWorkingDirectory(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	project := filepath.Join(dir, "project")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := fm.SetWorkingDirectory(project); err != nil {
+		t.Fatalf("SetWorkingDirectory failed: %v", err)
+	}
+
+	// Relative paths now resolve against the project directory
+	content, err := fm.ReadFile("main.go", false)
+	if err != nil {
+		t.Fatalf("Expected relative path to resolve against working directory: %v", err)
+	}
+	if content != "package main\n" {
+		t.Errorf("Expected file content, got %q", content)
+	}
+
+	// Directories outside the sandbox are rejected and leave the setting unchanged
+	if _, err := fm.SetWorkingDirectory(filepath.Dir(dir)); err == nil {
+		t.Error("Expected error setting a working directory outside allowed directories")
+	}
+	if workingDir, _ := fm.GetWorkingDirectory(); workingDir != project {
+		t.Errorf("Expected working directory %s, got %s", project, workingDir)
+	}
+}
+
+func TestReadMultipleFilesJSON(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	// Content containing the text separator must survive the JSON form intact
+	tricky := filepath.Join(dir, "tricky.txt")
+	trickyContent := "before\n---\nother.txt:\nafter"
+	if err := os.WriteFile(tricky, []byte(trickyContent), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	missing := filepath.Join(dir, "missing.txt")
+
+	output, err := fm.ReadMultipleFiles([]string{tricky, missing}, MultiFileFormatJSON, false)
+	if err != nil {
+		t.Fatalf("ReadMultipleFiles failed: %v", err)
+	}
+
+	var entries []MultiFileEntry
+	if err := json.Unmarshal([]byte(output), &entries); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, output)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Path != tricky || entries[0].Content != trickyContent || entries[0].Error != "" {
+		t.Errorf("Expected tricky file content intact, got %+v", entries[0])
+	}
+	if entries[1].Path != missing || entries[1].Error == "" {
+		t.Errorf("Expected an error entry for the missing file, got %+v", entries[1])
+	}
+}
+
+func TestParseReadMultipleFilesArgsFormat(t *testing.T) {
+	_, format, _, err := ParseReadMultipleFilesArgs(json.RawMessage(`{"paths": ["a.txt"]}`))
+	if err != nil || format != MultiFileFormatText {
+		t.Errorf("Expected default text format, got %q (%v)", format, err)
+	}
+
+	_, format, _, err = ParseReadMultipleFilesArgs(json.RawMessage(`{"paths": ["a.txt"], "format": "json"}`))
+	if err != nil || format != MultiFileFormatJSON {
+		t.Errorf("Expected json format, got %q (%v)", format, err)
+	}
+
+	if _, _, _, err := ParseReadMultipleFilesArgs(json.RawMessage(`{"paths": ["a.txt"], "format": "xml"}`)); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestParseReadFileArgsStrict(t *testing.T) {
+	args := json.RawMessage(`{"path": "a.txt", "file_path": "b.txt"}`)
+
+	// Lenient by default: the misspelled argument is ignored
+	if path, _, _, _, err := ParseReadFileArgs(args); err != nil || path != "a.txt" {
+		t.Errorf("Expected the unknown argument to be ignored, got %q (%v)", path, err)
+	}
+
+	toolargs.SetStrict(true)
+	t.Cleanup(func() { toolargs.SetStrict(false) })
+
+	_, _, _, _, err := ParseReadFileArgs(args)
+	if err == nil || !strings.Contains(err.Error(), `unexpected argument "file_path"`) {
+		t.Errorf("Expected an error naming file_path, got %v", err)
+	}
+	if _, _, _, _, err := ParseReadFileArgs(json.RawMessage(`{"path": "a.txt", "strict_utf8": true}`)); err != nil {
+		t.Errorf("Expected known arguments to be accepted, got %v", err)
+	}
+}
+
+func TestGetFileInfoJSON(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("execute bits and symlinks need Unix")
+	}
+	fm, dir := newTestFileManager(t)
+
+	script := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0750); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.Chmod(script, 0750); err != nil {
+		t.Fatalf("Failed to set permissions: %v", err)
+	}
+	link := filepath.Join(dir, "run")
+	if err := os.Symlink("run.sh", link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	output, err := fm.GetFileInfo(link, FileInfoFormatJSON)
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	var info FileInfo
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatalf("Failed to parse JSON output %q: %v", output, err)
+	}
+
+	if info.Permissions != "750" || info.Mode != "-rwxr-x---" || !info.Executable {
+		t.Errorf("Expected permissions 750, mode -rwxr-x--- and executable, got %q, %q and %v",
+			info.Permissions, info.Mode, info.Executable)
+	}
+	if info.SymlinkTarget != "run.sh" {
+		t.Errorf("Expected symlink target run.sh, got %q", info.SymlinkTarget)
+	}
+	if info.Owner == "" || info.Group == "" {
+		t.Errorf("Expected an owner and group, got %q and %q", info.Owner, info.Group)
+	}
+
+	text, err := fm.GetFileInfo(script, FileInfoFormatText)
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	if !strings.Contains(text, "mode: -rwxr-x---") || strings.Contains(text, "symlinkTarget") {
+		t.Errorf("Expected the text output to include the mode and no symlink target, got:\n%s", text)
+	}
+}
+
+func TestGetFileInfoMimeType(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	files := map[string][]byte{
+		"image.dat": append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 16)...),
+		"notes":     []byte("plain notes\n"),
+		"empty":     nil,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		mimeType string
+		content  string
+	}{
+		{"image.dat", "image/png", "binary"},
+		{"notes", "text/plain; charset=utf-8", "text"},
+		{"empty", "text/plain; charset=utf-8", "text"},
+	}
+	for _, tt := range tests {
+		info, err := fm.StatFile(filepath.Join(dir, tt.name))
+		if err != nil {
+			t.Fatalf("StatFile failed for %s: %v", tt.name, err)
+		}
+		if info.MimeType != tt.mimeType || info.Content != tt.content {
+			t.Errorf("Expected %s to be %s %s, got %q %q", tt.name, tt.content, tt.mimeType, info.Content, info.MimeType)
+		}
+	}
+
+	text, err := fm.GetFileInfo(dir, FileInfoFormatText)
+	if err != nil {
+		t.Fatalf("GetFileInfo failed: %v", err)
+	}
+	if strings.Contains(text, "mimeType") {
+		t.Errorf("Expected no MIME type for a directory, got:\n%s", text)
+	}
+}
+
+func TestCreateDirectoryReportsCreated(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	if err := os.Mkdir(filepath.Join(dir, "a"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	created, err := fm.CreateDirectory(filepath.Join(dir, "a", "b", "c"), false)
+	if err != nil {
+		t.Fatalf("CreateDirectory failed: %v", err)
+	}
+	expected := []string{filepath.Join(dir, "a", "b"), filepath.Join(dir, "a", "b", "c")}
+	if strings.Join(created, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected created directories %v, got %v", expected, created)
+	}
+
+	// An existing directory is fine unless fail_if_exists is set
+	created, err = fm.CreateDirectory(filepath.Join(dir, "a", "b"), false)
+	if err != nil || len(created) != 0 {
+		t.Errorf("Expected an existing directory to succeed with nothing created, got %v and %v", created, err)
+	}
+	if _, err := fm.CreateDirectory(filepath.Join(dir, "a", "b"), true); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected fail_if_exists to reject an existing directory, got %v", err)
+	}
+	if created, err := fm.CreateDirectory(filepath.Join(dir, "fresh"), true); err != nil || len(created) != 1 {
+		t.Errorf("Expected fail_if_exists to create a new directory, got %v and %v", created, err)
+	}
+}
+
+func TestCreateDirectoryOutsideAllowedDirectories(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need Unix")
+	}
+	fm, dir := newTestFileManager(t)
+
+	// A link inside the allowed directory leading outside it
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "escape")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if _, err := fm.CreateDirectory(filepath.Join(dir, "escape", "x", "y"), false); err == nil {
+		t.Error("Expected a chain through a symlink leading outside to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "x")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing to be created outside the allowed directories, got %v", err)
+	}
+	if _, err := fm.CreateDirectory(filepath.Join(outside, "x"), false); err == nil {
+		t.Error("Expected a path outside the allowed directories to be rejected")
+	}
+}
+
+func TestDeleteFile(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	path := filepath.Join(dir, "doomed.txt")
+	if err := os.WriteFile(path, []byte("bye"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if err := fm.DeleteFile(path); err != nil {
+		t.Fatalf("DeleteFile failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the file to be gone, got %v", err)
+	}
+
+	if err := fm.DeleteFile(path); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected an error for a missing file, got %v", err)
+	}
+
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := fm.DeleteFile(sub); err == nil || !strings.Contains(err.Error(), "is a directory") {
+		t.Errorf("Expected a directory to be refused, got %v", err)
+	}
+	if _, err := os.Stat(sub); err != nil {
+		t.Errorf("Expected the directory to remain, got %v", err)
+	}
+
+	if err := fm.DeleteFile(filepath.Join(t.TempDir(), "outside.txt")); err == nil {
+		t.Error("Expected a path outside the allowed directories to be rejected")
+	}
+}
+
+func TestDeleteFileSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need Unix")
+	}
+	fm, dir := newTestFileManager(t)
+
+	target := filepath.Join(dir, "target.txt")
+	if err := os.WriteFile(target, []byte("keep"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	if err := fm.DeleteFile(link); err != nil {
+		t.Fatalf("DeleteFile failed: %v", err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Errorf("Expected the link to be gone, got %v", err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("Expected the link's target to remain, got %v", err)
+	}
+
+	// A dangling link can still be deleted
+	dangling := filepath.Join(dir, "dangling")
+	if err := os.Symlink(filepath.Join(dir, "missing.txt"), dangling); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := fm.DeleteFile(dangling); err != nil {
+		t.Fatalf("DeleteFile failed for a dangling link: %v", err)
+	}
+	if _, err := os.Lstat(dangling); !os.IsNotExist(err) {
+		t.Errorf("Expected the dangling link to be gone, got %v", err)
+	}
+}
+
+func TestDeleteDirectory(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	tree := filepath.Join(dir, "tree")
+	if err := os.MkdirAll(filepath.Join(tree, "nested"), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tree, "nested", "file.txt"), []byte("data"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// Recursive deletion is opt-in
+	if err := fm.DeleteDirectory(tree, false); err == nil || !strings.Contains(err.Error(), "not empty") {
+		t.Errorf("Expected a non-empty directory to be refused, got %v", err)
+	}
+	if err := fm.DeleteDirectory(filepath.Join(tree, "nested", "file.txt"), true); err == nil || !strings.Contains(err.Error(), "is a file") {
+		t.Errorf("Expected a file to be refused, got %v", err)
+	}
+	if err := fm.DeleteDirectory(tree, true); err != nil {
+		t.Fatalf("DeleteDirectory failed: %v", err)
+	}
+	if _, err := os.Stat(tree); !os.IsNotExist(err) {
+		t.Errorf("Expected the tree to be gone, got %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := fm.DeleteDirectory(empty, false); err != nil {
+		t.Errorf("Expected an empty directory to be deleted, got %v", err)
+	}
+	if err := fm.DeleteDirectory(empty, false); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected an error for a missing directory, got %v", err)
+	}
+}
+
+func TestDeleteDirectorySymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need Unix")
+	}
+	fm, dir := newTestFileManager(t)
+
+	important := filepath.Join(dir, "important")
+	if err := os.Mkdir(important, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(important, "keep.txt"), []byte("keep"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(important, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	// Deleting the link, even recursively, must leave its target alone
+	if err := fm.DeleteDirectory(link, true); err != nil {
+		t.Fatalf("DeleteDirectory failed: %v", err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Errorf("Expected the link to be gone, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(important, "keep.txt")); err != nil {
+		t.Errorf("Expected the link's target to remain, got %v", err)
+	}
+
+	// A link to the allowed directory itself is removed without touching it
+	if err := os.Symlink(dir, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := fm.DeleteDirectory(link, true); err != nil {
+		t.Fatalf("DeleteDirectory failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(important, "keep.txt")); err != nil {
+		t.Errorf("Expected the allowed directory to remain, got %v", err)
+	}
+}
+
+func TestDeleteDirectoryRefusesAllowedRoots(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	if err := fm.DeleteDirectory(dir, true); err == nil || !strings.Contains(err.Error(), "cannot delete allowed directory") {
+		t.Errorf("Expected the allowed directory to be refused, got %v", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("Expected the allowed directory to remain, got %v", err)
+	}
+
+	// A directory holding another allowed directory is refused too
+	inner := filepath.Join(dir, "parent", "inner")
+	if err := os.MkdirAll(inner, 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	fm = NewFileManager([]string{dir, inner})
+	if err := fm.DeleteDirectory(filepath.Join(dir, "parent"), true); err == nil {
+		t.Error("Expected a directory containing an allowed directory to be refused")
+	}
+	if _, err := os.Stat(inner); err != nil {
+		t.Errorf("Expected the inner allowed directory to remain, got %v", err)
+	}
+}
+
+func TestAppendFile(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	path := filepath.Join(dir, "log.txt")
+	size, err := fm.AppendFile(path, "first\n")
+	if err != nil {
+		t.Fatalf("AppendFile failed: %v", err)
+	}
+	if size != 6 {
+		t.Errorf("Expected size 6 after creating the file, got %d", size)
+	}
+
+	size, err = fm.AppendFile(path, "second\n")
+	if err != nil {
+		t.Fatalf("AppendFile failed: %v", err)
+	}
+	if size != 13 {
+		t.Errorf("Expected size 13 after appending, got %d", size)
+	}
+	if content, _ := os.ReadFile(path); string(content) != "first\nsecond\n" {
+		t.Errorf("Expected both lines, got %q", string(content))
+	}
+
+	if _, err := fm.AppendFile(dir, "text"); err == nil {
+		t.Error("Expected appending to a directory to fail")
+	}
+	if _, err := fm.AppendFile(filepath.Join(t.TempDir(), "outside.txt"), "text"); err == nil {
+		t.Error("Expected a path outside the allowed directories to be rejected")
+	}
+}
+
+func TestWriteFileWithLockingRewritesInPlace(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	fm.SetFileLocking(true)
+
+	testFile := filepath.Join(dir, "locked.txt")
+	if err := os.WriteFile(testFile, []byte("old content"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	before, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+
+	if err := fm.WriteFile(testFile, "new", false); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// The lock is taken on the file written, not on one renamed away
+	after, err := os.Stat(testFile)
+	if err != nil {
+		t.Fatalf("Failed to stat file: %v", err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("Expected the file to be rewritten in place while locking is enabled")
+	}
+	if content, err := os.ReadFile(testFile); err != nil || string(content) != "new" {
+		t.Errorf("Expected content %q, got %q (%v)", "new", content, err)
+	}
+}
@@ -0,0 +1,284 @@
+package filesystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// fileTypeSniffLen is how many leading bytes detect_file_type reads
+const fileTypeSniffLen = 512
+
+// FileType is the guessed kind of a file
+type FileType struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	MimeType string `json:"mimeType"`
+	Encoding string `json:"encoding"`
+	Binary   bool   `json:"binary"`
+	Shebang  string `json:"shebang,omitempty"`
+}
+
+// magicSignatures maps leading bytes to the type of file that starts with them
+var magicSignatures = []struct {
+	prefix   []byte
+	fileType string
+}{
+	{[]byte("\x7fELF"), "elf-binary"},
+	{[]byte("MZ"), "pe-binary"},
+	{[]byte{0xCF, 0xFA, 0xED, 0xFE}, "macho-binary"},
+	{[]byte{0xCE, 0xFA, 0xED, 0xFE}, "macho-binary"},
+	{[]byte("\x00asm"), "wasm"},
+	{[]byte("%PDF-"), "pdf"},
+	{[]byte("\x89PNG\r\n\x1a\n"), "png"},
+	{[]byte{0xFF, 0xD8, 0xFF}, "jpeg"},
+	{[]byte("GIF8"), "gif"},
+	{[]byte("PK\x03\x04"), "zip"},
+	{[]byte{0x1F, 0x8B}, "gzip"},
+	{[]byte("BZh"), "bzip2"},
+	{[]byte{0xFD, '7', 'z', 'X', 'Z', 0x00}, "xz"},
+	{[]byte{'7', 'z', 0xBC, 0xAF, 0x27, 0x1C}, "7z"},
+	{[]byte("SQLite format 3\x00"), "sqlite"},
+}
+
+// interpreterTypes maps shebang interpreters to file types
+var interpreterTypes = map[string]string{
+	"sh":      "sh",
+	"dash":    "sh",
+	"bash":    "bash",
+	"zsh":     "zsh",
+	"ksh":     "ksh",
+	"fish":    "fish",
+	"python":  "python",
+	"node":    "javascript",
+	"nodejs":  "javascript",
+	"deno":    "typescript",
+	"ruby":    "ruby",
+	"perl":    "perl",
+	"php":     "php",
+	"lua":     "lua",
+	"pwsh":    "powershell",
+	"Rscript": "r",
+}
+
+// versionSuffix matches a version on an interpreter name such as python3.11
+var versionSuffix = regexp.MustCompile(`[0-9.]+$`)
+
+// extensionTypes maps file extensions to types for text files without a shebang
+var extensionTypes = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".sh":   "sh",
+	".bash": "bash",
+	".zsh":  "zsh",
+	".js":   "javascript",
+	".mjs":  "javascript",
+	".ts":   "typescript",
+	".json": "json",
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".toml": "toml",
+	".xml":  "xml",
+	".html": "html",
+	".htm":  "html",
+	".css":  "css",
+	".md":   "markdown",
+	".c":    "c",
+	".h":    "c",
+	".cpp":  "cpp",
+	".rs":   "rust",
+	".java": "java",
+	".rb":   "ruby",
+	".pl":   "perl",
+	".php":  "php",
+	".ps1":  "powershell",
+	".sql":  "sql",
+	".csv":  "csv",
+}
+
+// DetectFileType guesses what kind of file path is from its first bytes:
+// magic numbers identify binary formats, a shebang names the interpreter of
+// a script, and otherwise the content and extension are used. Only a small
+// prefix of the file is read, so this is cheap even for large files.
+func (fm *FileManager) DetectFileType(path string) (FileType, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return FileType{}, err
+	}
+
+	file, err := os.Open(validPath)
+	if err != nil {
+		return FileType{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return FileType{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.IsDir() {
+		return FileType{}, fmt.Errorf("%s is a directory", path)
+	}
+
+	prefix := make([]byte, fileTypeSniffLen)
+	n, err := io.ReadFull(file, prefix)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return FileType{}, fmt.Errorf("failed to read file: %w", err)
+	}
+	prefix = prefix[:n]
+
+	detected := FileType{
+		Path:     validPath,
+		MimeType: detectMimeType(validPath, prefix),
+		Encoding: detectEncoding(prefix),
+		Binary:   IsBinary(prefix),
+	}
+	if detected.Binary {
+		detected.Encoding = "binary"
+	}
+	detected.Type, detected.Shebang = guessFileType(validPath, prefix, detected.Binary, int64(n) == info.Size())
+	return detected, nil
+}
+
+// guessFileType returns the type of a file from its leading bytes, and its
+// shebang line if it has one. complete is set when prefix is the whole file.
+func guessFileType(path string, prefix []byte, binary, complete bool) (string, string) {
+	for _, signature := range magicSignatures {
+		if bytes.HasPrefix(prefix, signature.prefix) {
+			return signature.fileType, ""
+		}
+	}
+	if binary {
+		return "binary", ""
+	}
+	if len(prefix) == 0 {
+		return "empty", ""
+	}
+
+	text := bytes.TrimPrefix(prefix, []byte{0xEF, 0xBB, 0xBF})
+	if bytes.HasPrefix(text, []byte("#!")) {
+		line, _, _ := strings.Cut(string(text), "\n")
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if fileType := interpreterType(line); fileType != "" {
+			return fileType, line
+		}
+		return "script", line
+	}
+
+	trimmed := bytes.TrimSpace(text)
+	lower := strings.ToLower(string(trimmed[:min(len(trimmed), 64)]))
+	switch {
+	case strings.HasPrefix(lower, "<?xml"):
+		return "xml", ""
+	case strings.HasPrefix(lower, "<!doctype html"), strings.HasPrefix(lower, "<html"):
+		return "html", ""
+	case bytes.HasPrefix(trimmed, []byte("{")), bytes.HasPrefix(trimmed, []byte("[")):
+		// A whole file can be checked; a longer one is judged by its start
+		if !complete || json.Valid(trimmed) {
+			return "json", ""
+		}
+	}
+
+	if fileType, ok := extensionTypes[strings.ToLower(filepath.Ext(path))]; ok {
+		return fileType, ""
+	}
+	return "text", ""
+}
+
+// interpreterType returns the file type for the interpreter named in a
+// shebang line, following /usr/bin/env to the program it runs
+func interpreterType(shebang string) string {
+	fields := strings.Fields(strings.TrimPrefix(shebang, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, arg := range fields[1:] {
+			// Skip options such as -S and variable assignments
+			if strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+				continue
+			}
+			interpreter = filepath.Base(arg)
+			break
+		}
+	}
+
+	if fileType, ok := interpreterTypes[interpreter]; ok {
+		return fileType
+	}
+	return interpreterTypes[versionSuffix.ReplaceAllString(interpreter, "")]
+}
+
+// detectEncoding names the text encoding of a sample from its byte order
+// mark or content: utf-8-bom, utf-16le, utf-16be, ascii or utf-8, or
+// unknown when the sample is none of these
+func detectEncoding(sample []byte) string {
+	switch {
+	case bytes.HasPrefix(sample, []byte{0xEF, 0xBB, 0xBF}):
+		return "utf-8-bom"
+	case bytes.HasPrefix(sample, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	case bytes.HasPrefix(sample, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	}
+
+	// The sample may end partway through a character
+	text := trimPartialRune(string(sample))
+	if !utf8.ValidString(text) {
+		return "unknown"
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return "utf-8"
+		}
+	}
+	return "ascii"
+}
+
+// String formats the file type as indented JSON
+func (t FileType) String() string {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("failed to encode file type: %v", err)
+	}
+	return string(data)
+}
+
+// DetectFileTypeSchema defines the input schema for detect_file_type
+var DetectFileTypeSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseDetectFileTypeArgs parses arguments for detect_file_type
+func ParseDetectFileTypeArgs(args json.RawMessage) (string, error) {
+	var params struct {
+		Path string `json:"path"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", fmt.Errorf("invalid arguments for detect_file_type: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", fmt.Errorf("path parameter is required")
+	}
+
+	return params.Path, nil
+}
@@ -0,0 +1,56 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectFileType(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	tests := []struct {
+		name     string
+		content  []byte
+		fileType string
+		encoding string
+		shebang  string
+	}{
+		{"script", []byte("#!/usr/bin/env python3\nprint('hi')\n"), "python", "ascii", "#!/usr/bin/env python3"},
+		{"run", []byte("#!/bin/bash -e\necho hi\n"), "bash", "ascii", "#!/bin/bash -e"},
+		{"program", []byte("\x7fELF\x02\x01\x01\x00\x00\x00"), "elf-binary", "binary", ""},
+		{"data.txt", []byte(`{"name": "café"}`), "json", "utf-8", ""},
+		{"broken.txt", []byte(`{"name": `), "text", "ascii", ""},
+		{"main.go", []byte("package main\n"), "go", "ascii", ""},
+		{"empty", nil, "empty", "ascii", ""},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.name)
+		if err := os.WriteFile(path, tt.content, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		detected, err := fm.DetectFileType(path)
+		if err != nil {
+			t.Fatalf("%s: DetectFileType failed: %v", tt.name, err)
+		}
+		if detected.Type != tt.fileType || detected.Encoding != tt.encoding || detected.Shebang != tt.shebang {
+			t.Errorf("%s: Expected type %q, encoding %q and shebang %q, got %q, %q and %q",
+				tt.name, tt.fileType, tt.encoding, tt.shebang, detected.Type, detected.Encoding, detected.Shebang)
+		}
+	}
+}
+
+func TestDetectFileTypeOutsideAllowedDirectories(t *testing.T) {
+	fm, _ := newTestFileManager(t)
+
+	outside := filepath.Join(t.TempDir(), "script.sh")
+	if err := os.WriteFile(outside, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	if _, err := fm.DetectFileType(outside); err == nil {
+		t.Error("Expected a path outside the allowed directories to be rejected")
+	}
+}
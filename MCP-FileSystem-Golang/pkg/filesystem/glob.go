@@ -0,0 +1,280 @@
+package filesystem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// DefaultGlobMaxResults caps the number of paths returned by Glob when no limit is given
+const DefaultGlobMaxResults = 1000
+
+// GlobResult holds the paths matching a glob pattern
+type GlobResult struct {
+	Paths     []string
+	Truncated bool // more paths matched than the result cap
+}
+
+// Glob returns the files and directories matching pattern, which uses '/'
+// separators and doublestar semantics: '*', '?' and '[...]' match within a
+// single path segment, and a '**' segment matches any number of segments.
+// A relative pattern is resolved against the working directory. Paths
+// matching any exclude pattern, which are relative to the pattern's base
+// directory, are left out, as are the directories in SnapshotIgnore. With
+// relative set, paths are returned relative to the base directory. A '**'
+// matches at most maxDepth levels below the base directory, or the
+// configured default depth when maxDepth is 0.
+func (fm *FileManager) Glob(pattern string, exclude []string, relative bool, maxResults, maxDepth int) (GlobResult, error) {
+	for _, p := range append([]string{pattern}, exclude...) {
+		if err := validateGlobPattern(p); err != nil {
+			return GlobResult{}, err
+		}
+	}
+
+	base, rest := splitGlobPattern(pattern)
+	if base == "" {
+		base = "."
+	}
+	validBase, err := fm.ValidatePath(filepath.FromSlash(base))
+	if err != nil {
+		return GlobResult{}, err
+	}
+
+	if maxResults <= 0 {
+		maxResults = DefaultGlobMaxResults
+	}
+	maxDepth = fm.walkDepth(maxDepth)
+
+	// A pattern without wildcards names a single path
+	if len(rest) == 0 {
+		if _, err := os.Lstat(validBase); err != nil {
+			return GlobResult{}, nil
+		}
+		return GlobResult{Paths: []string{globResultPath(validBase, ".", relative)}}, nil
+	}
+
+	excludeSegments := make([][]string, len(exclude))
+	for i, ex := range exclude {
+		excludeSegments[i] = strings.Split(strings.Trim(ex, "/"), "/")
+	}
+
+	var result GlobResult
+	err = filepath.WalkDir(validBase, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip errors and continue walking
+			return nil
+		}
+		if walkPath == validBase {
+			return nil
+		}
+
+		rel, err := filepath.Rel(validBase, walkPath)
+		if err != nil {
+			return nil
+		}
+		segments := strings.Split(filepath.ToSlash(rel), "/")
+
+		if isSnapshotIgnored(d.Name()) || isGlobExcluded(excludeSegments, segments, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if _, validateErr := fm.ValidatePath(walkPath); validateErr != nil {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if matchGlobSegments(rest, segments) {
+			if len(result.Paths) >= maxResults {
+				result.Truncated = true
+				return filepath.SkipAll
+			}
+			result.Paths = append(result.Paths, globResultPath(validBase, rel, relative))
+		}
+
+		// Don't descend further than a pattern without '**' can reach
+		if d.IsDir() && !containsDoublestar(rest) && len(segments) >= len(rest) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() && reachedMaxDepth(validBase, walkPath, maxDepth) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return GlobResult{}, err
+	}
+
+	return result, nil
+}
+
+// globResultPath formats a matched path as absolute or relative to the base directory
+func globResultPath(base, rel string, relative bool) string {
+	if relative {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.Join(base, rel)
+}
+
+// validateGlobPattern checks that every segment of a pattern is well formed
+func validateGlobPattern(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("pattern must not be empty")
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "**" {
+			continue
+		}
+		if strings.Contains(segment, "**") {
+			return fmt.Errorf("invalid pattern %q: '**' must be a whole path segment", pattern)
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// splitGlobPattern splits a pattern into the leading directory without
+// wildcards and the remaining segments
+func splitGlobPattern(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			base := strings.Join(segments[:i], "/")
+			if base == "" && i > 0 {
+				base = "/"
+			}
+			return base, segments[i:]
+		}
+	}
+	return pattern, nil
+}
+
+// containsDoublestar reports whether any segment is '**'
+func containsDoublestar(segments []string) bool {
+	for _, segment := range segments {
+		if segment == "**" {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobSegments reports whether the path segments match the pattern
+// segments, where a '**' segment matches zero or more path segments
+func matchGlobSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlobSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// isGlobExcluded reports whether a path matches an exclude pattern. A
+// directory is also excluded by a pattern ending in '/**' that matches it,
+// so its contents aren't walked.
+func isGlobExcluded(excludes [][]string, segments []string, isDir bool) bool {
+	for _, exclude := range excludes {
+		if matchGlobSegments(exclude, segments) {
+			return true
+		}
+		if isDir && len(exclude) > 1 && exclude[len(exclude)-1] == "**" &&
+			matchGlobSegments(exclude[:len(exclude)-1], segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// GlobSchema defines the input schema for glob
+var GlobSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"pattern": map[string]interface{}{
+			"type":        "string",
+			"description": "Glob pattern using '/' separators, e.g. src/**/*.go. '**' matches any number of directories",
+		},
+		"exclude": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "string",
+			},
+			"description": "Patterns for paths to leave out, relative to the pattern's base directory, e.g. **/testdata/**",
+		},
+		"relative": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Return paths relative to the pattern's base directory (default false)",
+		},
+		"max_results": map[string]interface{}{
+			"type":        "integer",
+			"description": "Maximum number of paths to return (default 1000)",
+		},
+		"max_depth": maxDepthSchema,
+	},
+	"required": []string{"pattern"},
+}
+
+// ParseGlobArgs parses arguments for glob
+func ParseGlobArgs(args json.RawMessage) (string, []string, bool, int, int, error) {
+	var params struct {
+		Pattern    string   `json:"pattern"`
+		Exclude    []string `json:"exclude"`
+		Relative   bool     `json:"relative"`
+		MaxResults int      `json:"max_results"`
+		MaxDepth   int      `json:"max_depth"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", nil, false, 0, 0, fmt.Errorf("invalid arguments for glob: %w", err)
+	}
+
+	if params.Pattern == "" {
+		return "", nil, false, 0, 0, fmt.Errorf("pattern parameter is required")
+	}
+
+	if params.MaxResults < 0 {
+		return "", nil, false, 0, 0, fmt.Errorf("max_results parameter must not be negative")
+	}
+
+	if params.MaxDepth < 0 {
+		return "", nil, false, 0, 0, fmt.Errorf("max_depth parameter must not be negative")
+	}
+
+	return params.Pattern, params.Exclude, params.Relative, params.MaxResults, params.MaxDepth, nil
+}
+
+// String formats the result as one path per line with a note when truncated
+func (r GlobResult) String() string {
+	if len(r.Paths) == 0 {
+		return "No matches found"
+	}
+	output := strings.Join(r.Paths, "\n")
+	if r.Truncated {
+		output += fmt.Sprintf("\n(results truncated at %d paths)", len(r.Paths))
+	}
+	return output
+}
@@ -0,0 +1,90 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMatchGlobSegments(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "cmd/server/main.go", true},
+		{"src/**/*.go", "src/a/b/c.go", true},
+		{"src/**/*.go", "lib/a.go", false},
+		{"src/**", "src/a/b", true},
+		{"a/?.txt", "a/b.txt", true},
+		{"a/[bc].txt", "a/d.txt", false},
+	}
+
+	for _, tt := range tests {
+		got := matchGlobSegments(strings.Split(tt.pattern, "/"), strings.Split(tt.path, "/"))
+		if got != tt.want {
+			t.Errorf("matchGlobSegments(%q, %q) = %t, expected %t", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestGlob(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	for _, name := range []string{
+		"main.go",
+		"README.md",
+		"cmd/server/main.go",
+		"pkg/util/util.go",
+		"pkg/util/testdata/fixture.go",
+		"node_modules/dep/index.go",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("package x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	pattern := filepath.ToSlash(dir) + "/**/*.go"
+	result, err := fm.Glob(pattern, []string{"**/testdata/**"}, true, 0, 0)
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	expected := []string{"cmd/server/main.go", "main.go", "pkg/util/util.go"}
+	if !reflect.DeepEqual(result.Paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, result.Paths)
+	}
+
+	// Absolute paths by default, and the cap marks the result truncated
+	result, err = fm.Glob(pattern, nil, false, 2, 0)
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if len(result.Paths) != 2 || !result.Truncated {
+		t.Errorf("Expected 2 paths and truncation, got %v (truncated=%t)", result.Paths, result.Truncated)
+	}
+	if !filepath.IsAbs(result.Paths[0]) {
+		t.Errorf("Expected absolute paths, got %q", result.Paths[0])
+	}
+
+	// Single-segment wildcards don't cross directories
+	result, err = fm.Glob(filepath.ToSlash(dir)+"/*.go", nil, true, 0, 0)
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Paths, []string{"main.go"}) {
+		t.Errorf("Expected only main.go, got %v", result.Paths)
+	}
+
+	if _, err := fm.Glob(filepath.ToSlash(dir)+"/a**b", nil, false, 0, 0); err == nil {
+		t.Error("Expected an error for '**' within a segment")
+	}
+}
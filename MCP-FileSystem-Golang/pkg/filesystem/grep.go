@@ -0,0 +1,325 @@
+package filesystem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// DefaultGrepMaxLinesPerFile is the line count above which a file is skipped by GrepFiles
+const DefaultGrepMaxLinesPerFile = 100000
+
+// DefaultGrepTimeout bounds how long a single GrepFiles call may run
+const DefaultGrepTimeout = 30 * time.Second
+
+// maxGrepLineLength is the longest line GrepFiles will scan; files with longer lines are skipped
+const maxGrepLineLength = 1024 * 1024
+
+// DefaultGrepMaxResults is the number of matches after which GrepFiles stops
+// when no cap is given
+const DefaultGrepMaxResults = 1000
+
+// grepCancelCheckInterval is how many lines are scanned between checks for cancellation
+const grepCancelCheckInterval = 1000
+
+// GrepOptions limits the work done by GrepFiles; zero fields use the defaults
+type GrepOptions struct {
+	MaxLinesPerFile int           // files with more lines are skipped
+	Timeout         time.Duration // overall time limit for one search
+}
+
+// SetGrepOptions sets the limits applied to GrepFiles
+func (fm *FileManager) SetGrepOptions(opts GrepOptions) {
+	fm.grepOptions = opts
+}
+
+// GrepQuery holds the per-call options of a GrepFiles search
+type GrepQuery struct {
+	MaxDepth   int  // levels below the root to descend; 0 uses the default
+	IgnoreCase bool // match letters regardless of case
+	MaxResults int  // stop after this many matches; 0 uses DefaultGrepMaxResults
+	// Exclude holds glob patterns of files and directories to skip
+	Exclude []string
+}
+
+// GrepMatch is a single matching line
+type GrepMatch struct {
+	Path string
+	Line int
+	Text string
+}
+
+// GrepResult holds the matches found along with warnings about skipped files
+type GrepResult struct {
+	Matches  []GrepMatch
+	Warnings []string
+	TimedOut bool
+	// Truncated is set when the search stopped at the match cap
+	Truncated bool
+}
+
+// errGrepLineCap is returned when a file has more lines than the configured cap
+var errGrepLineCap = errors.New("line cap exceeded")
+
+// errGrepMaxResults stops the walk once the match cap is reached
+var errGrepMaxResults = errors.New("match cap reached")
+
+// GrepFiles searches the contents of text files under rootPath for lines
+// matching the regular expression pattern. Binary files and hidden entries
+// are skipped, as are entries matching query.Exclude and files exceeding the
+// per-file line cap, which are noted in the warnings. When the timeout
+// expires or ctx is cancelled the search stops and the matches found so far
+// are returned with TimedOut set; the same happens with Truncated set once
+// query.MaxResults matches are found.
+// The walk goes at most query.MaxDepth levels below rootPath, or the
+// configured default depth when it is 0.
+func GrepFiles(ctx context.Context, fm *FileManager, rootPath, pattern string, query GrepQuery) (GrepResult, error) {
+	if query.IgnoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return GrepResult{}, fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	excluder, err := newWalkExcluder(query.Exclude)
+	if err != nil {
+		return GrepResult{}, err
+	}
+
+	validRootPath, err := fm.ValidatePath(rootPath)
+	if err != nil {
+		return GrepResult{}, err
+	}
+
+	opts := fm.grepOptions
+	if opts.MaxLinesPerFile <= 0 {
+		opts.MaxLinesPerFile = DefaultGrepMaxLinesPerFile
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultGrepTimeout
+	}
+	maxDepth := fm.walkDepth(query.MaxDepth)
+	maxResults := query.MaxResults
+	if maxResults <= 0 {
+		maxResults = DefaultGrepMaxResults
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	var result GrepResult
+	err = filepath.WalkDir(validRootPath, func(path string, d fs.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if err != nil {
+			// Skip errors and continue walking
+			return nil
+		}
+
+		if (path != validRootPath && isHidden(d.Name())) || excluder.excluded(validRootPath, path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if _, validateErr := fm.ValidatePath(path); validateErr != nil {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if d.IsDir() && reachedMaxDepth(validRootPath, path, maxDepth) {
+			return filepath.SkipDir
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		matches, err := grepFile(ctx, path, re, opts.MaxLinesPerFile, maxResults-len(result.Matches))
+		switch {
+		case err == nil:
+			result.Matches = append(result.Matches, matches...)
+			if len(result.Matches) >= maxResults {
+				return errGrepMaxResults
+			}
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			return err
+		case errors.Is(err, errGrepLineCap):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: more than %d lines", path, opts.MaxLinesPerFile))
+		case errors.Is(err, bufio.ErrTooLong):
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: line longer than %d bytes", path, maxGrepLineLength))
+		default:
+			result.Warnings = append(result.Warnings, fmt.Sprintf("skipped %s: %v", path, err))
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		result.TimedOut = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf("search stopped after %v; results are incomplete", opts.Timeout))
+		return result, nil
+	}
+	if errors.Is(err, errGrepMaxResults) {
+		result.Truncated = true
+		result.Warnings = append(result.Warnings, fmt.Sprintf("search stopped after %d matches; narrow the pattern or path, or raise max_results", maxResults))
+		return result, nil
+	}
+	if err != nil {
+		return GrepResult{}, err
+	}
+
+	return result, nil
+}
+
+// grepFile returns up to maxMatches lines of a single file matching re.
+// Binary files yield no matches.
+func grepFile(ctx context.Context, path string, re *regexp.Regexp, maxLines, maxMatches int) ([]GrepMatch, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, binarySniffLen)
+	sample, err := reader.Peek(binarySniffLen)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if IsBinary(sample) {
+		return nil, nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxGrepLineLength)
+
+	var matches []GrepMatch
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		if lineNumber > maxLines {
+			return nil, errGrepLineCap
+		}
+
+		if lineNumber%grepCancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		line := scanner.Text()
+		if re.MatchString(line) {
+			matches = append(matches, GrepMatch{Path: path, Line: lineNumber, Text: line})
+			if len(matches) >= maxMatches {
+				return matches, nil
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// String formats the result as one "path:line: text" entry per match followed by any warnings
+func (r GrepResult) String() string {
+	var lines []string
+	if len(r.Matches) == 0 {
+		lines = append(lines, "No matches found")
+	} else {
+		lines = append(lines, fmt.Sprintf("%d matches found:", len(r.Matches)))
+		for _, match := range r.Matches {
+			lines = append(lines, fmt.Sprintf("%s:%d: %s", match.Path, match.Line, match.Text))
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		lines = append(lines, "", "Warnings:")
+		lines = append(lines, r.Warnings...)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GrepFilesSchema defines the input schema for grep_files
+var GrepFilesSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"pattern": map[string]interface{}{
+			"type":        "string",
+			"description": "Regular expression (Go RE2 syntax) matched against each line",
+		},
+		"ignore_case": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Match letters regardless of case (default false)",
+		},
+		"max_results": map[string]interface{}{
+			"type":        "integer",
+			"description": "Stop after this many matches (default 1000)",
+		},
+		"exclude":   excludeSchema,
+		"max_depth": maxDepthSchema,
+	},
+	"required": []string{"path", "pattern"},
+}
+
+// ParseGrepFilesArgs parses arguments for grep_files
+func ParseGrepFilesArgs(args json.RawMessage) (string, string, GrepQuery, error) {
+	var params struct {
+		Path       string   `json:"path"`
+		Pattern    string   `json:"pattern"`
+		IgnoreCase bool     `json:"ignore_case"`
+		MaxResults int      `json:"max_results"`
+		MaxDepth   int      `json:"max_depth"`
+		Exclude    []string `json:"exclude"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", GrepQuery{}, fmt.Errorf("invalid arguments for grep_files: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", "", GrepQuery{}, fmt.Errorf("path parameter is required")
+	}
+
+	if params.Pattern == "" {
+		return "", "", GrepQuery{}, fmt.Errorf("pattern parameter is required")
+	}
+
+	if params.MaxDepth < 0 {
+		return "", "", GrepQuery{}, fmt.Errorf("max_depth parameter must not be negative")
+	}
+
+	if params.MaxResults < 0 {
+		return "", "", GrepQuery{}, fmt.Errorf("max_results parameter must not be negative")
+	}
+
+	query := GrepQuery{
+		MaxDepth:   params.MaxDepth,
+		IgnoreCase: params.IgnoreCase,
+		MaxResults: params.MaxResults,
+		Exclude:    params.Exclude,
+	}
+	return params.Path, params.Pattern, query, nil
+}
@@ -0,0 +1,107 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGrepFilesLineCap(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	fm.SetGrepOptions(GrepOptions{MaxLinesPerFile: 10})
+
+	if err := os.WriteFile(filepath.Join(dir, "small.txt"), []byte("one\nneedle here\nthree\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	huge := strings.Repeat("needle\n", 50)
+	if err := os.WriteFile(filepath.Join(dir, "huge.txt"), []byte(huge), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	longLine := strings.Repeat("x", maxGrepLineLength+1) + " needle\n"
+	if err := os.WriteFile(filepath.Join(dir, "long.txt"), []byte(longLine), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data.bin"), []byte("needle\x00\x01\x02"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{})
+	if err != nil {
+		t.Fatalf("GrepFiles failed: %v", err)
+	}
+
+	if len(result.Matches) != 1 {
+		t.Fatalf("Expected 1 match from small.txt, got %d: %v", len(result.Matches), result.Matches)
+	}
+	if filepath.Base(result.Matches[0].Path) != "small.txt" || result.Matches[0].Line != 2 {
+		t.Errorf("Expected match at small.txt:2, got %s:%d", result.Matches[0].Path, result.Matches[0].Line)
+	}
+
+	if result.TimedOut {
+		t.Error("Expected search not to time out")
+	}
+
+	warnings := strings.Join(result.Warnings, "\n")
+	if !strings.Contains(warnings, "huge.txt: more than 10 lines") {
+		t.Errorf("Expected warning about the line cap, got %q", warnings)
+	}
+	if !strings.Contains(warnings, "long.txt: line longer than") {
+		t.Errorf("Expected warning about the long line, got %q", warnings)
+	}
+}
+
+func TestGrepFilesTimeout(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	fm.SetGrepOptions(GrepOptions{Timeout: time.Nanosecond})
+
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("needle\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{})
+	if err != nil {
+		t.Fatalf("Expected a timed out search to return partial results, got error: %v", err)
+	}
+	if !result.TimedOut {
+		t.Error("Expected search to report that it timed out")
+	}
+}
+
+func TestGrepFilesIgnoreCaseAndMaxResults(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("Needle\nneedle\nNEEDLE\nhay\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.txt"), []byte("needle\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("GrepFiles failed: %v", err)
+	}
+	if len(result.Matches) != 4 || result.Truncated {
+		t.Errorf("Expected 4 case-insensitive matches, got %d: %v", len(result.Matches), result.Matches)
+	}
+
+	result, err = GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{})
+	if err != nil || len(result.Matches) != 2 {
+		t.Errorf("Expected 2 case-sensitive matches, got %v (%v)", result.Matches, err)
+	}
+
+	// The cap stops the search partway through a file
+	result, err = GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{IgnoreCase: true, MaxResults: 2})
+	if err != nil {
+		t.Fatalf("GrepFiles failed: %v", err)
+	}
+	if len(result.Matches) != 2 || !result.Truncated {
+		t.Errorf("Expected 2 matches and the result marked truncated, got %d: %v", len(result.Matches), result.Matches)
+	}
+	if !strings.Contains(result.String(), "search stopped after 2 matches") {
+		t.Errorf("Expected a warning about the cap, got %q", result.String())
+	}
+}
@@ -0,0 +1,286 @@
+package filesystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// Planned action kinds
+const (
+	ActionCreate    = "create"
+	ActionOverwrite = "overwrite"
+	ActionModify    = "modify"
+)
+
+// PlannedAction describes one change a multi-file operation makes, or would
+// make in plan-only mode
+type PlannedAction struct {
+	Path         string `json:"path"`
+	Action       string `json:"action"`
+	Bytes        int    `json:"bytes"`
+	Replacements int    `json:"replacements,omitempty"`
+}
+
+// Plan is the result of a multi-file operation: the actions taken, or with
+// PlanOnly set, the actions that would be taken
+type Plan struct {
+	PlanOnly bool            `json:"planOnly"`
+	Actions  []PlannedAction `json:"actions"`
+}
+
+// FileWrite is a single file to write with write_multiple_files
+type FileWrite struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
+
+// plannedWrite pairs an action with the content it writes, so execution
+// writes exactly what was planned
+type plannedWrite struct {
+	action  PlannedAction
+	content string
+}
+
+// WriteMultipleFiles writes several files. Every path is validated before
+// anything is written. With planOnly set nothing is written and the plan
+// describes what would happen.
+func (fm *FileManager) WriteMultipleFiles(files []FileWrite, planOnly bool) (Plan, error) {
+	writes, err := fm.planWrites(files)
+	if err != nil {
+		return Plan{}, err
+	}
+	return fm.executePlan(writes, planOnly)
+}
+
+// SearchAndReplaceAcrossFiles replaces every occurrence of search with
+// replace in the text files under rootPath. Binary and hidden files are
+// skipped. With planOnly set nothing is written and the plan lists the files
+// that would change with their replacement counts.
+func (fm *FileManager) SearchAndReplaceAcrossFiles(rootPath, search, replace string, planOnly bool) (Plan, error) {
+	writes, err := fm.planReplacements(rootPath, search, replace)
+	if err != nil {
+		return Plan{}, err
+	}
+	return fm.executePlan(writes, planOnly)
+}
+
+// planWrites validates each file to write and records whether it will be created or overwritten
+func (fm *FileManager) planWrites(files []FileWrite) ([]plannedWrite, error) {
+	var writes []plannedWrite
+	for _, file := range files {
+		validPath, err := fm.ValidatePath(file.Path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path, err)
+		}
+
+		action := ActionCreate
+		if info, err := os.Stat(validPath); err == nil {
+			if info.IsDir() {
+				return nil, fmt.Errorf("%s is a directory", file.Path)
+			}
+			action = ActionOverwrite
+		}
+
+		writes = append(writes, plannedWrite{
+			action:  PlannedAction{Path: validPath, Action: action, Bytes: len(file.Content)},
+			content: file.Content,
+		})
+	}
+	return writes, nil
+}
+
+// planReplacements walks rootPath and computes the new content of every text
+// file containing search
+func (fm *FileManager) planReplacements(rootPath, search, replace string) ([]plannedWrite, error) {
+	if search == "" {
+		return nil, fmt.Errorf("search must not be empty")
+	}
+
+	validRootPath, err := fm.ValidatePath(rootPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var writes []plannedWrite
+	err = filepath.WalkDir(validRootPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip errors and continue walking
+			return nil
+		}
+
+		if path != validRootPath && isHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if _, validateErr := fm.ValidatePath(path); validateErr != nil {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+
+		sample := content
+		if len(sample) > binarySniffLen {
+			sample = sample[:binarySniffLen]
+		}
+		if IsBinary(sample) {
+			return nil
+		}
+
+		count := bytes.Count(content, []byte(search))
+		if count == 0 {
+			return nil
+		}
+
+		updated := bytes.ReplaceAll(content, []byte(search), []byte(replace))
+		writes = append(writes, plannedWrite{
+			action:  PlannedAction{Path: path, Action: ActionModify, Bytes: len(updated), Replacements: count},
+			content: string(updated),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return writes, nil
+}
+
+// executePlan performs the planned writes unless planOnly is set
+func (fm *FileManager) executePlan(writes []plannedWrite, planOnly bool) (Plan, error) {
+	plan := Plan{PlanOnly: planOnly, Actions: make([]PlannedAction, 0, len(writes))}
+	for _, write := range writes {
+		if !planOnly {
+			if err := fm.WriteFile(write.action.Path, write.content, false); err != nil {
+				return plan, fmt.Errorf("%s: %w", write.action.Path, err)
+			}
+		}
+		plan.Actions = append(plan.Actions, write.action)
+	}
+	return plan, nil
+}
+
+// String formats the plan as indented JSON
+func (p Plan) String() string {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("failed to encode plan: %v", err)
+	}
+	return string(data)
+}
+
+// WriteMultipleFilesSchema defines the input schema for write_multiple_files
+var WriteMultipleFilesSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"files": map[string]interface{}{
+			"type": "array",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": map[string]interface{}{
+						"type": "string",
+					},
+					"content": map[string]interface{}{
+						"type": "string",
+					},
+				},
+				"required": []string{"path", "content"},
+			},
+		},
+		"plan_only": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Return the planned actions without writing anything (default false)",
+		},
+	},
+	"required": []string{"files"},
+}
+
+// SearchAndReplaceAcrossFilesSchema defines the input schema for search_and_replace_across_files
+var SearchAndReplaceAcrossFilesSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"search": map[string]interface{}{
+			"type":        "string",
+			"description": "Exact text to find",
+		},
+		"replace": map[string]interface{}{
+			"type":        "string",
+			"description": "Text to replace each occurrence with",
+		},
+		"plan_only": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Return the files that would change and their replacement counts without writing anything (default false)",
+		},
+	},
+	"required": []string{"path", "search", "replace"},
+}
+
+// ParseWriteMultipleFilesArgs parses arguments for write_multiple_files
+func ParseWriteMultipleFilesArgs(args json.RawMessage) ([]FileWrite, bool, error) {
+	var params struct {
+		Files    []FileWrite `json:"files"`
+		PlanOnly bool        `json:"plan_only"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return nil, false, fmt.Errorf("invalid arguments for write_multiple_files: %w", err)
+	}
+
+	if len(params.Files) == 0 {
+		return nil, false, fmt.Errorf("files parameter is required and must not be empty")
+	}
+
+	for _, file := range params.Files {
+		if file.Path == "" {
+			return nil, false, fmt.Errorf("each file requires a path")
+		}
+	}
+
+	return params.Files, params.PlanOnly, nil
+}
+
+// ParseSearchAndReplaceAcrossFilesArgs parses arguments for search_and_replace_across_files
+func ParseSearchAndReplaceAcrossFilesArgs(args json.RawMessage) (string, string, string, bool, error) {
+	var params struct {
+		Path     string `json:"path"`
+		Search   string `json:"search"`
+		Replace  string `json:"replace"`
+		PlanOnly bool   `json:"plan_only"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", "", false, fmt.Errorf("invalid arguments for search_and_replace_across_files: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", "", "", false, fmt.Errorf("path parameter is required")
+	}
+
+	if params.Search == "" {
+		return "", "", "", false, fmt.Errorf("search parameter is required")
+	}
+
+	return params.Path, params.Search, params.Replace, params.PlanOnly, nil
+}
@@ -0,0 +1,140 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteMultipleFilesPlanMatchesEffects(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	existing := filepath.Join(dir, "existing.txt")
+	if err := os.WriteFile(existing, []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	created := filepath.Join(dir, "new.txt")
+
+	files := []FileWrite{
+		{Path: existing, Content: "replaced"},
+		{Path: created, Content: "brand new"},
+	}
+
+	plan, err := fm.WriteMultipleFiles(files, true)
+	if err != nil {
+		t.Fatalf("WriteMultipleFiles plan failed: %v", err)
+	}
+	if !plan.PlanOnly || len(plan.Actions) != 2 {
+		t.Fatalf("Expected a plan with 2 actions, got %+v", plan)
+	}
+	if plan.Actions[0].Action != ActionOverwrite || plan.Actions[1].Action != ActionCreate {
+		t.Errorf("Expected overwrite then create, got %s then %s", plan.Actions[0].Action, plan.Actions[1].Action)
+	}
+
+	// Planning must not touch disk
+	if content, _ := os.ReadFile(existing); string(content) != "old" {
+		t.Errorf("Expected existing file to be unchanged by the plan, got %q", content)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("Expected new file not to be created by the plan, got %v", err)
+	}
+
+	result, err := fm.WriteMultipleFiles(files, false)
+	if err != nil {
+		t.Fatalf("WriteMultipleFiles failed: %v", err)
+	}
+	if result.PlanOnly || len(result.Actions) != len(plan.Actions) {
+		t.Fatalf("Expected executed actions to match the plan, got %+v", result)
+	}
+	for i, action := range plan.Actions {
+		if result.Actions[i] != action {
+			t.Errorf("Expected action %+v, got %+v", action, result.Actions[i])
+		}
+		info, err := os.Stat(action.Path)
+		if err != nil || info.Size() != int64(action.Bytes) {
+			t.Errorf("Expected %s to have %d bytes as planned, got %v (%v)", action.Path, action.Bytes, info, err)
+		}
+	}
+}
+
+func TestWriteMultipleFilesValidatesBeforeWriting(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	first := filepath.Join(dir, "first.txt")
+	_, err := fm.WriteMultipleFiles([]FileWrite{
+		{Path: first, Content: "data"},
+		{Path: filepath.Join(os.TempDir(), "outside-allowed.txt"), Content: "data"},
+	}, false)
+	if err == nil {
+		t.Fatal("Expected an error for a path outside the allowed directories")
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("Expected no files to be written when any path is invalid, got %v", err)
+	}
+}
+
+func TestSearchAndReplacePlanMatchesEffects(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	files := map[string]string{
+		"a.txt":         "foo bar foo",
+		"sub/b.txt":     "no match here",
+		"sub/c.txt":     "foo",
+		".hidden/d.txt": "foo",
+		"image.bin":     "foo\x00\x01\x02",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	plan, err := fm.SearchAndReplaceAcrossFiles(dir, "foo", "quux", true)
+	if err != nil {
+		t.Fatalf("SearchAndReplaceAcrossFiles plan failed: %v", err)
+	}
+
+	expected := map[string]int{
+		filepath.Join(dir, "a.txt"):     2,
+		filepath.Join(dir, "sub/c.txt"): 1,
+	}
+	if len(plan.Actions) != len(expected) {
+		t.Fatalf("Expected %d planned modifications, got %+v", len(expected), plan.Actions)
+	}
+	for _, action := range plan.Actions {
+		if action.Action != ActionModify || action.Replacements != expected[action.Path] {
+			t.Errorf("Unexpected planned action %+v", action)
+		}
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(content) != "foo bar foo" {
+		t.Errorf("Expected the plan not to modify files, got %q", content)
+	}
+
+	result, err := fm.SearchAndReplaceAcrossFiles(dir, "foo", "quux", false)
+	if err != nil {
+		t.Fatalf("SearchAndReplaceAcrossFiles failed: %v", err)
+	}
+	if len(result.Actions) != len(plan.Actions) {
+		t.Fatalf("Expected executed actions to match the plan, got %+v", result.Actions)
+	}
+	for i, action := range plan.Actions {
+		if result.Actions[i] != action {
+			t.Errorf("Expected action %+v, got %+v", action, result.Actions[i])
+		}
+		content, err := os.ReadFile(action.Path)
+		if err != nil || len(content) != action.Bytes {
+			t.Errorf("Expected %s to have %d bytes as planned, got %q (%v)", action.Path, action.Bytes, content, err)
+		}
+	}
+
+	if content, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(content) != "quux bar quux" {
+		t.Errorf("Expected replacements in a.txt, got %q", content)
+	}
+	if content, _ := os.ReadFile(filepath.Join(dir, ".hidden/d.txt")); string(content) != "foo" {
+		t.Errorf("Expected hidden files to be skipped, got %q", content)
+	}
+}
@@ -0,0 +1,188 @@
+package filesystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// DefaultOpenFileMaxBytes is the most content open_file returns when no limit is given
+const DefaultOpenFileMaxBytes = 256 * 1024
+
+// OpenedFile is a file's metadata together with its content
+type OpenedFile struct {
+	Path         string    `json:"path"`
+	Size         int64     `json:"size"`
+	ModifiedTime time.Time `json:"modifiedTime"`
+	MimeType     string    `json:"mimeType"`
+	LineCount    int       `json:"lineCount"`
+	Binary       bool      `json:"binary"`
+	Truncated    bool      `json:"truncated"`
+	Content      string    `json:"content"`
+}
+
+// OpenFile returns a file's metadata and up to maxBytes of its content in one
+// call. Truncated is set when the file is longer than maxBytes; LineCount
+// always covers the whole file. Binary files are reported with their
+// metadata but no content.
+func (fm *FileManager) OpenFile(path string, maxBytes int) (OpenedFile, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return OpenedFile{}, err
+	}
+
+	info, err := GetFileStats(validPath)
+	if err != nil {
+		return OpenedFile{}, fmt.Errorf("failed to get file info: %w", err)
+	}
+	if info.IsDirectory {
+		return OpenedFile{}, fmt.Errorf("%s is a directory", path)
+	}
+
+	if maxBytes <= 0 {
+		maxBytes = DefaultOpenFileMaxBytes
+	}
+
+	content, atEOF, err := fm.ReadFileAt(validPath, 0, maxBytes)
+	if err != nil {
+		return OpenedFile{}, err
+	}
+
+	opened := OpenedFile{
+		Path:         validPath,
+		Size:         info.Size,
+		ModifiedTime: info.Modified,
+		MimeType:     detectMimeType(validPath, []byte(content)),
+		Truncated:    !atEOF,
+	}
+
+	sample := []byte(content)
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if IsBinary(sample) {
+		opened.Binary = true
+		return opened, nil
+	}
+
+	opened.LineCount, err = countLines(validPath)
+	if err != nil {
+		return OpenedFile{}, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	// Don't end truncated content partway through a character
+	if opened.Truncated {
+		content = trimPartialRune(content)
+	}
+	opened.Content = strings.ToValidUTF8(content, string(utf8.RuneError))
+
+	return opened, nil
+}
+
+// detectMimeType guesses a file's MIME type from its extension, falling back to sniffing its content
+func detectMimeType(path string, content []byte) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(path)); mimeType != "" {
+		return mimeType
+	}
+	return http.DetectContentType(content)
+}
+
+// countLines counts the lines in a file, including a final line without a newline
+func countLines(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	buf := make([]byte, 32*1024)
+	lines := 0
+	last := byte('\n') // an empty file has no lines
+	for {
+		n, err := file.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if last != '\n' {
+		lines++
+	}
+	return lines, nil
+}
+
+// trimPartialRune drops an incomplete UTF-8 sequence cut off at the end of s
+func trimPartialRune(s string) string {
+	for i := 1; i < utf8.UTFMax && i <= len(s); i++ {
+		if utf8.RuneStart(s[len(s)-i]) {
+			if !utf8.FullRuneInString(s[len(s)-i:]) {
+				return s[:len(s)-i]
+			}
+			break
+		}
+	}
+	return s
+}
+
+// String formats the opened file as indented JSON
+func (f OpenedFile) String() string {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("failed to encode file: %v", err)
+	}
+	return string(data)
+}
+
+// OpenFileSchema defines the input schema for open_file
+var OpenFileSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"max_bytes": map[string]interface{}{
+			"type":        "integer",
+			"description": "Maximum number of bytes of content to return (default 262144); longer files are truncated",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseOpenFileArgs parses arguments for open_file
+func ParseOpenFileArgs(args json.RawMessage) (string, int, error) {
+	var params struct {
+		Path     string `json:"path"`
+		MaxBytes int    `json:"max_bytes"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, fmt.Errorf("invalid arguments for open_file: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", 0, fmt.Errorf("path parameter is required")
+	}
+
+	if params.MaxBytes < 0 {
+		return "", 0, fmt.Errorf("max_bytes parameter must not be negative")
+	}
+
+	return params.Path, params.MaxBytes, nil
+}
@@ -0,0 +1,69 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenFile(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	testFile := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(testFile, []byte("first\nsecond\nthird"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	opened, err := fm.OpenFile(testFile, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if opened.Content != "first\nsecond\nthird" || opened.Truncated {
+		t.Errorf("Expected full content without truncation, got %q (truncated=%t)", opened.Content, opened.Truncated)
+	}
+	if opened.Size != 18 || opened.LineCount != 3 {
+		t.Errorf("Expected size 18 and 3 lines, got size %d and %d lines", opened.Size, opened.LineCount)
+	}
+	if opened.MimeType != "text/plain; charset=utf-8" {
+		t.Errorf("Expected text/plain mime type, got %q", opened.MimeType)
+	}
+
+	// A limit smaller than the file truncates the content but not the line count
+	opened, err = fm.OpenFile(testFile, 8)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if opened.Content != "first\nse" || !opened.Truncated || opened.LineCount != 3 {
+		t.Errorf("Expected truncated content %q with 3 lines, got %q (truncated=%t, lines=%d)", "first\nse", opened.Content, opened.Truncated, opened.LineCount)
+	}
+
+	// Truncation never splits a multi-byte character
+	unicodeFile := filepath.Join(dir, "unicode.txt")
+	if err := os.WriteFile(unicodeFile, []byte("abécd"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	opened, err = fm.OpenFile(unicodeFile, 3)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if opened.Content != "ab" {
+		t.Errorf("Expected the partial character to be dropped, got %q", opened.Content)
+	}
+}
+
+func TestOpenFileBinary(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	testFile := filepath.Join(dir, "data.bin")
+	if err := os.WriteFile(testFile, []byte{0x00, 0x01, 0x02, 0x03}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	opened, err := fm.OpenFile(testFile, 0)
+	if err != nil {
+		t.Fatalf("OpenFile failed: %v", err)
+	}
+	if !opened.Binary || opened.Content != "" || opened.Size != 4 {
+		t.Errorf("Expected binary file with no content and size 4, got binary=%t content=%q size=%d", opened.Binary, opened.Content, opened.Size)
+	}
+}
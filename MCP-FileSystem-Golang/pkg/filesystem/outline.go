@@ -0,0 +1,308 @@
+package filesystem
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// OutlineThreshold is the size above which read_file returns an outline
+// instead of the content when outline_if_large is set
+const OutlineThreshold = 256 * 1024
+
+const (
+	outlineHeadLines  = 40   // lines shown from the start of the file
+	outlineTailLines  = 20   // lines shown from the end of the file
+	outlineMaxEntries = 200  // declarations or headers listed
+	outlineMaxLineLen = 1000 // bytes kept of each line shown
+)
+
+// OutlineEntry is one top-level declaration or section header
+type OutlineEntry struct {
+	Line int    // 1-based line number
+	Text string // the declaration or header line
+}
+
+// FileOutline summarizes a file too large to return whole: its first and
+// last lines and the structure in between
+type FileOutline struct {
+	Path     string
+	Size     int64
+	Lines    int
+	Language string // go or text
+	Head     []string
+	// Tail holds the last lines, starting at line TailStart, excluding any
+	// already in Head
+	Tail      []string
+	TailStart int
+	Entries   []OutlineEntry
+	Truncated bool // more entries were found than are listed
+}
+
+// OutlineIfLarge returns an outline of a text file larger than
+// OutlineThreshold, or nil if the file is small enough to read whole
+func (fm *FileManager) OutlineIfLarge(path string) (*FileOutline, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, not a file", path)
+	}
+	if info.Size() <= OutlineThreshold {
+		return nil, nil
+	}
+
+	file, err := os.Open(validPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	defer file.Close()
+
+	outline, err := buildOutline(file, outlineLanguage(validPath))
+	if err != nil {
+		return nil, err
+	}
+	outline.Path = path
+	outline.Size = info.Size()
+	return outline, nil
+}
+
+// outlineLanguage picks the outline extractor for a file from its extension
+func outlineLanguage(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".go") {
+		return "go"
+	}
+	return "text"
+}
+
+// outliner extracts outline entries one line at a time
+type outliner interface {
+	line(text string) (string, bool)
+}
+
+// buildOutline reads r line by line, keeping the head and tail and the
+// entries found by the language's outliner, so memory use doesn't grow with
+// the file
+func buildOutline(r io.Reader, language string) (*FileOutline, error) {
+	reader := bufio.NewReaderSize(r, 64*1024)
+
+	sample, err := reader.Peek(binarySniffLen)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if IsBinary(sample) {
+		return nil, fmt.Errorf("file appears to be binary; use read_file_tail_bytes to read raw bytes as base64")
+	}
+
+	var extractor outliner = &textOutliner{}
+	if language == "go" {
+		extractor = &goOutliner{}
+	}
+
+	outline := &FileOutline{Language: language}
+	tail := make([]string, 0, outlineTailLines)
+	for {
+		text, err := readOutlineLine(reader)
+		if text == "" && errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		outline.Lines++
+
+		if outline.Lines <= outlineHeadLines {
+			outline.Head = append(outline.Head, text)
+		} else {
+			if len(tail) == outlineTailLines {
+				tail = tail[1:]
+			}
+			tail = append(tail, text)
+		}
+
+		if entry, ok := extractor.line(text); ok {
+			if len(outline.Entries) < outlineMaxEntries {
+				outline.Entries = append(outline.Entries, OutlineEntry{Line: outline.Lines, Text: entry})
+			} else {
+				outline.Truncated = true
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	outline.Tail = tail
+	outline.TailStart = outline.Lines - len(tail) + 1
+	return outline, nil
+}
+
+// readOutlineLine reads the next line without its line ending, keeping at
+// most outlineMaxLineLen bytes of it and discarding the rest
+func readOutlineLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	truncated := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		chunk = []byte(strings.TrimRight(string(chunk), "\r\n"))
+		if room := outlineMaxLineLen - len(line); len(chunk) > room {
+			chunk = chunk[:room]
+			truncated = true
+		}
+		line = append(line, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+
+		text := string(line)
+		if truncated {
+			text = trimPartialRune(text) + "…"
+		}
+		return strings.ToValidUTF8(text, "\uFFFD"), err
+	}
+}
+
+// goOutliner finds top-level Go declarations: unindented func, type, var
+// and const lines, and the names declared inside grouped type, var and
+// const blocks. It works on lines, not a syntax tree, so it tolerates files
+// that don't compile and never holds the whole file in memory.
+type goOutliner struct {
+	group string // keyword of the grouped declaration being read, if any
+}
+
+func (o *goOutliner) line(text string) (string, bool) {
+	if o.group != "" {
+		if strings.HasPrefix(text, ")") {
+			o.group = ""
+			return "", false
+		}
+		// Members sit one tab in; deeper lines are struct fields and the like
+		if strings.HasPrefix(text, "\t") && !strings.HasPrefix(text, "\t\t") {
+			member := strings.TrimSpace(text)
+			if member != "" && isIdentifierStart(member) {
+				return o.group + " " + trimBlockOpening(member), true
+			}
+		}
+		return "", false
+	}
+
+	for _, keyword := range []string{"func", "type", "var", "const"} {
+		if !strings.HasPrefix(text, keyword+" ") && !strings.HasPrefix(text, keyword+"(") {
+			continue
+		}
+		if strings.TrimSpace(text) == keyword+" (" || strings.TrimSpace(text) == keyword+"(" {
+			o.group = keyword
+			return "", false
+		}
+		return trimBlockOpening(text), true
+	}
+	return "", false
+}
+
+// trimBlockOpening drops the opening brace and anything after it from a
+// declaration line, leaving the signature
+func trimBlockOpening(text string) string {
+	if i := strings.LastIndex(text, "{"); i > 0 {
+		text = text[:i]
+	}
+	return strings.TrimSpace(text)
+}
+
+// isIdentifierStart reports whether s starts like a Go identifier
+func isIdentifierStart(s string) bool {
+	for _, r := range s {
+		return r == '_' || unicode.IsLetter(r)
+	}
+	return false
+}
+
+// textOutliner finds section headers in plain text and Markdown: lines
+// starting with #, and lines underlined with = or - (setext headers).
+// Lines inside ``` or ~~~ fenced blocks are ignored.
+type textOutliner struct {
+	previous string // the last line, if it could be a setext header
+	fence    string // the fence of the code block being read, if any
+}
+
+func (o *textOutliner) line(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	previous := o.previous
+	o.previous = ""
+
+	for _, fence := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, fence) {
+			if o.fence == "" {
+				o.fence = fence
+			} else if o.fence == fence {
+				o.fence = ""
+			}
+			return "", false
+		}
+	}
+	if o.fence != "" {
+		return "", false
+	}
+
+	if level := strings.IndexFunc(text, func(r rune) bool { return r != '#' }); level >= 1 && level <= 6 && text[level] == ' ' {
+		return trimmed, true
+	}
+
+	if previous != "" && len(trimmed) >= 3 {
+		if strings.Trim(trimmed, "=") == "" {
+			return "# " + previous, true
+		}
+		if strings.Trim(trimmed, "-") == "" {
+			return "## " + previous, true
+		}
+	}
+
+	o.previous = trimmed
+	return "", false
+}
+
+// String formats the outline with the head, the entries and the tail
+func (o *FileOutline) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s is too large to read whole (%s, %d lines); showing the first %d lines, an outline and the last %d lines. Use read_file_at to read other parts.\n",
+		o.Path, formatBytes(uint64(o.Size)), o.Lines, len(o.Head), len(o.Tail))
+
+	fmt.Fprintf(&b, "\n--- lines 1-%d ---\n", len(o.Head))
+	for _, line := range o.Head {
+		b.WriteString(line + "\n")
+	}
+
+	kind := "section headers"
+	if o.Language == "go" {
+		kind = "top-level declarations"
+	}
+	fmt.Fprintf(&b, "\n--- outline: %s ---\n", kind)
+	if len(o.Entries) == 0 {
+		b.WriteString("(none found)\n")
+	}
+	for _, entry := range o.Entries {
+		fmt.Fprintf(&b, "%d: %s\n", entry.Line, entry.Text)
+	}
+	if o.Truncated {
+		fmt.Fprintf(&b, "(only the first %d are listed)\n", len(o.Entries))
+	}
+
+	if len(o.Tail) > 0 {
+		fmt.Fprintf(&b, "\n--- lines %d-%d ---\n", o.TailStart, o.Lines)
+		for _, line := range o.Tail {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOutlineIfLargeGo(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	var source strings.Builder
+	source.WriteString("package big\n\nimport \"fmt\"\n\nconst (\n\tFirst = iota\n\tSecond\n)\n\ntype Widget struct {\n\tName string\n}\n\n")
+	for i := 0; source.Len() <= OutlineThreshold; i++ {
+		fmt.Fprintf(&source, "func (w *Widget) Method%d(n int) error {\n\tif n > 0 {\n\t\treturn fmt.Errorf(\"bad\")\n\t}\n\treturn nil\n}\n\n", i)
+	}
+	source.WriteString("var last = 1\n")
+	path := filepath.Join(dir, "big.go")
+	if err := os.WriteFile(path, []byte(source.String()), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	outline, err := fm.OutlineIfLarge(path)
+	if err != nil {
+		t.Fatalf("OutlineIfLarge failed: %v", err)
+	}
+	if outline == nil {
+		t.Fatalf("Expected an outline of a file over the threshold")
+	}
+
+	if outline.Language != "go" || len(outline.Head) != outlineHeadLines || len(outline.Tail) != outlineTailLines {
+		t.Errorf("Expected Go with a full head and tail, got %s with %d and %d lines", outline.Language, len(outline.Head), len(outline.Tail))
+	}
+	if outline.Tail[len(outline.Tail)-1] != "var last = 1" || outline.TailStart+len(outline.Tail)-1 != outline.Lines {
+		t.Errorf("Expected the tail to end at the last line, got %q ending at %d of %d", outline.Tail[len(outline.Tail)-1], outline.TailStart+len(outline.Tail)-1, outline.Lines)
+	}
+
+	want := []OutlineEntry{
+		{Line: 6, Text: "const First = iota"},
+		{Line: 7, Text: "const Second"},
+		{Line: 10, Text: "type Widget struct"},
+		{Line: 14, Text: "func (w *Widget) Method0(n int) error"},
+	}
+	for i, entry := range want {
+		if outline.Entries[i] != entry {
+			t.Errorf("Expected entry %d to be %+v, got %+v", i, entry, outline.Entries[i])
+		}
+	}
+	if len(outline.Entries) != outlineMaxEntries || !outline.Truncated {
+		t.Errorf("Expected the entries to be capped at %d, got %d", outlineMaxEntries, len(outline.Entries))
+	}
+
+	text := outline.String()
+	if !strings.Contains(text, "top-level declarations") || !strings.Contains(text, "14: func (w *Widget) Method0(n int) error") {
+		t.Errorf("Expected the text to list the declarations, got %q", text[:500])
+	}
+}
+
+func TestOutlineIfLargeText(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	var doc strings.Builder
+	doc.WriteString("Title\n=====\n\nIntro text.\n\n# Usage\n\n```\n# not a header\n```\n\nDetails\n-------\n\n")
+	for doc.Len() <= OutlineThreshold {
+		doc.WriteString("Some long paragraph text that pads the document out.\n")
+	}
+	doc.WriteString(strings.Repeat("x", 5000) + "\n## Appendix\n")
+	path := filepath.Join(dir, "big.md")
+	if err := os.WriteFile(path, []byte(doc.String()), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	outline, err := fm.OutlineIfLarge(path)
+	if err != nil || outline == nil {
+		t.Fatalf("Expected an outline, got %v", err)
+	}
+
+	var headers []string
+	for _, entry := range outline.Entries {
+		headers = append(headers, entry.Text)
+	}
+	if got := strings.Join(headers, "|"); got != "# Title|# Usage|## Details|## Appendix" {
+		t.Errorf("Expected the section headers, got %q", got)
+	}
+
+	// Long lines are cut short
+	long := outline.Tail[len(outline.Tail)-2]
+	if len(long) > outlineMaxLineLen+len("…") || !strings.HasSuffix(long, "…") {
+		t.Errorf("Expected a long line to be truncated, got %d bytes", len(long))
+	}
+}
+
+func TestOutlineIfLargeSmallFile(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	path := filepath.Join(dir, "small.txt")
+	if err := os.WriteFile(path, []byte("# Small\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	outline, err := fm.OutlineIfLarge(path)
+	if err != nil || outline != nil {
+		t.Errorf("Expected no outline for a small file, got %+v and %v", outline, err)
+	}
+}
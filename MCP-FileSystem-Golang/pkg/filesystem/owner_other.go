@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package filesystem
+
+import "os"
+
+// fileOwner is not supported on this platform, so no owner is reported
+func fileOwner(info os.FileInfo) (string, string) {
+	return "", ""
+}
@@ -0,0 +1,49 @@
+package filesystem
+
+import (
+	"runtime"
+	"time"
+)
+
+// DefaultRetryAttempts is how many times an operation is tried when it hits a sharing violation
+const DefaultRetryAttempts = 5
+
+// DefaultRetryDelay is the wait before the first retry; it doubles for each later attempt
+const DefaultRetryDelay = 50 * time.Millisecond
+
+// retrySleep waits between attempts; replaced in tests
+var retrySleep = time.Sleep
+
+// DefaultRetrySharingViolations reports whether retries are enabled by
+// default. Antivirus scanners and indexers briefly lock files on Windows.
+func DefaultRetrySharingViolations() bool {
+	return runtime.GOOS == "windows"
+}
+
+// SetRetrySharingViolations enables retrying writes and moves that fail
+// because another process briefly holds the file
+func (fm *FileManager) SetRetrySharingViolations(enabled bool) {
+	fm.retrySharingViolations = enabled
+}
+
+// RetrySharingViolations reports whether sharing violations are retried
+func (fm *FileManager) RetrySharingViolations() bool {
+	return fm.retrySharingViolations
+}
+
+// withRetry runs op, retrying with exponential backoff while it fails with a
+// sharing violation and retries are enabled. Other errors are returned at once.
+func (fm *FileManager) withRetry(op func() error) error {
+	err := op()
+	if !fm.retrySharingViolations {
+		return err
+	}
+
+	delay := DefaultRetryDelay
+	for attempt := 1; attempt < DefaultRetryAttempts && err != nil && isSharingViolation(err); attempt++ {
+		retrySleep(delay)
+		delay *= 2
+		err = op()
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package filesystem
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWithRetrySharingViolation(t *testing.T) {
+	fm, _ := newTestFileManager(t)
+	fm.SetRetrySharingViolations(true)
+
+	var delays []time.Duration
+	originalSleep := retrySleep
+	retrySleep = func(d time.Duration) { delays = append(delays, d) }
+	defer func() { retrySleep = originalSleep }()
+
+	// Fails once with a sharing violation, then succeeds
+	calls := 0
+	err := fm.withRetry(func() error {
+		calls++
+		if calls == 1 {
+			return &os.PathError{Op: "open", Path: "locked.txt", Err: sharingViolationErrno}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected retry to succeed, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 attempts, got %d", calls)
+	}
+	if len(delays) != 1 || delays[0] != DefaultRetryDelay {
+		t.Errorf("Expected one wait of %v, got %v", DefaultRetryDelay, delays)
+	}
+
+	// Other errors are not retried
+	calls = 0
+	permissionErr := &os.PathError{Op: "open", Path: "denied.txt", Err: os.ErrPermission}
+	err = fm.withRetry(func() error {
+		calls++
+		return permissionErr
+	})
+	if !errors.Is(err, os.ErrPermission) || calls != 1 {
+		t.Errorf("Expected a single attempt returning the permission error, got %d attempts and %v", calls, err)
+	}
+
+	// Persistent violations give up after the attempt limit
+	calls = 0
+	err = fm.withRetry(func() error {
+		calls++
+		return sharingViolationErrno
+	})
+	if err == nil || calls != DefaultRetryAttempts {
+		t.Errorf("Expected %d attempts ending in an error, got %d attempts and %v", DefaultRetryAttempts, calls, err)
+	}
+
+	// Disabled retries try once
+	fm.SetRetrySharingViolations(false)
+	calls = 0
+	fm.withRetry(func() error {
+		calls++
+		return sharingViolationErrno
+	})
+	if calls != 1 {
+		t.Errorf("Expected 1 attempt with retries disabled, got %d", calls)
+	}
+}
@@ -0,0 +1,58 @@
+package filesystem
+
+import (
+	"regexp"
+	"strings"
+)
+
+// safeContentTag marks the start and end of file content wrapped by WrapSafeContent
+const safeContentTag = "file-content"
+
+// safeContentTagPattern matches opening or closing tags inside file content
+// that could be mistaken for the wrapper's own
+var safeContentTagPattern = regexp.MustCompile(`(?i)<(/?` + safeContentTag + `)`)
+
+// safeContentAttrEscaper escapes a path for use in the wrapper's attribute
+var safeContentAttrEscaper = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;")
+
+// SetSafeContent sets whether read_file and read_multiple_files wrap content
+// with WrapSafeContent when the caller doesn't say
+func (fm *FileManager) SetSafeContent(enabled bool) {
+	fm.safeContent = enabled
+}
+
+// UseSafeContent reports whether content should be wrapped: as requested by
+// the caller, or the configured default when requested is nil
+func (fm *FileManager) UseSafeContent(requested *bool) bool {
+	if requested != nil {
+		return *requested
+	}
+	return fm.safeContent
+}
+
+// WrapSafeContent marks file content as data rather than instructions for a
+// model reading it. The content is placed between <file-content path="...">
+// and </file-content> lines; tags inside it that could end the wrapper early
+// are escaped, and control characters other than tab, newline and carriage
+// return, such as terminal escape sequences, are removed.
+func WrapSafeContent(path, content string) string {
+	content = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return r
+		}
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, content)
+	content = safeContentTagPattern.ReplaceAllString(content, "&lt;${1}")
+
+	var wrapped strings.Builder
+	wrapped.WriteString("<" + safeContentTag + ` path="` + safeContentAttrEscaper.Replace(path) + "\">\n")
+	wrapped.WriteString(content)
+	if !strings.HasSuffix(content, "\n") {
+		wrapped.WriteString("\n")
+	}
+	wrapped.WriteString("</" + safeContentTag + ">")
+	return wrapped.String()
+}
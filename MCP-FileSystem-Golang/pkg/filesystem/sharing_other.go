@@ -0,0 +1,16 @@
+//go:build !windows
+
+package filesystem
+
+import (
+	"errors"
+	"syscall"
+)
+
+// sharingViolationErrno is the error a transient file lock produces on this platform
+var sharingViolationErrno error = syscall.EBUSY
+
+// isSharingViolation reports whether err was caused by the file being busy
+func isSharingViolation(err error) bool {
+	return errors.Is(err, syscall.EBUSY)
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package filesystem
+
+import (
+	"errors"
+	"syscall"
+)
+
+// Windows error codes returned when another process holds a file open
+const (
+	errorSharingViolation = syscall.Errno(32) // ERROR_SHARING_VIOLATION
+	errorLockViolation    = syscall.Errno(33) // ERROR_LOCK_VIOLATION
+)
+
+// sharingViolationErrno is the error a transient file lock produces on this platform
+var sharingViolationErrno error = errorSharingViolation
+
+// isSharingViolation reports whether err was caused by another process holding the file
+func isSharingViolation(err error) bool {
+	return errors.Is(err, errorSharingViolation) || errors.Is(err, errorLockViolation)
+}
@@ -0,0 +1,327 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+// MaxSnapshotFiles bounds how many files a single snapshot may record
+const MaxSnapshotFiles = 20000
+
+// SnapshotIgnore lists directory and file names skipped when taking a snapshot
+var SnapshotIgnore = []string{".git", ".hg", ".svn", "node_modules", "__pycache__", ".DS_Store"}
+
+// snapshotNamePattern restricts snapshot names to characters that are safe in a filename
+var snapshotNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// Snapshot records the files under a directory and their content hashes
+type Snapshot struct {
+	Root    string                   `json:"root"`
+	Created time.Time                `json:"created"`
+	Files   map[string]SnapshotEntry `json:"files"`
+}
+
+// SnapshotEntry describes a single file within a snapshot
+type SnapshotEntry struct {
+	Size int64  `json:"size"`
+	Hash string `json:"hash"`
+}
+
+// SnapshotDiff lists the relative paths that changed since a snapshot
+type SnapshotDiff struct {
+	Added    []string
+	Removed  []string
+	Modified []string
+}
+
+// SetSnapshotDir sets the directory where named snapshots are stored
+func (fm *FileManager) SetSnapshotDir(dir string) {
+	fm.snapshotDir = dir
+}
+
+// snapshotPath returns the file a named snapshot is stored in
+func (fm *FileManager) snapshotPath(name string) (string, error) {
+	if !snapshotNamePattern.MatchString(name) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid snapshot name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return filepath.Join(fm.snapshotDir, name+".json"), nil
+}
+
+// isSnapshotIgnored reports whether a file or directory name is on the ignore list
+func isSnapshotIgnored(name string) bool {
+	for _, ignored := range SnapshotIgnore {
+		if name == ignored {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSnapshot records the files under path with their hashes as a named
+// snapshot, replacing any snapshot of the same name. It returns the number
+// of files recorded.
+func (fm *FileManager) CreateSnapshot(path, name string) (int, error) {
+	snapshotFile, err := fm.snapshotPath(name)
+	if err != nil {
+		return 0, err
+	}
+
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	files, err := fm.hashTree(validPath)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshot := Snapshot{
+		Root:    validPath,
+		Created: time.Now(),
+		Files:   files,
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	if err := os.MkdirAll(fm.snapshotDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(snapshotFile, data, 0644); err != nil {
+		return 0, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	return len(files), nil
+}
+
+// DiffSnapshot compares the current contents of path against a named snapshot
+func (fm *FileManager) DiffSnapshot(path, name string) (SnapshotDiff, error) {
+	snapshotFile, err := fm.snapshotPath(name)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+
+	data, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return SnapshotDiff{}, fmt.Errorf("snapshot %q does not exist", name)
+		}
+		return SnapshotDiff{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return SnapshotDiff{}, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+
+	if snapshot.Root != validPath {
+		return SnapshotDiff{}, fmt.Errorf("snapshot %q was taken of %s, not %s", name, snapshot.Root, validPath)
+	}
+
+	current, err := fm.hashTree(validPath)
+	if err != nil {
+		return SnapshotDiff{}, err
+	}
+
+	var diff SnapshotDiff
+	for relPath, entry := range current {
+		previous, ok := snapshot.Files[relPath]
+		if !ok {
+			diff.Added = append(diff.Added, relPath)
+		} else if previous != entry {
+			diff.Modified = append(diff.Modified, relPath)
+		}
+	}
+	for relPath := range snapshot.Files {
+		if _, ok := current[relPath]; !ok {
+			diff.Removed = append(diff.Removed, relPath)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+
+	return diff, nil
+}
+
+// hashTree hashes every regular file under root, keyed by slash-separated relative path
+func (fm *FileManager) hashTree(root string) (map[string]SnapshotEntry, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	files := make(map[string]SnapshotEntry)
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip unreadable entries and continue walking
+			return nil
+		}
+
+		if path != root && isSnapshotIgnored(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// Only regular files are recorded; symlinks are not followed
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		if _, err := fm.ValidatePath(path); err != nil {
+			return nil
+		}
+
+		if len(files) >= MaxSnapshotFiles {
+			return fmt.Errorf("directory contains more than %d files; snapshot a smaller directory", MaxSnapshotFiles)
+		}
+
+		entry, err := hashFile(path)
+		if err != nil {
+			// The file may have been removed during the walk
+			return nil
+		}
+
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		files[filepath.ToSlash(relPath)] = entry
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// hashFile returns the size and SHA-256 hash of a file
+func hashFile(path string) (SnapshotEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return SnapshotEntry{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return SnapshotEntry{}, err
+	}
+
+	return SnapshotEntry{Size: size, Hash: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// String formats the diff as a report listing added, removed and modified files
+func (d SnapshotDiff) String() string {
+	if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0 {
+		return "No changes"
+	}
+
+	var sections []string
+	for _, section := range []struct {
+		label string
+		paths []string
+	}{
+		{"Added", d.Added},
+		{"Removed", d.Removed},
+		{"Modified", d.Modified},
+	} {
+		if len(section.paths) == 0 {
+			continue
+		}
+		sections = append(sections, fmt.Sprintf("%s (%d):\n  %s", section.label, len(section.paths), strings.Join(section.paths, "\n  ")))
+	}
+
+	return strings.Join(sections, "\n")
+}
+
+// CreateSnapshotSchema defines the input schema for create_snapshot
+var CreateSnapshotSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Snapshot name (letters, digits, '.', '_' or '-'); an existing snapshot with this name is replaced",
+		},
+	},
+	"required": []string{"path", "name"},
+}
+
+// DiffSnapshotSchema defines the input schema for diff_snapshot
+var DiffSnapshotSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Name of a snapshot previously created for this path",
+		},
+	},
+	"required": []string{"path", "name"},
+}
+
+// ParseCreateSnapshotArgs parses arguments for create_snapshot
+func ParseCreateSnapshotArgs(args json.RawMessage) (string, string, error) {
+	return parseSnapshotArgs("create_snapshot", args)
+}
+
+// ParseDiffSnapshotArgs parses arguments for diff_snapshot
+func ParseDiffSnapshotArgs(args json.RawMessage) (string, string, error) {
+	return parseSnapshotArgs("diff_snapshot", args)
+}
+
+// parseSnapshotArgs parses the path and snapshot name shared by the snapshot tools
+func parseSnapshotArgs(toolName string, args json.RawMessage) (string, string, error) {
+	var params struct {
+		Path string `json:"path"`
+		Name string `json:"name"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", "", fmt.Errorf("invalid arguments for %s: %w", toolName, err)
+	}
+
+	if params.Path == "" {
+		return "", "", fmt.Errorf("path parameter is required")
+	}
+
+	if params.Name == "" {
+		return "", "", fmt.Errorf("name parameter is required")
+	}
+
+	return params.Path, params.Name, nil
+}
@@ -0,0 +1,168 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultTreeMaxEntries caps the number of entries DirectoryTree returns
+const DefaultTreeMaxEntries = 1000
+
+// treeMaxDepth is how deep DirectoryTree descends when neither the call nor
+// the server config limits the depth, so a very deep tree can't run away
+const treeMaxDepth = 32
+
+// TreeNode is a file, directory or symlink in a directory tree
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`             // file, directory or symlink
+	Target   string      `json:"target,omitempty"` // where a symlink points
+	Loop     bool        `json:"loop,omitempty"`   // a symlink back to a directory above it, not followed
+	Children []*TreeNode `json:"children,omitempty"`
+}
+
+// DirectoryTree is the tree of files and directories below a directory
+type DirectoryTree struct {
+	Path      string    `json:"path"`
+	Root      *TreeNode `json:"root"`
+	Entries   int       `json:"entries"`
+	Truncated bool      `json:"truncated,omitempty"` // more entries than the cap
+}
+
+// String formats the tree with two spaces of indentation per level.
+// Directories end in '/' and symlinks show their target.
+func (t *DirectoryTree) String() string {
+	var b strings.Builder
+	b.WriteString(t.Path + "/\n")
+	for _, child := range t.Root.Children {
+		writeTreeNode(&b, child, 1)
+	}
+	if t.Truncated {
+		fmt.Fprintf(&b, "... truncated at %d entries\n", t.Entries)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// writeTreeNode writes a node and its children at the given depth
+func writeTreeNode(b *strings.Builder, node *TreeNode, depth int) {
+	b.WriteString(strings.Repeat("  ", depth) + node.Name)
+	if node.Type == "directory" || (node.Type == "symlink" && node.Children != nil) {
+		b.WriteString("/")
+	}
+	if node.Target != "" {
+		b.WriteString(" -> " + node.Target)
+	}
+	if node.Loop {
+		b.WriteString(" (loop, not followed)")
+	}
+	b.WriteString("\n")
+	for _, child := range node.Children {
+		writeTreeNode(b, child, depth+1)
+	}
+}
+
+// DirectoryTree returns the files and directories below path, down to
+// maxDepth levels, or the configured default depth when maxDepth is 0.
+// Symlinked directories within the allowed directories are followed unless
+// they point back to a directory above them. The directories in
+// SnapshotIgnore are listed but not descended into, and the tree is cut
+// off after DefaultTreeMaxEntries entries.
+func (fm *FileManager) DirectoryTree(path string, maxDepth int) (*DirectoryTree, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", validPath)
+	}
+
+	maxDepth = fm.walkDepth(maxDepth)
+	if maxDepth <= 0 || maxDepth > treeMaxDepth {
+		maxDepth = treeMaxDepth
+	}
+
+	tree := &DirectoryTree{
+		Path: validPath,
+		Root: &TreeNode{Name: filepath.Base(validPath), Type: "directory"},
+	}
+	builder := treeBuilder{fm: fm, tree: tree, maxDepth: maxDepth, ancestors: map[string]bool{}}
+	if err := builder.fill(tree.Root, validPath, 1); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// treeBuilder fills in a DirectoryTree, tracking the real paths of the
+// directories being read so symlink loops are caught
+type treeBuilder struct {
+	fm        *FileManager
+	tree      *DirectoryTree
+	maxDepth  int
+	ancestors map[string]bool
+}
+
+// fill adds the entries of the directory at realPath, at the given depth, to node
+func (b *treeBuilder) fill(node *TreeNode, realPath string, depth int) error {
+	entries, err := os.ReadDir(realPath)
+	if err != nil {
+		if depth == 1 {
+			return fmt.Errorf("failed to read directory: %w", err)
+		}
+		// Leave unreadable directories below the root empty
+		return nil
+	}
+
+	b.ancestors[realPath] = true
+	defer delete(b.ancestors, realPath)
+
+	node.Children = []*TreeNode{}
+	for _, entry := range entries {
+		if b.tree.Entries >= DefaultTreeMaxEntries {
+			b.tree.Truncated = true
+			return nil
+		}
+		b.tree.Entries++
+
+		child := &TreeNode{Name: entry.Name(), Type: "file"}
+		node.Children = append(node.Children, child)
+		entryPath := filepath.Join(realPath, entry.Name())
+
+		// Follow symlinks only to directories within the allowed directories
+		childReal := entryPath
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			child.Type = "symlink"
+			child.Target, _ = os.Readlink(entryPath)
+			resolved, err := b.fm.ValidatePath(entryPath)
+			if err != nil {
+				continue
+			}
+			info, err := os.Stat(resolved)
+			if err != nil || !info.IsDir() {
+				continue
+			}
+			if b.ancestors[resolved] {
+				child.Loop = true
+				continue
+			}
+			childReal, isDir = resolved, true
+		} else if isDir {
+			child.Type = "directory"
+		}
+
+		if !isDir || depth >= b.maxDepth || isSnapshotIgnored(entry.Name()) {
+			continue
+		}
+		if err := b.fill(child, childReal, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDirectoryTree(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	for _, name := range []string{"a/b/c/deep.txt", "a/one.txt", "node_modules/pkg/index.js", "top.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	// A symlink back up the tree must not be followed forever
+	if err := os.Symlink(dir, filepath.Join(dir, "a", "loop")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	tree, err := fm.DirectoryTree(dir, 0)
+	if err != nil {
+		t.Fatalf("DirectoryTree failed: %v", err)
+	}
+	want := strings.Join([]string{
+		dir + "/",
+		"  a/",
+		"    b/",
+		"      c/",
+		"        deep.txt",
+		"    loop -> " + dir + " (loop, not followed)",
+		"    one.txt",
+		"  node_modules/",
+		"  top.txt",
+	}, "\n")
+	if got := tree.String(); got != want {
+		t.Errorf("Expected tree:\n%s\ngot:\n%s", want, got)
+	}
+
+	// Depth 2 lists b but not its contents
+	tree, err = fm.DirectoryTree(dir, 2)
+	if err != nil {
+		t.Fatalf("DirectoryTree failed: %v", err)
+	}
+	if strings.Contains(tree.String(), "c/") || !strings.Contains(tree.String(), "    b/") {
+		t.Errorf("Expected the tree to stop at depth 2, got:\n%s", tree.String())
+	}
+
+	if _, err := fm.DirectoryTree(filepath.Join(dir, "top.txt"), 0); err == nil {
+		t.Error("Expected a file to be rejected")
+	}
+}
+
+func TestDirectoryTreeTruncated(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	for i := 0; i < DefaultTreeMaxEntries+5; i++ {
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%04d.txt", i)), nil, 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tree, err := fm.DirectoryTree(dir, 0)
+	if err != nil {
+		t.Fatalf("DirectoryTree failed: %v", err)
+	}
+	if !tree.Truncated || tree.Entries != DefaultTreeMaxEntries || len(tree.Root.Children) != DefaultTreeMaxEntries {
+		t.Errorf("Expected the tree to be cut off at %d entries, got %d (truncated %v)", DefaultTreeMaxEntries, tree.Entries, tree.Truncated)
+	}
+}
@@ -0,0 +1,44 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxDepthSchema is the max_depth property shared by the tools that walk a directory tree
+var maxDepthSchema = map[string]interface{}{
+	"type": "integer",
+	"description": "How many directory levels below the starting directory to descend, where 1 is only " +
+		"its direct entries (default from server config, unlimited if not set)",
+}
+
+// SetDefaultMaxWalkDepth sets the depth tree walks stop at when a call gives
+// no max_depth. Zero leaves walks unlimited.
+func (fm *FileManager) SetDefaultMaxWalkDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	fm.defaultMaxWalkDepth = depth
+}
+
+// walkDepth returns the depth limit for a walk: maxDepth when the call gives
+// one, otherwise the configured default. Zero means unlimited.
+func (fm *FileManager) walkDepth(maxDepth int) int {
+	if maxDepth > 0 {
+		return maxDepth
+	}
+	return fm.defaultMaxWalkDepth
+}
+
+// reachedMaxDepth reports whether a directory is as deep below root as a
+// walk limited to maxDepth may go, so its entries must not be visited
+func reachedMaxDepth(root, path string, maxDepth int) bool {
+	if maxDepth <= 0 || path == root {
+		return false
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return strings.Count(filepath.ToSlash(rel), "/")+1 >= maxDepth
+}
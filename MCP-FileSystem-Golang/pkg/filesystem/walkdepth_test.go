@@ -0,0 +1,65 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultMaxWalkDepth(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	// needle.txt at depths 1, 2 and 3 below dir
+	for _, rel := range []string{"needle.txt", "a/needle.txt", "a/b/needle.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("needle\n"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	fm.SetDefaultMaxWalkDepth(2)
+
+	results, err := SearchFiles(fm, dir, "needle", SearchQuery{IncludeHidden: true})
+	if err != nil {
+		t.Fatalf("SearchFiles failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("Expected the default depth to limit search_files to 2 matches, got %v", results)
+	}
+
+	grep, err := GrepFiles(context.Background(), fm, dir, "needle", GrepQuery{})
+	if err != nil {
+		t.Fatalf("GrepFiles failed: %v", err)
+	}
+	if len(grep.Matches) != 2 {
+		t.Errorf("Expected the default depth to limit grep_files to 2 matches, got %v", grep.Matches)
+	}
+
+	glob, err := fm.Glob(filepath.ToSlash(dir)+"/**/needle.txt", nil, true, 0, 0)
+	if err != nil {
+		t.Fatalf("Glob failed: %v", err)
+	}
+	if len(glob.Paths) != 2 {
+		t.Errorf("Expected the default depth to limit glob to 2 matches, got %v", glob.Paths)
+	}
+
+	// An explicit max_depth overrides the default in either direction
+	results, err = SearchFiles(fm, dir, "needle", SearchQuery{IncludeHidden: true, MaxDepth: 3})
+	if err != nil {
+		t.Fatalf("SearchFiles failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Errorf("Expected max_depth 3 to find all 3 matches, got %v", results)
+	}
+	results, err = SearchFiles(fm, dir, "needle", SearchQuery{IncludeHidden: true, MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("SearchFiles failed: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected max_depth 1 to find only the top-level match, got %v", results)
+	}
+}
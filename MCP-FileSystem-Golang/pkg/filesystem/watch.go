@@ -0,0 +1,195 @@
+package filesystem
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/LaurieRhodes/PUBLIC-Golang-MCP-Servers/MCP-FileSystem-Golang/internal/toolargs"
+)
+
+const (
+	// DefaultWatchDuration is how long watch_directory watches when no duration is given
+	DefaultWatchDuration = 10 * time.Second
+
+	// MaxWatchDuration caps how long a single watch_directory call blocks
+	MaxWatchDuration = 60 * time.Second
+
+	// MaxWatchEvents caps the number of events a watch returns
+	MaxWatchEvents = 500
+
+	// maxWatchEntries caps the number of files and directories a watch
+	// tracks, since every one is checked on each poll, or the number of
+	// directories it adds event watches for
+	maxWatchEntries = 20000
+)
+
+// Watch event operations
+const (
+	WatchCreate = "create"
+	WatchModify = "modify"
+	WatchDelete = "delete"
+)
+
+// WatchEvent is a change to a path seen during a watch. Repeated
+// modifications of a path are merged into one event counting them.
+type WatchEvent struct {
+	Path  string    `json:"path"` // relative to the watched directory
+	Op    string    `json:"op"`   // create, modify or delete
+	IsDir bool      `json:"isDir,omitempty"`
+	Time  time.Time `json:"time"` // when the change was last seen
+	Count int       `json:"count"`
+}
+
+// WatchResult holds the changes seen while watching a directory
+type WatchResult struct {
+	Path       string        `json:"path"`
+	Duration   time.Duration `json:"-"`
+	Events     []WatchEvent  `json:"events"`
+	Truncated  bool          `json:"truncated,omitempty"`  // more events than MaxWatchEvents
+	Overflowed bool          `json:"overflowed,omitempty"` // the OS dropped events, so some changes are missing
+
+	latest map[string]int // index of each path's latest event
+}
+
+// WatchDirectory watches the tree below path for duration, or
+// DefaultWatchDuration when it is 0, and returns the files and directories
+// created, modified and deleted in that time. It blocks until the window
+// closes or ctx is done. On Linux changes are reported by inotify as they
+// happen; elsewhere, or when inotify can't watch the tree, the tree is
+// polled, so a file written and removed between two polls goes unseen.
+// Symlinks are not followed, the directories in SnapshotIgnore are skipped,
+// and the configured default walk depth applies.
+func (fm *FileManager) WatchDirectory(ctx context.Context, path string, duration time.Duration) (WatchResult, error) {
+	validPath, err := fm.ValidatePath(path)
+	if err != nil {
+		return WatchResult{}, err
+	}
+
+	info, err := os.Stat(validPath)
+	if err != nil {
+		return WatchResult{}, fmt.Errorf("failed to stat directory: %w", err)
+	}
+	if !info.IsDir() {
+		return WatchResult{}, fmt.Errorf("path is not a directory: %s", validPath)
+	}
+
+	if duration <= 0 {
+		duration = DefaultWatchDuration
+	} else if duration > MaxWatchDuration {
+		duration = MaxWatchDuration
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	result := newWatchResult(validPath, duration)
+	if err := watchTree(ctx, validPath, fm.walkDepth(0), result); err != nil {
+		return WatchResult{}, err
+	}
+	return *result, nil
+}
+
+// newWatchResult returns an empty result for a watch of path
+func newWatchResult(path string, duration time.Duration) *WatchResult {
+	return &WatchResult{Path: path, Duration: duration, Events: []WatchEvent{}, latest: make(map[string]int)}
+}
+
+// latestOp returns the operation of the latest event recorded for path, or
+// "" if there is none
+func (r *WatchResult) latestOp(path string) string {
+	if i, ok := r.latest[path]; ok {
+		return r.Events[i].Op
+	}
+	return ""
+}
+
+// record adds changes seen at the same time to the result, merging a
+// modification into the path's latest event when that was also a create or
+// modify
+func (r *WatchResult) record(events []WatchEvent, now time.Time) {
+	for _, event := range events {
+		if i, ok := r.latest[event.Path]; ok && event.Op == WatchModify && r.Events[i].Op != WatchDelete {
+			r.Events[i].Count++
+			r.Events[i].Time = now
+			continue
+		}
+
+		if len(r.Events) >= MaxWatchEvents {
+			r.Truncated = true
+			return
+		}
+		event.Time = now
+		event.Count = 1
+		r.latest[event.Path] = len(r.Events)
+		r.Events = append(r.Events, event)
+	}
+}
+
+// String formats the events one per line in the order they were seen
+func (r WatchResult) String() string {
+	if len(r.Events) == 0 {
+		return fmt.Sprintf("No changes in %s during %v", r.Path, r.Duration)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d changes in %s during %v:\n", len(r.Events), r.Path, r.Duration)
+	for _, event := range r.Events {
+		name := event.Path
+		if event.IsDir {
+			name += "/"
+		}
+		fmt.Fprintf(&b, "%s %s %s", event.Time.Format("15:04:05.000"), event.Op, name)
+		if event.Count > 1 {
+			fmt.Fprintf(&b, " (%d times)", event.Count)
+		}
+		b.WriteString("\n")
+	}
+	if r.Truncated {
+		fmt.Fprintf(&b, "... stopped after %d events\n", MaxWatchEvents)
+	}
+	if r.Overflowed {
+		b.WriteString("Some changes may be missing: the OS dropped events that arrived too fast\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// WatchDirectorySchema defines the input schema for watch_directory
+var WatchDirectorySchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"path": map[string]interface{}{
+			"type": "string",
+		},
+		"duration": map[string]interface{}{
+			"type":        "integer",
+			"description": "Seconds to watch for changes before returning, from 1 to 60 (default 10)",
+		},
+	},
+	"required": []string{"path"},
+}
+
+// ParseWatchDirectoryArgs parses arguments for watch_directory
+func ParseWatchDirectoryArgs(args json.RawMessage) (string, time.Duration, error) {
+	var params struct {
+		Path     string `json:"path"`
+		Duration int    `json:"duration"`
+	}
+
+	if err := toolargs.Decode(args, &params); err != nil {
+		return "", 0, fmt.Errorf("invalid arguments for watch_directory: %w", err)
+	}
+
+	if params.Path == "" {
+		return "", 0, fmt.Errorf("path parameter is required")
+	}
+
+	if params.Duration < 0 || params.Duration > int(MaxWatchDuration/time.Second) {
+		return "", 0, fmt.Errorf("duration parameter must be between 1 and %d seconds", int(MaxWatchDuration/time.Second))
+	}
+
+	return params.Path, time.Duration(params.Duration) * time.Second, nil
+}
@@ -0,0 +1,235 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// inotifyMask is the set of events watched on each directory. Symlinks are
+// never followed, and files unlinked while open stop reporting events.
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_ONLYDIR | syscall.IN_DONT_FOLLOW | syscall.IN_EXCL_UNLINK
+
+// errTooManyWatches is returned when a tree has more directories than a watch may add
+var errTooManyWatches = fmt.Errorf("directory has more than %d subdirectories; watch a smaller directory", maxWatchEntries)
+
+// watchTree records the changes below root in result until ctx is done, from
+// inotify events. When inotify can't watch the tree, for example because the
+// user's watch limit is reached, it falls back to polling.
+func watchTree(ctx context.Context, root string, maxDepth int, result *WatchResult) error {
+	watcher, err := newInotifyWatcher(root, maxDepth)
+	if errors.Is(err, errTooManyWatches) {
+		return err
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: can't watch %s for events (%v); polling for changes instead\n", root, err)
+		return pollTree(ctx, root, maxDepth, result)
+	}
+	defer watcher.close()
+
+	return watcher.run(ctx, result)
+}
+
+// inotifyWatcher follows the changes below a directory through one inotify
+// instance with a watch on each directory
+type inotifyWatcher struct {
+	file     *os.File
+	fd       int
+	root     string
+	maxDepth int
+	dirs     map[int32]string // watched directories by watch descriptor, relative to root
+	writing  map[string]bool  // files modified since they were last closed
+}
+
+// newInotifyWatcher starts watching every directory below root
+func newInotifyWatcher(root string, maxDepth int) (*inotifyWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize inotify: %w", err)
+	}
+
+	// A non-blocking descriptor is served by the runtime poller, so reads
+	// honor deadlines
+	w := &inotifyWatcher{
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		fd:       fd,
+		root:     root,
+		maxDepth: maxDepth,
+		dirs:     make(map[int32]string),
+		writing:  make(map[string]bool),
+	}
+	if _, err := w.addTree(""); err != nil {
+		w.close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// close releases the inotify instance and all its watches
+func (w *inotifyWatcher) close() {
+	w.file.Close()
+}
+
+// addTree watches the directory rel and the directories below it, down to
+// the walk depth, and returns a create event for every entry found below
+// it. Entries that vanish or can't be read mid-walk are skipped.
+func (w *inotifyWatcher) addTree(rel string) ([]WatchEvent, error) {
+	var events []WatchEvent
+	start := filepath.Join(w.root, filepath.FromSlash(rel))
+	err := filepath.WalkDir(start, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		entryRel, err := filepath.Rel(w.root, walkPath)
+		if err != nil {
+			return nil
+		}
+		entryRel = filepath.ToSlash(entryRel)
+		if entryRel == "." {
+			entryRel = ""
+		}
+
+		if walkPath != start {
+			if isSnapshotIgnored(d.Name()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			events = append(events, WatchEvent{Path: entryRel, Op: WatchCreate, IsDir: d.IsDir()})
+		}
+		if !d.IsDir() || reachedMaxDepth(w.root, walkPath, w.maxDepth) {
+			return nil
+		}
+
+		if len(w.dirs) >= maxWatchEntries {
+			return errTooManyWatches
+		}
+		wd, err := syscall.InotifyAddWatch(w.fd, walkPath, inotifyMask)
+		if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ENOTDIR) {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return fmt.Errorf("failed to watch %s: %w", walkPath, err)
+		}
+		w.dirs[int32(wd)] = entryRel
+		return nil
+	})
+	return events, err
+}
+
+// forget removes the watches on the directory rel and those below it, once
+// it has been moved away
+func (w *inotifyWatcher) forget(rel string) {
+	for wd, dir := range w.dirs {
+		if dir == rel || strings.HasPrefix(dir, rel+"/") {
+			syscall.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.dirs, wd)
+		}
+	}
+}
+
+// run reads events into result until ctx is done or the event cap is reached
+func (w *inotifyWatcher) run(ctx context.Context, result *WatchResult) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		w.file.SetReadDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() {
+		w.file.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	buf := make([]byte, 64*1024)
+	for !result.Truncated {
+		n, err := w.file.Read(buf)
+		if errors.Is(err, os.ErrDeadlineExceeded) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read watch events: %w", err)
+		}
+
+		for offset := 0; offset+syscall.SizeofInotifyEvent <= n; {
+			wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			nameLen := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			offset += syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buf[offset:offset+nameLen]), "\x00")
+			offset += nameLen
+
+			w.handle(wd, mask, name, result)
+		}
+	}
+	return nil
+}
+
+// handle records one inotify event. A file's writes until it is closed
+// count as a single modification, and writes to a file just created are
+// merged into its create event.
+func (w *inotifyWatcher) handle(wd int32, mask uint32, name string, result *WatchResult) {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		result.Overflowed = true
+		return
+	}
+	dir, ok := w.dirs[wd]
+	if mask&syscall.IN_IGNORED != 0 {
+		delete(w.dirs, wd)
+		return
+	}
+	// Changes to a watched directory itself are reported by its parent
+	if !ok || name == "" || isSnapshotIgnored(name) {
+		return
+	}
+
+	rel := path.Join(dir, name)
+	isDir := mask&syscall.IN_ISDIR != 0
+	now := time.Now()
+
+	switch {
+	case mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0:
+		// A new directory's entries may be reported by both the walk and
+		// their own events, so a path already created isn't created again
+		var events []WatchEvent
+		if result.latestOp(rel) != WatchCreate {
+			events = append(events, WatchEvent{Path: rel, Op: WatchCreate, IsDir: isDir})
+		}
+		if isDir {
+			found, _ := w.addTree(rel)
+			for _, event := range found {
+				if result.latestOp(event.Path) != WatchCreate {
+					events = append(events, event)
+				}
+			}
+		} else if mask&syscall.IN_CREATE != 0 {
+			w.writing[rel] = true
+		}
+		result.record(events, now)
+
+	case mask&syscall.IN_MODIFY != 0 && !isDir:
+		if !w.writing[rel] {
+			w.writing[rel] = true
+			result.record([]WatchEvent{{Path: rel, Op: WatchModify}}, now)
+		}
+
+	case mask&syscall.IN_CLOSE_WRITE != 0:
+		delete(w.writing, rel)
+
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		delete(w.writing, rel)
+		if isDir {
+			w.forget(rel)
+		}
+		result.record([]WatchEvent{{Path: rel, Op: WatchDelete, IsDir: isDir}}, now)
+	}
+}
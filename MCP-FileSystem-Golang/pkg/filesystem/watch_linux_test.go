@@ -0,0 +1,57 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchDirectorySeesEveryEvent(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		// Gone long before any poll could see it
+		os.WriteFile(filepath.Join(dir, "scratch.tmp"), []byte("x"), 0644)
+		os.Remove(filepath.Join(dir, "scratch.tmp"))
+		// Entries made before the new directory's watch is added
+		os.MkdirAll(filepath.Join(dir, "out", "bin"), 0755)
+		os.WriteFile(filepath.Join(dir, "out", "bin", "app"), []byte("binary"), 0644)
+		time.Sleep(50 * time.Millisecond)
+		os.Rename(filepath.Join(dir, "out"), filepath.Join(dir, "dist"))
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(filepath.Join(dir, "dist", "bin", "app"), []byte("rebuilt"), 0644)
+	}()
+
+	result, err := fm.WatchDirectory(context.Background(), dir, time.Second)
+	if err != nil {
+		t.Fatalf("WatchDirectory failed: %v", err)
+	}
+
+	var got []string
+	for _, event := range result.Events {
+		got = append(got, event.Op+" "+event.Path)
+	}
+	expected := []string{
+		"create scratch.tmp", "delete scratch.tmp",
+		"create out", "create out/bin", "create out/bin/app",
+		"delete out", "create dist", "create dist/bin", "create dist/bin/app",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("Expected events %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("Expected event %d to be %q, got %q", i, expected[i], got[i])
+		}
+	}
+
+	// The rebuild of the moved file is seen through the new watches
+	if app := result.Events[len(result.Events)-1]; app.Count != 2 {
+		t.Errorf("Expected the rewrite of dist/bin/app to be counted, got %+v", app)
+	}
+}
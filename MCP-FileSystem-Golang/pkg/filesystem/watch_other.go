@@ -0,0 +1,11 @@
+//go:build !linux
+
+package filesystem
+
+import "context"
+
+// watchTree records the changes below root in result until ctx is done. Only
+// Linux has an event-based watcher, so the tree is polled here.
+func watchTree(ctx context.Context, root string, maxDepth int, result *WatchResult) error {
+	return pollTree(ctx, root, maxDepth, result)
+}
@@ -0,0 +1,112 @@
+package filesystem
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// watchPollInterval is how often a polling watch checks the directory for changes
+var watchPollInterval = 250 * time.Millisecond
+
+// watchedEntry is the state of a path compared between polls
+type watchedEntry struct {
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+// pollTree records the changes below root in result until ctx is done, by
+// comparing the size and modification time of every entry on each poll
+func pollTree(ctx context.Context, root string, maxDepth int, result *WatchResult) error {
+	previous, err := scanWatchedTree(root, maxDepth)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(watchPollInterval)
+	defer ticker.Stop()
+
+	for done := false; !done && !result.Truncated; {
+		select {
+		case <-ctx.Done():
+			done = true
+		case <-ticker.C:
+		}
+
+		// Check once more when the window closes so late changes are seen
+		current, err := scanWatchedTree(root, maxDepth)
+		if err != nil {
+			return err
+		}
+		result.record(diffWatchedTrees(previous, current), time.Now())
+		previous = current
+	}
+	return nil
+}
+
+// scanWatchedTree records the state of every entry below root
+func scanWatchedTree(root string, maxDepth int) (map[string]watchedEntry, error) {
+	entries := make(map[string]watchedEntry)
+	err := filepath.WalkDir(root, func(walkPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip entries that vanish or can't be read mid-walk
+			return nil
+		}
+		if walkPath == root {
+			return nil
+		}
+		if isSnapshotIgnored(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if len(entries) >= maxWatchEntries {
+			return fmt.Errorf("directory has more than %d entries; watch a smaller directory", maxWatchEntries)
+		}
+		rel, err := filepath.Rel(root, walkPath)
+		if err != nil {
+			return nil
+		}
+		entries[filepath.ToSlash(rel)] = watchedEntry{size: info.Size(), modTime: info.ModTime(), isDir: d.IsDir()}
+
+		if d.IsDir() && reachedMaxDepth(root, walkPath, maxDepth) {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// diffWatchedTrees returns the changes between two scans, ordered by path
+func diffWatchedTrees(previous, current map[string]watchedEntry) []WatchEvent {
+	var events []WatchEvent
+	for path, entry := range current {
+		old, existed := previous[path]
+		switch {
+		case !existed || old.isDir != entry.isDir:
+			events = append(events, WatchEvent{Path: path, Op: WatchCreate, IsDir: entry.isDir})
+		case !entry.isDir && (old.size != entry.size || !old.modTime.Equal(entry.modTime)):
+			events = append(events, WatchEvent{Path: path, Op: WatchModify})
+		}
+	}
+	for path, entry := range previous {
+		if _, exists := current[path]; !exists {
+			events = append(events, WatchEvent{Path: path, Op: WatchDelete, IsDir: entry.isDir})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Path < events[j].Path
+	})
+	return events
+}
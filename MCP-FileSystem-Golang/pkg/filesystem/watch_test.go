@@ -0,0 +1,103 @@
+package filesystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchDirectory(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	testWatch(t, dir, func() (WatchResult, error) {
+		return fm.WatchDirectory(context.Background(), dir, time.Second)
+	})
+}
+
+func TestWatchDirectoryPolling(t *testing.T) {
+	_, dir := newTestFileManager(t)
+
+	interval := watchPollInterval
+	watchPollInterval = 20 * time.Millisecond
+	defer func() { watchPollInterval = interval }()
+
+	testWatch(t, dir, func() (WatchResult, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		result := newWatchResult(dir, time.Second)
+		err := pollTree(ctx, dir, 0, result)
+		return *result, err
+	})
+}
+
+// testWatch checks the events watch reports while a build-like process
+// changes dir
+func testWatch(t *testing.T, dir string, watch func() (WatchResult, error)) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, "old.txt"), []byte("old"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.Remove(filepath.Join(dir, "old.txt"))
+		os.Mkdir(filepath.Join(dir, "out"), 0755)
+		os.WriteFile(filepath.Join(dir, "out", "app"), []byte("binary"), 0644)
+		for i := 2; i <= 3; i++ {
+			time.Sleep(60 * time.Millisecond)
+			os.WriteFile(filepath.Join(dir, "edited.txt"), []byte(strings.Repeat("v", i+1)), 0644)
+		}
+	}()
+
+	result, err := watch()
+	if err != nil {
+		t.Fatalf("WatchDirectory failed: %v", err)
+	}
+
+	got := make(map[string]WatchEvent)
+	for _, event := range result.Events {
+		got[event.Path] = event
+	}
+	expected := map[string]string{"old.txt": WatchDelete, "out": WatchCreate, "out/app": WatchCreate, "edited.txt": WatchModify}
+	for path, op := range expected {
+		if got[path].Op != op {
+			t.Errorf("Expected %s of %s, got %+v", op, path, got[path])
+		}
+	}
+	if len(result.Events) != len(expected) {
+		t.Errorf("Expected %d events, got %+v", len(expected), result.Events)
+	}
+	if got["edited.txt"].Count != 2 || !got["out"].IsDir {
+		t.Errorf("Expected two merged modifications and a directory create, got %+v and %+v", got["edited.txt"], got["out"])
+	}
+}
+
+func TestWatchDirectoryCancelled(t *testing.T) {
+	fm, dir := newTestFileManager(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	result, err := fm.WatchDirectory(ctx, dir, MaxWatchDuration)
+	if err != nil {
+		t.Fatalf("WatchDirectory failed: %v", err)
+	}
+	if time.Since(start) > time.Second || len(result.Events) != 0 {
+		t.Errorf("Expected a cancelled watch to return at once without events, got %+v", result)
+	}
+}
+
+func TestParseWatchDirectoryArgs(t *testing.T) {
+	if _, _, err := ParseWatchDirectoryArgs([]byte(`{"path": "/tmp", "duration": 61}`)); err == nil {
+		t.Error("Expected a duration over the cap to be rejected")
+	}
+	_, duration, err := ParseWatchDirectoryArgs([]byte(`{"path": "/tmp", "duration": 5}`))
+	if err != nil || duration != 5*time.Second {
+		t.Errorf("Expected 5s, got %v and %v", duration, err)
+	}
+}
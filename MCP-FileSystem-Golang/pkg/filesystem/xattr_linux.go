@@ -0,0 +1,32 @@
+//go:build linux
+
+package filesystem
+
+import (
+	"bytes"
+	"syscall"
+)
+
+// listExtendedAttributes returns the names of a file's extended attributes,
+// or nil if they can't be read, for example on a filesystem without them
+func listExtendedAttributes(path string) []string {
+	size, err := syscall.Listxattr(path, nil)
+	if err != nil || size <= 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	size, err = syscall.Listxattr(path, buf)
+	if err != nil {
+		return nil
+	}
+
+	// Names are NUL-terminated and packed one after another
+	var names []string
+	for _, name := range bytes.Split(buf[:size], []byte{0}) {
+		if len(name) > 0 {
+			names = append(names, string(name))
+		}
+	}
+	return names
+}
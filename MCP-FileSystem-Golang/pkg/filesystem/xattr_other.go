@@ -0,0 +1,9 @@
+//go:build !linux
+
+package filesystem
+
+// listExtendedAttributes is not supported on this platform, so no extended
+// attributes are reported
+func listExtendedAttributes(path string) []string {
+	return nil
+}
@@ -0,0 +1,87 @@
+// Package flock provides OS-level advisory file locks so that read-modify-write
+// operations don't race with other processes (editors, build tools) that honor
+// the same locks.
+//
+// Platform notes:
+//   - Linux, macOS and the BSDs use flock(2). Locks are advisory: they only
+//     exclude other processes that also take a lock on the file.
+//   - Windows and other platforms report ErrUnsupported. Windows already
+//     applies share-mode restrictions to open handles, and its byte-range
+//     locks are mandatory, which would block the server's own writes.
+//   - A lock belongs to the file the path names when it is taken. Replacing
+//     the file by renaming a new one over it, as atomic writes do, leaves
+//     the lock on the old file and lets the next process lock the new one
+//     straight away, so writes made under a lock must rewrite the file in
+//     place. That keeps hard links to the file intact too, but gives up
+//     atomicity: processes that don't take the lock can see a partial write.
+//
+// Callers should treat ErrUnsupported as "proceed without a lock".
+package flock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrUnsupported is returned when advisory locks aren't available on this platform or filesystem
+var ErrUnsupported = errors.New("advisory file locks are not supported on this platform")
+
+// DefaultTimeout is how long Acquire waits for another process to release a lock
+const DefaultTimeout = 5 * time.Second
+
+// retryInterval is the delay between attempts to take a contended lock
+const retryInterval = 50 * time.Millisecond
+
+// Acquire takes an exclusive advisory lock on an existing file and returns a
+// function that releases it. If the file doesn't exist yet there is nothing to
+// contend over, so a no-op release is returned.
+func Acquire(path string, timeout time.Duration) (func(), error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return func() {}, nil
+		}
+		return nil, fmt.Errorf("failed to open file for locking: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLock(file)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("timed out waiting for lock on %s; it is locked by another process", path)
+		}
+		time.Sleep(retryInterval)
+	}
+
+	return func() {
+		unlock(file)
+		file.Close()
+	}, nil
+}
+
+// unsupportedWarning ensures the degraded-mode warning is only logged once
+var unsupportedWarning sync.Once
+
+// AcquireBestEffort behaves like Acquire but degrades to a no-op lock when
+// advisory locks are unsupported, logging a warning the first time
+func AcquireBestEffort(path string, timeout time.Duration) (func(), error) {
+	release, err := Acquire(path, timeout)
+	if errors.Is(err, ErrUnsupported) {
+		unsupportedWarning.Do(func() {
+			fmt.Fprintf(os.Stderr, "Warning: %v; continuing without file locks\n", err)
+		})
+		return func() {}, nil
+	}
+	return release, err
+}
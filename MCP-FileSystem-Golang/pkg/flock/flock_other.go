@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package flock
+
+import "os"
+
+// tryLock reports that advisory locks are unsupported on this platform
+func tryLock(file *os.File) (bool, error) {
+	return false, ErrUnsupported
+}
+
+// unlock is a no-op on platforms without advisory locks
+func unlock(file *os.File) {}
@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// IDGenerator returns the id for a request the server sends to the client.
+// It may be called from several goroutines at once and must never return
+// the same id twice.
+type IDGenerator func() RequestID
+
+// NewNumberID returns a numeric request id. Numbers are held as float64,
+// as when decoded from JSON, so an id sent by the server compares equal to
+// the id echoed back in the client's response.
+func NewNumberID(n int64) RequestID {
+	return RequestID{value: float64(n)}
+}
+
+// NewStringID returns a string request id
+func NewStringID(s string) RequestID {
+	return RequestID{value: s}
+}
+
+// NewCounterIDGenerator returns a generator of the numeric ids 1, 2, 3 and so on
+func NewCounterIDGenerator() IDGenerator {
+	var counter atomic.Int64
+	return func() RequestID {
+		return NewNumberID(counter.Add(1))
+	}
+}
+
+// outboundIDs holds the generator used for server-initiated requests
+type outboundIDs struct {
+	mu        sync.RWMutex
+	generator IDGenerator
+}
+
+// SetIDGenerator replaces the generator of ids for requests the server sends
+// to the client, such as sampling or progress requests. A nil generator
+// restores the default counter.
+func (s *Server) SetIDGenerator(generator IDGenerator) {
+	if generator == nil {
+		generator = NewCounterIDGenerator()
+	}
+
+	s.outbound.mu.Lock()
+	defer s.outbound.mu.Unlock()
+	s.outbound.generator = generator
+}
+
+// NextRequestID returns a new id for a request the server sends to the
+// client, so the client's response can be matched to it
+func (s *Server) NextRequestID() RequestID {
+	s.outbound.mu.RLock()
+	defer s.outbound.mu.RUnlock()
+	return s.outbound.generator()
+}
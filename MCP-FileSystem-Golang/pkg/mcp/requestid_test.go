@@ -0,0 +1,75 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestNextRequestIDUniqueUnderConcurrency(t *testing.T) {
+	server := NewServer(ServerInfo{Name: "test", Version: "1.0"}, ServerConfig{})
+
+	const goroutines, perGoroutine = 50, 200
+	ids := make(chan RequestID, goroutines*perGoroutine)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < perGoroutine; j++ {
+				ids <- server.NextRequestID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool, goroutines*perGoroutine)
+	for id := range ids {
+		if seen[id.key()] {
+			t.Fatalf("Expected unique ids, got %s twice", id)
+		}
+		seen[id.key()] = true
+	}
+	if len(seen) != goroutines*perGoroutine {
+		t.Errorf("Expected %d ids, got %d", goroutines*perGoroutine, len(seen))
+	}
+}
+
+func TestNextRequestIDMatchesDecodedResponse(t *testing.T) {
+	server := NewServer(ServerInfo{Name: "test", Version: "1.0"}, ServerConfig{})
+	id := server.NextRequestID()
+
+	data, err := json.Marshal(ResponseMessage{JsonRPC: "2.0", ID: id})
+	if err != nil {
+		t.Fatalf("Failed to encode response: %v", err)
+	}
+	var response ResponseMessage
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.ID.key() != id.key() {
+		t.Errorf("Expected the decoded id %s to match the sent id %s", response.ID.key(), id.key())
+	}
+}
+
+func TestSetIDGenerator(t *testing.T) {
+	server := NewServer(ServerInfo{Name: "test", Version: "1.0"}, ServerConfig{})
+
+	count := 0
+	server.SetIDGenerator(func() RequestID {
+		count++
+		return NewStringID(fmt.Sprintf("server-%d", count))
+	})
+	if id := server.NextRequestID(); id.String() != "server-1" {
+		t.Errorf("Expected id server-1, got %s", id)
+	}
+
+	// A nil generator restores the default counter
+	server.SetIDGenerator(nil)
+	if id := server.NextRequestID(); id.key() != NewNumberID(1).key() {
+		t.Errorf("Expected id 1, got %s", id.key())
+	}
+}
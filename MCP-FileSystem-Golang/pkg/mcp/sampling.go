@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrSamplingUnsupported is returned by RequestSampling when the client
+// didn't advertise the sampling capability at initialize
+var ErrSamplingUnsupported = errors.New("the client does not support sampling")
+
+// SamplingContent is the content of a sampling message: text, or base64
+// image or audio data with its MIME type
+type SamplingContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Data     string `json:"data,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+}
+
+// SamplingMessage is one message of the conversation sent for sampling
+type SamplingMessage struct {
+	Role    string          `json:"role"` // user or assistant
+	Content SamplingContent `json:"content"`
+}
+
+// ModelHint suggests a model, by full or partial name, for the client to prefer
+type ModelHint struct {
+	Name string `json:"name,omitempty"`
+}
+
+// ModelPreferences guides the client's choice of model. The priorities
+// range from 0 to 1.
+type ModelPreferences struct {
+	Hints                []ModelHint `json:"hints,omitempty"`
+	CostPriority         *float64    `json:"costPriority,omitempty"`
+	SpeedPriority        *float64    `json:"speedPriority,omitempty"`
+	IntelligencePriority *float64    `json:"intelligencePriority,omitempty"`
+}
+
+// CreateMessageParams are the parameters of a sampling/createMessage request
+type CreateMessageParams struct {
+	Messages         []SamplingMessage      `json:"messages"`
+	ModelPreferences *ModelPreferences      `json:"modelPreferences,omitempty"`
+	SystemPrompt     string                 `json:"systemPrompt,omitempty"`
+	IncludeContext   string                 `json:"includeContext,omitempty"` // none, thisServer or allServers
+	Temperature      *float64               `json:"temperature,omitempty"`
+	MaxTokens        int                    `json:"maxTokens"`
+	StopSequences    []string               `json:"stopSequences,omitempty"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// CreateMessageResult is the client's reply to a sampling/createMessage request
+type CreateMessageResult struct {
+	Role       string          `json:"role"`
+	Content    SamplingContent `json:"content"`
+	Model      string          `json:"model"`
+	StopReason string          `json:"stopReason,omitempty"`
+}
+
+// pendingRequests tracks requests sent to the client that await a response
+type pendingRequests struct {
+	mu        sync.Mutex
+	responses map[string]chan ResponseMessage // by RequestID.key()
+}
+
+// add registers id as awaiting a response and returns the channel it will arrive on
+func (p *pendingRequests) add(id RequestID) chan ResponseMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.responses == nil {
+		p.responses = make(map[string]chan ResponseMessage)
+	}
+	ch := make(chan ResponseMessage, 1)
+	p.responses[id.key()] = ch
+	return ch
+}
+
+// remove stops waiting for a response to id
+func (p *pendingRequests) remove(id RequestID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.responses, id.key())
+}
+
+// deliver hands a response to the request awaiting it, returning false if
+// no request is waiting for its id
+func (p *pendingRequests) deliver(response ResponseMessage) bool {
+	p.mu.Lock()
+	ch, ok := p.responses[response.ID.key()]
+	delete(p.responses, response.ID.key())
+	p.mu.Unlock()
+
+	if ok {
+		ch <- response
+	}
+	return ok
+}
+
+// isResponseMessage reports whether a message is a response, with a result
+// or error and no method, rather than a request or notification
+func isResponseMessage(data []byte) bool {
+	var message struct {
+		Method *string          `json:"method"`
+		Result json.RawMessage  `json:"result"`
+		Error  *json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return false
+	}
+	return message.Method == nil && (message.Result != nil || message.Error != nil)
+}
+
+// handleResponse passes a client's response to the server-initiated request
+// awaiting it. Responses nobody is waiting for are logged and dropped.
+func (s *Server) handleResponse(data []byte) {
+	var response ResponseMessage
+	if err := json.Unmarshal(data, &response); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to unmarshal response: %v\n", err)
+		return
+	}
+	if !s.pending.deliver(response) {
+		fmt.Fprintf(os.Stderr, "Dropping response with unknown ID: %s\n", response.ID.String())
+	}
+}
+
+// SupportsSampling reports whether the client advertised the sampling capability
+func (s *Server) SupportsSampling() bool {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+	sampling := s.clientCapabilities.Sampling
+	return len(sampling) > 0 && string(sampling) != "null"
+}
+
+// RequestSampling asks the client to sample a message from its language
+// model and waits for the reply, or until ctx is done. It fails with
+// ErrSamplingUnsupported unless the client advertised sampling, and needs a
+// transport that implements MessageSender.
+func (s *Server) RequestSampling(ctx context.Context, params CreateMessageParams) (*CreateMessageResult, error) {
+	if !s.SupportsSampling() {
+		return nil, ErrSamplingUnsupported
+	}
+
+	result, err := s.sendRequest(ctx, "sampling/createMessage", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var message CreateMessageResult
+	if err := json.Unmarshal(result, &message); err != nil {
+		return nil, fmt.Errorf("invalid sampling result: %w", err)
+	}
+	return &message, nil
+}
+
+// sendRequest sends a request to the client and waits for its result
+func (s *Server) sendRequest(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	sender, ok := s.transport.(MessageSender)
+	if !ok {
+		return nil, fmt.Errorf("the transport cannot send requests to the client")
+	}
+
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s params: %w", method, err)
+	}
+
+	id := s.NextRequestID()
+	data, err := json.Marshal(RequestMessage{
+		JsonRPC: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  paramsJSON,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", method, err)
+	}
+
+	responses := s.pending.add(id)
+	defer s.pending.remove(id)
+
+	fmt.Fprintf(os.Stderr, "Sending %s request with ID: %s\n", method, id.String())
+	if err := sender.Send(data); err != nil {
+		return nil, fmt.Errorf("failed to send %s request: %w", method, err)
+	}
+
+	select {
+	case response := <-responses:
+		if response.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s (code %d)", method, response.Error.Message, response.Error.Code)
+		}
+		return response.Result, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s request %s: %w", method, id.String(), ctx.Err())
+	}
+}
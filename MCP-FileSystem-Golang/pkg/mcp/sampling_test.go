@@ -0,0 +1,165 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// replyingTransport answers every request the server sends with reply,
+// delivering the response back through the server like a client would
+type replyingTransport struct {
+	server *Server
+	reply  func(request RequestMessage) string
+	sent   []RequestMessage
+}
+
+func (t *replyingTransport) Start(handler RequestHandlerFunc) error { return nil }
+func (t *replyingTransport) Stop() error                           { return nil }
+
+func (t *replyingTransport) Send(message []byte) error {
+	var request RequestMessage
+	if err := json.Unmarshal(message, &request); err != nil {
+		return err
+	}
+	t.sent = append(t.sent, request)
+	go t.server.handleRequest([]byte(t.reply(request)))
+	return nil
+}
+
+// newSamplingServer returns a server initialized by a client with the given
+// capabilities and connected to transport
+func newSamplingServer(t *testing.T, capabilities string, transport Transport) *Server {
+	t.Helper()
+
+	server := NewServer(ServerInfo{Name: "test", Version: "1.0"}, ServerConfig{})
+	if err := server.Connect(transport); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	initialize := `{"jsonrpc":"2.0","id":"init","method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"client","version":"1"},"capabilities":` + capabilities + `}}`
+	if _, err := server.handleRequest([]byte(initialize)); err != nil {
+		t.Fatalf("initialize failed: %v", err)
+	}
+	return server
+}
+
+func TestRequestSampling(t *testing.T) {
+	transport := &replyingTransport{reply: func(request RequestMessage) string {
+		id, _ := json.Marshal(request.ID)
+		return `{"jsonrpc":"2.0","id":` + string(id) + `,"result":{"role":"assistant","content":{"type":"text","text":"A config file"},"model":"test-model","stopReason":"endTurn"}}`
+	}}
+	server := newSamplingServer(t, `{"sampling":{}}`, transport)
+	transport.server = server
+
+	result, err := server.RequestSampling(context.Background(), CreateMessageParams{
+		Messages:  []SamplingMessage{{Role: "user", Content: SamplingContent{Type: "text", Text: "Explain this file"}}},
+		MaxTokens: 100,
+	})
+	if err != nil {
+		t.Fatalf("RequestSampling failed: %v", err)
+	}
+	if result.Content.Text != "A config file" || result.Model != "test-model" || result.StopReason != "endTurn" {
+		t.Errorf("Expected the client's reply, got %+v", result)
+	}
+
+	if len(transport.sent) != 1 || transport.sent[0].Method != "sampling/createMessage" {
+		t.Fatalf("Expected one sampling/createMessage request, got %+v", transport.sent)
+	}
+	var params CreateMessageParams
+	if err := json.Unmarshal(transport.sent[0].Params, &params); err != nil || params.MaxTokens != 100 || len(params.Messages) != 1 {
+		t.Errorf("Expected the sampling params to be sent, got %s and %v", string(transport.sent[0].Params), err)
+	}
+}
+
+func TestRequestSamplingErrors(t *testing.T) {
+	transport := &replyingTransport{reply: func(request RequestMessage) string {
+		id, _ := json.Marshal(request.ID)
+		return `{"jsonrpc":"2.0","id":` + string(id) + `,"error":{"code":-1,"message":"User rejected sampling request"}}`
+	}}
+	server := newSamplingServer(t, `{"sampling":{}}`, transport)
+	transport.server = server
+
+	_, err := server.RequestSampling(context.Background(), CreateMessageParams{MaxTokens: 10})
+	if err == nil || !strings.Contains(err.Error(), "User rejected sampling request") {
+		t.Errorf("Expected the client's error, got %v", err)
+	}
+
+	// Without the capability nothing is sent
+	unsupported := &replyingTransport{}
+	server = newSamplingServer(t, `{"roots":{}}`, unsupported)
+	if _, err := server.RequestSampling(context.Background(), CreateMessageParams{MaxTokens: 10}); !errors.Is(err, ErrSamplingUnsupported) {
+		t.Errorf("Expected ErrSamplingUnsupported, got %v", err)
+	}
+	if len(unsupported.sent) != 0 {
+		t.Errorf("Expected no request to be sent, got %+v", unsupported.sent)
+	}
+}
+
+func TestRequestSamplingCancelled(t *testing.T) {
+	// A client that never answers
+	transport := &replyingTransport{reply: func(request RequestMessage) string { return `{}` }}
+	server := newSamplingServer(t, `{"sampling":{}}`, transport)
+	transport.server = server
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := server.RequestSampling(ctx, CreateMessageParams{MaxTokens: 10}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the request to time out, got %v", err)
+	}
+}
+
+func TestStdioTransportSamplingFromHandler(t *testing.T) {
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	transport := newStdioTransport(serverIn, serverOut)
+
+	server := NewServer(ServerInfo{Name: "test", Version: "1.0"}, ServerConfig{})
+	server.SetRequestHandler("explain", func(params json.RawMessage) (json.RawMessage, error) {
+		result, err := server.RequestSampling(context.Background(), CreateMessageParams{MaxTokens: 10})
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(result.Content.Text)
+	})
+	if err := server.Connect(transport); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer clientOut.Close()
+
+	lines := bufio.NewScanner(clientIn)
+	send := func(message string) {
+		clientOut.Write([]byte(message + "\n"))
+	}
+	receive := func() map[string]json.RawMessage {
+		if !lines.Scan() {
+			t.Fatalf("Expected a message from the server: %v", lines.Err())
+		}
+		var message map[string]json.RawMessage
+		if err := json.Unmarshal(lines.Bytes(), &message); err != nil {
+			t.Fatalf("Failed to parse %s: %v", lines.Text(), err)
+		}
+		return message
+	}
+
+	send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","clientInfo":{"name":"client","version":"1"},"capabilities":{"sampling":{}}}}`)
+	receive()
+
+	// The handler's sampling request reaches the client while the tool call
+	// is still being handled, and the client's reply unblocks it
+	go send(`{"jsonrpc":"2.0","id":2,"method":"explain"}`)
+	request := receive()
+	if string(request["method"]) != `"sampling/createMessage"` {
+		t.Fatalf("Expected a sampling request, got %v", request)
+	}
+	go send(`{"jsonrpc":"2.0","id":` + string(request["id"]) + `,"result":{"role":"assistant","content":{"type":"text","text":"sampled"},"model":"m"}}`)
+
+	response := receive()
+	if string(response["id"]) != "2" || string(response["result"]) != `"sampled"` {
+		t.Errorf("Expected the tool result to carry the sampled text, got %v", response)
+	}
+}
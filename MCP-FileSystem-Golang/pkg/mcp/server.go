@@ -2,8 +2,10 @@ package mcp
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"runtime/debug"
 	"sync"
 )
 
@@ -15,6 +17,18 @@ type Server struct {
 	transport   Transport
 	handlersMux sync.RWMutex
 	initialized bool
+
+	strictJSONRPC bool // reject messages without jsonrpc "2.0"
+
+	rejectDuplicateIDs bool
+	inFlightMux        sync.Mutex
+	inFlight           map[string]bool // ids of requests being handled
+
+	outbound outboundIDs     // ids for requests sent to the client
+	pending  pendingRequests // requests sent to the client awaiting a response
+
+	clientMu           sync.RWMutex
+	clientCapabilities ClientCapabilities // as advertised at initialize
 }
 
 // NewServer creates a new MCP server
@@ -24,7 +38,49 @@ func NewServer(info ServerInfo, config ServerConfig) *Server {
 		config:      config,
 		handlers:    make(map[string]RequestHandler),
 		initialized: false,
+		inFlight:    make(map[string]bool),
+		outbound:    outboundIDs{generator: NewCounterIDGenerator()},
+	}
+}
+
+// SetStrictJSONRPC enables rejecting messages whose jsonrpc field is missing
+// or not "2.0". By default such messages are accepted, for clients that
+// omit the field.
+func (s *Server) SetStrictJSONRPC(enabled bool) {
+	s.strictJSONRPC = enabled
+}
+
+// SetRejectDuplicateIDs enables rejecting a request whose id matches one
+// still being handled. An id may be reused once its earlier request has
+// completed.
+func (s *Server) SetRejectDuplicateIDs(enabled bool) {
+	s.inFlightMux.Lock()
+	defer s.inFlightMux.Unlock()
+	s.rejectDuplicateIDs = enabled
+}
+
+// beginRequest records id as in flight, returning false if it already is.
+// Detection is skipped, always returning true, when it is disabled.
+func (s *Server) beginRequest(id RequestID) bool {
+	s.inFlightMux.Lock()
+	defer s.inFlightMux.Unlock()
+
+	if !s.rejectDuplicateIDs {
+		return true
+	}
+	key := id.key()
+	if s.inFlight[key] {
+		return false
 	}
+	s.inFlight[key] = true
+	return true
+}
+
+// endRequest marks id as no longer in flight
+func (s *Server) endRequest(id RequestID) {
+	s.inFlightMux.Lock()
+	defer s.inFlightMux.Unlock()
+	delete(s.inFlight, id.key())
 }
 
 // SetRequestHandler sets a handler for a specific request method
@@ -41,6 +97,18 @@ func (s *Server) GetHandler(method string) RequestHandler {
 	return s.handlers[method]
 }
 
+// knownMethods returns every method the server responds to
+func (s *Server) knownMethods() []string {
+	s.handlersMux.RLock()
+	defer s.handlersMux.RUnlock()
+
+	methods := []string{"initialize", "notifications/initialized"}
+	for method := range s.handlers {
+		methods = append(methods, method)
+	}
+	return methods
+}
+
 // Connect connects the server to a transport
 func (s *Server) Connect(transport Transport) error {
 	s.transport = transport
@@ -64,8 +132,47 @@ func (s *Server) handleRequest(data []byte) ([]byte, error) {
 		return nil, fmt.Errorf("failed to unmarshal request: %w", err)
 	}
 
+	// A response to a request the server sent goes to whoever awaits it
+	if request.Method == "" && isResponseMessage(data) {
+		fmt.Fprintf(os.Stderr, "Handling response to ID: %s\n", request.ID.String())
+		s.handleResponse(data)
+		return nil, nil
+	}
+
 	fmt.Fprintf(os.Stderr, "Handling method: %s, ID: %s\n", request.Method, request.ID.String())
 
+	// In strict mode only JSON-RPC 2.0 messages are accepted
+	if s.strictJSONRPC && request.JsonRPC != "2.0" {
+		fmt.Fprintf(os.Stderr, "Rejecting request with jsonrpc version %q\n", request.JsonRPC)
+		response := ResponseMessage{
+			JsonRPC: "2.0",
+			ID:      request.ID,
+			Error: &ErrorResponse{
+				Code:    -32600,
+				Message: fmt.Sprintf("Invalid Request: jsonrpc must be \"2.0\", got %q", request.JsonRPC),
+			},
+		}
+		return json.Marshal(response)
+	}
+
+	// Reject a request reusing the id of one still in flight, as its
+	// response could not be told apart from the earlier one
+	if !request.ID.IsEmpty() {
+		if !s.beginRequest(request.ID) {
+			fmt.Fprintf(os.Stderr, "Rejecting request with duplicate ID: %s\n", request.ID.String())
+			response := ResponseMessage{
+				JsonRPC: "2.0",
+				ID:      request.ID,
+				Error: &ErrorResponse{
+					Code:    -32600,
+					Message: fmt.Sprintf("Invalid Request: request id %s is already in progress", request.ID.String()),
+				},
+			}
+			return json.Marshal(response)
+		}
+		defer s.endRequest(request.ID)
+	}
+
 	// Check if this is the initialize method
 	if request.Method == "initialize" {
 		fmt.Fprintf(os.Stderr, "Processing initialize request\n")
@@ -108,13 +215,17 @@ func (s *Server) handleRequest(data []byte) ([]byte, error) {
 
 	if !ok {
 		fmt.Fprintf(os.Stderr, "Method not supported: %s\n", request.Method)
-		// Method not supported
+		// Method not supported, suggesting the closest known method for likely typos
+		message := fmt.Sprintf("Method not supported: %s", request.Method)
+		if suggestion := suggestMethod(request.Method, s.knownMethods()); suggestion != "" {
+			message = fmt.Sprintf("%s (did you mean %s?)", message, suggestion)
+		}
 		response := ResponseMessage{
 			JsonRPC: "2.0",
 			ID:      request.ID,
 			Error: &ErrorResponse{
 				Code:    -32601,
-				Message: fmt.Sprintf("Method not supported: %s", request.Method),
+				Message: message,
 			},
 		}
 		return json.Marshal(response)
@@ -122,7 +233,20 @@ func (s *Server) handleRequest(data []byte) ([]byte, error) {
 
 	// Call the handler
 	fmt.Fprintf(os.Stderr, "Calling handler for method: %s\n", request.Method)
-	result, err := handler(request.Params)
+	result, err := callHandler(request.Method, handler, request.Params)
+	var panicErr *handlerPanic
+	if errors.As(err, &panicErr) {
+		// Keep serving other requests after a handler bug
+		response := ResponseMessage{
+			JsonRPC: "2.0",
+			ID:      request.ID,
+			Error: &ErrorResponse{
+				Code:    -32603,
+				Message: "Internal error: " + panicErr.Error(),
+			},
+		}
+		return json.Marshal(response)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Handler error for method %s: %v\n", request.Method, err)
 		// Handler returned an error
@@ -155,6 +279,27 @@ func (s *Server) handleRequest(data []byte) ([]byte, error) {
 	return responseBytes, nil
 }
 
+// handlerPanic is the error callHandler returns when a handler panics
+type handlerPanic struct {
+	value interface{}
+}
+
+func (p *handlerPanic) Error() string {
+	return fmt.Sprintf("handler panicked: %v", p.value)
+}
+
+// callHandler calls a request handler, turning a panic into a handlerPanic
+// error and logging its stack so one bad request can't stop the server
+func callHandler(method string, handler RequestHandler, params json.RawMessage) (result json.RawMessage, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "Panic in handler for method %s: %v\n%s", method, r, debug.Stack())
+			err = &handlerPanic{value: r}
+		}
+	}()
+	return handler(params)
+}
+
 // handleInitialize handles the initialize method
 func (s *Server) handleInitialize(request RequestMessage) ([]byte, error) {
 	fmt.Fprintf(os.Stderr, "Parsing initialize params\n")
@@ -175,6 +320,17 @@ func (s *Server) handleInitialize(request RequestMessage) ([]byte, error) {
 	fmt.Fprintf(os.Stderr, "Client info: %s %s\n", params.ClientInfo.Name, params.ClientInfo.Version)
 	fmt.Fprintf(os.Stderr, "Protocol version: %s\n", params.ProtocolVersion)
 
+	// Record what the client can do, for server-initiated requests
+	var clientCapabilities ClientCapabilities
+	if len(params.Capabilities) > 0 {
+		if err := json.Unmarshal(params.Capabilities, &clientCapabilities); err != nil {
+			fmt.Fprintf(os.Stderr, "Ignoring invalid client capabilities: %v\n", err)
+		}
+	}
+	s.clientMu.Lock()
+	s.clientCapabilities = clientCapabilities
+	s.clientMu.Unlock()
+
 	// Accept the client's protocol version
 	protocolVersion := params.ProtocolVersion
 	if protocolVersion == "" {
@@ -187,12 +343,13 @@ func (s *Server) handleInitialize(request RequestMessage) ([]byte, error) {
 		Version: s.info.Version,
 	}
 
-	// Create capabilities object
-	capabilities := map[string]interface{}{
-		"tools": map[string]interface{}{
+	// Create capabilities object from the server configuration
+	capabilities := s.config.Capabilities
+	if capabilities.Tools == nil {
+		capabilities.Tools = map[string]interface{}{
 			"list": true,
 			"call": true,
-		},
+		}
 	}
 
 	// Create the initialize result
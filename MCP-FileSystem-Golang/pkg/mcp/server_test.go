@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// newBlockingServer returns an initialized server whose "block" method waits
+// for release, signalling started once each call begins
+func newBlockingServer() (server *Server, started chan struct{}, release chan struct{}) {
+	server = NewServer(ServerInfo{Name: "test", Version: "1.0"}, ServerConfig{})
+	server.initialized = true
+
+	started = make(chan struct{})
+	release = make(chan struct{})
+	server.SetRequestHandler("block", func(params json.RawMessage) (json.RawMessage, error) {
+		started <- struct{}{}
+		<-release
+		return json.RawMessage(`{}`), nil
+	})
+	server.SetRequestHandler("echo", func(params json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{}`), nil
+	})
+	return server, started, release
+}
+
+// responseError returns the error in a response, or nil for a result
+func responseError(t *testing.T, data []byte) *ErrorResponse {
+	t.Helper()
+
+	var response ResponseMessage
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatalf("Failed to parse response %s: %v", string(data), err)
+	}
+	return response.Error
+}
+
+func TestDuplicateRequestIDRejected(t *testing.T) {
+	server, started, release := newBlockingServer()
+	server.SetRejectDuplicateIDs(true)
+
+	first := make(chan []byte)
+	go func() {
+		response, _ := server.handleRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"block"}`))
+		first <- response
+	}()
+	<-started
+
+	// A second request with the same id while the first is in flight is rejected
+	response, err := server.handleRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"echo"}`))
+	if err != nil {
+		t.Fatalf("handleRequest failed: %v", err)
+	}
+	if rpcErr := responseError(t, response); rpcErr == nil || rpcErr.Code != -32600 {
+		t.Errorf("Expected a -32600 error for the duplicate id, got %s", string(response))
+	}
+
+	// A string id with the same text is a different id
+	response, _ = server.handleRequest([]byte(`{"jsonrpc":"2.0","id":"7","method":"echo"}`))
+	if rpcErr := responseError(t, response); rpcErr != nil {
+		t.Errorf("Expected string id \"7\" to be accepted, got %s", rpcErr.Message)
+	}
+
+	close(release)
+	if rpcErr := responseError(t, <-first); rpcErr != nil {
+		t.Errorf("Expected the first request to succeed, got %s", rpcErr.Message)
+	}
+
+	// The id may be reused once the first request has completed
+	response, _ = server.handleRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"echo"}`))
+	if rpcErr := responseError(t, response); rpcErr != nil {
+		t.Errorf("Expected id reuse after completion to be accepted, got %s", rpcErr.Message)
+	}
+}
+
+func TestDuplicateRequestIDAllowedWhenDisabled(t *testing.T) {
+	server, started, release := newBlockingServer()
+
+	first := make(chan []byte)
+	go func() {
+		response, _ := server.handleRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"block"}`))
+		first <- response
+	}()
+	<-started
+
+	response, _ := server.handleRequest([]byte(`{"jsonrpc":"2.0","id":7,"method":"echo"}`))
+	if rpcErr := responseError(t, response); rpcErr != nil {
+		t.Errorf("Expected duplicate ids to be accepted when detection is off, got %s", rpcErr.Message)
+	}
+
+	close(release)
+	<-first
+}
+
+func TestStrictJSONRPC(t *testing.T) {
+	server, _, _ := newBlockingServer()
+
+	requests := []string{
+		`{"id":1,"method":"echo"}`,
+		`{"jsonrpc":"1.0","id":2,"method":"echo"}`,
+	}
+
+	// Lenient mode accepts messages without a valid jsonrpc version
+	for _, request := range requests {
+		response, err := server.handleRequest([]byte(request))
+		if err != nil {
+			t.Fatalf("handleRequest failed: %v", err)
+		}
+		if rpcErr := responseError(t, response); rpcErr != nil {
+			t.Errorf("Expected %s to be accepted in lenient mode, got %s", request, string(response))
+		}
+	}
+
+	server.SetStrictJSONRPC(true)
+	for _, request := range requests {
+		response, err := server.handleRequest([]byte(request))
+		if err != nil {
+			t.Fatalf("handleRequest failed: %v", err)
+		}
+		if rpcErr := responseError(t, response); rpcErr == nil || rpcErr.Code != -32600 {
+			t.Errorf("Expected a -32600 error for %s in strict mode, got %s", request, string(response))
+		}
+	}
+
+	response, _ := server.handleRequest([]byte(`{"jsonrpc":"2.0","id":3,"method":"echo"}`))
+	if rpcErr := responseError(t, response); rpcErr != nil {
+		t.Errorf("Expected a valid request to be accepted in strict mode, got %s", string(response))
+	}
+}
+
+func TestHandlerPanicReturnsInternalError(t *testing.T) {
+	server, _, _ := newBlockingServer()
+	server.SetRequestHandler("panic", func(params json.RawMessage) (json.RawMessage, error) {
+		var values []string
+		return json.RawMessage(values[1]), nil
+	})
+
+	response, err := server.handleRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"panic"}`))
+	if err != nil {
+		t.Fatalf("handleRequest failed: %v", err)
+	}
+	if rpcErr := responseError(t, response); rpcErr == nil || rpcErr.Code != -32603 {
+		t.Errorf("Expected a -32603 error for the panic, got %s", string(response))
+	}
+
+	// The server keeps handling requests, including one reusing the id
+	server.SetRejectDuplicateIDs(true)
+	response, err = server.handleRequest([]byte(`{"jsonrpc":"2.0","id":1,"method":"echo"}`))
+	if err != nil {
+		t.Fatalf("handleRequest failed: %v", err)
+	}
+	if rpcErr := responseError(t, response); rpcErr != nil {
+		t.Errorf("Expected a result after the panic, got error %+v", rpcErr)
+	}
+}
@@ -0,0 +1,25 @@
+package mcp
+
+import "testing"
+
+func TestSuggestMethod(t *testing.T) {
+	known := []string{"initialize", "tools/list", "tools/call", "list_tools", "call_tool"}
+
+	tests := []struct {
+		method   string
+		expected string
+	}{
+		{"tool/list", "tools/list"},
+		{"tools/cal", "tools/call"},
+		{"Tools/List", "tools/list"},
+		{"initialise", "initialize"},
+		{"resources/list", ""},
+		{"x", ""},
+	}
+
+	for _, tt := range tests {
+		if got := suggestMethod(tt.method, known); got != tt.expected {
+			t.Errorf("suggestMethod(%q) = %q, expected %q", tt.method, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package mcp
+
+import "sort"
+
+// LimitTools orders tools by priority and keeps at most max of them,
+// returning the kept tools and the names of those left out. Tools named in
+// priority come first, in that order, followed by the rest sorted by name.
+// A max of zero or less keeps every tool.
+func LimitTools(tools []Tool, priority []string, max int) ([]Tool, []string) {
+	rank := make(map[string]int, len(priority))
+	for i, name := range priority {
+		if _, seen := rank[name]; !seen {
+			rank[name] = i
+		}
+	}
+
+	ordered := append([]Tool(nil), tools...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ri, rankedI := rank[ordered[i].Name]
+		rj, rankedJ := rank[ordered[j].Name]
+		if rankedI != rankedJ {
+			return rankedI
+		}
+		if rankedI && ri != rj {
+			return ri < rj
+		}
+		return ordered[i].Name < ordered[j].Name
+	})
+
+	if max <= 0 || len(ordered) <= max {
+		return ordered, nil
+	}
+
+	hidden := make([]string, 0, len(ordered)-max)
+	for _, tool := range ordered[max:] {
+		hidden = append(hidden, tool.Name)
+	}
+	return ordered[:max], hidden
+}
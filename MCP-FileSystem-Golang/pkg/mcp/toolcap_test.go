@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func toolNames(tools []Tool) []string {
+	names := make([]string, len(tools))
+	for i, tool := range tools {
+		names[i] = tool.Name
+	}
+	return names
+}
+
+func TestLimitTools(t *testing.T) {
+	tools := []Tool{{Name: "glob"}, {Name: "write_file"}, {Name: "read_file"}, {Name: "grep_files"}, {Name: "create_snapshot"}}
+	priority := []string{"read_file", "write_file", "unknown_tool"}
+
+	exposed, hidden := LimitTools(tools, priority, 3)
+	if names := toolNames(exposed); !reflect.DeepEqual(names, []string{"read_file", "write_file", "create_snapshot"}) {
+		t.Errorf("Expected priority tools first then by name, got %v", names)
+	}
+	if !reflect.DeepEqual(hidden, []string{"glob", "grep_files"}) {
+		t.Errorf("Expected glob and grep_files to be hidden, got %v", hidden)
+	}
+
+	all, hidden := LimitTools(tools, priority, 0)
+	if len(all) != len(tools) || len(hidden) != 0 {
+		t.Errorf("Expected no cap to keep all %d tools, got %d with %v hidden", len(tools), len(all), hidden)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 // RequestHandlerFunc is a function that processes a request and returns a response
@@ -18,25 +19,59 @@ type Transport interface {
 	Stop() error
 }
 
-// StdioTransport implements the Transport interface using stdin/stdout
+// MessageSender is implemented by transports that can send a message to the
+// client outside of a response, as server-initiated requests need
+type MessageSender interface {
+	Send(message []byte) error
+}
+
+// StdioTransport implements the Transport interface using stdin/stdout.
+// Requests are handled one at a time in the order they arrive, while
+// responses to server-initiated requests are delivered as soon as they are
+// read, so a handler can wait on the client without blocking its own reply.
 type StdioTransport struct {
 	running   bool
 	stopChan  chan struct{}
 	waitGroup sync.WaitGroup
 	reader    *bufio.Reader
 	writer    *bufio.Writer
+	writeMu   sync.Mutex // serializes writes to writer
 	mutex     sync.Mutex
+	idle      *idleMonitor
 }
 
 // NewStdioTransport creates a new stdio transport
 func NewStdioTransport() *StdioTransport {
+	return newStdioTransport(os.Stdin, os.Stdout)
+}
+
+// newStdioTransport creates a transport reading from r and writing to w
+func newStdioTransport(r io.Reader, w io.Writer) *StdioTransport {
 	return &StdioTransport{
-		reader:   bufio.NewReader(os.Stdin),
-		writer:   bufio.NewWriter(os.Stdout),
+		reader:   bufio.NewReader(r),
+		writer:   bufio.NewWriter(w),
 		stopChan: make(chan struct{}),
+		idle:     newIdleMonitor(0),
 	}
 }
 
+// SetIdleTimeout configures how long the transport may go without receiving a
+// request before signalling idle. A zero duration disables the idle timeout.
+// It must be called before Start.
+func (t *StdioTransport) SetIdleTimeout(timeout time.Duration) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.idle = newIdleMonitor(timeout)
+}
+
+// Idle returns a channel that is closed once the idle timeout elapses with no
+// request in flight. The channel is never closed if the timeout is disabled.
+func (t *StdioTransport) Idle() <-chan struct{} {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.idle.Done()
+}
+
 // Start starts the transport
 func (t *StdioTransport) Start(handler RequestHandlerFunc) error {
 	t.mutex.Lock()
@@ -47,9 +82,12 @@ func (t *StdioTransport) Start(handler RequestHandlerFunc) error {
 	}
 
 	t.running = true
-	t.waitGroup.Add(1)
+	t.waitGroup.Add(2)
+	t.idle.Start()
 
-	go t.processRequests(handler)
+	requests := make(chan string)
+	go t.readMessages(handler, requests)
+	go t.processRequests(handler, requests)
 
 	return nil
 }
@@ -64,15 +102,19 @@ func (t *StdioTransport) Stop() error {
 	}
 
 	close(t.stopChan)
+	t.idle.Stop()
 	t.waitGroup.Wait()
 	t.running = false
 
 	return nil
 }
 
-// processRequests reads and processes requests from stdin
-func (t *StdioTransport) processRequests(handler RequestHandlerFunc) {
+// readMessages reads messages from stdin, delivering responses to
+// server-initiated requests straight to the handler and queueing everything
+// else for processRequests
+func (t *StdioTransport) readMessages(handler RequestHandlerFunc, requests chan<- string) {
 	defer t.waitGroup.Done()
+	defer close(requests)
 
 	for {
 		select {
@@ -100,39 +142,148 @@ func (t *StdioTransport) processRequests(handler RequestHandlerFunc) {
 			// Log the received message
 			fmt.Fprintf(os.Stderr, "Received message: %s\n", line)
 
-			// Process the request
-			response, err := handler([]byte(line))
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error processing request: %v\n", err)
+			// A response may be awaited by the request being processed, so
+			// it can't wait its turn behind that request
+			if isResponseMessage([]byte(line)) {
+				if _, err := handler([]byte(line)); err != nil {
+					fmt.Fprintf(os.Stderr, "Error processing response: %v\n", err)
+				}
 				continue
 			}
 
-			// If empty response, don't send anything (notification)
-			if len(response) == 0 {
-				continue
+			select {
+			case requests <- line:
+			case <-t.stopChan:
+				return
 			}
+		}
+	}
+}
 
-			// Add newline to the response
-			response = append(response, '\n')
+// processRequests handles queued requests in order and writes their responses
+func (t *StdioTransport) processRequests(handler RequestHandlerFunc, requests <-chan string) {
+	defer t.waitGroup.Done()
 
-			// Debug the outgoing message
-			fmt.Fprintf(os.Stderr, "Sending response: %s", string(response))
+	for line := range requests {
+		// Process the request, holding off the idle timer while it runs
+		t.idle.Begin()
+		response, err := handler([]byte(line))
+		t.idle.End()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error processing request: %v\n", err)
+			continue
+		}
 
-			// Write the response
-			_, err = t.writer.Write(response)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error writing response: %v\n", err)
-				continue
-			}
-			
-			// Flush the buffer to ensure the response is sent
-			err = t.writer.Flush()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error flushing response: %v\n", err)
-				continue
-			}
+		// If empty response, don't send anything (notification)
+		if len(response) == 0 {
+			continue
+		}
 
-			fmt.Fprintf(os.Stderr, "Response sent successfully\n")
+		// Debug the outgoing message
+		fmt.Fprintf(os.Stderr, "Sending response: %s\n", string(response))
+
+		if err := t.Send(response); err != nil {
+			fmt.Fprintf(os.Stderr, "Error sending response: %v\n", err)
+			continue
 		}
+
+		fmt.Fprintf(os.Stderr, "Response sent successfully\n")
+	}
+}
+
+// Send writes a message to stdout as a single line
+func (t *StdioTransport) Send(message []byte) error {
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+
+	// Write the message followed by a newline
+	if _, err := t.writer.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := t.writer.WriteByte('\n'); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	
+	// Flush the buffer to ensure the message is sent
+	if err := t.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to flush message: %w", err)
+	}
+	return nil
+}
+
+// idleMonitor signals when no request has been in flight for a configured duration
+type idleMonitor struct {
+	timeout  time.Duration
+	timer    *time.Timer
+	inFlight int
+	done     chan struct{}
+	once     sync.Once
+	mu       sync.Mutex
+}
+
+// newIdleMonitor creates an idle monitor; a zero timeout disables it
+func newIdleMonitor(timeout time.Duration) *idleMonitor {
+	return &idleMonitor{
+		timeout: timeout,
+		done:    make(chan struct{}),
+	}
+}
+
+// Done returns a channel that is closed when the idle timeout fires
+func (m *idleMonitor) Done() <-chan struct{} {
+	return m.done
+}
+
+// Start arms the idle timer
+func (m *idleMonitor) Start() {
+	if m.timeout <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timer = time.AfterFunc(m.timeout, m.fire)
+}
+
+// Stop disarms the idle timer without signalling
+func (m *idleMonitor) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+}
+
+// Begin marks a request as in flight, preventing the idle timeout from firing
+func (m *idleMonitor) Begin() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight++
+	if m.timer != nil {
+		m.timer.Stop()
+	}
+}
+
+// End marks a request as complete and restarts the idle timer once nothing is in flight
+func (m *idleMonitor) End() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight > 0 {
+		m.inFlight--
+	}
+	if m.inFlight == 0 && m.timer != nil {
+		m.timer.Reset(m.timeout)
+	}
+}
+
+// fire is called by the timer and signals idle unless a request is in flight
+func (m *idleMonitor) fire() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.inFlight > 0 {
+		return
 	}
+	m.once.Do(func() {
+		close(m.done)
+	})
 }
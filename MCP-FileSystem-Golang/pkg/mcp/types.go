@@ -45,6 +45,11 @@ func (r RequestID) String() string {
 	return fmt.Sprintf("%v", r.value)
 }
 
+// key identifies the ID for comparison, keeping the number 1 distinct from the string "1"
+func (r RequestID) key() string {
+	return fmt.Sprintf("%T:%v", r.value, r.value)
+}
+
 // IsEmpty returns true if the ID is empty/nil
 func (r RequestID) IsEmpty() bool {
 	return r.value == nil
@@ -98,6 +103,15 @@ type InitializeParams struct {
 	Capabilities    json.RawMessage `json:"capabilities"`
 }
 
+// ClientCapabilities are the optional features a client advertises at
+// initialize. A capability is supported when its field is present, even
+// as an empty object.
+type ClientCapabilities struct {
+	Sampling     json.RawMessage `json:"sampling,omitempty"`
+	Roots        json.RawMessage `json:"roots,omitempty"`
+	Experimental json.RawMessage `json:"experimental,omitempty"`
+}
+
 // InitializeResult represents the response to the initialize request
 type InitializeResult struct {
 	ProtocolVersion string          `json:"protocolVersion"`
@@ -126,6 +140,18 @@ type ListToolsResponse struct {
 type CallToolRequest struct {
 	Name      string          `json:"name"`
 	Arguments json.RawMessage `json:"arguments"`
+	Meta      json.RawMessage `json:"_meta,omitempty"` // e.g. progressToken, preserved verbatim
+}
+
+// CallToolBatchRequest represents a request to call several tools in one round trip
+type CallToolBatchRequest struct {
+	Calls       []CallToolRequest `json:"calls"`
+	StopOnError bool              `json:"stop_on_error,omitempty"`
+}
+
+// CallToolBatchResponse represents the ordered results of a batch tool call
+type CallToolBatchResponse struct {
+	Results []json.RawMessage `json:"results"`
 }
 
 // ContentItem represents an item in the content array
@@ -137,7 +163,11 @@ type ContentItem struct {
 // CallToolResponse represents a response from calling a tool
 type CallToolResponse struct {
 	Content []ContentItem `json:"content"`
-	IsError bool          `json:"isError,omitempty"`
+	// StructuredContent is a machine-readable JSON object with the same
+	// result as Content, for tools that have structured data
+	StructuredContent json.RawMessage `json:"structuredContent,omitempty"`
+	IsError           bool            `json:"isError,omitempty"`
+	Meta              json.RawMessage `json:"_meta,omitempty"`
 }
 
 // RequestHandler is a function that handles a specific request method
@@ -145,7 +175,20 @@ type RequestHandler func(params json.RawMessage) (json.RawMessage, error)
 
 // ServerCapabilities represents the capabilities of the server
 type ServerCapabilities struct {
-	Tools map[string]interface{} `json:"tools"`
+	Tools    map[string]interface{} `json:"tools"`
+	Features *ServerFeatures        `json:"features,omitempty"`
+}
+
+// ServerFeatures reports which optional features are enabled by the
+// server's effective configuration, so clients can adapt their behavior.
+// It is sent as an extension field of the initialize capabilities.
+type ServerFeatures struct {
+	ReadOnly bool                   `json:"readOnly"`
+	Tools    []string               `json:"tools"`
+	Cache    bool                   `json:"cache"`
+	Retry    map[string]interface{} `json:"retry,omitempty"`
+	Limits   map[string]interface{} `json:"limits,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
 }
 
 // ServerConfig represents the server configuration
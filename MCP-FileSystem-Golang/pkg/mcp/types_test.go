@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCallToolRequestMetaRoundTrip(t *testing.T) {
+	input := `{"name":"read_file","arguments":{"path":"a.txt"},"_meta":{"progressToken":"abc-123","trace":{"id":7}}}`
+
+	var request CallToolRequest
+	if err := json.Unmarshal([]byte(input), &request); err != nil {
+		t.Fatalf("Failed to unmarshal request: %v", err)
+	}
+
+	expectedMeta := `{"progressToken":"abc-123","trace":{"id":7}}`
+	if string(request.Meta) != expectedMeta {
+		t.Errorf("Meta not preserved verbatim. Expected %s, got %s", expectedMeta, string(request.Meta))
+	}
+
+	output, err := json.Marshal(request)
+	if err != nil {
+		t.Fatalf("Failed to marshal request: %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("Round trip mismatch. Expected:\n%s\nGot:\n%s", input, string(output))
+	}
+}
+
+func TestCallToolResponseMeta(t *testing.T) {
+	// Meta is omitted when empty
+	output, err := json.Marshal(CallToolResponse{
+		Content: []ContentItem{{Type: "text", Text: "ok"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	if strings.Contains(string(output), "_meta") {
+		t.Errorf("Expected _meta to be omitted, got %s", string(output))
+	}
+
+	// Meta is carried through when set
+	input := `{"content":[{"type":"text","text":"ok"}],"_meta":{"progressToken":42}}`
+	var response CallToolResponse
+	if err := json.Unmarshal([]byte(input), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	output, err = json.Marshal(response)
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	if string(output) != input {
+		t.Errorf("Round trip mismatch. Expected:\n%s\nGot:\n%s", input, string(output))
+	}
+}
@@ -0,0 +1,94 @@
+package pathlock
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Manager serializes operations on the same path while letting operations on
+// different paths proceed in parallel. Locks are reference counted and removed
+// once no caller holds or waits on them, so the map does not grow unbounded.
+type Manager struct {
+	locks sync.Map // map[string]*entry
+}
+
+// entry is a reference-counted mutex for a single path
+type entry struct {
+	mu    sync.Mutex
+	refMu sync.Mutex
+	refs  int
+	dead  bool
+}
+
+// defaultManager is shared by every package that locks paths so that, for
+// example, a write_file and a str_replace on the same file serialize
+var defaultManager = New()
+
+// New creates a new lock manager
+func New() *Manager {
+	return &Manager{}
+}
+
+// Default returns the process-wide lock manager
+func Default() *Manager {
+	return defaultManager
+}
+
+// Lock acquires the lock for a path and returns a function that releases it
+func (m *Manager) Lock(path string) func() {
+	key := filepath.Clean(path)
+
+	for {
+		value, _ := m.locks.LoadOrStore(key, &entry{})
+		e := value.(*entry)
+
+		e.refMu.Lock()
+		if e.dead {
+			// The entry was released and removed after we loaded it; retry with a fresh one
+			e.refMu.Unlock()
+			continue
+		}
+		e.refs++
+		e.refMu.Unlock()
+
+		e.mu.Lock()
+		return func() {
+			e.mu.Unlock()
+
+			e.refMu.Lock()
+			defer e.refMu.Unlock()
+			e.refs--
+			if e.refs == 0 {
+				e.dead = true
+				m.locks.Delete(key)
+			}
+		}
+	}
+}
+
+// LockAll acquires the locks for several paths in a consistent order to avoid
+// deadlocks and returns a function that releases them all
+func (m *Manager) LockAll(paths ...string) func() {
+	keys := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		key := filepath.Clean(path)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	unlocks := make([]func(), 0, len(keys))
+	for _, key := range keys {
+		unlocks = append(unlocks, m.Lock(key))
+	}
+
+	return func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package pathlock
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockSerializesSamePath(t *testing.T) {
+	m := New()
+
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := m.Lock("/tmp/file.txt")
+			defer unlock()
+			value := counter
+			counter = value + 1
+		}()
+	}
+	wg.Wait()
+
+	if counter != 100 {
+		t.Errorf("Expected counter to be 100, got %d", counter)
+	}
+
+	// All locks were released, so no entries should remain
+	remaining := 0
+	m.locks.Range(func(key, value interface{}) bool {
+		remaining++
+		return true
+	})
+	if remaining != 0 {
+		t.Errorf("Expected no lock entries after release, got %d", remaining)
+	}
+}
+
+func TestLockAllDeduplicatesPaths(t *testing.T) {
+	m := New()
+
+	// Locking the same path twice through LockAll must not deadlock
+	unlock := m.LockAll("/tmp/a.txt", "/tmp/b.txt", "/tmp/./a.txt")
+	unlock()
+
+	unlock = m.Lock("/tmp/a.txt")
+	unlock()
+}